@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/bbernstein/flowebb/backend-go/internal/api"
+	"github.com/bbernstein/flowebb/backend-go/internal/cache"
+	"github.com/bbernstein/flowebb/backend-go/internal/coalesce"
+	"github.com/bbernstein/flowebb/backend-go/internal/config"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/internal/station"
+	"github.com/bbernstein/flowebb/backend-go/internal/tide"
+	"github.com/bbernstein/flowebb/backend-go/pkg/http/client"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"sync"
+)
+
+// Variables exposed for testing
+var (
+	lambdaStart    = lambda.Start // Allow mocking of lambda.Start in tests
+	currentService tide.CurrentService
+	setupOnce      sync.Once
+)
+
+// initializeService is exposed for testing
+func initializeService() {
+	setupOnce.Do(func() {
+		cfg := config.LoadFromEnv()
+		cfg.InitializeLogging()
+
+		httpClient := client.New(client.Options{
+			Timeout:    cfg.HTTPTimeout,
+			MaxRetries: cfg.MaxRetries,
+			BaseURL:    cfg.NOAABaseURL,
+		})
+
+		// Currents are cached in the prediction cache layers, under their
+		// current station
+		predictionCache, err := cache.NewCacheService(context.Background(), config.GetCacheConfig())
+		if err != nil {
+			log.Fatal().Err(err).Msgf("Failed to create cache service: %v", err)
+		}
+		currentService = &tide.Service{
+			HttpClient:           httpClient,
+			CurrentStationFinder: station.NewNOAACurrentStationFinder(httpClient, nil),
+			PredictionCache:      predictionCache,
+		}
+	})
+}
+
+func init() {
+	initializeService()
+}
+
+func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	params := request.QueryStringParameters
+	log.Info().Msg("Handling currents request")
+	defer coalesce.LogStats(currentService)
+
+	var startTimeStr, endTimeStr *string
+	if str, ok := params["startDateTime"]; ok {
+		startTimeStr = &str
+	}
+	if str, ok := params["endDateTime"]; ok {
+		endTimeStr = &str
+	}
+
+	var response *models.CurrentResponse
+	var err error
+	var lat, lon float64
+
+	// Check if we're looking up by station ID or coordinates
+	if stationID, ok := params["stationId"]; ok {
+		response, err = currentService.GetCurrentsForStation(ctx, stationID, startTimeStr, endTimeStr)
+	} else if lat, lon, err = api.ParseCoordinates(params); err == nil {
+		response, err = currentService.GetCurrents(ctx, lat, lon, startTimeStr, endTimeStr)
+	} else {
+		return api.Error("Missing required parameters", http.StatusBadRequest)
+	}
+
+	if err != nil {
+		var noaaErr *tide.NoaaAPIError
+		var rangeErr *tide.InvalidRangeError
+		if errors.As(err, &noaaErr) {
+			log.Error().Err(err).Msg("Error from NOAA API")
+			return api.Error("Error fetching current data from upstream service: "+err.Error(), http.StatusBadGateway)
+		} else if errors.As(err, &rangeErr) {
+			log.Error().Err(err).Msg("Invalid range")
+			return api.Error("Invalid range: "+err.Error(), http.StatusBadRequest)
+		} else {
+			log.Error().Err(err).Msg("Error getting current data")
+			return api.Error("Error getting current data: "+err.Error(), http.StatusInternalServerError)
+		}
+	}
+
+	return api.Success(response)
+}
+
+func main() {
+	lambdaStart(handleRequest)
+}
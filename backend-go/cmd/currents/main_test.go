@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/internal/tide"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockCurrentService struct {
+	getCurrentsFn           func(ctx context.Context, lat, lon float64, startTimeStr, endTimeStr *string) (*models.CurrentResponse, error)
+	getCurrentsForStationFn func(ctx context.Context, stationID string, startTimeStr, endTimeStr *string) (*models.CurrentResponse, error)
+}
+
+func (m *mockCurrentService) GetCurrents(ctx context.Context, lat, lon float64, startTimeStr, endTimeStr *string) (*models.CurrentResponse, error) {
+	if m.getCurrentsFn != nil {
+		return m.getCurrentsFn(ctx, lat, lon, startTimeStr, endTimeStr)
+	}
+	return &models.CurrentResponse{ResponseType: "currents", StationID: "NEAREST"}, nil
+}
+
+func (m *mockCurrentService) GetCurrentsForStation(ctx context.Context, stationID string, startTimeStr, endTimeStr *string) (*models.CurrentResponse, error) {
+	if m.getCurrentsForStationFn != nil {
+		return m.getCurrentsForStationFn(ctx, stationID, startTimeStr, endTimeStr)
+	}
+	return &models.CurrentResponse{ResponseType: "currents", StationID: stationID}, nil
+}
+
+func TestHandleRequest(t *testing.T) {
+	originalService := currentService
+	defer func() { currentService = originalService }()
+
+	tests := []struct {
+		name          string
+		params        map[string]string
+		service       *mockCurrentService
+		wantCode      int
+		wantStationID string
+	}{
+		{
+			name:          "station ID",
+			params:        map[string]string{"stationId": "PUG1515"},
+			service:       &mockCurrentService{},
+			wantCode:      http.StatusOK,
+			wantStationID: "PUG1515",
+		},
+		{
+			name:          "coordinates",
+			params:        map[string]string{"lat": "47.27", "lon": "-122.55"},
+			service:       &mockCurrentService{},
+			wantCode:      http.StatusOK,
+			wantStationID: "NEAREST",
+		},
+		{
+			name: "time range is passed through",
+			params: map[string]string{
+				"stationId":     "PUG1515",
+				"startDateTime": "2024-06-01T00:00:00",
+				"endDateTime":   "2024-06-02T00:00:00",
+			},
+			service: &mockCurrentService{
+				getCurrentsForStationFn: func(_ context.Context, stationID string, startTimeStr, endTimeStr *string) (*models.CurrentResponse, error) {
+					if startTimeStr == nil || *startTimeStr != "2024-06-01T00:00:00" || endTimeStr == nil || *endTimeStr != "2024-06-02T00:00:00" {
+						return nil, fmt.Errorf("unexpected range")
+					}
+					return &models.CurrentResponse{ResponseType: "currents", StationID: stationID}, nil
+				},
+			},
+			wantCode:      http.StatusOK,
+			wantStationID: "PUG1515",
+		},
+		{
+			name:     "missing parameters",
+			params:   map[string]string{},
+			service:  &mockCurrentService{},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:   "invalid range",
+			params: map[string]string{"stationId": "PUG1515"},
+			service: &mockCurrentService{
+				getCurrentsForStationFn: func(_ context.Context, _ string, _, _ *string) (*models.CurrentResponse, error) {
+					return nil, tide.NewInvalidRangeError("date range cannot exceed 30 days")
+				},
+			},
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:   "upstream error",
+			params: map[string]string{"stationId": "PUG1515"},
+			service: &mockCurrentService{
+				getCurrentsForStationFn: func(_ context.Context, _ string, _, _ *string) (*models.CurrentResponse, error) {
+					return nil, tide.NewNoaaAPIError("No Predictions data was found", nil)
+				},
+			},
+			wantCode: http.StatusBadGateway,
+		},
+		{
+			name:   "unknown station",
+			params: map[string]string{"stationId": "NOPE"},
+			service: &mockCurrentService{
+				getCurrentsForStationFn: func(_ context.Context, stationID string, _, _ *string) (*models.CurrentResponse, error) {
+					return nil, fmt.Errorf("current station not found: %s", stationID)
+				},
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			currentService = tt.service
+
+			response, err := handleRequest(context.Background(), events.APIGatewayProxyRequest{
+				QueryStringParameters: tt.params,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCode, response.StatusCode)
+
+			if tt.wantStationID != "" {
+				var body models.CurrentResponse
+				require.NoError(t, json.Unmarshal([]byte(response.Body), &body))
+				assert.Equal(t, tt.wantStationID, body.StationID)
+				assert.Equal(t, "currents", body.ResponseType)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/bbernstein/flowebb/backend-go/graph"
+	"github.com/bbernstein/flowebb/backend-go/internal/config"
 	"github.com/bbernstein/flowebb/backend-go/internal/station"
 	"github.com/bbernstein/flowebb/backend-go/internal/tide"
 	"github.com/bbernstein/flowebb/backend-go/pkg/http/client"
@@ -24,12 +25,13 @@ var (
 )
 
 func defaultInitHandler(ctx context.Context) (*graph.Handler, error) {
+	cfg := config.LoadFromEnv()
 	httpClient := client.New(client.Options{
-		BaseURL: "https://api.tidesandcurrents.noaa.gov",
+		BaseURL: cfg.NOAABaseURL,
 		Timeout: 30 * time.Second,
 	})
 
-	stationFinder, err := finderFactory.NewFinder(httpClient, nil)
+	stationFinder, err := finderFactory.NewFinder(cfg, station.StationListCacheOptions(ctx, config.GetCacheConfig())...)
 	if err != nil {
 		return nil, fmt.Errorf("initializing station finder: %w", err)
 	}
@@ -38,10 +40,18 @@ func defaultInitHandler(ctx context.Context) (*graph.Handler, error) {
 	if err != nil {
 		return nil, fmt.Errorf("initializing tide service: %w", err)
 	}
+	tideService.PredictionFetchers = tide.NewPredictionFetchers(cfg)
+
+	currentStationFinder := station.NewNOAACurrentStationFinder(httpClient, nil)
+	tideService.CurrentStationFinder = currentStationFinder
 
 	resolver := &graph.Resolver{
-		TideService:   tideService,
-		StationFinder: stationFinder,
+		TideService:          tideService,
+		StationFinder:        stationFinder,
+		WindowService:        tideService,
+		StationMetadata:      station.NewNOAAMetadataFetcher(httpClient, nil),
+		CurrentService:       tideService,
+		CurrentStationFinder: currentStationFinder,
 	}
 
 	return graph.NewHandler(resolver, nil), nil
@@ -5,7 +5,7 @@ import (
 	"errors"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/bbernstein/flowebb/backend-go/graph"
-	"github.com/bbernstein/flowebb/backend-go/internal/cache"
+	"github.com/bbernstein/flowebb/backend-go/internal/config"
 	"github.com/bbernstein/flowebb/backend-go/internal/models"
 	"github.com/bbernstein/flowebb/backend-go/internal/station"
 	"github.com/bbernstein/flowebb/backend-go/internal/tide"
@@ -21,11 +21,11 @@ type MockService struct {
 	mock.Mock
 }
 
-func (m *MockService) GetCurrentTide(_ context.Context, _, _ float64, _, _ *string) (*models.ExtendedTideResponse, error) {
+func (m *MockService) GetCurrentTide(_ context.Context, _, _ float64, _, _ *string, _ models.Datum, _ models.Units) (*models.ExtendedTideResponse, error) {
 	panic("implement me")
 }
 
-func (m *MockService) GetCurrentTideForStation(_ context.Context, _ string, _, _ *string) (*models.ExtendedTideResponse, error) {
+func (m *MockService) GetCurrentTideForStation(_ context.Context, _ string, _, _ *string, _ models.Datum, _ models.Units) (*models.ExtendedTideResponse, error) {
 	panic("implement me")
 }
 
@@ -57,8 +57,24 @@ func (m *MockFinder) FindStation(ctx context.Context, stationID string) (*models
 	return args.Get(0).(*models.Station), args.Error(1)
 }
 
-func (m *MockFinder) FindNearestStations(ctx context.Context, lat float64, lon float64, limit int) ([]models.Station, error) {
-	args := m.Called(ctx, lat, lon, limit)
+func (m *MockFinder) FindNearestStations(ctx context.Context, lat float64, lon float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
+	args := m.Called(ctx, lat, lon, limit, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Station), args.Error(1)
+}
+
+func (m *MockFinder) FindStationsInBounds(ctx context.Context, south, west, north, east float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
+	args := m.Called(ctx, south, west, north, east, limit, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Station), args.Error(1)
+}
+
+func (m *MockFinder) SearchStations(ctx context.Context, query string, near *models.Coordinates, limit int, filter *models.StationFilter) ([]models.Station, error) {
+	args := m.Called(ctx, query, near, limit, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -77,12 +93,12 @@ type mockFinderFactory struct {
 	mock.Mock
 }
 
-func (m *mockFinderFactory) NewFinder(httpClient *client.Client, memCache *cache.StationCache) (*station.NOAAStationFinder, error) {
-	args := m.Called(httpClient, memCache)
+func (m *mockFinderFactory) NewFinder(cfg *config.Config, noaaOpts ...station.NOAAOption) (models.StationFinder, error) {
+	args := m.Called(cfg)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*station.NOAAStationFinder), args.Error(1)
+	return args.Get(0).(models.StationFinder), args.Error(1)
 }
 
 type mockTideFactory struct {
@@ -212,7 +228,7 @@ func TestInitHandler_ErrorInitializingStationFinder(t *testing.T) {
 	defer func() { finderFactory = originalFinderFactory }()
 
 	mockFinderFactory := &mockFinderFactory{}
-	mockFinderFactory.On("NewFinder", mock.Anything, mock.Anything).
+	mockFinderFactory.On("NewFinder", mock.Anything).
 		Return(nil, errors.New("mock error initializing station finder"))
 	finderFactory = mockFinderFactory
 
@@ -7,7 +7,6 @@ import (
 	"github.com/bbernstein/flowebb/backend-go/internal/config"
 	"github.com/bbernstein/flowebb/backend-go/internal/handler"
 	"github.com/bbernstein/flowebb/backend-go/internal/station"
-	"github.com/bbernstein/flowebb/backend-go/pkg/http/client"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"os"
@@ -25,12 +24,6 @@ func init() {
 		cfg := config.LoadFromEnv()
 		cfg.InitializeLogging()
 
-		httpClient := client.New(client.Options{
-			Timeout:    cfg.HTTPTimeout,
-			MaxRetries: cfg.MaxRetries,
-			BaseURL:    cfg.NOAABaseURL,
-		})
-
 		// Initialize logger
 		zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
 		levelStr := os.Getenv("LOG_LEVEL")
@@ -48,8 +41,10 @@ func init() {
 			log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stdout})
 		}
 
-		// Initialize station finder with cache
-		stationFinder, _ := station.NewNOAAStationFinder(httpClient, nil)
+		// Initialize a station finder over every configured source, keeping
+		// the NOAA station list in S3 between cold starts
+		ctx := context.Background()
+		stationFinder := station.NewDefaultStationFinder(cfg, station.StationListCacheOptions(ctx, config.GetCacheConfig())...)
 
 		// Initialize handler
 		stationsHandler = handler.NewStationsHandler(stationFinder)
@@ -18,8 +18,10 @@ import (
 
 // mockStationFinder implements model.StationFinder interface for testing
 type mockStationFinder struct {
-	findStationFn         func(ctx context.Context, stationID string) (*models.Station, error)
-	findNearestStationsFn func(ctx context.Context, lat, lon float64, limit int) ([]models.Station, error)
+	findStationFn          func(ctx context.Context, stationID string) (*models.Station, error)
+	findNearestStationsFn  func(ctx context.Context, lat, lon float64, limit int, filter *models.StationFilter) ([]models.Station, error)
+	findStationsInBoundsFn func(ctx context.Context, south, west, north, east float64, limit int, filter *models.StationFilter) ([]models.Station, error)
+	searchStationsFn       func(ctx context.Context, query string, near *models.Coordinates, limit int, filter *models.StationFilter) ([]models.Station, error)
 }
 
 func (m *mockStationFinder) FindStation(ctx context.Context, stationID string) (*models.Station, error) {
@@ -29,9 +31,23 @@ func (m *mockStationFinder) FindStation(ctx context.Context, stationID string) (
 	return nil, nil
 }
 
-func (m *mockStationFinder) FindNearestStations(ctx context.Context, lat, lon float64, limit int) ([]models.Station, error) {
+func (m *mockStationFinder) FindNearestStations(ctx context.Context, lat, lon float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
 	if m.findNearestStationsFn != nil {
-		return m.findNearestStationsFn(ctx, lat, lon, limit)
+		return m.findNearestStationsFn(ctx, lat, lon, limit, filter)
+	}
+	return nil, nil
+}
+
+func (m *mockStationFinder) FindStationsInBounds(ctx context.Context, south, west, north, east float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
+	if m.findStationsInBoundsFn != nil {
+		return m.findStationsInBoundsFn(ctx, south, west, north, east, limit, filter)
+	}
+	return nil, nil
+}
+
+func (m *mockStationFinder) SearchStations(ctx context.Context, query string, near *models.Coordinates, limit int, filter *models.StationFilter) ([]models.Station, error) {
+	if m.searchStationsFn != nil {
+		return m.searchStationsFn(ctx, query, near, limit, filter)
 	}
 	return nil, nil
 }
@@ -191,7 +207,7 @@ func TestHandleRequest(t *testing.T) {
 			},
 			setupMock: func() models.StationFinder {
 				return &mockStationFinder{
-					findNearestStationsFn: func(ctx context.Context, lat, lon float64, limit int) ([]models.Station, error) {
+					findNearestStationsFn: func(ctx context.Context, lat, lon float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
 						return []models.Station{
 							createTestStation("TEST001"),
 							createTestStation("TEST002"),
@@ -334,7 +350,7 @@ func TestErrorHandling(t *testing.T) {
 			},
 			setupMock: func() models.StationFinder {
 				return &mockStationFinder{
-					findNearestStationsFn: func(ctx context.Context, lat, lon float64, limit int) ([]models.Station, error) {
+					findNearestStationsFn: func(ctx context.Context, lat, lon float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
 						return nil, assert.AnError // Simulate internal error
 					},
 				}
@@ -3,9 +3,11 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/bbernstein/flowebb/backend-go/internal/api"
+	"github.com/bbernstein/flowebb/backend-go/internal/coalesce"
 	"github.com/bbernstein/flowebb/backend-go/internal/config"
 	"github.com/bbernstein/flowebb/backend-go/internal/models"
 	"github.com/bbernstein/flowebb/backend-go/internal/station"
@@ -15,6 +17,7 @@ import (
 	"github.com/rs/zerolog/log"
 	"net/http"
 	"os"
+	"strconv"
 	"sync"
 )
 
@@ -57,12 +60,14 @@ func initializeService() {
 			log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stdout})
 		}
 
-		stationFinder, _ := station.NewNOAAStationFinder(httpClient, nil)
+		// Keep the NOAA station list in S3 between cold starts when a bucket is configured
+		stationFinder := station.NewDefaultStationFinder(cfg, station.StationListCacheOptions(ctx, config.GetCacheConfig())...)
 
 		tideService, err = tide.NewService(ctx, httpClient, stationFinder)
 		if err != nil {
 			log.Fatal().Err(err).Msgf("Failed to create tide service: %v", err)
 		}
+		tideService.PredictionFetchers = tide.NewPredictionFetchers(cfg)
 	})
 }
 
@@ -73,6 +78,7 @@ func init() {
 func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	params := request.QueryStringParameters
 	log.Info().Msg("Handling tides request")
+	defer coalesce.LogStats(tideService)
 
 	var startTimeStr, endTimeStr *string
 	if str, ok := params["startDateTime"]; ok {
@@ -82,28 +88,52 @@ func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (
 		endTimeStr = &str
 	}
 
+	datum, err := models.ParseDatum(params["datum"])
+	if err != nil {
+		return api.Error("Invalid datum: "+err.Error(), http.StatusBadRequest)
+	}
+
+	units, err := models.ParseUnits(params["units"])
+	if err != nil {
+		return api.Error("Invalid units: "+err.Error(), http.StatusBadRequest)
+	}
+
 	var response *models.ExtendedTideResponse
-	var err error
 	var lat, lon float64
 
 	// Check if we're looking up by station ID or coordinates
 	if stationID, ok := params["stationId"]; ok {
-		response, err = tideService.GetCurrentTideForStation(ctx, stationID, startTimeStr, endTimeStr)
+		response, err = tideService.GetCurrentTideForStation(ctx, stationID, startTimeStr, endTimeStr, datum, units)
 	} else if lat, lon, err = api.ParseCoordinates(params); err == nil {
-		response, err = tideService.GetCurrentTide(ctx, lat, lon, startTimeStr, endTimeStr)
+		blend, stationCount, blendErr := parseBlend(params)
+		if blendErr != nil {
+			return api.Error("Invalid blend: "+blendErr.Error(), http.StatusBadRequest)
+		}
+		if blend {
+			response, err = tideService.GetBlendedTide(ctx, lat, lon, stationCount, startTimeStr, endTimeStr, datum, units)
+		} else {
+			response, err = tideService.GetCurrentTide(ctx, lat, lon, startTimeStr, endTimeStr, datum, units)
+		}
 	} else {
 		return api.Error("Missing required parameters", http.StatusBadRequest)
 	}
 
 	if err != nil {
 		var noaaErr *tide.NoaaAPIError
+		var sourceErr *tide.SourceAPIError
 		var rangeErr *tide.InvalidRangeError
+		var datumErr *tide.UnsupportedDatumError
 		if errors.As(err, &noaaErr) {
 			log.Error().Err(err).Msg("Error from NOAA API")
 			return api.Error("Error fetching tide data from upstream service: "+err.Error(), http.StatusBadGateway)
+		} else if errors.As(err, &sourceErr) {
+			log.Error().Err(err).Str("source", string(sourceErr.Source)).Msg("Error from source API")
+			return api.Error("Error fetching tide data from upstream service: "+err.Error(), http.StatusBadGateway)
 		} else if errors.As(err, &rangeErr) {
 			log.Error().Err(err).Msg("Invalid range")
 			return api.Error("Invalid range: "+err.Error(), http.StatusBadRequest)
+		} else if errors.As(err, &datumErr) {
+			return api.Error("Invalid datum: "+err.Error(), http.StatusBadRequest)
 		} else {
 			log.Error().Err(err).Msg("Error getting tide data")
 			return api.Error("Error getting tide data: "+err.Error(), http.StatusInternalServerError)
@@ -113,6 +143,28 @@ func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (
 	return api.Success(response)
 }
 
+// parseBlend reads whether to blend the nearest stations for coordinates
+// (blend=true) and how many to blend (stations, 0 for the default)
+func parseBlend(params map[string]string) (bool, int, error) {
+	blendStr, ok := params["blend"]
+	if !ok {
+		return false, 0, nil
+	}
+	blend, err := strconv.ParseBool(blendStr)
+	if err != nil {
+		return false, 0, fmt.Errorf("blend must be true or false, got %q", blendStr)
+	}
+
+	stationCount := 0
+	if countStr, ok := params["stations"]; ok && blend {
+		stationCount, err = strconv.Atoi(countStr)
+		if err != nil || stationCount < 1 || stationCount > tide.MaxBlendStations {
+			return false, 0, fmt.Errorf("stations must be between 1 and %d", tide.MaxBlendStations)
+		}
+	}
+	return blend, stationCount, nil
+}
+
 func main() {
 	lambdaStart(handleRequest)
 }
@@ -25,7 +25,7 @@ import (
 
 type mockCacheService struct{}
 
-func (m *mockCacheService) GetPredictions(_ context.Context, stationID string, date time.Time) (*models.TidePredictionRecord, error) {
+func (m *mockCacheService) GetPredictions(_ context.Context, stationID string, date time.Time, _ models.Datum) (*models.TidePredictionRecord, error) {
 	return &models.TidePredictionRecord{
 		StationID:   stationID,
 		Date:        date.Format("2006-01-02"),
@@ -48,7 +48,7 @@ func newMockTideService() *tide.Service {
 
 type mockStationFinder struct {
 	findStationFunc         func(ctx context.Context, stationID string) (*models.Station, error)
-	findNearestStationsFunc func(ctx context.Context, lat, lon float64, limit int) ([]models.Station, error)
+	findNearestStationsFunc func(ctx context.Context, lat, lon float64, limit int, filter *models.StationFilter) ([]models.Station, error)
 }
 
 func (m *mockStationFinder) FindStation(ctx context.Context, stationID string) (*models.Station, error) {
@@ -67,9 +67,9 @@ func (m *mockStationFinder) FindStation(ctx context.Context, stationID string) (
 	}, nil
 }
 
-func (m *mockStationFinder) FindNearestStations(ctx context.Context, lat, lon float64, limit int) ([]models.Station, error) {
+func (m *mockStationFinder) FindNearestStations(ctx context.Context, lat, lon float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
 	if m.findNearestStationsFunc != nil {
-		return m.findNearestStationsFunc(ctx, lat, lon, limit)
+		return m.findNearestStationsFunc(ctx, lat, lon, limit, filter)
 	}
 	// Default successful response instead of empty slice
 	stationType := "R"
@@ -85,6 +85,14 @@ func (m *mockStationFinder) FindNearestStations(ctx context.Context, lat, lon fl
 	}, nil
 }
 
+func (m *mockStationFinder) FindStationsInBounds(_ context.Context, _, _, _, _ float64, _ int, _ *models.StationFilter) ([]models.Station, error) {
+	return nil, nil
+}
+
+func (m *mockStationFinder) SearchStations(_ context.Context, _ string, _ *models.Coordinates, _ int, _ *models.StationFilter) ([]models.Station, error) {
+	return nil, nil
+}
+
 func TestHandleRequest(t *testing.T) {
 	// Replace the real tide service with our mock
 	originalTideService := tideService
@@ -121,6 +129,102 @@ func TestHandleRequest(t *testing.T) {
 			},
 			expectedCode: http.StatusOK,
 		},
+		{
+			name: "valid datum request",
+			request: events.APIGatewayProxyRequest{
+				QueryStringParameters: map[string]string{
+					"stationId": "1234567",
+					"datum":     "mhhw",
+				},
+			},
+			setupMock: func() *tide.Service {
+				return newMockTideService()
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "invalid datum request",
+			request: events.APIGatewayProxyRequest{
+				QueryStringParameters: map[string]string{
+					"stationId": "1234567",
+					"datum":     "WGS84",
+				},
+			},
+			setupMock: func() *tide.Service {
+				return newMockTideService()
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "metric units request",
+			request: events.APIGatewayProxyRequest{
+				QueryStringParameters: map[string]string{
+					"stationId": "1234567",
+					"units":     "meters",
+				},
+			},
+			setupMock: func() *tide.Service {
+				return newMockTideService()
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "invalid units request",
+			request: events.APIGatewayProxyRequest{
+				QueryStringParameters: map[string]string{
+					"stationId": "1234567",
+					"units":     "fathoms",
+				},
+			},
+			setupMock: func() *tide.Service {
+				return newMockTideService()
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "blended coordinates request",
+			request: events.APIGatewayProxyRequest{
+				QueryStringParameters: map[string]string{
+					"lat":      "42.0",
+					"lon":      "-70.0",
+					"blend":    "true",
+					"stations": "2",
+				},
+			},
+			setupMock: func() *tide.Service {
+				return newMockTideService()
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "invalid blend request",
+			request: events.APIGatewayProxyRequest{
+				QueryStringParameters: map[string]string{
+					"lat":   "42.0",
+					"lon":   "-70.0",
+					"blend": "sometimes",
+				},
+			},
+			setupMock: func() *tide.Service {
+				return newMockTideService()
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "too many stations to blend",
+			request: events.APIGatewayProxyRequest{
+				QueryStringParameters: map[string]string{
+					"lat":      "42.0",
+					"lon":      "-70.0",
+					"blend":    "true",
+					"stations": "50",
+				},
+			},
+			setupMock: func() *tide.Service {
+				return newMockTideService()
+			},
+			expectedCode: http.StatusBadRequest,
+		},
 		// ... other test cases remain the same
 	}
 
@@ -322,7 +426,7 @@ type mockCacheService2 struct {
 	savePredictionsBatchFn func(ctx context.Context, records []models.TidePredictionRecord) error
 }
 
-func (m *mockCacheService2) GetPredictions(ctx context.Context, stationID string, date time.Time) (*models.TidePredictionRecord, error) {
+func (m *mockCacheService2) GetPredictions(ctx context.Context, stationID string, date time.Time, _ models.Datum) (*models.TidePredictionRecord, error) {
 	if m.getPredictionsFn != nil {
 		return m.getPredictionsFn(ctx, stationID, date)
 	}
@@ -420,6 +524,41 @@ func TestNoaaAPIErrorHandling(t *testing.T) {
 			expectedStatus: http.StatusInternalServerError,
 			expectedError:  "Error getting tide data",
 		},
+		{
+			name: "datum the source doesn't publish",
+			request: events.APIGatewayProxyRequest{
+				QueryStringParameters: map[string]string{
+					"stationId": "CHS-07795",
+					"datum":     "NAVD88",
+				},
+			},
+			setupMock: func() *tide.Service {
+				mockFinder := &mockStationFinder{
+					findStationFunc: func(ctx context.Context, stationID string) (*models.Station, error) {
+						stationType := "R"
+						return &models.Station{
+							ID:             stationID,
+							Name:           "Point Atkinson",
+							Latitude:       49.337,
+							Longitude:      -123.253,
+							Source:         models.SourceCHS,
+							TimeZoneOffset: -28800,
+							StationType:    &stationType,
+						}, nil
+					},
+				}
+				return &tide.Service{
+					HttpClient:      &client.Client{},
+					StationFinder:   mockFinder,
+					PredictionCache: &mockCacheService2{},
+					PredictionFetchers: map[models.Source]tide.PredictionFetcher{
+						models.SourceCHS: tide.NewCHSPredictionFetcher(&client.Client{}),
+					},
+				}
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "CHS predictions are not available relative to NAVD88",
+		},
 	}
 
 	for _, tt := range tests {
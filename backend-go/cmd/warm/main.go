@@ -0,0 +1,283 @@
+// Command warm fills the DynamoDB prediction cache for popular stations
+// ahead of their first requests, so nobody waits on NOAA for them. It runs as
+// a scheduled Lambda, taking a warmRequest as its event, or from the command
+// line with the same settings as flags. Settings left out come from the
+// WARM_* environment variables.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/bbernstein/flowebb/backend-go/internal/cache"
+	"github.com/bbernstein/flowebb/backend-go/internal/coalesce"
+	"github.com/bbernstein/flowebb/backend-go/internal/config"
+	"github.com/bbernstein/flowebb/backend-go/internal/station"
+	"github.com/bbernstein/flowebb/backend-go/internal/tide"
+	"github.com/bbernstein/flowebb/backend-go/pkg/http/client"
+	"github.com/rs/zerolog/log"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// predictionWarmer warms the prediction cache for a station
+type predictionWarmer interface {
+	WarmPredictions(ctx context.Context, stationID string, days int) error
+}
+
+// stationRanker ranks stations by how often they're requested
+type stationRanker interface {
+	TopStations(ctx context.Context, n int) ([]cache.StationRequestCount, error)
+}
+
+// Variables exposed for testing
+var (
+	lambdaStart   = lambda.Start // Allow mocking of lambda.Start in tests
+	tideService   predictionWarmer
+	requestCounts stationRanker
+	warmConfig    *config.WarmConfig
+	setupOnce     sync.Once
+)
+
+// initializeService is exposed for testing
+func initializeService() {
+	setupOnce.Do(func() {
+		cfg := config.LoadFromEnv()
+		cfg.InitializeLogging()
+		cacheConfig := config.GetCacheConfig()
+		warmConfig = config.GetWarmConfig()
+
+		ctx := context.Background()
+		// NOAA is asked no faster than the configured rate, however many
+		// stations are warmed at once
+		httpClient := client.New(client.Options{
+			Timeout:           cfg.HTTPTimeout,
+			MaxRetries:        cfg.MaxRetries,
+			BaseURL:           cfg.NOAABaseURL,
+			RequestsPerSecond: warmConfig.NOAARequestsPerSecond,
+		})
+
+		dynamoClient, err := cache.NewDynamoClient(ctx)
+		if err != nil {
+			log.Fatal().Err(err).Msgf("Failed to create DynamoDB client: %v", err)
+		}
+		requestCounts = cache.NewDynamoRequestCounts(dynamoClient, cacheConfig)
+
+		stationFinder := station.NewDefaultStationFinder(cfg, station.StationListCacheOptions(ctx, cacheConfig)...)
+
+		// Predictions are saved straight to DynamoDB, as an in-memory cache
+		// ends with the run. Warming isn't counted as requests.
+		service, err := tide.NewService(ctx, httpClient, stationFinder,
+			tide.WithPredictionCache(cache.NewDynamoPredictionCache(dynamoClient, cacheConfig)),
+			tide.WithRequestCounter(cache.NoopRequestCounter{}))
+		if err != nil {
+			log.Fatal().Err(err).Msgf("Failed to create tide service: %v", err)
+		}
+		service.PredictionFetchers = tide.NewPredictionFetchers(cfg)
+		tideService = service
+	})
+}
+
+func init() {
+	initializeService()
+}
+
+// warmRequest says which stations to warm and how. Fields left out take the
+// warm config's values.
+type warmRequest struct {
+	// Stations to warm, in addition to the most requested ones
+	StationIDs []string `json:"stationIds,omitempty"`
+	// Number of most requested stations to warm, 0 for none
+	Top *int `json:"top,omitempty"`
+	// Days of predictions to warm, from today
+	Days int `json:"days,omitempty"`
+	// Stations warmed at once
+	Concurrency int `json:"concurrency,omitempty"`
+}
+
+// warmResult lists the stations warmed, and the error for each that failed
+type warmResult struct {
+	Warmed []string          `json:"warmed"`
+	Failed map[string]string `json:"failed,omitempty"`
+}
+
+func (r warmRequest) withDefaults(cfg *config.WarmConfig) warmRequest {
+	if len(r.StationIDs) == 0 {
+		r.StationIDs = cfg.StationIDs
+	}
+	if r.Top == nil {
+		top := cfg.TopStations
+		r.Top = &top
+	}
+	if r.Days == 0 {
+		r.Days = cfg.Days
+	}
+	if r.Concurrency <= 0 {
+		r.Concurrency = cfg.Concurrency
+	}
+	if r.Concurrency <= 0 {
+		r.Concurrency = 1
+	}
+	return r
+}
+
+func handleRequest(ctx context.Context, request warmRequest) (*warmResult, error) {
+	request = request.withDefaults(warmConfig)
+	if request.Days < 1 || request.Days > tide.MaxWarmDays {
+		return nil, fmt.Errorf("days must be between 1 and %d", tide.MaxWarmDays)
+	}
+	if *request.Top < 0 {
+		return nil, fmt.Errorf("top must not be negative")
+	}
+
+	stationIDs, err := stationsToWarm(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if len(stationIDs) == 0 {
+		return nil, fmt.Errorf("no stations to warm")
+	}
+
+	log.Info().
+		Int("stations", len(stationIDs)).
+		Int("days", request.Days).
+		Int("concurrency", request.Concurrency).
+		Msg("Warming prediction cache")
+
+	result := warmStations(ctx, stationIDs, request.Days, request.Concurrency)
+
+	log.Info().
+		Int("warmed", len(result.Warmed)).
+		Int("failed", len(result.Failed)).
+		Msg("Warmed prediction cache")
+	coalesce.LogStats(tideService)
+
+	// A scheduled run is only failed when nothing could be warmed, as when
+	// NOAA is down, so partial runs aren't retried from the start
+	if len(result.Warmed) == 0 {
+		return result, fmt.Errorf("warming all %d stations failed", len(stationIDs))
+	}
+	return result, nil
+}
+
+// stationsToWarm returns the requested stations followed by the most
+// requested ones, each once
+func stationsToWarm(ctx context.Context, request warmRequest) ([]string, error) {
+	stationIDs := make([]string, 0, len(request.StationIDs)+*request.Top)
+	seen := make(map[string]bool)
+	add := func(id string) {
+		if !seen[id] {
+			seen[id] = true
+			stationIDs = append(stationIDs, id)
+		}
+	}
+
+	for _, id := range request.StationIDs {
+		add(id)
+	}
+
+	if *request.Top > 0 {
+		top, err := requestCounts.TopStations(ctx, *request.Top)
+		if err != nil {
+			return nil, fmt.Errorf("getting most requested stations: %w", err)
+		}
+		for _, count := range top {
+			add(count.StationID)
+		}
+	}
+
+	return stationIDs, nil
+}
+
+// warmStations warms the stations, no more than concurrency at once
+func warmStations(ctx context.Context, stationIDs []string, days, concurrency int) *warmResult {
+	errs := make([]error, len(stationIDs))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				errs[i] = tideService.WarmPredictions(ctx, stationIDs[i], days)
+			}
+		}()
+	}
+	for i := range stationIDs {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	result := &warmResult{Warmed: make([]string, 0, len(stationIDs))}
+	for i, err := range errs {
+		if err != nil {
+			log.Warn().Err(err).
+				Str("station_id", stationIDs[i]).
+				Msg("Error warming predictions")
+			if result.Failed == nil {
+				result.Failed = make(map[string]string)
+			}
+			result.Failed[stationIDs[i]] = err.Error()
+			continue
+		}
+		result.Warmed = append(result.Warmed, stationIDs[i])
+	}
+	return result
+}
+
+// runCLI warms the stations the flags ask for and writes the result to out
+// as JSON
+func runCLI(ctx context.Context, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("warm", flag.ContinueOnError)
+	stations := flags.String("stations", "", "Comma-separated station IDs to warm")
+	top := flags.Int("top", 0, "Number of most requested stations to warm, 0 for none (default WARM_TOP_STATIONS)")
+	days := flags.Int("days", 0, "Days of predictions to warm, from today (default WARM_DAYS)")
+	concurrency := flags.Int("concurrency", 0, "Stations warmed at once (default WARM_CONCURRENCY)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	request := warmRequest{
+		StationIDs: strings.FieldsFunc(*stations, func(r rune) bool {
+			return r == ',' || unicode.IsSpace(r)
+		}),
+		Days:        *days,
+		Concurrency: *concurrency,
+	}
+	// -top 0 warms none of the most requested stations, so the config's
+	// number is only used when -top isn't given
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "top" {
+			request.Top = top
+		}
+	})
+
+	result, err := handleRequest(ctx, request)
+	if result != nil {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if encodeErr := encoder.Encode(result); encodeErr != nil && err == nil {
+			err = encodeErr
+		}
+	}
+	return err
+}
+
+func main() {
+	// Lambda's runtime API is only set for functions it runs
+	if os.Getenv("AWS_LAMBDA_RUNTIME_API") != "" {
+		lambdaStart(handleRequest)
+		return
+	}
+
+	if err := runCLI(context.Background(), os.Args[1:], os.Stdout); err != nil {
+		log.Error().Err(err).Msg("Warming prediction cache failed")
+		os.Exit(1)
+	}
+}
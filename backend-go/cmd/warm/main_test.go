@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bbernstein/flowebb/backend-go/internal/cache"
+	"github.com/bbernstein/flowebb/backend-go/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockWarmer records the stations warmed, failing those listed
+type mockWarmer struct {
+	mu       sync.Mutex
+	failing  map[string]bool
+	warmed   []string
+	days     int
+	inFlight int
+	most     int
+}
+
+func (m *mockWarmer) WarmPredictions(ctx context.Context, stationID string, days int) error {
+	m.mu.Lock()
+	m.inFlight++
+	if m.inFlight > m.most {
+		m.most = m.inFlight
+	}
+	m.days = days
+	m.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight--
+	if m.failing[stationID] {
+		return fmt.Errorf("NOAA API error: station %s", stationID)
+	}
+	m.warmed = append(m.warmed, stationID)
+	return nil
+}
+
+type mockRanker struct {
+	counts []cache.StationRequestCount
+	err    error
+}
+
+func (m *mockRanker) TopStations(ctx context.Context, n int) ([]cache.StationRequestCount, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if n < len(m.counts) {
+		return m.counts[:n], nil
+	}
+	return m.counts, nil
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+// useMocks swaps in the mocks and warm config for a test
+func useMocks(t *testing.T, warmer *mockWarmer, ranker *mockRanker, cfg *config.WarmConfig) {
+	originalService, originalCounts, originalConfig := tideService, requestCounts, warmConfig
+	tideService, requestCounts, warmConfig = warmer, ranker, cfg
+	t.Cleanup(func() {
+		tideService, requestCounts, warmConfig = originalService, originalCounts, originalConfig
+	})
+}
+
+func TestHandleRequest(t *testing.T) {
+	ranker := &mockRanker{counts: []cache.StationRequestCount{
+		{StationID: "9414290", Requests: 40},
+		{StationID: "9447130", Requests: 25},
+		{StationID: "8443970", Requests: 12},
+	}}
+
+	tests := []struct {
+		name       string
+		request    warmRequest
+		config     config.WarmConfig
+		failing    map[string]bool
+		ranker     *mockRanker
+		wantWarmed []string
+		wantFailed []string
+		wantDays   int
+		wantErr    string
+	}{
+		{
+			name:       "listed stations",
+			request:    warmRequest{StationIDs: []string{"9447130", "CHS-07795"}, Days: 3},
+			config:     config.WarmConfig{Days: 7, Concurrency: 2},
+			wantWarmed: []string{"9447130", "CHS-07795"},
+			wantDays:   3,
+		},
+		{
+			name:       "most requested stations after listed ones, each once",
+			request:    warmRequest{StationIDs: []string{"9447130"}, Top: intPtr(2)},
+			config:     config.WarmConfig{Days: 7, Concurrency: 2},
+			wantWarmed: []string{"9447130", "9414290"},
+			wantDays:   7,
+		},
+		{
+			name:       "none of the most requested stations",
+			request:    warmRequest{StationIDs: []string{"9447130"}, Top: intPtr(0)},
+			config:     config.WarmConfig{TopStations: 2, Days: 7, Concurrency: 2},
+			wantWarmed: []string{"9447130"},
+			wantDays:   7,
+		},
+		{
+			name:       "defaults from the config",
+			config:     config.WarmConfig{StationIDs: []string{"UKHO-0014"}, TopStations: 1, Days: 5, Concurrency: 2},
+			wantWarmed: []string{"UKHO-0014", "9414290"},
+			wantDays:   5,
+		},
+		{
+			name:       "failed stations are reported",
+			request:    warmRequest{Top: intPtr(3)},
+			config:     config.WarmConfig{Days: 7, Concurrency: 2},
+			failing:    map[string]bool{"9447130": true},
+			wantWarmed: []string{"9414290", "8443970"},
+			wantFailed: []string{"9447130"},
+			wantDays:   7,
+		},
+		{
+			name:    "every station failing fails the run",
+			request: warmRequest{StationIDs: []string{"9447130"}},
+			config:  config.WarmConfig{Days: 7, Concurrency: 2},
+			failing: map[string]bool{"9447130": true},
+			wantErr: "warming all 1 stations failed",
+		},
+		{
+			name:    "no stations",
+			config:  config.WarmConfig{Days: 7, Concurrency: 2},
+			wantErr: "no stations to warm",
+		},
+		{
+			name:    "too many days",
+			request: warmRequest{StationIDs: []string{"9447130"}, Days: 31},
+			config:  config.WarmConfig{Days: 7, Concurrency: 2},
+			wantErr: "days must be between 1 and 30",
+		},
+		{
+			name:    "negative top",
+			request: warmRequest{StationIDs: []string{"9447130"}, Top: intPtr(-1)},
+			config:  config.WarmConfig{Days: 7, Concurrency: 2},
+			wantErr: "top must not be negative",
+		},
+		{
+			name:    "request counts unavailable",
+			request: warmRequest{Top: intPtr(10)},
+			config:  config.WarmConfig{Days: 7, Concurrency: 2},
+			ranker:  &mockRanker{err: errors.New("throttled")},
+			wantErr: "getting most requested stations: throttled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warmer := &mockWarmer{failing: tt.failing}
+			testRanker := tt.ranker
+			if testRanker == nil {
+				testRanker = ranker
+			}
+			cfg := tt.config
+			useMocks(t, warmer, testRanker, &cfg)
+
+			result, err := handleRequest(context.Background(), tt.request)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantWarmed, result.Warmed)
+			assert.ElementsMatch(t, tt.wantWarmed, warmer.warmed)
+			assert.Equal(t, tt.wantDays, warmer.days)
+			failed := make([]string, 0, len(result.Failed))
+			for id := range result.Failed {
+				failed = append(failed, id)
+			}
+			assert.ElementsMatch(t, tt.wantFailed, failed)
+		})
+	}
+}
+
+func TestWarmStations_BoundedConcurrency(t *testing.T) {
+	warmer := &mockWarmer{}
+	useMocks(t, warmer, &mockRanker{}, &config.WarmConfig{})
+
+	stationIDs := make([]string, 12)
+	for i := range stationIDs {
+		stationIDs[i] = fmt.Sprintf("STATION%02d", i)
+	}
+
+	result := warmStations(context.Background(), stationIDs, 7, 3)
+
+	assert.Equal(t, stationIDs, result.Warmed, "in the order given")
+	assert.LessOrEqual(t, warmer.most, 3)
+	assert.Greater(t, warmer.most, 1, "stations are warmed at once")
+}
+
+func TestRunCLI(t *testing.T) {
+	warmer := &mockWarmer{}
+	useMocks(t, warmer, &mockRanker{counts: []cache.StationRequestCount{{StationID: "9414290", Requests: 40}}},
+		&config.WarmConfig{Days: 7, Concurrency: 4})
+
+	var out bytes.Buffer
+	err := runCLI(context.Background(), []string{"-stations", "9447130, CHS-07795", "-top", "1", "-days", "2"}, &out)
+	require.NoError(t, err)
+
+	var result warmResult
+	require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	assert.Equal(t, []string{"9447130", "CHS-07795", "9414290"}, result.Warmed)
+	assert.Empty(t, result.Failed)
+	assert.Equal(t, 2, warmer.days)
+
+	// -top 0 leaves out the most requested stations, which the config would add
+	warmConfig.TopStations = 1
+	out.Reset()
+	require.NoError(t, runCLI(context.Background(), []string{"-stations", "9447130", "-top", "0"}, &out))
+	require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	assert.Equal(t, []string{"9447130"}, result.Warmed)
+
+	out.Reset()
+	require.NoError(t, runCLI(context.Background(), []string{"-stations", "9447130"}, &out))
+	require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	assert.Equal(t, []string{"9447130", "9414290"}, result.Warmed)
+
+	assert.Error(t, runCLI(context.Background(), []string{"-days", "many"}, &out))
+}
+
+func TestLambdaMain(t *testing.T) {
+	t.Setenv("AWS_LAMBDA_RUNTIME_API", "localhost")
+
+	originalStartFn := lambdaStart
+	defer func() { lambdaStart = originalStartFn }()
+
+	var handler interface{}
+	lambdaStart = func(h interface{}) {
+		handler = h
+	}
+
+	main()
+
+	require.NotNil(t, handler, "runs as a Lambda")
+	_, ok := handler.(func(context.Context, warmRequest) (*warmResult, error))
+	assert.True(t, ok, "handler takes a warm request")
+}
@@ -0,0 +1,228 @@
+package graph
+
+import (
+	"strings"
+
+	"github.com/bbernstein/flowebb/backend-go/graph/model"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+)
+
+// toStationModels converts internal stations to GraphQL stations
+func toStationModels(stations []models.Station) []*model.Station {
+	result := make([]*model.Station, len(stations))
+	for i, s := range stations {
+		result[i] = &model.Station{
+			ID:             s.ID,
+			Name:           s.Name,
+			State:          s.State,
+			Region:         s.Region,
+			Distance:       s.Distance,
+			Latitude:       s.Latitude,
+			Longitude:      s.Longitude,
+			Source:         string(s.Source),
+			Capabilities:   s.Capabilities,
+			TimeZoneOffset: s.TimeZoneOffset,
+			TimeZone:       optionalString(s.TimeZone),
+		}
+	}
+	return result
+}
+
+// toStationDetailsModel converts the station's own fields. Its datums, sensors
+// and the rest are resolved separately, only when a query asks for them.
+func toStationDetailsModel(s *models.Station) *model.StationDetails {
+	details := &model.StationDetails{
+		ID:             s.ID,
+		Name:           s.Name,
+		State:          s.State,
+		Region:         s.Region,
+		Latitude:       s.Latitude,
+		Longitude:      s.Longitude,
+		Source:         string(s.Source),
+		Capabilities:   s.Capabilities,
+		TimeZoneOffset: s.TimeZoneOffset,
+		TimeZone:       optionalString(s.TimeZone),
+	}
+	if s.StationType != nil {
+		switch *s.StationType {
+		case models.StationTypeReference:
+			stationType := model.StationTypeReference
+			details.StationType = &stationType
+		case models.StationTypeSubordinate:
+			stationType := model.StationTypeSubordinate
+			details.StationType = &stationType
+		}
+	}
+	return details
+}
+
+func toStationDatumsModel(d *models.StationDatums) *model.StationDatums {
+	datums := make([]*model.DatumValue, len(d.Datums))
+	for i, datum := range d.Datums {
+		datums[i] = &model.DatumValue{
+			Name:        datum.Name,
+			Description: optionalString(datum.Description),
+			Value:       datum.Value,
+		}
+	}
+	return &model.StationDatums{
+		Epoch:            optionalString(d.Epoch),
+		Units:            d.Units,
+		OrthometricDatum: optionalString(d.OrthometricDatum),
+		Datums:           datums,
+	}
+}
+
+// toStationFilter converts a GraphQL station filter, returning nil when there
+// is none
+func toStationFilter(f *model.StationFilterInput) *models.StationFilter {
+	if f == nil {
+		return nil
+	}
+
+	filter := &models.StationFilter{
+		Capabilities:    f.Capabilities,
+		HasObservations: f.HasObservations != nil && *f.HasObservations,
+	}
+	if f.StationType != nil {
+		switch *f.StationType {
+		case model.StationTypeReference:
+			filter.StationType = models.StationTypeReference
+		case model.StationTypeSubordinate:
+			filter.StationType = models.StationTypeSubordinate
+		}
+	}
+	if f.State != nil {
+		filter.State = *f.State
+	}
+	if f.Region != nil {
+		filter.Region = *f.Region
+	}
+	if f.Source != nil {
+		filter.Source = models.Source(strings.ToUpper(*f.Source))
+	}
+	return filter
+}
+
+// optionalString maps an empty string to null
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func toCurrentPredictionModel(p models.CurrentPrediction) *model.CurrentPrediction {
+	return &model.CurrentPrediction{
+		Timestamp: int(p.Timestamp),
+		LocalTime: p.LocalTime,
+		Velocity:  p.Velocity,
+		Speed:     p.Speed,
+		Direction: p.Direction,
+		State:     model.CurrentState(p.State),
+	}
+}
+
+// toDataFreshnessModel converts prediction freshness, returning nil when
+// it's unknown
+func toDataFreshnessModel(f *models.DataFreshness) *model.DataFreshness {
+	if f == nil {
+		return nil
+	}
+	return &model.DataFreshness{
+		Status:     model.FreshnessStatus(f.Status),
+		UpdatedAt:  int(f.UpdatedAt),
+		AgeSeconds: int(f.AgeSeconds),
+	}
+}
+
+// toConditionsModel converts met readings, returning nil when there are none
+func toConditionsModel(c *models.MarineConditions) *model.Conditions {
+	if c == nil {
+		return nil
+	}
+
+	conditions := &model.Conditions{
+		AirPressure:      toMetReadingModel(c.AirPressure),
+		WaterTemperature: toMetReadingModel(c.WaterTemperature),
+		AirTemperature:   toMetReadingModel(c.AirTemperature),
+	}
+	if c.Wind != nil {
+		conditions.Wind = &model.Wind{
+			Timestamp:         int(c.Wind.Timestamp),
+			Speed:             c.Wind.Speed,
+			Gust:              c.Wind.Gust,
+			Direction:         c.Wind.Direction,
+			CardinalDirection: c.Wind.CardinalDirection,
+			Units:             c.Wind.Units,
+		}
+	}
+	return conditions
+}
+
+func toMetReadingModel(r *models.MetReading) *model.MetReading {
+	if r == nil {
+		return nil
+	}
+	return &model.MetReading{
+		Timestamp: int(r.Timestamp),
+		Value:     r.Value,
+		Units:     r.Units,
+	}
+}
+
+func toAstronomyModels(days []models.AstronomyDay) []*model.AstronomyDay {
+	result := make([]*model.AstronomyDay, len(days))
+	for i, d := range days {
+		result[i] = &model.AstronomyDay{
+			Date:             d.Date,
+			CivilDawn:        toAstronomicalTimeModel(d.CivilDawn),
+			Sunrise:          toAstronomicalTimeModel(d.Sunrise),
+			Sunset:           toAstronomicalTimeModel(d.Sunset),
+			CivilDusk:        toAstronomicalTimeModel(d.CivilDusk),
+			Moonrise:         toAstronomicalTimeModel(d.Moonrise),
+			Moonset:          toAstronomicalTimeModel(d.Moonset),
+			MoonPhase:        model.MoonPhase(d.MoonPhase),
+			MoonIllumination: d.MoonIllumination,
+		}
+	}
+	return result
+}
+
+func toAstronomicalTimeModel(t *models.AstronomicalTime) *model.AstronomicalTime {
+	if t == nil {
+		return nil
+	}
+	return &model.AstronomicalTime{
+		Timestamp: int(t.Timestamp),
+		LocalTime: t.LocalTime,
+	}
+}
+
+func toTideDaySummaryModels(days []models.TideDaySummary) []*model.TideDaySummary {
+	result := make([]*model.TideDaySummary, len(days))
+	for i, d := range days {
+		result[i] = &model.TideDaySummary{
+			Date:            d.Date,
+			HigherHigh:      toTideExtremeModel(d.HigherHigh),
+			LowerLow:        toTideExtremeModel(d.LowerLow),
+			Range:           d.Range,
+			MeanLevel:       d.MeanLevel,
+			RangePercentile: d.RangePercentile,
+			SpringNeap:      model.SpringNeap(d.SpringNeap),
+		}
+	}
+	return result
+}
+
+func toTideExtremeModel(e *models.TideExtreme) *model.TideExtreme {
+	if e == nil {
+		return nil
+	}
+	return &model.TideExtreme{
+		Type:      string(e.Type),
+		Timestamp: int(e.Timestamp),
+		LocalTime: e.LocalTime,
+		Height:    e.Height,
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/bbernstein/flowebb/backend-go/graph/generated"
+	"github.com/bbernstein/flowebb/backend-go/internal/coalesce"
 	"github.com/rs/zerolog/log"
 	"net/http"
 )
@@ -18,6 +19,7 @@ type RequestCreator func(ctx context.Context, method, url string, body *bytes.Bu
 type Handler struct {
 	srv            *handler.Server
 	requestCreator RequestCreator
+	resolver       *Resolver
 }
 
 func defaultRequestCreator(ctx context.Context, method, url string, body *bytes.Buffer) (*http.Request, error) {
@@ -54,6 +56,7 @@ func NewHandler(resolver *Resolver, requestCreator RequestCreator) *Handler {
 	return &Handler{
 		srv:            srv,
 		requestCreator: requestCreator,
+		resolver:       resolver,
 	}
 }
 
@@ -61,6 +64,9 @@ func (h *Handler) HandleRequest(ctx context.Context, event events.APIGatewayProx
 	if event.HTTPMethod == "" {
 		event.HTTPMethod = "POST"
 	}
+	if h.resolver != nil {
+		defer coalesce.LogStats(h.resolver.TideService, h.resolver.CurrentService)
+	}
 	if event.HTTPMethod != "POST" {
 		return events.APIGatewayProxyResponse{
 			StatusCode: http.StatusMethodNotAllowed,
@@ -13,10 +13,10 @@ import (
 )
 
 type mockTideService struct {
-	getCurrentTideForStationFn func(ctx context.Context, stationID string, startTimeStr, endTimeStr *string) (*models.ExtendedTideResponse, error)
+	getCurrentTideForStationFn func(ctx context.Context, stationID string, startTimeStr, endTimeStr *string, datum models.Datum, units models.Units) (*models.ExtendedTideResponse, error)
 }
 
-func (m *mockTideService) GetCurrentTide(_ context.Context, _, _ float64, _, _ *string) (*models.ExtendedTideResponse, error) {
+func (m *mockTideService) GetCurrentTide(_ context.Context, _, _ float64, _, _ *string, _ models.Datum, _ models.Units) (*models.ExtendedTideResponse, error) {
 	waterLevel := 1.5
 	return &models.ExtendedTideResponse{
 		NearestStation: "TEST001",
@@ -24,16 +24,18 @@ func (m *mockTideService) GetCurrentTide(_ context.Context, _, _ float64, _, _ *
 	}, nil
 }
 
-func (m *mockTideService) GetCurrentTideForStation(ctx context.Context, stationID string, startTimeStr, endTimeStr *string) (*models.ExtendedTideResponse, error) {
+func (m *mockTideService) GetCurrentTideForStation(ctx context.Context, stationID string, startTimeStr, endTimeStr *string, datum models.Datum, units models.Units) (*models.ExtendedTideResponse, error) {
 	if m.getCurrentTideForStationFn != nil {
-		return m.getCurrentTideForStationFn(ctx, stationID, startTimeStr, endTimeStr)
+		return m.getCurrentTideForStationFn(ctx, stationID, startTimeStr, endTimeStr, datum, units)
 	}
 	return nil, nil
 }
 
 type mockStationFinder struct {
-	findStationFn         func(ctx context.Context, stationID string) (*models.Station, error)
-	findNearestStationsFn func(ctx context.Context, lat, lon float64, limit int) ([]models.Station, error)
+	findStationFn          func(ctx context.Context, stationID string) (*models.Station, error)
+	findNearestStationsFn  func(ctx context.Context, lat, lon float64, limit int, filter *models.StationFilter) ([]models.Station, error)
+	findStationsInBoundsFn func(ctx context.Context, south, west, north, east float64, limit int, filter *models.StationFilter) ([]models.Station, error)
+	searchStationsFn       func(ctx context.Context, query string, near *models.Coordinates, limit int, filter *models.StationFilter) ([]models.Station, error)
 }
 
 func (m *mockStationFinder) FindStation(ctx context.Context, stationID string) (*models.Station, error) {
@@ -43,9 +45,23 @@ func (m *mockStationFinder) FindStation(ctx context.Context, stationID string) (
 	return nil, nil
 }
 
-func (m *mockStationFinder) FindNearestStations(ctx context.Context, lat, lon float64, limit int) ([]models.Station, error) {
+func (m *mockStationFinder) FindNearestStations(ctx context.Context, lat, lon float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
 	if m.findNearestStationsFn != nil {
-		return m.findNearestStationsFn(ctx, lat, lon, limit)
+		return m.findNearestStationsFn(ctx, lat, lon, limit, filter)
+	}
+	return nil, nil
+}
+
+func (m *mockStationFinder) FindStationsInBounds(ctx context.Context, south, west, north, east float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
+	if m.findStationsInBoundsFn != nil {
+		return m.findStationsInBoundsFn(ctx, south, west, north, east, limit, filter)
+	}
+	return nil, nil
+}
+
+func (m *mockStationFinder) SearchStations(ctx context.Context, query string, near *models.Coordinates, limit int, filter *models.StationFilter) ([]models.Station, error) {
+	if m.searchStationsFn != nil {
+		return m.searchStationsFn(ctx, query, near, limit, filter)
 	}
 	return nil, nil
 }
@@ -67,7 +83,7 @@ func TestHandler_HandleRequest(t *testing.T) {
 			setupMock: func() *Resolver {
 				return &Resolver{
 					StationFinder: &mockStationFinder{
-						findNearestStationsFn: func(ctx context.Context, lat, lon float64, limit int) ([]models.Station, error) {
+						findNearestStationsFn: func(ctx context.Context, lat, lon float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
 							return []models.Station{
 								{
 									ID:        "TEST001",
@@ -97,7 +113,7 @@ func TestHandler_HandleRequest(t *testing.T) {
 			setupMock: func() *Resolver {
 				return &Resolver{
 					StationFinder: &mockStationFinder{
-						findNearestStationsFn: func(ctx context.Context, lat, lon float64, limit int) ([]models.Station, error) {
+						findNearestStationsFn: func(ctx context.Context, lat, lon float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
 							return []models.Station{
 								{
 									ID:        "TEST001",
@@ -132,7 +148,7 @@ func TestHandler_HandleRequest(t *testing.T) {
 			setupMock: func() *Resolver {
 				return &Resolver{
 					StationFinder: &mockStationFinder{
-						findNearestStationsFn: func(ctx context.Context, lat, lon float64, limit int) ([]models.Station, error) {
+						findNearestStationsFn: func(ctx context.Context, lat, lon float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
 							return nil, errors.New("mock error")
 						},
 					},
@@ -149,7 +165,7 @@ func TestHandler_HandleRequest(t *testing.T) {
 			setupMock: func() *Resolver {
 				return &Resolver{
 					StationFinder: &mockStationFinder{
-						findNearestStationsFn: func(ctx context.Context, lat, lon float64, limit int) ([]models.Station, error) {
+						findNearestStationsFn: func(ctx context.Context, lat, lon float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
 							return nil, errors.New("mock error")
 						},
 					},
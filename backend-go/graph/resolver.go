@@ -9,6 +9,14 @@ import (
 type Resolver struct {
 	TideService   tide.TideService
 	StationFinder models.StationFinder
+	WindowService tide.WindowService
+
+	// Station details beyond the station list, fetched when a query asks for them
+	StationMetadata models.StationMetadataFetcher
+
+	// Tidal currents come from separate current prediction stations
+	CurrentService       tide.CurrentService
+	CurrentStationFinder models.StationFinder
 }
 
 // Ensure Resolver implements the ResolverRoot interface
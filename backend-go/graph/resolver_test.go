@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 func TestResolver_Stations(t *testing.T) {
@@ -28,7 +29,7 @@ func TestResolver_Stations(t *testing.T) {
 			setupMock: func() *Resolver {
 				return &Resolver{
 					StationFinder: &mockStationFinder{
-						findNearestStationsFn: func(ctx context.Context, lat, lon float64, limit int) ([]models.Station, error) {
+						findNearestStationsFn: func(ctx context.Context, lat, lon float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
 							return []models.Station{
 								{
 									ID:        "TEST001",
@@ -58,7 +59,7 @@ func TestResolver_Stations(t *testing.T) {
 			resolver := tt.setupMock()
 			queryResolver := resolver.Query()
 
-			got, err := queryResolver.Stations(context.Background(), &tt.lat, &tt.lon, tt.limit)
+			got, err := queryResolver.Stations(context.Background(), &tt.lat, &tt.lon, tt.limit, nil)
 
 			if tt.wantErr {
 				require.Error(t, err)
@@ -78,12 +79,210 @@ func TestResolver_Stations(t *testing.T) {
 	}
 }
 
+func TestResolver_StationsInBounds(t *testing.T) {
+	limit := 10
+	var gotLimit int
+	resolver := &Resolver{
+		StationFinder: &mockStationFinder{
+			findStationsInBoundsFn: func(ctx context.Context, south, west, north, east float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
+				gotLimit = limit
+				if south > north {
+					return nil, fmt.Errorf("south %f is north of north %f", south, north)
+				}
+				return []models.Station{
+					{ID: "TEST001", Name: "Test Station 1", Latitude: 47.6, Longitude: -122.3},
+				}, nil
+			},
+		},
+	}
+
+	got, err := resolver.Query().StationsInBounds(context.Background(), 47, -123, 48, -122, &limit, nil)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "TEST001", got[0].ID)
+	assert.Equal(t, 10, gotLimit)
+
+	_, err = resolver.Query().StationsInBounds(context.Background(), 47, -123, 48, -122, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, gotLimit, "no limit unless one is given")
+
+	_, err = resolver.Query().StationsInBounds(context.Background(), 48, -123, 47, -122, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestResolver_SearchStations(t *testing.T) {
+	var gotQuery string
+	var gotNear *models.Coordinates
+	var gotLimit int
+	resolver := &Resolver{
+		StationFinder: &mockStationFinder{
+			searchStationsFn: func(ctx context.Context, query string, near *models.Coordinates, limit int, filter *models.StationFilter) ([]models.Station, error) {
+				gotQuery, gotNear, gotLimit = query, near, limit
+				if query == "" {
+					return nil, fmt.Errorf("search query is required")
+				}
+				return []models.Station{
+					{ID: "9447130", Name: "Seattle", Latitude: 47.6026, Longitude: -122.3393},
+				}, nil
+			},
+		},
+	}
+
+	got, err := resolver.Query().SearchStations(context.Background(), "Seatle", nil, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "Seattle", got[0].Name)
+	assert.Equal(t, "Seatle", gotQuery)
+	assert.Nil(t, gotNear)
+	assert.Equal(t, 10, gotLimit, "10 results unless a limit is given")
+
+	limit := 3
+	_, err = resolver.Query().SearchStations(context.Background(), "Seattle", &model.CoordinatesInput{Lat: 47.6, Lon: -122.3}, &limit, nil)
+	require.NoError(t, err)
+	assert.Equal(t, &models.Coordinates{Latitude: 47.6, Longitude: -122.3}, gotNear)
+	assert.Equal(t, 3, gotLimit)
+
+	_, err = resolver.Query().SearchStations(context.Background(), "", nil, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestResolver_StationsFilter(t *testing.T) {
+	var gotFilter *models.StationFilter
+	resolver := &Resolver{
+		StationFinder: &mockStationFinder{
+			findNearestStationsFn: func(ctx context.Context, lat, lon float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
+				gotFilter = filter
+				return []models.Station{}, nil
+			},
+		},
+	}
+	lat, lon := 43.6567, -70.2467
+
+	stationType := model.StationTypeReference
+	state := "ME"
+	source := "noaa"
+	hasObservations := true
+	_, err := resolver.Query().Stations(context.Background(), &lat, &lon, nil, &model.StationFilterInput{
+		StationType:     &stationType,
+		Capabilities:    []string{models.CapabilityWind},
+		State:           &state,
+		Source:          &source,
+		HasObservations: &hasObservations,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &models.StationFilter{
+		StationType:     models.StationTypeReference,
+		Capabilities:    []string{models.CapabilityWind},
+		State:           "ME",
+		Source:          models.SourceNOAA,
+		HasObservations: true,
+	}, gotFilter)
+
+	_, err = resolver.Query().Stations(context.Background(), &lat, &lon, nil, nil)
+	require.NoError(t, err)
+	assert.Nil(t, gotFilter)
+}
+
+// mockStationMetadata serves fixed details and counts what is fetched
+type mockStationMetadata struct {
+	calls []string
+}
+
+func (m *mockStationMetadata) Datums(ctx context.Context, stationID string) (*models.StationDatums, error) {
+	m.calls = append(m.calls, "datums")
+	return &models.StationDatums{
+		Epoch: "1983-2001",
+		Units: "feet",
+		Datums: []models.DatumValue{
+			{Name: "MHHW", Value: 18.05},
+			{Name: "MLLW", Description: "Mean Lower-Low Water", Value: 6.64},
+		},
+	}, nil
+}
+
+func (m *mockStationMetadata) Sensors(ctx context.Context, stationID string) ([]models.Sensor, error) {
+	m.calls = append(m.calls, "sensors")
+	return []models.Sensor{{ID: "A1", Name: "Aquatrak", DCP: 1, Active: true}}, nil
+}
+
+func (m *mockStationMetadata) FloodLevels(ctx context.Context, stationID string) (*models.FloodLevels, error) {
+	m.calls = append(m.calls, "floodLevels")
+	minor := 20.2
+	return &models.FloodLevels{Units: "feet", Datum: models.DatumSTND, NOSMinor: &minor}, nil
+}
+
+func (m *mockStationMetadata) Offsets(ctx context.Context, stationID string) (*models.TideOffsets, error) {
+	m.calls = append(m.calls, "offsets")
+	return nil, nil
+}
+
+func (m *mockStationMetadata) HarmonicConstituents(ctx context.Context, stationID string) ([]models.HarmonicConstituent, error) {
+	m.calls = append(m.calls, "harmonicConstituents")
+	return nil, fmt.Errorf("NOAA API unavailable")
+}
+
+func TestResolver_Station(t *testing.T) {
+	metadata := &mockStationMetadata{}
+	stationType := models.StationTypeReference
+	resolver := &Resolver{
+		StationFinder: &mockStationFinder{
+			findStationFn: func(ctx context.Context, stationID string) (*models.Station, error) {
+				if stationID != "9447130" {
+					return nil, fmt.Errorf("station not found: %s", stationID)
+				}
+				return &models.Station{ID: "9447130", Name: "Seattle", Source: models.SourceNOAA, StationType: &stationType}, nil
+			},
+		},
+		StationMetadata: metadata,
+	}
+	ctx := context.Background()
+
+	details, err := resolver.Query().Station(ctx, "9447130")
+	require.NoError(t, err)
+	assert.Equal(t, "Seattle", details.Name)
+	assert.Equal(t, model.StationTypeReference, *details.StationType)
+	assert.Empty(t, metadata.calls, "details are fetched only when asked for")
+
+	datums, err := resolver.StationDetails().Datums(ctx, details)
+	require.NoError(t, err)
+	require.Len(t, datums.Datums, 2)
+	assert.Equal(t, "MLLW", datums.Datums[1].Name)
+	assert.Nil(t, datums.Datums[0].Description)
+	assert.Nil(t, datums.OrthometricDatum)
+
+	sensors, err := resolver.StationDetails().Sensors(ctx, details)
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Sensor{{ID: "A1", Name: "Aquatrak", Dcp: 1, Active: true}}, sensors)
+
+	levels, err := resolver.StationDetails().FloodLevels(ctx, details)
+	require.NoError(t, err)
+	assert.Equal(t, model.DatumStnd, levels.Datum)
+	assert.Equal(t, 20.2, *levels.NosMinor)
+
+	offsets, err := resolver.StationDetails().Offsets(ctx, details)
+	require.NoError(t, err)
+	assert.Nil(t, offsets)
+
+	_, err = resolver.StationDetails().HarmonicConstituents(ctx, details)
+	assert.EqualError(t, err, "NOAA API unavailable")
+
+	assert.Equal(t, []string{"datums", "sensors", "floodLevels", "offsets", "harmonicConstituents"}, metadata.calls)
+
+	_, err = resolver.Query().Station(ctx, "0000000")
+	assert.EqualError(t, err, "station not found: 0000000")
+
+	_, err = (&Resolver{}).StationDetails().Datums(ctx, details)
+	assert.EqualError(t, err, "StationMetadata is not initialized")
+}
+
 func TestResolver_Tides(t *testing.T) {
 	tests := []struct {
 		name      string
 		stationID string
 		startTime string
 		endTime   string
+		datum     *model.Datum
+		units     *model.Units
 		setupMock func() *Resolver
 		want      *model.TideData
 		wantErr   bool
@@ -96,17 +295,22 @@ func TestResolver_Tides(t *testing.T) {
 			setupMock: func() *Resolver {
 				waterLevel := 1.5
 				predictedLevel := 1.6
+				residual := -0.1
+				observationAge := int64(240)
 				tideType := models.TideTypeHigh
 				timeZoneOffset := -28800
 
 				return &Resolver{
 					TideService: &mockTideService{
-						getCurrentTideForStationFn: func(ctx context.Context, stationID string, startTimeStr, endTimeStr *string) (*models.ExtendedTideResponse, error) {
+						getCurrentTideForStationFn: func(ctx context.Context, stationID string, startTimeStr, endTimeStr *string, datum models.Datum, units models.Units) (*models.ExtendedTideResponse, error) {
 							return &models.ExtendedTideResponse{
 								Timestamp:             1704067200000,
 								LocalTime:             "2024-01-01T00:00:00",
 								WaterLevel:            &waterLevel,
 								PredictedLevel:        &predictedLevel,
+								Residual:              &residual,
+								ObservationAgeSeconds: &observationAge,
+								ObservationStatus:     models.ObservationStatusObserved,
 								NearestStation:        stationID,
 								Latitude:              47.6062,
 								Longitude:             -122.3321,
@@ -128,8 +332,11 @@ func TestResolver_Tides(t *testing.T) {
 			want: &model.TideData{
 				Timestamp:             1704067200000,
 				LocalTime:             "2024-01-01T00:00:00",
-				WaterLevel:            1.5,
+				WaterLevel:            float64Ptr(1.5),
 				PredictedLevel:        1.6,
+				Residual:              float64Ptr(-0.1),
+				ObservationAgeSeconds: intPtr(240),
+				ObservationStatus:     model.ObservationStatusObserved,
 				NearestStation:        "TEST001",
 				Latitude:              47.6062,
 				Longitude:             -122.3321,
@@ -146,6 +353,168 @@ func TestResolver_Tides(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:      "requested datum is passed through and echoed",
+			stationID: "TEST004",
+			startTime: "2024-01-01T00:00:00",
+			endTime:   "2024-01-02T00:00:00",
+			datum:     datumPtr(model.DatumMhhw),
+			setupMock: func() *Resolver {
+				return &Resolver{
+					TideService: &mockTideService{
+						getCurrentTideForStationFn: func(ctx context.Context, stationID string, startTimeStr, endTimeStr *string, datum models.Datum, units models.Units) (*models.ExtendedTideResponse, error) {
+							if datum != models.DatumMHHW {
+								return nil, fmt.Errorf("unexpected datum %q", datum)
+							}
+							return &models.ExtendedTideResponse{
+								Timestamp:         1704067200000,
+								LocalTime:         "2024-01-01T00:00:00",
+								NearestStation:    stationID,
+								CalculationMethod: "NOAA API",
+								Datum:             datum,
+							}, nil
+						},
+					},
+				}
+			},
+			want: &model.TideData{
+				Timestamp:         1704067200000,
+				LocalTime:         "2024-01-01T00:00:00",
+				NearestStation:    "TEST004",
+				CalculationMethod: "NOAA API",
+				Datum:             model.DatumMhhw,
+				ObservationStatus: model.ObservationStatusUnavailable,
+				Predictions:       []*model.TidePrediction{},
+				Extremes:          []*model.TideExtreme{},
+			},
+			wantErr: false,
+		},
+		{
+			name:      "requested units are passed through and echoed",
+			stationID: "TEST005",
+			startTime: "2024-01-01T00:00:00",
+			endTime:   "2024-01-02T00:00:00",
+			units:     unitsPtr(model.UnitsMeters),
+			setupMock: func() *Resolver {
+				return &Resolver{
+					TideService: &mockTideService{
+						getCurrentTideForStationFn: func(ctx context.Context, stationID string, startTimeStr, endTimeStr *string, datum models.Datum, units models.Units) (*models.ExtendedTideResponse, error) {
+							if units != models.UnitsMeters {
+								return nil, fmt.Errorf("unexpected units %q", units)
+							}
+							return &models.ExtendedTideResponse{
+								Timestamp:         1704067200000,
+								LocalTime:         "2024-01-01T00:00:00",
+								NearestStation:    stationID,
+								CalculationMethod: "NOAA API",
+								Datum:             models.DatumMLLW,
+								Units:             units,
+							}, nil
+						},
+					},
+				}
+			},
+			want: &model.TideData{
+				Timestamp:         1704067200000,
+				LocalTime:         "2024-01-01T00:00:00",
+				NearestStation:    "TEST005",
+				CalculationMethod: "NOAA API",
+				Datum:             model.DatumMllw,
+				Units:             model.UnitsMeters,
+				ObservationStatus: model.ObservationStatusUnavailable,
+				Predictions:       []*model.TidePrediction{},
+				Extremes:          []*model.TideExtreme{},
+			},
+			wantErr: false,
+		},
+		{
+			name:      "met conditions, day summaries and astronomy are mapped",
+			stationID: "TEST006",
+			startTime: "2024-01-01T00:00:00",
+			endTime:   "2024-01-02T00:00:00",
+			setupMock: func() *Resolver {
+				return &Resolver{
+					TideService: &mockTideService{
+						getCurrentTideForStationFn: func(ctx context.Context, stationID string, startTimeStr, endTimeStr *string, datum models.Datum, units models.Units) (*models.ExtendedTideResponse, error) {
+							return &models.ExtendedTideResponse{
+								Timestamp:         1704067200000,
+								LocalTime:         "2024-01-01T00:00:00",
+								NearestStation:    stationID,
+								CalculationMethod: "NOAA API",
+								Datum:             models.DatumMLLW,
+								Conditions: &models.MarineConditions{
+									Wind: &models.WindObservation{
+										Timestamp:         1704066840000,
+										Speed:             12.5,
+										Gust:              float64Ptr(18.1),
+										Direction:         225,
+										CardinalDirection: "SW",
+										Units:             "knots",
+									},
+									WaterTemperature: &models.MetReading{Timestamp: 1704066840000, Value: 48.2, Units: "F"},
+								},
+								Days: []models.TideDaySummary{
+									{
+										Date:       "2024-01-01",
+										HigherHigh: &models.TideExtreme{Type: models.TideTypeHigh, Timestamp: 1704112200000, LocalTime: "2024-01-01T04:30:00", Height: 11.2},
+										Range:      float64Ptr(13.9),
+										SpringNeap: models.SpringNeapSpring,
+									},
+								},
+								Astronomy: []models.AstronomyDay{
+									{
+										Date:             "2024-01-01",
+										Sunrise:          &models.AstronomicalTime{Timestamp: 1704123480000, LocalTime: "2024-01-01T07:58:00"},
+										MoonPhase:        models.MoonPhaseWaningGibbous,
+										MoonIllumination: 0.72,
+									},
+								},
+								Freshness: &models.DataFreshness{Status: models.FreshnessStale, UpdatedAt: 1703808000000, AgeSeconds: 259200},
+							}, nil
+						},
+					},
+				}
+			},
+			want: &model.TideData{
+				Timestamp:         1704067200000,
+				LocalTime:         "2024-01-01T00:00:00",
+				NearestStation:    "TEST006",
+				CalculationMethod: "NOAA API",
+				Datum:             model.DatumMllw,
+				ObservationStatus: model.ObservationStatusUnavailable,
+				Predictions:       []*model.TidePrediction{},
+				Extremes:          []*model.TideExtreme{},
+				Conditions: &model.Conditions{
+					Wind: &model.Wind{
+						Timestamp:         1704066840000,
+						Speed:             12.5,
+						Gust:              float64Ptr(18.1),
+						Direction:         225,
+						CardinalDirection: "SW",
+						Units:             "knots",
+					},
+					WaterTemperature: &model.MetReading{Timestamp: 1704066840000, Value: 48.2, Units: "F"},
+				},
+				Days: []*model.TideDaySummary{
+					{
+						Date:       "2024-01-01",
+						HigherHigh: &model.TideExtreme{Type: "HIGH", Timestamp: 1704112200000, LocalTime: "2024-01-01T04:30:00", Height: 11.2},
+						Range:      float64Ptr(13.9),
+						SpringNeap: model.SpringNeapSpring,
+					},
+				},
+				Astronomy: []*model.AstronomyDay{
+					{
+						Date:             "2024-01-01",
+						Sunrise:          &model.AstronomicalTime{Timestamp: 1704123480000, LocalTime: "2024-01-01T07:58:00"},
+						MoonPhase:        model.MoonPhaseWaningGibbous,
+						MoonIllumination: 0.72,
+					},
+				},
+				Freshness: &model.DataFreshness{Status: model.FreshnessStatusStale, UpdatedAt: 1703808000000, AgeSeconds: 259200},
+			},
+			wantErr: false,
+		},
 		{
 			name:      "nil tide response",
 			stationID: "TEST002",
@@ -154,7 +523,7 @@ func TestResolver_Tides(t *testing.T) {
 			setupMock: func() *Resolver {
 				return &Resolver{
 					TideService: &mockTideService{
-						getCurrentTideForStationFn: func(ctx context.Context, stationID string, startTimeStr, endTimeStr *string) (*models.ExtendedTideResponse, error) {
+						getCurrentTideForStationFn: func(ctx context.Context, stationID string, startTimeStr, endTimeStr *string, datum models.Datum, units models.Units) (*models.ExtendedTideResponse, error) {
 							return nil, nil
 						},
 					},
@@ -171,7 +540,7 @@ func TestResolver_Tides(t *testing.T) {
 			setupMock: func() *Resolver {
 				return &Resolver{
 					TideService: &mockTideService{
-						getCurrentTideForStationFn: func(ctx context.Context, stationID string, startTimeStr, endTimeStr *string) (*models.ExtendedTideResponse, error) {
+						getCurrentTideForStationFn: func(ctx context.Context, stationID string, startTimeStr, endTimeStr *string, datum models.Datum, units models.Units) (*models.ExtendedTideResponse, error) {
 							return nil, fmt.Errorf("service error")
 						},
 					},
@@ -187,7 +556,7 @@ func TestResolver_Tides(t *testing.T) {
 			resolver := tt.setupMock()
 			queryResolver := resolver.Query()
 
-			got, err := queryResolver.Tides(context.Background(), tt.stationID, tt.startTime, tt.endTime)
+			got, err := queryResolver.Tides(context.Background(), tt.stationID, tt.startTime, tt.endTime, tt.datum, tt.units)
 
 			if tt.wantErr {
 				require.Error(t, err)
@@ -199,13 +568,28 @@ func TestResolver_Tides(t *testing.T) {
 			assert.Equal(t, tt.want.LocalTime, got.LocalTime)
 			assert.Equal(t, tt.want.WaterLevel, got.WaterLevel)
 			assert.Equal(t, tt.want.PredictedLevel, got.PredictedLevel)
+			assert.Equal(t, tt.want.Residual, got.Residual)
+			assert.Equal(t, tt.want.ObservationAgeSeconds, got.ObservationAgeSeconds)
+			assert.Equal(t, tt.want.ObservationStatus, got.ObservationStatus)
 			assert.Equal(t, tt.want.NearestStation, got.NearestStation)
 			assert.Equal(t, tt.want.Latitude, got.Latitude)
 			assert.Equal(t, tt.want.Longitude, got.Longitude)
 			assert.Equal(t, tt.want.StationDistance, got.StationDistance)
 			assert.Equal(t, tt.want.TideType, got.TideType)
 			assert.Equal(t, tt.want.CalculationMethod, got.CalculationMethod)
+			assert.Equal(t, tt.want.Datum, got.Datum)
+			assert.Equal(t, tt.want.Units, got.Units)
 			assert.Equal(t, tt.want.TimeZoneOffsetSeconds, got.TimeZoneOffsetSeconds)
+			assert.Equal(t, tt.want.Conditions, got.Conditions)
+			assert.Equal(t, tt.want.Freshness, got.Freshness)
+			assert.Equal(t, len(tt.want.Days), len(got.Days))
+			for i, d := range tt.want.Days {
+				assert.Equal(t, d, got.Days[i])
+			}
+			assert.Equal(t, len(tt.want.Astronomy), len(got.Astronomy))
+			for i, d := range tt.want.Astronomy {
+				assert.Equal(t, d, got.Astronomy[i])
+			}
 			assert.Equal(t, len(tt.want.Predictions), len(got.Predictions))
 			for i, p := range tt.want.Predictions {
 				assert.Equal(t, p.Timestamp, got.Predictions[i].Timestamp)
@@ -222,3 +606,209 @@ func TestResolver_Tides(t *testing.T) {
 		})
 	}
 }
+
+type mockCurrentService struct {
+	getCurrentsForStationFn func(ctx context.Context, stationID string, startTimeStr, endTimeStr *string) (*models.CurrentResponse, error)
+}
+
+func (m *mockCurrentService) GetCurrents(_ context.Context, _, _ float64, _, _ *string) (*models.CurrentResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *mockCurrentService) GetCurrentsForStation(ctx context.Context, stationID string, startTimeStr, endTimeStr *string) (*models.CurrentResponse, error) {
+	return m.getCurrentsForStationFn(ctx, stationID, startTimeStr, endTimeStr)
+}
+
+func TestResolver_Currents(t *testing.T) {
+	bin := 11
+	flood := models.NewCurrentPrediction(1717225200000, "2024-06-01T00:00:00", 2.1, 190, 10)
+
+	tests := []struct {
+		name     string
+		resolver *Resolver
+		want     *model.CurrentData
+		wantErr  string
+	}{
+		{
+			name: "successful currents lookup",
+			resolver: &Resolver{
+				CurrentService: &mockCurrentService{
+					getCurrentsForStationFn: func(_ context.Context, stationID string, startTimeStr, endTimeStr *string) (*models.CurrentResponse, error) {
+						return &models.CurrentResponse{
+							Timestamp:      1717225200000,
+							LocalTime:      "2024-06-01T00:00:00",
+							StationID:      stationID,
+							StationName:    "Tacoma Narrows",
+							Bin:            &bin,
+							FloodDirection: 190,
+							EbbDirection:   10,
+							Current:        &flood,
+							Predictions:    []models.CurrentPrediction{flood},
+							Events: []models.CurrentEvent{
+								{Type: models.CurrentEventSlack, Timestamp: 1717234860000, LocalTime: "2024-06-01T02:41:00", Direction: 10},
+							},
+							TimeZoneOffsetSeconds: -28800,
+						}, nil
+					},
+				},
+			},
+			want: &model.CurrentData{
+				Timestamp:      1717225200000,
+				LocalTime:      "2024-06-01T00:00:00",
+				StationID:      "PUG1515",
+				StationName:    "Tacoma Narrows",
+				Bin:            intPtr(11),
+				FloodDirection: 190,
+				EbbDirection:   10,
+				Current: &model.CurrentPrediction{
+					Timestamp: 1717225200000, LocalTime: "2024-06-01T00:00:00", Velocity: 2.1, Speed: 2.1, Direction: 190, State: model.CurrentStateFlood,
+				},
+				Predictions: []*model.CurrentPrediction{
+					{Timestamp: 1717225200000, LocalTime: "2024-06-01T00:00:00", Velocity: 2.1, Speed: 2.1, Direction: 190, State: model.CurrentStateFlood},
+				},
+				Events: []*model.CurrentEvent{
+					{Type: model.CurrentEventType(models.CurrentEventSlack), Timestamp: 1717234860000, LocalTime: "2024-06-01T02:41:00", Direction: 10},
+				},
+				TimeZoneOffsetSeconds: -28800,
+			},
+		},
+		{
+			name: "error from current service",
+			resolver: &Resolver{
+				CurrentService: &mockCurrentService{
+					getCurrentsForStationFn: func(_ context.Context, stationID string, _, _ *string) (*models.CurrentResponse, error) {
+						return nil, fmt.Errorf("current station not found: %s", stationID)
+					},
+				},
+			},
+			wantErr: "current station not found",
+		},
+		{
+			name:     "service not initialized",
+			resolver: &Resolver{},
+			wantErr:  "CurrentService is not initialized",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.resolver.Query().Currents(context.Background(), "PUG1515", "2024-06-01T00:00:00", "2024-06-02T00:00:00")
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+type mockWindowService struct {
+	getTideWindowsFn func(ctx context.Context, stationID string, startTimeStr, endTimeStr *string, query models.TideWindowQuery) ([]models.TideWindow, error)
+}
+
+func (m *mockWindowService) GetTideWindows(ctx context.Context, stationID string, startTimeStr, endTimeStr *string, query models.TideWindowQuery) ([]models.TideWindow, error) {
+	return m.getTideWindowsFn(ctx, stationID, startTimeStr, endTimeStr, query)
+}
+
+func TestResolver_TideWindows(t *testing.T) {
+	minDuration := 45
+	daylightOnly := true
+
+	tests := []struct {
+		name     string
+		resolver *Resolver
+		want     []*model.TideWindow
+		wantErr  string
+	}{
+		{
+			name: "query is passed through",
+			resolver: &Resolver{
+				WindowService: &mockWindowService{
+					getTideWindowsFn: func(_ context.Context, _ string, _, _ *string, query models.TideWindowQuery) ([]models.TideWindow, error) {
+						want := models.TideWindowQuery{
+							Condition:    models.WindowBelow,
+							Threshold:    0.6,
+							MinDuration:  45 * time.Minute,
+							DaylightOnly: true,
+							Datum:        models.DatumMLLW,
+							Units:        models.UnitsMeters,
+						}
+						if query != want {
+							return nil, fmt.Errorf("unexpected query %+v", query)
+						}
+						return []models.TideWindow{
+							{
+								StartTimestamp:  1717239600000,
+								StartLocalTime:  "2024-06-01T04:00:00",
+								EndTimestamp:    1717254000000,
+								EndLocalTime:    "2024-06-01T08:00:00",
+								DurationMinutes: 240,
+								PeakHeight:      0.305,
+							},
+						}, nil
+					},
+				},
+			},
+			want: []*model.TideWindow{
+				{
+					StartTimestamp:  1717239600000,
+					StartLocalTime:  "2024-06-01T04:00:00",
+					EndTimestamp:    1717254000000,
+					EndLocalTime:    "2024-06-01T08:00:00",
+					DurationMinutes: 240,
+					PeakHeight:      0.305,
+				},
+			},
+		},
+		{
+			name: "error from window service",
+			resolver: &Resolver{
+				WindowService: &mockWindowService{
+					getTideWindowsFn: func(_ context.Context, _ string, _, _ *string, _ models.TideWindowQuery) ([]models.TideWindow, error) {
+						return nil, fmt.Errorf("finding station: station not found")
+					},
+				},
+			},
+			wantErr: "station not found",
+		},
+		{
+			name:     "service not initialized",
+			resolver: &Resolver{},
+			wantErr:  "WindowService is not initialized",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.resolver.Query().TideWindows(context.Background(), "TEST001", "2024-06-01T00:00:00", "2024-06-02T00:00:00",
+				model.WindowConditionBelow, 0.6, &minDuration, &daylightOnly, datumPtr(model.DatumMllw), unitsPtr(model.UnitsMeters))
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func datumPtr(d model.Datum) *model.Datum {
+	return &d
+}
+
+func unitsPtr(u model.Units) *model.Units {
+	return &u
+}
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
+
+func intPtr(i int) *int {
+	return &i
+}
@@ -7,13 +7,16 @@ package graph
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	generated1 "github.com/bbernstein/flowebb/backend-go/graph/generated"
 	"github.com/bbernstein/flowebb/backend-go/graph/model"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
 )
 
 // Stations is the resolver for the stations field.
-func (r *queryResolver) Stations(ctx context.Context, lat *float64, lon *float64, limit *int) ([]*model.Station, error) {
+func (r *queryResolver) Stations(ctx context.Context, lat *float64, lon *float64, limit *int, filter *model.StationFilterInput) ([]*model.Station, error) {
 	if lat == nil || lon == nil {
 		return nil, fmt.Errorf("lat and lon are required")
 	}
@@ -23,38 +26,80 @@ func (r *queryResolver) Stations(ctx context.Context, lat *float64, lon *float64
 		limitVal = *limit
 	}
 
-	stations, err := r.StationFinder.FindNearestStations(ctx, *lat, *lon, limitVal)
+	stations, err := r.StationFinder.FindNearestStations(ctx, *lat, *lon, limitVal, toStationFilter(filter))
 	if err != nil {
 		return nil, err
 	}
 
-	// Convert internal models to GraphQL models
-	result := make([]*model.Station, len(stations))
-	for i, s := range stations {
-		result[i] = &model.Station{
-			ID:             s.ID,
-			Name:           s.Name,
-			State:          s.State,
-			Region:         s.Region,
-			Distance:       s.Distance,
-			Latitude:       s.Latitude,
-			Longitude:      s.Longitude,
-			Source:         string(s.Source),
-			Capabilities:   s.Capabilities,
-			TimeZoneOffset: s.TimeZoneOffset,
-		}
+	return toStationModels(stations), nil
+}
+
+// StationsInBounds is the resolver for the stationsInBounds field.
+func (r *queryResolver) StationsInBounds(ctx context.Context, south float64, west float64, north float64, east float64, limit *int, filter *model.StationFilterInput) ([]*model.Station, error) {
+	limitVal := 0
+	if limit != nil {
+		limitVal = *limit
 	}
 
-	return result, nil
+	stations, err := r.StationFinder.FindStationsInBounds(ctx, south, west, north, east, limitVal, toStationFilter(filter))
+	if err != nil {
+		return nil, err
+	}
+
+	return toStationModels(stations), nil
+}
+
+// SearchStations is the resolver for the searchStations field.
+func (r *queryResolver) SearchStations(ctx context.Context, query string, near *model.CoordinatesInput, limit *int, filter *model.StationFilterInput) ([]*model.Station, error) {
+	limitVal := 10
+	if limit != nil {
+		limitVal = *limit
+	}
+
+	var nearCoords *models.Coordinates
+	if near != nil {
+		nearCoords = &models.Coordinates{Latitude: near.Lat, Longitude: near.Lon}
+	}
+
+	stations, err := r.StationFinder.SearchStations(ctx, query, nearCoords, limitVal, toStationFilter(filter))
+	if err != nil {
+		return nil, err
+	}
+
+	return toStationModels(stations), nil
+}
+
+// Station is the resolver for the station field.
+func (r *queryResolver) Station(ctx context.Context, id string) (*model.StationDetails, error) {
+	station, err := r.StationFinder.FindStation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toStationDetailsModel(station), nil
 }
 
 // Tides is the resolver for the tides field.
-func (r *queryResolver) Tides(ctx context.Context, stationID string, startDateTime string, endDateTime string) (*model.TideData, error) {
+func (r *queryResolver) Tides(ctx context.Context, stationID string, startDateTime string, endDateTime string, datum *model.Datum, units *model.Units) (*model.TideData, error) {
 	if r.TideService == nil {
 		return nil, fmt.Errorf("TideService is not initialized")
 	}
 
-	response, err := r.TideService.GetCurrentTideForStation(ctx, stationID, &startDateTime, &endDateTime)
+	var requestedDatum models.Datum
+	if datum != nil {
+		requestedDatum = models.Datum(*datum)
+	}
+
+	var requestedUnits models.Units
+	if units != nil {
+		var err error
+		requestedUnits, err = models.ParseUnits(string(*units))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	response, err := r.TideService.GetCurrentTideForStation(ctx, stationID, &startDateTime, &endDateTime, requestedDatum, requestedUnits)
 	if err != nil {
 		return nil, err
 	}
@@ -87,14 +132,22 @@ func (r *queryResolver) Tides(ctx context.Context, stationID string, startDateTi
 		tideType = string(*response.TideType)
 	}
 
-	var waterLevel, predictedLevel float64
-	if response.WaterLevel != nil {
-		waterLevel = *response.WaterLevel
-	}
+	var predictedLevel float64
 	if response.PredictedLevel != nil {
 		predictedLevel = *response.PredictedLevel
 	}
 
+	var observationAge *int
+	if response.ObservationAgeSeconds != nil {
+		age := int(*response.ObservationAgeSeconds)
+		observationAge = &age
+	}
+
+	observationStatus := model.ObservationStatus(response.ObservationStatus)
+	if observationStatus == "" {
+		observationStatus = model.ObservationStatusUnavailable
+	}
+
 	tzOffset := 0
 	if response.TimeZoneOffsetSeconds != nil {
 		tzOffset = *response.TimeZoneOffsetSeconds
@@ -103,8 +156,11 @@ func (r *queryResolver) Tides(ctx context.Context, stationID string, startDateTi
 	return &model.TideData{
 		Timestamp:             int(response.Timestamp),
 		LocalTime:             response.LocalTime,
-		WaterLevel:            waterLevel,
+		WaterLevel:            response.WaterLevel,
 		PredictedLevel:        predictedLevel,
+		Residual:              response.Residual,
+		ObservationAgeSeconds: observationAge,
+		ObservationStatus:     observationStatus,
 		NearestStation:        response.NearestStation,
 		Location:              response.Location,
 		Latitude:              response.Latitude,
@@ -112,13 +168,257 @@ func (r *queryResolver) Tides(ctx context.Context, stationID string, startDateTi
 		StationDistance:       response.StationDistance,
 		TideType:              tideType,
 		CalculationMethod:     response.CalculationMethod,
+		Datum:                 model.Datum(response.Datum),
+		Units:                 model.Units(strings.ToUpper(string(response.Units))),
 		Predictions:           predictions,
 		Extremes:              extremes,
+		Conditions:            toConditionsModel(response.Conditions),
+		Days:                  toTideDaySummaryModels(response.Days),
+		Astronomy:             toAstronomyModels(response.Astronomy),
 		TimeZoneOffsetSeconds: tzOffset,
+		TimeZone:              response.TimeZone,
+		Freshness:             toDataFreshnessModel(response.Freshness),
+	}, nil
+}
+
+// TideWindows is the resolver for the tideWindows field.
+func (r *queryResolver) TideWindows(ctx context.Context, stationID string, startDateTime string, endDateTime string, condition model.WindowCondition, threshold float64, minDuration *int, daylightOnly *bool, datum *model.Datum, units *model.Units) ([]*model.TideWindow, error) {
+	if r.WindowService == nil {
+		return nil, fmt.Errorf("WindowService is not initialized")
+	}
+
+	query := models.TideWindowQuery{
+		Condition: models.WindowCondition(condition),
+		Threshold: threshold,
+	}
+	if minDuration != nil {
+		query.MinDuration = time.Duration(*minDuration) * time.Minute
+	}
+	if daylightOnly != nil {
+		query.DaylightOnly = *daylightOnly
+	}
+	if datum != nil {
+		query.Datum = models.Datum(*datum)
+	}
+	if units != nil {
+		var err error
+		query.Units, err = models.ParseUnits(string(*units))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	windows, err := r.WindowService.GetTideWindows(ctx, stationID, &startDateTime, &endDateTime, query)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.TideWindow, len(windows))
+	for i, w := range windows {
+		result[i] = &model.TideWindow{
+			StartTimestamp:  int(w.StartTimestamp),
+			StartLocalTime:  w.StartLocalTime,
+			EndTimestamp:    int(w.EndTimestamp),
+			EndLocalTime:    w.EndLocalTime,
+			DurationMinutes: w.DurationMinutes,
+			PeakHeight:      w.PeakHeight,
+		}
+	}
+	return result, nil
+}
+
+// CurrentStations is the resolver for the currentStations field.
+func (r *queryResolver) CurrentStations(ctx context.Context, lat *float64, lon *float64, limit *int, filter *model.StationFilterInput) ([]*model.Station, error) {
+	if r.CurrentStationFinder == nil {
+		return nil, fmt.Errorf("CurrentStationFinder is not initialized")
+	}
+	if lat == nil || lon == nil {
+		return nil, fmt.Errorf("lat and lon are required")
+	}
+
+	limitVal := 5
+	if limit != nil {
+		limitVal = *limit
+	}
+
+	stations, err := r.CurrentStationFinder.FindNearestStations(ctx, *lat, *lon, limitVal, toStationFilter(filter))
+	if err != nil {
+		return nil, err
+	}
+
+	return toStationModels(stations), nil
+}
+
+// Currents is the resolver for the currents field.
+func (r *queryResolver) Currents(ctx context.Context, stationID string, startDateTime string, endDateTime string) (*model.CurrentData, error) {
+	if r.CurrentService == nil {
+		return nil, fmt.Errorf("CurrentService is not initialized")
+	}
+
+	response, err := r.CurrentService.GetCurrentsForStation(ctx, stationID, &startDateTime, &endDateTime)
+	if err != nil {
+		return nil, err
+	}
+
+	if response == nil {
+		return nil, fmt.Errorf("response is nil")
+	}
+
+	predictions := make([]*model.CurrentPrediction, len(response.Predictions))
+	for i, p := range response.Predictions {
+		predictions[i] = toCurrentPredictionModel(p)
+	}
+
+	events := make([]*model.CurrentEvent, len(response.Events))
+	for i, e := range response.Events {
+		events[i] = &model.CurrentEvent{
+			Type:      model.CurrentEventType(e.Type),
+			Timestamp: int(e.Timestamp),
+			LocalTime: e.LocalTime,
+			Speed:     e.Speed,
+			Direction: e.Direction,
+		}
+	}
+
+	var current *model.CurrentPrediction
+	if response.Current != nil {
+		current = toCurrentPredictionModel(*response.Current)
+	}
+
+	return &model.CurrentData{
+		Timestamp:             int(response.Timestamp),
+		LocalTime:             response.LocalTime,
+		StationID:             response.StationID,
+		StationName:           response.StationName,
+		Latitude:              response.Latitude,
+		Longitude:             response.Longitude,
+		StationDistance:       response.StationDistance,
+		Bin:                   response.Bin,
+		FloodDirection:        response.FloodDirection,
+		EbbDirection:          response.EbbDirection,
+		Current:               current,
+		Predictions:           predictions,
+		Events:                events,
+		TimeZoneOffsetSeconds: response.TimeZoneOffsetSeconds,
+		TimeZone:              response.TimeZone,
+	}, nil
+}
+
+// Datums is the resolver for the datums field.
+func (r *stationDetailsResolver) Datums(ctx context.Context, obj *model.StationDetails) (*model.StationDatums, error) {
+	if r.StationMetadata == nil {
+		return nil, fmt.Errorf("StationMetadata is not initialized")
+	}
+
+	datums, err := r.StationMetadata.Datums(ctx, obj.ID)
+	if err != nil || datums == nil {
+		return nil, err
+	}
+
+	return toStationDatumsModel(datums), nil
+}
+
+// Sensors is the resolver for the sensors field.
+func (r *stationDetailsResolver) Sensors(ctx context.Context, obj *model.StationDetails) ([]*model.Sensor, error) {
+	if r.StationMetadata == nil {
+		return nil, fmt.Errorf("StationMetadata is not initialized")
+	}
+
+	sensors, err := r.StationMetadata.Sensors(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.Sensor, len(sensors))
+	for i, s := range sensors {
+		result[i] = &model.Sensor{
+			ID:        s.ID,
+			Name:      s.Name,
+			Dcp:       s.DCP,
+			Active:    s.Active,
+			Elevation: s.Elevation,
+		}
+	}
+	return result, nil
+}
+
+// FloodLevels is the resolver for the floodLevels field.
+func (r *stationDetailsResolver) FloodLevels(ctx context.Context, obj *model.StationDetails) (*model.FloodLevels, error) {
+	if r.StationMetadata == nil {
+		return nil, fmt.Errorf("StationMetadata is not initialized")
+	}
+
+	levels, err := r.StationMetadata.FloodLevels(ctx, obj.ID)
+	if err != nil || levels == nil {
+		return nil, err
+	}
+
+	return &model.FloodLevels{
+		Units:       levels.Units,
+		Datum:       model.Datum(levels.Datum),
+		NosMinor:    levels.NOSMinor,
+		NosModerate: levels.NOSModerate,
+		NosMajor:    levels.NOSMajor,
+		NwsMinor:    levels.NWSMinor,
+		NwsModerate: levels.NWSModerate,
+		NwsMajor:    levels.NWSMajor,
 	}, nil
 }
 
+// Offsets is the resolver for the offsets field.
+func (r *stationDetailsResolver) Offsets(ctx context.Context, obj *model.StationDetails) (*model.TideOffsets, error) {
+	if r.StationMetadata == nil {
+		return nil, fmt.Errorf("StationMetadata is not initialized")
+	}
+
+	offsets, err := r.StationMetadata.Offsets(ctx, obj.ID)
+	if err != nil || offsets == nil {
+		return nil, err
+	}
+
+	return &model.TideOffsets{
+		ReferenceStationID: offsets.ReferenceStationID,
+		HighTimeOffset:     offsets.HighTimeOffset,
+		LowTimeOffset:      offsets.LowTimeOffset,
+		HighHeightOffset:   offsets.HighHeightOffset,
+		LowHeightOffset:    offsets.LowHeightOffset,
+		HeightAdjustment:   string(offsets.HeightAdjustment),
+	}, nil
+}
+
+// HarmonicConstituents is the resolver for the harmonicConstituents field.
+func (r *stationDetailsResolver) HarmonicConstituents(ctx context.Context, obj *model.StationDetails) ([]*model.HarmonicConstituent, error) {
+	if r.StationMetadata == nil {
+		return nil, fmt.Errorf("StationMetadata is not initialized")
+	}
+
+	constituents, err := r.StationMetadata.HarmonicConstituents(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.HarmonicConstituent, len(constituents))
+	for i, c := range constituents {
+		result[i] = &model.HarmonicConstituent{
+			Number:      c.Number,
+			Name:        c.Name,
+			Description: optionalString(c.Description),
+			Amplitude:   c.Amplitude,
+			PhaseGmt:    c.PhaseGMT,
+			PhaseLocal:  c.PhaseLocal,
+			Speed:       c.Speed,
+		}
+	}
+	return result, nil
+}
+
 // Query returns generated1.QueryResolver implementation.
 func (r *Resolver) Query() generated1.QueryResolver { return &queryResolver{r} }
 
+// StationDetails returns generated1.StationDetailsResolver implementation.
+func (r *Resolver) StationDetails() generated1.StationDetailsResolver {
+	return &stationDetailsResolver{r}
+}
+
 type queryResolver struct{ *Resolver }
+type stationDetailsResolver struct{ *Resolver }
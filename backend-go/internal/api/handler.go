@@ -2,10 +2,13 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/internal/spatial"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 type APIResponder interface {
@@ -110,8 +113,85 @@ func ParseCoordinates(params map[string]string) (float64, float64, error) {
 	return lat, lon, nil
 }
 
+// ParseBoundingBox parses a "west,south,east,north" box in decimal degrees,
+// the order map libraries such as Leaflet write them in. West is greater
+// than east when the box crosses the antimeridian.
+func ParseBoundingBox(bbox string) (south, west, north, east float64, err error) {
+	parts := strings.Split(bbox, ",")
+	if len(parts) != 4 {
+		return 0, 0, 0, 0, InvalidBoundingBoxError{Reason: "expected west,south,east,north"}
+	}
+
+	values := make([]float64, 4)
+	for i, part := range parts {
+		values[i], err = strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return 0, 0, 0, 0, InvalidBoundingBoxError{Reason: fmt.Sprintf("invalid number %q", part)}
+		}
+	}
+	west, south, east, north = values[0], values[1], values[2], values[3]
+
+	if _, err := spatial.NewBounds(south, west, north, east); err != nil {
+		return 0, 0, 0, 0, InvalidBoundingBoxError{Reason: err.Error()}
+	}
+
+	return south, west, north, east, nil
+}
+
+// ParseStationFilter reads the station filter parameters: stationType (R or
+// S), capabilities (comma separated, all required), state, region, source
+// and hasObservations. It returns nil when none are given.
+func ParseStationFilter(params map[string]string) (*models.StationFilter, error) {
+	filter := &models.StationFilter{
+		StationType: strings.ToUpper(strings.TrimSpace(params["stationType"])),
+		State:       strings.TrimSpace(params["state"]),
+		Region:      strings.TrimSpace(params["region"]),
+		Source:      models.Source(strings.ToUpper(strings.TrimSpace(params["source"]))),
+	}
+
+	if capabilities := params["capabilities"]; capabilities != "" {
+		for _, capability := range strings.Split(capabilities, ",") {
+			if capability = strings.TrimSpace(capability); capability != "" {
+				filter.Capabilities = append(filter.Capabilities, strings.ToUpper(capability))
+			}
+		}
+	}
+
+	if hasObservations, ok := params["hasObservations"]; ok {
+		value, err := strconv.ParseBool(hasObservations)
+		if err != nil {
+			return nil, InvalidStationFilterError{Reason: fmt.Sprintf("invalid hasObservations %q", hasObservations)}
+		}
+		filter.HasObservations = value
+	}
+
+	if err := filter.Validate(); err != nil {
+		return nil, InvalidStationFilterError{Reason: err.Error()}
+	}
+	if filter.IsEmpty() {
+		return nil, nil
+	}
+	return filter, nil
+}
+
 type InvalidCoordinatesError struct{}
 
 func (e InvalidCoordinatesError) Error() string {
 	return "Invalid coordinates"
 }
+
+type InvalidBoundingBoxError struct {
+	Reason string
+}
+
+func (e InvalidBoundingBoxError) Error() string {
+	return "Invalid bounding box: " + e.Reason
+}
+
+type InvalidStationFilterError struct {
+	Reason string
+}
+
+func (e InvalidStationFilterError) Error() string {
+	return "Invalid station filter: " + e.Reason
+}
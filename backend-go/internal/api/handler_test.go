@@ -158,6 +158,149 @@ func TestParseCoordinates(t *testing.T) {
 	}
 }
 
+func TestParseBoundingBox(t *testing.T) {
+	tests := []struct {
+		name      string
+		bbox      string
+		wantSouth float64
+		wantWest  float64
+		wantNorth float64
+		wantEast  float64
+		wantErr   bool
+	}{
+		{
+			name:      "valid box",
+			bbox:      "-123.0,47.0,-122.0,48.0",
+			wantSouth: 47.0,
+			wantWest:  -123.0,
+			wantNorth: 48.0,
+			wantEast:  -122.0,
+		},
+		{
+			name:      "spaces around values",
+			bbox:      " -123.0, 47.0 ,-122.0,48.0",
+			wantSouth: 47.0,
+			wantWest:  -123.0,
+			wantNorth: 48.0,
+			wantEast:  -122.0,
+		},
+		{
+			name:      "panned past the antimeridian",
+			bbox:      "170,50,190,55",
+			wantSouth: 50,
+			wantWest:  170,
+			wantNorth: 55,
+			wantEast:  190,
+		},
+		{
+			name:    "too few values",
+			bbox:    "-123.0,47.0,-122.0",
+			wantErr: true,
+		},
+		{
+			name:    "non-numeric value",
+			bbox:    "-123.0,north,-122.0,48.0",
+			wantErr: true,
+		},
+		{
+			name:    "south above north",
+			bbox:    "-123.0,48.0,-122.0,47.0",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			south, west, north, east, err := ParseBoundingBox(tt.bbox)
+
+			if tt.wantErr {
+				var bboxErr InvalidBoundingBoxError
+				assert.ErrorAs(t, err, &bboxErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSouth, south)
+			assert.Equal(t, tt.wantWest, west)
+			assert.Equal(t, tt.wantNorth, north)
+			assert.Equal(t, tt.wantEast, east)
+		})
+	}
+}
+
+func TestParseStationFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  map[string]string
+		want    *models.StationFilter
+		wantErr string
+	}{
+		{
+			name:   "no filter",
+			params: map[string]string{"lat": "47.6062", "lon": "-122.3321"},
+			want:   nil,
+		},
+		{
+			name:   "reference stations in Maine",
+			params: map[string]string{"stationType": "r", "state": "ME"},
+			want:   &models.StationFilter{StationType: models.StationTypeReference, State: "ME"},
+		},
+		{
+			name: "every field",
+			params: map[string]string{
+				"stationType":     "S",
+				"capabilities":    "water_level, WIND",
+				"state":           "WA",
+				"region":          "Puget Sound",
+				"source":          "noaa",
+				"hasObservations": "true",
+			},
+			want: &models.StationFilter{
+				StationType:     models.StationTypeSubordinate,
+				Capabilities:    []string{models.CapabilityWaterLevel, models.CapabilityWind},
+				State:           "WA",
+				Region:          "Puget Sound",
+				Source:          models.SourceNOAA,
+				HasObservations: true,
+			},
+		},
+		{
+			name:   "hasObservations false filters nothing",
+			params: map[string]string{"hasObservations": "false"},
+			want:   nil,
+		},
+		{
+			name:    "invalid station type",
+			params:  map[string]string{"stationType": "X"},
+			wantErr: "Invalid station filter: invalid station type: X",
+		},
+		{
+			name:    "invalid capability",
+			params:  map[string]string{"capabilities": "WATER_LEVEL,TSUNAMI"},
+			wantErr: "Invalid station filter: invalid capability: TSUNAMI",
+		},
+		{
+			name:    "invalid hasObservations",
+			params:  map[string]string{"hasObservations": "maybe"},
+			wantErr: `Invalid station filter: invalid hasObservations "maybe"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseStationFilter(tt.params)
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestNewStationsResponse(t *testing.T) {
 	stations := []models.Station{
 		{ID: "station1", Name: "Station 1"},
@@ -0,0 +1,242 @@
+// Package astronomy computes sunrise, sunset, civil twilight, moonrise,
+// moonset and the phase of the moon locally, without calling any external
+// service. Positions follow Meeus, Astronomical Algorithms (2nd ed.), and
+// event times are found by searching for the altitude crossings, so they
+// are good to about a minute away from the polar regions.
+package astronomy
+
+import (
+	"math"
+	"time"
+
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+)
+
+const (
+	// Altitude of the sun's center at sunrise and sunset, allowing for
+	// refraction and the sun's semi-diameter
+	sunriseAltitude = -0.8333
+	// Altitude of the sun's center at the start and end of civil twilight
+	civilTwilightAltitude = -6.0
+
+	earthRadiusKm = 6378.14
+	kmPerAU       = 149597870.7
+
+	// Step used to bracket altitude crossings. Short enough that the moon
+	// can't rise and set again between two samples away from the poles.
+	searchStep = 10 * time.Minute
+)
+
+// Days returns the sun and moon events at the given position for every local
+// calendar day from start through end.
+func Days(lat, lon float64, start, end time.Time, location *time.Location) []models.AstronomyDay {
+	start, end = start.In(location), end.In(location)
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, location)
+
+	var days []models.AstronomyDay
+	for !day.After(end) {
+		next := day.AddDate(0, 0, 1)
+		days = append(days, computeDay(lat, lon, day, next, location))
+		day = next
+	}
+	return days
+}
+
+// Period is a span of time between two events
+type Period struct {
+	Start time.Time
+	End   time.Time
+}
+
+// DaylightPeriods returns the spans between sunrise and sunset at the given
+// position, clipped to start and end. Under the midnight sun the whole range
+// is one period; in the polar night there are none.
+func DaylightPeriods(lat, lon float64, start, end time.Time) []Period {
+	sunAltitude := sunAltitudeAt(lat, lon)
+
+	startJD, endJD := julianDay(start), julianDay(end)
+	step := searchStep.Hours() / 24
+
+	var periods []Period
+	var periodStart *time.Time
+	if sunAltitude(startJD) >= sunriseAltitude {
+		periodStart = &start
+	}
+
+	prevJD := startJD
+	for prevJD < endJD {
+		jd := math.Min(prevJD+step, endJD)
+		up := sunAltitude(jd) >= sunriseAltitude
+
+		switch {
+		case up && periodStart == nil:
+			sunrise := fromJulianDay(bisect(sunAltitude, sunriseAltitude, prevJD, jd))
+			periodStart = &sunrise
+		case !up && periodStart != nil:
+			sunset := fromJulianDay(bisect(sunAltitude, sunriseAltitude, prevJD, jd))
+			periods = append(periods, Period{Start: *periodStart, End: sunset})
+			periodStart = nil
+		}
+
+		prevJD = jd
+	}
+
+	if periodStart != nil {
+		periods = append(periods, Period{Start: *periodStart, End: end})
+	}
+
+	return periods
+}
+
+func computeDay(lat, lon float64, dayStart, dayEnd time.Time, location *time.Location) models.AstronomyDay {
+	startJD, endJD := julianDay(dayStart), julianDay(dayEnd)
+
+	sunAltitude := sunAltitudeAt(lat, lon)
+	// Relative to the altitude of the moon's center at rise and set, which
+	// depends on its parallax (Meeus chapter 15)
+	moonAltitude := func(jd float64) float64 {
+		_, _, ra, dec, distance := moonPosition(jd)
+		parallax := degrees(math.Asin(earthRadiusKm / distance))
+		return altitude(jd, lat, lon, ra, dec) - (0.7275*parallax - 0.5667)
+	}
+
+	civilDawn, civilDusk := findCrossings(sunAltitude, civilTwilightAltitude, startJD, endJD)
+	sunrise, sunset := findCrossings(sunAltitude, sunriseAltitude, startJD, endJD)
+	moonrise, moonset := findCrossings(moonAltitude, 0, startJD, endJD)
+
+	return models.AstronomyDay{
+		Date:             dayStart.Format("2006-01-02"),
+		CivilDawn:        toAstronomicalTime(civilDawn, location),
+		Sunrise:          toAstronomicalTime(sunrise, location),
+		Sunset:           toAstronomicalTime(sunset, location),
+		CivilDusk:        toAstronomicalTime(civilDusk, location),
+		Moonrise:         toAstronomicalTime(moonrise, location),
+		Moonset:          toAstronomicalTime(moonset, location),
+		MoonPhase:        moonPhase(startJD, endJD),
+		MoonIllumination: math.Round(moonIllumination((startJD+endJD)/2)*1000) / 1000,
+	}
+}
+
+// findCrossings returns the first times between startJD and endJD that the
+// altitude rises through and sets through threshold, or nil if it doesn't.
+func findCrossings(altitude func(jd float64) float64, threshold, startJD, endJD float64) (rising, setting *float64) {
+	step := searchStep.Hours() / 24
+
+	prevJD := startJD
+	prev := altitude(prevJD) - threshold
+	for prevJD < endJD && (rising == nil || setting == nil) {
+		jd := math.Min(prevJD+step, endJD)
+		current := altitude(jd) - threshold
+
+		if prev < 0 && current >= 0 && rising == nil {
+			crossing := bisect(altitude, threshold, prevJD, jd)
+			rising = &crossing
+		} else if prev >= 0 && current < 0 && setting == nil {
+			crossing := bisect(altitude, threshold, prevJD, jd)
+			setting = &crossing
+		}
+
+		prevJD, prev = jd, current
+	}
+
+	return rising, setting
+}
+
+// bisect narrows a bracketed crossing down to about a second
+func bisect(altitude func(jd float64) float64, threshold, lowJD, highJD float64) float64 {
+	lowSign := altitude(lowJD) >= threshold
+	for i := 0; i < 10; i++ {
+		mid := (lowJD + highJD) / 2
+		if (altitude(mid) >= threshold) == lowSign {
+			lowJD = mid
+		} else {
+			highJD = mid
+		}
+	}
+	return (lowJD + highJD) / 2
+}
+
+// sunAltitudeAt returns the altitude of the sun's center seen from lat/lon
+// as a function of the Julian date
+func sunAltitudeAt(lat, lon float64) func(jd float64) float64 {
+	return func(jd float64) float64 {
+		_, ra, dec, _ := sunPosition(jd)
+		return altitude(jd, lat, lon, ra, dec)
+	}
+}
+
+// altitude returns the geocentric altitude in degrees of a body at the given
+// right ascension and declination, seen from lat/lon (east positive)
+func altitude(jd, lat, lon, rightAscension, declination float64) float64 {
+	hourAngle := radians(siderealTime(jd) + lon - rightAscension)
+	latRad, decRad := radians(lat), radians(declination)
+
+	sinAltitude := math.Sin(latRad)*math.Sin(decRad) + math.Cos(latRad)*math.Cos(decRad)*math.Cos(hourAngle)
+	return degrees(math.Asin(sinAltitude))
+}
+
+// siderealTime returns the mean sidereal time at Greenwich in degrees (Meeus 12.4)
+func siderealTime(jd float64) float64 {
+	t := julianCenturies(jd)
+	return normalizeAngle(280.46061837 + 360.98564736629*(jd-2451545) + 0.000387933*t*t - t*t*t/38710000)
+}
+
+// eclipticToEquatorial converts ecliptic coordinates to right ascension and
+// declination, all in degrees (Meeus 13.3 and 13.4)
+func eclipticToEquatorial(longitude, latitude, obliquity float64) (rightAscension, declination float64) {
+	lon, lat, eps := radians(longitude), radians(latitude), radians(obliquity)
+
+	rightAscension = degrees(math.Atan2(math.Sin(lon)*math.Cos(eps)-math.Tan(lat)*math.Sin(eps), math.Cos(lon)))
+	declination = degrees(math.Asin(math.Sin(lat)*math.Cos(eps) + math.Cos(lat)*math.Sin(eps)*math.Sin(lon)))
+
+	return normalizeAngle(rightAscension), declination
+}
+
+// meanObliquity returns the mean obliquity of the ecliptic in degrees (Meeus 22.2)
+func meanObliquity(t float64) float64 {
+	return 23.4392911 - (46.8150*t+0.00059*t*t-0.001813*t*t*t)/3600
+}
+
+func toAstronomicalTime(jd *float64, location *time.Location) *models.AstronomicalTime {
+	if jd == nil {
+		return nil
+	}
+	t := fromJulianDay(*jd).In(location)
+	return &models.AstronomicalTime{
+		Timestamp: t.UnixMilli(),
+		LocalTime: t.Format("2006-01-02T15:04:05"),
+	}
+}
+
+// julianDay returns the Julian date of t. The difference between universal
+// and dynamical time (about a minute) is ignored.
+func julianDay(t time.Time) float64 {
+	return float64(t.UnixMilli())/86400000 + 2440587.5
+}
+
+// fromJulianDay converts a Julian date back to a time, rounded to the second
+func fromJulianDay(jd float64) time.Time {
+	return time.Unix(int64(math.Round((jd-2440587.5)*86400)), 0).UTC()
+}
+
+// julianCenturies returns the Julian centuries since J2000.0
+func julianCenturies(jd float64) float64 {
+	return (jd - 2451545) / 36525
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func degrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
+
+// normalizeAngle maps an angle in degrees onto [0, 360)
+func normalizeAngle(deg float64) float64 {
+	deg = math.Mod(deg, 360)
+	if deg < 0 {
+		deg += 360
+	}
+	return deg
+}
@@ -0,0 +1,166 @@
+package astronomy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDays_Sunrise(t *testing.T) {
+	// Worked example from the USNO Almanac for Computers (1990): Wayne, New
+	// Jersey on 1990 June 25, sunrise at 9h26m UT (5:26 EDT)
+	edt := time.FixedZone("EDT", -4*3600)
+	date := time.Date(1990, 6, 25, 0, 0, 0, 0, edt)
+
+	days := Days(40.9, -74.3, date, date, edt)
+	require.Len(t, days, 1)
+
+	day := days[0]
+	assert.Equal(t, "1990-06-25", day.Date)
+	require.NotNil(t, day.Sunrise)
+	sunrise := time.UnixMilli(day.Sunrise.Timestamp).UTC()
+	assert.WithinDuration(t, time.Date(1990, 6, 25, 9, 26, 0, 0, time.UTC), sunrise, time.Minute)
+	assert.Equal(t, "1990-06-25T05:26", day.Sunrise.LocalTime[:16])
+
+	// Civil twilight brackets the day by about half an hour in June at this latitude
+	require.NotNil(t, day.CivilDawn)
+	require.NotNil(t, day.Sunset)
+	require.NotNil(t, day.CivilDusk)
+	assert.Less(t, day.CivilDawn.Timestamp, day.Sunrise.Timestamp)
+	assert.Less(t, day.Sunrise.Timestamp, day.Sunset.Timestamp)
+	assert.Less(t, day.Sunset.Timestamp, day.CivilDusk.Timestamp)
+	assert.InDelta(t, 33, (day.Sunrise.Timestamp-day.CivilDawn.Timestamp)/60000, 5)
+}
+
+func TestDays_Range(t *testing.T) {
+	pdt := time.FixedZone("PDT", -7*3600)
+	start := time.Date(2024, 4, 6, 12, 0, 0, 0, pdt)
+	end := time.Date(2024, 4, 25, 8, 0, 0, 0, pdt)
+
+	days := Days(47.6, -122.33, start, end, pdt)
+	require.Len(t, days, 20)
+	assert.Equal(t, "2024-04-06", days[0].Date)
+	assert.Equal(t, "2024-04-25", days[19].Date)
+
+	moonrises := 0
+	for i, day := range days {
+		require.NotNil(t, day.Sunrise, day.Date)
+		require.NotNil(t, day.Sunset, day.Date)
+		if i > 0 {
+			// Days lengthen through April
+			assert.Greater(t, day.Sunset.Timestamp-day.Sunrise.Timestamp,
+				days[i-1].Sunset.Timestamp-days[i-1].Sunrise.Timestamp)
+		}
+		if day.Moonrise != nil {
+			moonrises++
+		}
+		assert.GreaterOrEqual(t, day.MoonIllumination, 0.0)
+		assert.LessOrEqual(t, day.MoonIllumination, 1.0)
+	}
+	// The moon rises about 50 minutes later each day, so it misses a day now and then
+	assert.GreaterOrEqual(t, moonrises, 18)
+
+	byDate := make(map[string]models.AstronomyDay)
+	for _, day := range days {
+		byDate[day.Date] = day
+	}
+
+	// The new moon rises with the sun and the full moon with sunset
+	newMoon := byDate["2024-04-08"]
+	assert.Equal(t, models.MoonPhaseNew, newMoon.MoonPhase)
+	assert.Less(t, newMoon.MoonIllumination, 0.01)
+	require.NotNil(t, newMoon.Moonrise)
+	assert.InDelta(t, newMoon.Sunrise.Timestamp, newMoon.Moonrise.Timestamp, float64(time.Hour.Milliseconds()))
+
+	fullMoon := byDate["2024-04-23"]
+	assert.Equal(t, models.MoonPhaseFull, fullMoon.MoonPhase)
+	assert.Greater(t, fullMoon.MoonIllumination, 0.99)
+	require.NotNil(t, fullMoon.Moonrise)
+	assert.InDelta(t, fullMoon.Sunset.Timestamp, fullMoon.Moonrise.Timestamp, float64(time.Hour.Milliseconds()))
+}
+
+func TestDays_PolarDay(t *testing.T) {
+	// Tromsø has midnight sun around the June solstice
+	cest := time.FixedZone("CEST", 2*3600)
+	date := time.Date(2024, 6, 21, 0, 0, 0, 0, cest)
+
+	days := Days(69.65, 18.96, date, date, cest)
+	require.Len(t, days, 1)
+	assert.Nil(t, days[0].CivilDawn)
+	assert.Nil(t, days[0].Sunrise)
+	assert.Nil(t, days[0].Sunset)
+	assert.Nil(t, days[0].CivilDusk)
+}
+
+func TestDaylightPeriods(t *testing.T) {
+	edt := time.FixedZone("EDT", -4*3600)
+
+	tests := []struct {
+		name  string
+		lat   float64
+		lon   float64
+		start time.Time
+		end   time.Time
+		want  int
+	}{
+		{
+			name:  "two days",
+			lat:   40.9,
+			lon:   -74.3,
+			start: time.Date(1990, 6, 25, 0, 0, 0, 0, edt),
+			end:   time.Date(1990, 6, 27, 0, 0, 0, 0, edt),
+			want:  2,
+		},
+		{
+			name:  "starting in daylight",
+			lat:   40.9,
+			lon:   -74.3,
+			start: time.Date(1990, 6, 25, 12, 0, 0, 0, edt),
+			end:   time.Date(1990, 6, 26, 12, 0, 0, 0, edt),
+			want:  2,
+		},
+		{
+			name:  "midnight sun",
+			lat:   69.65,
+			lon:   18.96,
+			start: time.Date(2024, 6, 20, 0, 0, 0, 0, time.UTC),
+			end:   time.Date(2024, 6, 22, 0, 0, 0, 0, time.UTC),
+			want:  1,
+		},
+		{
+			name:  "polar night",
+			lat:   69.65,
+			lon:   18.96,
+			start: time.Date(2024, 12, 20, 0, 0, 0, 0, time.UTC),
+			end:   time.Date(2024, 12, 22, 0, 0, 0, 0, time.UTC),
+			want:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			periods := DaylightPeriods(tt.lat, tt.lon, tt.start, tt.end)
+			require.Len(t, periods, tt.want)
+
+			for i, p := range periods {
+				assert.True(t, p.Start.Before(p.End))
+				assert.False(t, p.Start.Before(tt.start))
+				assert.False(t, p.End.After(tt.end))
+				if i > 0 {
+					assert.True(t, periods[i-1].End.Before(p.Start))
+				}
+			}
+		})
+	}
+
+	// Periods agree with the day's sunrise and sunset
+	start := time.Date(1990, 6, 25, 0, 0, 0, 0, edt)
+	periods := DaylightPeriods(40.9, -74.3, start, start.AddDate(0, 0, 1))
+	require.Len(t, periods, 1)
+	day := Days(40.9, -74.3, start, start, edt)[0]
+	assert.WithinDuration(t, time.UnixMilli(day.Sunrise.Timestamp), periods[0].Start, 2*time.Second)
+	assert.WithinDuration(t, time.UnixMilli(day.Sunset.Timestamp), periods[0].End, 2*time.Second)
+}
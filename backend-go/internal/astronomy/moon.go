@@ -0,0 +1,197 @@
+package astronomy
+
+import (
+	"math"
+	"time"
+
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+)
+
+// Periodic terms of the moon's longitude and distance (Meeus, table 47.A),
+// truncated to those of at least 0.002 degrees. Each row holds the multiples
+// of D, M, M' and F followed by the longitude coefficient (1e-6 degrees) and
+// the distance coefficient (1e-3 km).
+var moonLongitudeTerms = [][6]float64{
+	{0, 0, 1, 0, 6288774, -20905355},
+	{2, 0, -1, 0, 1274027, -3699111},
+	{2, 0, 0, 0, 658314, -2955968},
+	{0, 0, 2, 0, 213618, -569925},
+	{0, 1, 0, 0, -185116, 48888},
+	{0, 0, 0, 2, -114332, -3149},
+	{2, 0, -2, 0, 58793, 246158},
+	{2, -1, -1, 0, 57066, -152138},
+	{2, 0, 1, 0, 53322, -170733},
+	{2, -1, 0, 0, 45758, -204586},
+	{0, 1, -1, 0, -40923, -129620},
+	{1, 0, 0, 0, -34720, 108743},
+	{0, 1, 1, 0, -30383, 104755},
+	{2, 0, 0, -2, 15327, 10321},
+	{0, 0, 1, 2, -12528, 0},
+	{0, 0, 1, -2, 10980, 79661},
+	{4, 0, -1, 0, 10675, -34782},
+	{0, 0, 3, 0, 10034, -23210},
+	{4, 0, -2, 0, 8548, -21636},
+	{2, 1, -1, 0, -7888, 24208},
+	{2, 1, 0, 0, -6766, 30824},
+	{1, 0, -1, 0, -5163, -8379},
+	{1, 1, 0, 0, 4987, -16675},
+	{2, -1, 1, 0, 4036, -12831},
+	{2, 0, 2, 0, 3994, -10445},
+	{4, 0, 0, 0, 3861, -11650},
+	{2, 0, -3, 0, 3665, 14403},
+	{0, 1, -2, 0, -2689, -7003},
+	{2, 0, -1, 2, -2602, 0},
+	{2, -1, -2, 0, 2390, 10056},
+	{1, 0, 1, 0, -2348, 6322},
+	{2, -2, 0, 0, 2236, -9884},
+}
+
+// Periodic terms of the moon's latitude (Meeus, table 47.B), truncated the
+// same way. Each row holds the multiples of D, M, M' and F followed by the
+// coefficient (1e-6 degrees).
+var moonLatitudeTerms = [][5]float64{
+	{0, 0, 0, 1, 5128122},
+	{0, 0, 1, 1, 280602},
+	{0, 0, 1, -1, 277693},
+	{2, 0, 0, -1, 173237},
+	{2, 0, -1, 1, 55413},
+	{2, 0, -1, -1, 46271},
+	{2, 0, 0, 1, 32573},
+	{0, 0, 2, 1, 17198},
+	{2, 0, 1, -1, 9266},
+	{0, 0, 2, -1, 8822},
+	{2, -1, 0, -1, 8216},
+	{2, 0, -2, -1, 4324},
+	{2, 0, 1, 1, 4200},
+	{2, 1, 0, -1, -3359},
+	{2, -1, -1, 1, 2463},
+	{2, -1, 0, 1, 2211},
+	{2, -1, -1, -1, 2065},
+}
+
+// moonPosition returns the moon's apparent ecliptic longitude and latitude
+// (degrees), its right ascension and declination (degrees) and its distance
+// from the center of the earth (km), following Meeus chapter 47.
+func moonPosition(jd float64) (longitude, latitude, rightAscension, declination, distance float64) {
+	t := julianCenturies(jd)
+
+	meanLongitude := 218.3164477 + 481267.88123421*t - 0.0015786*t*t + t*t*t/538841 - t*t*t*t/65194000
+	elongation := 297.8501921 + 445267.1114034*t - 0.0018819*t*t + t*t*t/545868 - t*t*t*t/113065000
+	sunAnomaly := 357.5291092 + 35999.0502909*t - 0.0001536*t*t + t*t*t/24490000
+	moonAnomaly := 134.9633964 + 477198.8675055*t + 0.0087414*t*t + t*t*t/69699 - t*t*t*t/14712000
+	argLatitude := 93.2720950 + 483202.0175233*t - 0.0036539*t*t - t*t*t/3526000 + t*t*t*t/863310000
+
+	a1 := radians(119.75 + 131.849*t)
+	a2 := radians(53.09 + 479264.290*t)
+	a3 := radians(313.45 + 481266.484*t)
+
+	// Terms involving the sun's anomaly shrink with the eccentricity of the earth's orbit
+	eccentricity := 1 - 0.002516*t - 0.0000074*t*t
+	eccentricityFactor := func(m float64) float64 {
+		return math.Pow(eccentricity, math.Abs(m))
+	}
+
+	var sumL, sumR, sumB float64
+	for _, term := range moonLongitudeTerms {
+		arg := radians(term[0]*elongation + term[1]*sunAnomaly + term[2]*moonAnomaly + term[3]*argLatitude)
+		factor := eccentricityFactor(term[1])
+		sumL += term[4] * factor * math.Sin(arg)
+		sumR += term[5] * factor * math.Cos(arg)
+	}
+	for _, term := range moonLatitudeTerms {
+		arg := radians(term[0]*elongation + term[1]*sunAnomaly + term[2]*moonAnomaly + term[3]*argLatitude)
+		sumB += term[4] * eccentricityFactor(term[1]) * math.Sin(arg)
+	}
+
+	// Additive terms for the action of Venus, Jupiter and the flattening of the earth
+	lRad, fRad, mpRad := radians(meanLongitude), radians(argLatitude), radians(moonAnomaly)
+	sumL += 3958*math.Sin(a1) + 1962*math.Sin(lRad-fRad) + 318*math.Sin(a2)
+	sumB += -2235*math.Sin(lRad) + 382*math.Sin(a3) + 175*math.Sin(a1-fRad) +
+		175*math.Sin(a1+fRad) + 127*math.Sin(lRad-mpRad) - 115*math.Sin(lRad+mpRad)
+
+	nutationLongitude, nutationObliquity := nutation(t)
+
+	longitude = normalizeAngle(meanLongitude + sumL/1e6 + nutationLongitude)
+	latitude = sumB / 1e6
+	distance = 385000.56 + sumR/1000
+
+	rightAscension, declination = eclipticToEquatorial(longitude, latitude, meanObliquity(t)+nutationObliquity)
+
+	return longitude, latitude, rightAscension, declination, distance
+}
+
+// moonIllumination returns the fraction of the moon's disk that is lit (Meeus chapter 48)
+func moonIllumination(jd float64) float64 {
+	moonLongitude, moonLatitude, _, _, moonDistance := moonPosition(jd)
+	sunLongitude, _, _, sunDistance := sunPosition(jd)
+	sunDistance *= kmPerAU
+
+	cosElongation := math.Cos(radians(moonLatitude)) * math.Cos(radians(moonLongitude-sunLongitude))
+	elongation := math.Acos(cosElongation)
+	phaseAngle := math.Atan2(sunDistance*math.Sin(elongation), moonDistance-sunDistance*cosElongation)
+
+	return (1 + math.Cos(phaseAngle)) / 2
+}
+
+// MoonPhaseAngle returns how far the moon is ahead of the sun in ecliptic
+// longitude at t, in degrees: 0 at new moon, 90 at first quarter, 180 at
+// full moon and 270 at last quarter.
+func MoonPhaseAngle(t time.Time) float64 {
+	return moonPhaseAngle(julianDay(t))
+}
+
+// moonPhaseAngle is MoonPhaseAngle for a Julian date
+func moonPhaseAngle(jd float64) float64 {
+	moonLongitude, _, _, _, _ := moonPosition(jd)
+	sunLongitude, _, _, _ := sunPosition(jd)
+	return normalizeAngle(moonLongitude - sunLongitude)
+}
+
+// moonPhase names the phase of the moon over the day between the two Julian
+// dates. A principal phase is only returned when the exact phase falls
+// within the day; otherwise the day is named for the phase in between.
+func moonPhase(startJD, endJD float64) models.MoonPhase {
+	start := moonPhaseAngle(startJD)
+	swept := normalizeAngle(moonPhaseAngle(endJD) - start)
+
+	principal := []struct {
+		angle float64
+		phase models.MoonPhase
+	}{
+		{0, models.MoonPhaseNew},
+		{90, models.MoonPhaseFirstQuarter},
+		{180, models.MoonPhaseFull},
+		{270, models.MoonPhaseLastQuarter},
+	}
+	for _, p := range principal {
+		if normalizeAngle(p.angle-start) < swept {
+			return p.phase
+		}
+	}
+
+	switch {
+	case start < 90:
+		return models.MoonPhaseWaxingCrescent
+	case start < 180:
+		return models.MoonPhaseWaxingGibbous
+	case start < 270:
+		return models.MoonPhaseWaningGibbous
+	default:
+		return models.MoonPhaseWaningCrescent
+	}
+}
+
+// nutation returns the nutation in longitude and in obliquity (degrees),
+// accurate to about 0.5 arcseconds (Meeus chapter 22)
+func nutation(t float64) (longitude, obliquity float64) {
+	omega := radians(125.04452 - 1934.136261*t)
+	sunLongitude := radians(280.4665 + 36000.7698*t)
+	moonLongitude := radians(218.3165 + 481267.8813*t)
+
+	longitude = (-17.20*math.Sin(omega) - 1.32*math.Sin(2*sunLongitude) -
+		0.23*math.Sin(2*moonLongitude) + 0.21*math.Sin(2*omega)) / 3600
+	obliquity = (9.20*math.Cos(omega) + 0.57*math.Cos(2*sunLongitude) +
+		0.10*math.Cos(2*moonLongitude) - 0.09*math.Cos(2*omega)) / 3600
+
+	return longitude, obliquity
+}
@@ -0,0 +1,88 @@
+package astronomy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoonPosition(t *testing.T) {
+	// Meeus example 47.a: 1992 April 12, 0h TD. The tables are truncated, so
+	// agreement is to a few thousandths of a degree.
+	longitude, latitude, ra, dec, distance := moonPosition(2448724.5)
+
+	assert.InDelta(t, 133.167265, longitude, 0.002)
+	assert.InDelta(t, -3.229126, latitude, 0.01)
+	assert.InDelta(t, 134.688470, ra, 0.005)
+	assert.InDelta(t, 13.768368, dec, 0.01)
+	assert.InDelta(t, 368409.7, distance, 30)
+}
+
+func TestMoonIllumination(t *testing.T) {
+	// Meeus example 48.a: 1992 April 12, 0h TD
+	assert.InDelta(t, 0.6786, moonIllumination(2448724.5), 1e-3)
+}
+
+func TestMoonPhaseAngle_PrincipalPhases(t *testing.T) {
+	tests := []struct {
+		name  string
+		time  time.Time
+		angle float64
+	}{
+		// Meeus example 49.a, converted from TD
+		{name: "new moon 1977 February 18", time: time.Date(1977, 2, 18, 3, 36, 54, 0, time.UTC), angle: 0},
+		// The new moon of the total solar eclipse
+		{name: "new moon 2024 April 8", time: time.Date(2024, 4, 8, 18, 21, 0, 0, time.UTC), angle: 0},
+		{name: "full moon 2024 April 23", time: time.Date(2024, 4, 23, 23, 49, 0, 0, time.UTC), angle: 180},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The moon gains about half a degree on the sun per hour, so this is
+			// within a couple of minutes of the published time
+			angle := moonPhaseAngle(julianDay(tt.time))
+			assert.InDelta(t, 0, signedAngle(angle-tt.angle), 0.02)
+		})
+	}
+}
+
+func TestMoonPhase(t *testing.T) {
+	day := func(year int, month time.Month, d int) (float64, float64) {
+		start := time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+		return julianDay(start), julianDay(start.AddDate(0, 0, 1))
+	}
+
+	tests := []struct {
+		name  string
+		month time.Month
+		day   int
+		want  models.MoonPhase
+	}{
+		{name: "new moon", month: time.April, day: 8, want: models.MoonPhaseNew},
+		{name: "after new moon", month: time.April, day: 9, want: models.MoonPhaseWaxingCrescent},
+		{name: "first quarter", month: time.April, day: 15, want: models.MoonPhaseFirstQuarter},
+		{name: "waxing gibbous", month: time.April, day: 20, want: models.MoonPhaseWaxingGibbous},
+		{name: "full moon", month: time.April, day: 23, want: models.MoonPhaseFull},
+		{name: "waning gibbous", month: time.April, day: 27, want: models.MoonPhaseWaningGibbous},
+		{name: "last quarter", month: time.May, day: 1, want: models.MoonPhaseLastQuarter},
+		{name: "waning crescent", month: time.May, day: 4, want: models.MoonPhaseWaningCrescent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := day(2024, tt.month, tt.day)
+			assert.Equal(t, tt.want, moonPhase(start, end))
+		})
+	}
+}
+
+// signedAngle maps an angle in degrees onto (-180, 180]
+func signedAngle(deg float64) float64 {
+	deg = normalizeAngle(deg)
+	if deg > 180 {
+		deg -= 360
+	}
+	return deg
+}
@@ -0,0 +1,33 @@
+package astronomy
+
+import "math"
+
+// sunPosition returns the sun's apparent ecliptic longitude (degrees), its
+// right ascension and declination (degrees) and its distance (AU), using the
+// low accuracy method of Meeus, Astronomical Algorithms, chapter 25. It is
+// good to about 0.01 degrees.
+func sunPosition(jd float64) (longitude, rightAscension, declination, distance float64) {
+	t := julianCenturies(jd)
+
+	l0 := 280.46646 + 36000.76983*t + 0.0003032*t*t
+	m := 357.52911 + 35999.05029*t - 0.0001537*t*t
+	e := 0.016708634 - 0.000042037*t - 0.0000001267*t*t
+
+	mRad := radians(m)
+	center := (1.914602-0.004817*t-0.000014*t*t)*math.Sin(mRad) +
+		(0.019993-0.000101*t)*math.Sin(2*mRad) +
+		0.000289*math.Sin(3*mRad)
+
+	trueLongitude := l0 + center
+	trueAnomaly := radians(m + center)
+	distance = 1.000001018 * (1 - e*e) / (1 + e*math.Cos(trueAnomaly))
+
+	// Correct for nutation and aberration
+	omega := radians(125.04 - 1934.136*t)
+	longitude = normalizeAngle(trueLongitude - 0.00569 - 0.00478*math.Sin(omega))
+
+	obliquity := meanObliquity(t) + 0.00256*math.Cos(omega)
+	rightAscension, declination = eclipticToEquatorial(longitude, 0, obliquity)
+
+	return longitude, rightAscension, declination, distance
+}
@@ -0,0 +1,22 @@
+package astronomy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSunPosition(t *testing.T) {
+	// Meeus, Astronomical Algorithms, example 25.a: 1992 October 13.0 TD
+	longitude, ra, dec, distance := sunPosition(2448908.5)
+
+	assert.InDelta(t, 199.90895, longitude, 1e-4)
+	assert.InDelta(t, 198.38083, ra, 1e-4)
+	assert.InDelta(t, -7.78507, dec, 1e-4)
+	assert.InDelta(t, 0.99766, distance, 1e-4)
+}
+
+func TestSiderealTime(t *testing.T) {
+	// Meeus example 12.a: 1987 April 10, 0h UT, 13h10m46.3668s
+	assert.InDelta(t, 197.693195, siderealTime(2446895.5), 1e-5)
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"fmt"
+	"github.com/bbernstein/flowebb/backend-go/internal/config"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"sync"
+	"time"
+)
+
+type conditionsEntry struct {
+	conditions *models.MarineConditions
+	expiresAt  time.Time
+}
+
+// ConditionsCache holds the latest met readings per station and units for
+// a few minutes, like ObservationCache does for water levels.
+type ConditionsCache struct {
+	entries map[string]conditionsEntry
+	mu      sync.RWMutex
+	ttl     time.Duration
+	clock   clock
+}
+
+func NewConditionsCache(cacheConfig *config.CacheConfig) *ConditionsCache {
+	// If no config provided, use default config
+	if cacheConfig == nil {
+		cacheConfig = config.GetCacheConfig()
+	}
+
+	return &ConditionsCache{
+		entries: make(map[string]conditionsEntry),
+		ttl:     cacheConfig.GetObservationTTL(),
+		clock:   &systemClock{},
+	}
+}
+
+func conditionsKey(stationID string, units models.Units) string {
+	return fmt.Sprintf("%s:%s", stationID, units.OrDefault())
+}
+
+// GetConditions returns the cached conditions and whether there was an
+// unexpired entry. Nil conditions with ok set mean the station recently
+// returned no readings.
+func (c *ConditionsCache) GetConditions(stationID string, units models.Units) (*models.MarineConditions, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[conditionsKey(stationID, units)]
+	if !ok || !entry.expiresAt.After(c.clock.Now()) {
+		return nil, false
+	}
+	return entry.conditions, true
+}
+
+// SetConditions caches conditions, or nil to record that the station has no readings
+func (c *ConditionsCache) SetConditions(stationID string, units models.Units, conditions *models.MarineConditions) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock.Now()
+	c.entries[conditionsKey(stationID, units)] = conditionsEntry{
+		conditions: conditions,
+		expiresAt:  now.Add(c.ttl),
+	}
+
+	// Drop expired entries so stations that are no longer requested don't accumulate
+	for key, entry := range c.entries {
+		if !entry.expiresAt.After(now) {
+			delete(c.entries, key)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"github.com/bbernstein/flowebb/backend-go/internal/config"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestConditionsCache(t *testing.T) {
+	t.Parallel()
+
+	cache := NewConditionsCache(&config.CacheConfig{ObservationTTLMinutes: 6})
+	mockClock := &fakeClock{now: time.Now()}
+	cache.clock = mockClock
+
+	conditions := &models.MarineConditions{
+		AirTemperature: &models.MetReading{Timestamp: mockClock.Now().UnixMilli(), Value: 61.2, Units: "F"},
+	}
+
+	_, ok := cache.GetConditions("9447130", models.UnitsFeet)
+	assert.False(t, ok)
+
+	cache.SetConditions("9447130", models.UnitsFeet, conditions)
+	cache.SetConditions("9414305", models.UnitsFeet, nil)
+
+	got, ok := cache.GetConditions("9447130", models.UnitsFeet)
+	require.True(t, ok)
+	assert.Equal(t, conditions, got)
+
+	got, ok = cache.GetConditions("9414305", models.UnitsFeet)
+	assert.True(t, ok, "a station without readings should be cached")
+	assert.Nil(t, got)
+
+	// Readings in other units are separate
+	_, ok = cache.GetConditions("9447130", models.UnitsMeters)
+	assert.False(t, ok)
+
+	// Expires after the TTL
+	mockClock.Advance(7 * time.Minute)
+	_, ok = cache.GetConditions("9447130", models.UnitsFeet)
+	assert.False(t, ok)
+}
@@ -10,21 +10,29 @@ import (
 	"github.com/bbernstein/flowebb/backend-go/internal/config"
 	"github.com/bbernstein/flowebb/backend-go/internal/models"
 	"github.com/rs/zerolog/log"
+	"sync"
 	"time"
 )
 
 const (
-	tableName         = "tide-predictions-cache"
-	cacheValidityDays = 7
+	tableName = "tide-predictions-cache"
 )
 
-// DynamoPredictionCache handles caching tide predictions in DynamoDB
+// DynamoPredictionCache handles caching tide predictions in DynamoDB.
+// Records are kept until the hard TTL, which DynamoDB expires them by, and
+// marked stale once they're older than the soft TTL.
 type DynamoPredictionCache struct {
-	client DynamoDBClient
-	config *config.CacheConfig
-	clock  clock
+	client     DynamoDBClient
+	config     *config.CacheConfig
+	clock      clock
+	statsMutex sync.RWMutex
+	hits       uint64
+	staleHits  uint64
+	misses     uint64
 }
 
+var _ CacheService = (*DynamoPredictionCache)(nil)
+
 func NewDynamoPredictionCache(client DynamoDBClient, cacheConfig *config.CacheConfig) *DynamoPredictionCache {
 	if cacheConfig == nil {
 		cacheConfig = config.GetCacheConfig()
@@ -36,15 +44,36 @@ func NewDynamoPredictionCache(client DynamoDBClient, cacheConfig *config.CacheCo
 	}
 }
 
-// GetPredictions retrieves cached predictions for a station and date
-func (c *DynamoPredictionCache) GetPredictions(ctx context.Context, stationID string, date time.Time) (*models.TidePredictionRecord, error) {
+// dateKey builds the table's sort key from a record's date and datum. MLLW
+// records keep the bare date so entries cached before datums were selectable
+// are still found.
+func dateKey(date string, datum models.Datum) string {
+	datum = datum.OrDefault()
+	if datum == models.DefaultDatum {
+		return date
+	}
+	return date + "#" + string(datum)
+}
+
+// marshalRecord converts a record to a DynamoDB item keyed by date and datum
+func marshalRecord(record models.TidePredictionRecord) (map[string]types.AttributeValue, error) {
+	item, err := attributevalue.MarshalMap(record)
+	if err != nil {
+		return nil, err
+	}
+	item["date"] = &types.AttributeValueMemberS{Value: dateKey(record.Date, record.Datum)}
+	return item, nil
+}
+
+// GetPredictions retrieves cached predictions for a station, date and datum
+func (c *DynamoPredictionCache) GetPredictions(ctx context.Context, stationID string, date time.Time, datum models.Datum) (*models.TidePredictionRecord, error) {
 	dateStr := date.Format("2006-01-02")
 
 	input := &dynamodb.GetItemInput{
 		TableName: aws.String(tableName),
 		Key: map[string]types.AttributeValue{
 			"stationId": &types.AttributeValueMemberS{Value: stationID},
-			"date":      &types.AttributeValueMemberS{Value: dateStr},
+			"date":      &types.AttributeValueMemberS{Value: dateKey(dateStr, datum)},
 		},
 	}
 
@@ -54,6 +83,7 @@ func (c *DynamoPredictionCache) GetPredictions(ctx context.Context, stationID st
 	}
 
 	if result.Item == nil {
+		c.recordLookup(false)
 		return nil, nil
 	}
 
@@ -61,6 +91,8 @@ func (c *DynamoPredictionCache) GetPredictions(ctx context.Context, stationID st
 	if err := attributevalue.UnmarshalMap(result.Item, &record); err != nil {
 		return nil, fmt.Errorf("unmarshaling prediction record: %w", err)
 	}
+	record.Date = dateStr
+	record.Datum = datum.OrDefault()
 
 	// Check if cache is valid
 	if !c.isValid(record) {
@@ -68,9 +100,21 @@ func (c *DynamoPredictionCache) GetPredictions(ctx context.Context, stationID st
 			Str("station_id", stationID).
 			Str("date", dateStr).
 			Msg("Cache expired")
+		c.recordLookup(false)
 		return nil, nil
 	}
 
+	record.Stale = c.isStale(record)
+	if record.Stale {
+		log.Debug().
+			Str("station_id", stationID).
+			Str("date", dateStr).
+			Msg("Cache stale")
+		c.recordStaleHit()
+		return &record, nil
+	}
+
+	c.recordLookup(true)
 	return &record, nil
 }
 
@@ -83,9 +127,9 @@ func (c *DynamoPredictionCache) SavePredictions(ctx context.Context, record mode
 
 	now := c.clock.Now().Unix()
 	record.LastUpdated = now
-	record.TTL = now + (cacheValidityDays * 24 * 60 * 60)
+	record.TTL = now + int64(c.config.GetDynamoHardTTL().Seconds())
 
-	item, err := attributevalue.MarshalMap(record)
+	item, err := marshalRecord(record)
 	if err != nil {
 		return fmt.Errorf("marshaling prediction record: %w", err)
 	}
@@ -125,10 +169,10 @@ func (c *DynamoPredictionCache) SavePredictionsBatch(ctx context.Context, record
 		for _, record := range batch {
 			now := c.clock.Now().Unix()
 			record.LastUpdated = now
-			// Use configured TTL
-			record.TTL = now + int64(c.config.GetDynamoTTL().Seconds())
+			// DynamoDB expires records at the hard TTL
+			record.TTL = now + int64(c.config.GetDynamoHardTTL().Seconds())
 
-			item, err := attributevalue.MarshalMap(record)
+			item, err := marshalRecord(record)
 			if err != nil {
 				return fmt.Errorf("marshaling prediction record: %w", err)
 			}
@@ -167,7 +211,43 @@ func (c *DynamoPredictionCache) SavePredictionsBatch(ctx context.Context, record
 	return nil
 }
 
+// isValid reports whether the record is within the hard TTL
 func (c *DynamoPredictionCache) isValid(record models.TidePredictionRecord) bool {
 	now := c.clock.Now().Unix()
 	return now < record.TTL
 }
+
+// isStale reports whether the record is older than the soft TTL
+func (c *DynamoPredictionCache) isStale(record models.TidePredictionRecord) bool {
+	now := c.clock.Now().Unix()
+	return now >= record.LastUpdated+int64(c.config.GetDynamoTTL().Seconds())
+}
+
+// GetCacheStats returns statistics about cache hits and misses. Stale hits
+// are counted apart from hits.
+func (c *DynamoPredictionCache) GetCacheStats() map[string]uint64 {
+	c.statsMutex.RLock()
+	defer c.statsMutex.RUnlock()
+
+	return map[string]uint64{
+		"dynamo_hits":       c.hits,
+		"dynamo_stale_hits": c.staleHits,
+		"dynamo_misses":     c.misses,
+	}
+}
+
+func (c *DynamoPredictionCache) recordLookup(hit bool) {
+	c.statsMutex.Lock()
+	if hit {
+		c.hits++
+	} else {
+		c.misses++
+	}
+	c.statsMutex.Unlock()
+}
+
+func (c *DynamoPredictionCache) recordStaleHit() {
+	c.statsMutex.Lock()
+	c.staleHits++
+	c.statsMutex.Unlock()
+}
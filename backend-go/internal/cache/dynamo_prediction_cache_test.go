@@ -8,6 +8,7 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/bbernstein/flowebb/backend-go/internal/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -21,6 +22,22 @@ type mockDynamoDBClient struct {
 	putItemFunc        func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
 	batchWriteItemFunc func(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error)
 	listTablesFunc     func(ctx context.Context, params *dynamodb.ListTablesInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ListTablesOutput, error)
+	updateItemFunc     func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
+	scanFunc           func(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+}
+
+func (m *mockDynamoDBClient) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	if m.updateItemFunc != nil {
+		return m.updateItemFunc(ctx, params, optFns...)
+	}
+	return &dynamodb.UpdateItemOutput{}, nil
+}
+
+func (m *mockDynamoDBClient) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	if m.scanFunc != nil {
+		return m.scanFunc(ctx, params, optFns...)
+	}
+	return &dynamodb.ScanOutput{}, nil
 }
 
 func (m *mockDynamoDBClient) ListTables(ctx context.Context, params *dynamodb.ListTablesInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ListTablesOutput, error) {
@@ -125,7 +142,7 @@ func TestGetPredictions(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			cache := NewDynamoPredictionCache(tt.mockSetup(), testConfig)
-			got, err := cache.GetPredictions(context.Background(), tt.stationID, tt.date)
+			got, err := cache.GetPredictions(context.Background(), tt.stationID, tt.date, models.DatumMLLW)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -142,6 +159,49 @@ func TestGetPredictions(t *testing.T) {
 	}
 }
 
+func TestPredictionsKeyedByDatum(t *testing.T) {
+	tests := []struct {
+		name    string
+		datum   models.Datum
+		wantKey string
+	}{
+		{name: "default datum keeps bare date", datum: models.DatumMLLW, wantKey: "2024-01-15"},
+		{name: "unset datum keeps bare date", datum: "", wantKey: "2024-01-15"},
+		{name: "other datum is appended", datum: models.DatumNAVD88, wantKey: "2024-01-15#NAVD88"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := createTestPredictionRecord()
+			record.Date = "2024-01-15"
+			record.Datum = tt.datum
+
+			var stored map[string]types.AttributeValue
+			client := &mockDynamoDBClient{
+				putItemFunc: func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+					stored = params.Item
+					return &dynamodb.PutItemOutput{}, nil
+				},
+				getItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+					assert.Equal(t, stored["date"], params.Key["date"])
+					return &dynamodb.GetItemOutput{Item: stored}, nil
+				},
+			}
+
+			cache := NewDynamoPredictionCache(client, testConfig)
+			require.NoError(t, cache.SavePredictions(context.Background(), record))
+			assert.Equal(t, &types.AttributeValueMemberS{Value: tt.wantKey}, stored["date"])
+
+			date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+			got, err := cache.GetPredictions(context.Background(), record.StationID, date, tt.datum)
+			require.NoError(t, err)
+			require.NotNil(t, got)
+			assert.Equal(t, "2024-01-15", got.Date)
+			assert.Equal(t, tt.datum.OrDefault(), got.Datum)
+		})
+	}
+}
+
 func TestSavePredictions(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -274,3 +334,54 @@ func TestCacheValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestGetPredictions_Stale(t *testing.T) {
+	cfg := &config.CacheConfig{TidePredictionDynamoTTLDays: 2, TidePredictionDynamoHardTTLDays: 7}
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		age       time.Duration
+		wantFound bool
+		wantStale bool
+	}{
+		{name: "within soft TTL", age: 24 * time.Hour, wantFound: true},
+		{name: "past soft TTL", age: 3 * 24 * time.Hour, wantFound: true, wantStale: true},
+		{name: "past hard TTL", age: 8 * 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stored map[string]types.AttributeValue
+			client := &mockDynamoDBClient{
+				putItemFunc: func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+					stored = params.Item
+					return &dynamodb.PutItemOutput{}, nil
+				},
+				getItemFunc: func(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+					return &dynamodb.GetItemOutput{Item: stored}, nil
+				},
+			}
+			clock := &mockClock{now: now.Add(-tt.age)}
+			cache := NewDynamoPredictionCache(client, cfg)
+			cache.clock = clock
+
+			record := createTestPredictionRecord()
+			require.NoError(t, cache.SavePredictions(context.Background(), record))
+
+			clock.now = now
+			got, err := cache.GetPredictions(context.Background(), record.StationID, now, models.DatumMLLW)
+			require.NoError(t, err)
+			if !tt.wantFound {
+				assert.Nil(t, got)
+				assert.Equal(t, uint64(1), cache.GetCacheStats()["dynamo_misses"])
+				return
+			}
+			require.NotNil(t, got)
+			assert.Equal(t, tt.wantStale, got.Stale)
+			if tt.wantStale {
+				assert.Equal(t, uint64(1), cache.GetCacheStats()["dynamo_stale_hits"])
+			}
+		})
+	}
+}
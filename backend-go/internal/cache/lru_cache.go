@@ -3,9 +3,11 @@ package cache
 import (
 	"context"
 	"fmt"
+	"github.com/bbernstein/flowebb/backend-go/internal/coalesce"
 	"github.com/bbernstein/flowebb/backend-go/internal/config"
 	"github.com/bbernstein/flowebb/backend-go/internal/models"
 	"github.com/hashicorp/golang-lru/v2"
+	"github.com/rs/zerolog/log"
 	"sync"
 	"time"
 )
@@ -20,144 +22,231 @@ func (s *systemClock) Now() time.Time {
 	return time.Now()
 }
 
-// LRUCacheEntry wraps the cached data with metadata
+// LRUCacheEntry wraps the cached data with metadata. Entries are served as
+// they are until StaleAt, then served while they're re-read from the next
+// layer until ExpiresAt.
 type LRUCacheEntry struct {
 	Data      *models.TidePredictionRecord
+	StaleAt   time.Time
 	ExpiresAt time.Time
 }
 
 type CacheService interface {
-	GetPredictions(ctx context.Context, stationID string, date time.Time) (*models.TidePredictionRecord, error)
+	GetPredictions(ctx context.Context, stationID string, date time.Time, datum models.Datum) (*models.TidePredictionRecord, error)
 	SavePredictionsBatch(ctx context.Context, records []models.TidePredictionRecord) error
 }
 
-// LRUCacheService provides a two-layer caching system using LRU and DynamoDB
+// statsProvider is implemented by cache layers that count hits and misses
+type statsProvider interface {
+	GetCacheStats() map[string]uint64
+}
+
+// recordSaver is implemented by cache layers that save single records
+// without batching them
+type recordSaver interface {
+	SavePredictions(ctx context.Context, record models.TidePredictionRecord) error
+}
+
+// NewCacheService creates the prediction cache layers the config enables,
+// an in-memory LRU cache in front of DynamoDB. With neither enabled nothing
+// is cached.
+func NewCacheService(ctx context.Context, config *config.CacheConfig) (CacheService, error) {
+	var layer CacheService = NoopCacheService{}
+
+	if config.EnableDynamoCache {
+		dynamoClient, err := NewDynamoClient(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("creating DynamoDB client: %w", err)
+		}
+		layer = NewDynamoPredictionCache(dynamoClient, config)
+	}
+
+	if config.EnableLRUCache {
+		lruCache, err := NewLRUCacheService(config, layer)
+		if err != nil {
+			return nil, err
+		}
+		layer = lruCache
+	}
+
+	return layer, nil
+}
+
+// LRUCacheService caches predictions in memory in front of another cache
+// layer, which it reads through and writes through to. Entries past the soft
+// TTL are re-read from the next layer in the background; records the next
+// layer no longer has are marked stale, so callers refresh them from the
+// source.
 type LRUCacheService struct {
 	lru          *lru.Cache[string, *LRUCacheEntry]
-	dynamoCache  *DynamoPredictionCache
+	next         CacheService
 	ttl          time.Duration
+	hardTTL      time.Duration
 	clock        clock
+	revalidation coalesce.Group // One re-read of an entry at a time
 	statsMutex   sync.RWMutex
 	lruHits      uint64
+	lruStaleHits uint64
 	lruMisses    uint64
-	dynamoHits   uint64
-	dynamoMisses uint64
 }
 
-// NewCacheService creates a new cache service with both LRU and DynamoDB caching
-func NewCacheService(ctx context.Context, config *config.CacheConfig) (*LRUCacheService, error) {
+// NewLRUCacheService creates an LRU cache in front of next, or on its own
+// when next is nil
+func NewLRUCacheService(config *config.CacheConfig, next CacheService) (*LRUCacheService, error) {
 	lruCache, err := lru.New[string, *LRUCacheEntry](config.TidePredictionLRUSize)
 	if err != nil {
 		return nil, fmt.Errorf("creating LRU cache: %w", err)
 	}
 
-	dynamoClient, err := NewDynamoClient(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("creating DynamoDB client: %w", err)
+	if next == nil {
+		next = NoopCacheService{}
 	}
 
 	return &LRUCacheService{
-		lru:         lruCache,
-		dynamoCache: NewDynamoPredictionCache(dynamoClient, config),
-		ttl:         config.GetTidePredictionLRUTTL(),
-		clock:       &systemClock{},
+		lru:     lruCache,
+		next:    next,
+		ttl:     config.GetTidePredictionLRUTTL(),
+		hardTTL: config.GetTidePredictionLRUHardTTL(),
+		clock:   &systemClock{},
 	}, nil
 }
 
-// getCacheKey generates a unique cache key for a station and date string
-func getCacheKey(stationID string, date string) string {
-	return fmt.Sprintf("%s:%s", stationID, date)
+// getCacheKey generates a unique cache key for a station, date string and datum
+func getCacheKey(stationID string, date string, datum models.Datum) string {
+	return fmt.Sprintf("%s:%s:%s", stationID, date, datum.OrDefault())
 }
 
-// GetPredictions tries to get predictions first from LRU cache, then from DynamoDB
-func (c *LRUCacheService) GetPredictions(ctx context.Context, stationID string, date time.Time) (*models.TidePredictionRecord, error) {
+// GetPredictions tries to get predictions first from the LRU cache, then from
+// the next layer
+func (c *LRUCacheService) GetPredictions(ctx context.Context, stationID string, date time.Time, datum models.Datum) (*models.TidePredictionRecord, error) {
 	// Try LRU cache
-	key := getCacheKey(stationID, date.Format("2006-01-02"))
+	key := getCacheKey(stationID, date.Format("2006-01-02"), datum)
 	if entry, ok := c.lru.Get(key); ok {
-		if entry.ExpiresAt.After(c.clock.Now()) {
+		now := c.clock.Now()
+		switch {
+		case entry.StaleAt.After(now):
 			c.incrementLRUHits()
 			return entry.Data, nil
-		} else {
+		case entry.ExpiresAt.After(now):
+			c.incrementLRUStaleHits()
+			c.revalidate(key, entry, stationID, date, datum)
+			return entry.Data, nil
+		default:
 			c.lru.Remove(key)
 		}
 	}
 
 	c.incrementLRUMisses()
 
-	// Try DynamoDB cache
-	record, err := c.dynamoCache.GetPredictions(ctx, stationID, date)
+	// Try the next layer
+	record, err := c.next.GetPredictions(ctx, stationID, date, datum)
 	if err != nil {
-		return nil, fmt.Errorf("getting predictions from DynamoDB: %w", err)
+		return nil, err
 	}
 
-	if record != nil {
-		c.incrementDynamoHits()
-		// Save to LRU cache
-		if err := c.SavePredictions(ctx, *record); err != nil {
-			return nil, fmt.Errorf("saving predictions to LRU cache: %w", err)
-		}
-		return record, nil
+	// Save to LRU cache only, as the next layer already has it. Stale
+	// records are left to the next layer until they're refreshed.
+	if record != nil && !record.Stale {
+		c.lru.Add(key, c.newEntry(record))
+	}
+
+	return record, nil
+}
+
+// newEntry wraps a record with the LRU cache's TTLs
+func (c *LRUCacheService) newEntry(record *models.TidePredictionRecord) *LRUCacheEntry {
+	now := c.clock.Now().Truncate(time.Second)
+	return &LRUCacheEntry{
+		Data:      record,
+		StaleAt:   now.Add(c.ttl),
+		ExpiresAt: now.Add(c.hardTTL),
 	}
-	c.incrementDynamoMisses()
+}
 
-	return nil, nil
+// revalidationTimeout bounds a background read of a stale entry
+const revalidationTimeout = 30 * time.Second
+
+// revalidate re-reads a stale entry from the next layer in the background.
+// The entry is replaced by what's read, unless it was replaced meanwhile, or
+// marked stale when there's nothing to read.
+func (c *LRUCacheService) revalidate(key string, entry *LRUCacheEntry, stationID string, date time.Time, datum models.Datum) {
+	go func() {
+		_, err := c.revalidation.DoContext(context.Background(), key, revalidationTimeout, func(ctx context.Context) (interface{}, error) {
+			record, err := c.next.GetPredictions(ctx, stationID, date, datum)
+			if err != nil {
+				return nil, err
+			}
+
+			if current, ok := c.lru.Peek(key); !ok || current != entry {
+				return nil, nil
+			}
+			if record != nil {
+				c.lru.Add(key, c.newEntry(record))
+				return nil, nil
+			}
+
+			stale := *entry.Data
+			stale.Stale = true
+			c.lru.Add(key, &LRUCacheEntry{Data: &stale, StaleAt: entry.StaleAt, ExpiresAt: entry.ExpiresAt})
+			return nil, nil
+		})
+		if err != nil {
+			log.Warn().Err(err).
+				Str("station_id", stationID).
+				Time("date", date).
+				Msg("Error revalidating cached predictions")
+		}
+	}()
 }
 
-// SavePredictions saves predictions to both LRU and DynamoDB caches
+// SavePredictions saves predictions to the LRU cache and the next layer
 func (c *LRUCacheService) SavePredictions(ctx context.Context, record models.TidePredictionRecord) error {
 	if err := record.Validate(); err != nil {
 		return fmt.Errorf("invalid prediction record: %w", err)
 	}
 
-	key := getCacheKey(record.StationID, record.Date)
+	key := getCacheKey(record.StationID, record.Date, record.Datum)
 
 	// Save to LRU cache
-	c.lru.Add(key, &LRUCacheEntry{
-		Data:      &record,
-		ExpiresAt: c.clock.Now().Truncate(time.Second).Add(c.ttl),
-	})
-
-	// Save to DynamoDB
-	if err := c.dynamoCache.SavePredictions(ctx, record); err != nil {
-		return fmt.Errorf("saving predictions to DynamoDB: %w", err)
-	}
+	c.lru.Add(key, c.newEntry(&record))
 
-	return nil
+	// Save to the next layer
+	if saver, ok := c.next.(recordSaver); ok {
+		return saver.SavePredictions(ctx, record)
+	}
+	return c.next.SavePredictionsBatch(ctx, []models.TidePredictionRecord{record})
 }
 
-// SavePredictionsBatch saves multiple predictions to both caches
+// SavePredictionsBatch saves multiple predictions to the LRU cache and the
+// next layer
 func (c *LRUCacheService) SavePredictionsBatch(ctx context.Context, records []models.TidePredictionRecord) error {
 	// Save to LRU cache
 	for _, record := range records {
 		// Create a copy of the record
 		recordCopy := record // Make a copy of the record
 
-		key := getCacheKey(recordCopy.StationID, recordCopy.Date)
-		c.lru.Add(key, &LRUCacheEntry{
-			Data:      &recordCopy,
-			ExpiresAt: c.clock.Now().Truncate(time.Second).Add(c.ttl),
-		})
-	}
-
-	// Save to DynamoDB
-	if err := c.dynamoCache.SavePredictionsBatch(ctx, records); err != nil {
-		return fmt.Errorf("saving predictions batch to DynamoDB: %w", err)
+		key := getCacheKey(recordCopy.StationID, recordCopy.Date, recordCopy.Datum)
+		c.lru.Add(key, c.newEntry(&recordCopy))
 	}
 
-	return nil
+	// Save to the next layer
+	return c.next.SavePredictionsBatch(ctx, records)
 }
 
-// GetCacheStats returns statistics about cache hits and misses
+// GetCacheStats returns statistics about cache hits and misses, including
+// those of the layers behind the LRU cache
 func (c *LRUCacheService) GetCacheStats() map[string]uint64 {
+	stats := map[string]uint64{}
+	if next, ok := c.next.(statsProvider); ok {
+		stats = next.GetCacheStats()
+	}
+
 	c.statsMutex.RLock()
 	defer c.statsMutex.RUnlock()
-
-	return map[string]uint64{
-		"lru_hits":      c.lruHits,
-		"lru_misses":    c.lruMisses,
-		"dynamo_hits":   c.dynamoHits,
-		"dynamo_misses": c.dynamoMisses,
-	}
+	stats["lru_hits"] = c.lruHits
+	stats["lru_stale_hits"] = c.lruStaleHits
+	stats["lru_misses"] = c.lruMisses
+	return stats
 }
 
 // Clear removes all entries from the LRU cache
@@ -171,20 +260,14 @@ func (c *LRUCacheService) incrementLRUHits() {
 	c.statsMutex.Unlock()
 }
 
-func (c *LRUCacheService) incrementLRUMisses() {
-	c.statsMutex.Lock()
-	c.lruMisses++
-	c.statsMutex.Unlock()
-}
-
-func (c *LRUCacheService) incrementDynamoHits() {
+func (c *LRUCacheService) incrementLRUStaleHits() {
 	c.statsMutex.Lock()
-	c.dynamoHits++
+	c.lruStaleHits++
 	c.statsMutex.Unlock()
 }
 
-func (c *LRUCacheService) incrementDynamoMisses() {
+func (c *LRUCacheService) incrementLRUMisses() {
 	c.statsMutex.Lock()
-	c.dynamoMisses++
+	c.lruMisses++
 	c.statsMutex.Unlock()
 }
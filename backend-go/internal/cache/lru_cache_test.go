@@ -34,6 +34,22 @@ type mockDynamoDBClientLRU struct {
 	putItemFunc        func(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
 	batchWriteItemFunc func(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error)
 	listTablesFunc     func(ctx context.Context, params *dynamodb.ListTablesInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ListTablesOutput, error)
+	updateItemFunc     func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
+	scanFunc           func(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+}
+
+func (m *mockDynamoDBClientLRU) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	if m.updateItemFunc != nil {
+		return m.updateItemFunc(ctx, params, optFns...)
+	}
+	return &dynamodb.UpdateItemOutput{}, nil
+}
+
+func (m *mockDynamoDBClientLRU) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	if m.scanFunc != nil {
+		return m.scanFunc(ctx, params, optFns...)
+	}
+	return &dynamodb.ScanOutput{}, nil
 }
 
 func (m *mockDynamoDBClientLRU) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
@@ -91,15 +107,16 @@ func createTestCacheService(t *testing.T, cfg *config.CacheConfig) *LRUCacheServ
 		},
 	}
 
-	service, err := NewCacheService(context.Background(), cfg)
+	// Pass the fake clock to DynamoPredictionCache
+	fakeClock := &fakeClock{now: time.Now().UTC()}
+	dynamoCache := NewDynamoPredictionCache(mockDynamo, cfg)
+	dynamoCache.clock = fakeClock // Use the fake clock
+
+	service, err := NewLRUCacheService(cfg, dynamoCache)
 	if err != nil {
 		t.Fatalf("failed to create cache service: %v", err)
 		return nil
 	}
-	// Pass the fake clock to DynamoPredictionCache
-	fakeClock := &fakeClock{now: time.Now().UTC()}
-	service.dynamoCache = NewDynamoPredictionCache(mockDynamo, cfg)
-	service.dynamoCache.clock = fakeClock // Use the fake clock
 	service.clock = fakeClock
 
 	return service
@@ -107,21 +124,52 @@ func createTestCacheService(t *testing.T, cfg *config.CacheConfig) *LRUCacheServ
 
 func TestNewCacheService(t *testing.T) {
 	tests := []struct {
-		name      string
-		lruSize   int
-		ttl       time.Duration
-		wantError bool
+		name       string
+		lruSize    int
+		enableLRU  bool
+		enableDyn  bool
+		wantError  bool
+		checkLayer func(t *testing.T, service CacheService)
 	}{
 		{
-			name:      "valid configuration",
+			name:      "LRU in front of DynamoDB",
+			lruSize:   1000,
+			enableLRU: true,
+			enableDyn: true,
+			checkLayer: func(t *testing.T, service CacheService) {
+				lruService, ok := service.(*LRUCacheService)
+				require.True(t, ok)
+				assert.NotNil(t, lruService.lru)
+				assert.IsType(t, &DynamoPredictionCache{}, lruService.next)
+			},
+		},
+		{
+			name:      "LRU only",
 			lruSize:   1000,
-			ttl:       15 * time.Minute,
-			wantError: false,
+			enableLRU: true,
+			checkLayer: func(t *testing.T, service CacheService) {
+				lruService, ok := service.(*LRUCacheService)
+				require.True(t, ok)
+				assert.Equal(t, NoopCacheService{}, lruService.next)
+			},
+		},
+		{
+			name:      "DynamoDB only",
+			enableDyn: true,
+			checkLayer: func(t *testing.T, service CacheService) {
+				assert.IsType(t, &DynamoPredictionCache{}, service)
+			},
+		},
+		{
+			name: "caching disabled",
+			checkLayer: func(t *testing.T, service CacheService) {
+				assert.Equal(t, NoopCacheService{}, service)
+			},
 		},
 		{
 			name:      "zero size",
 			lruSize:   0,
-			ttl:       15 * time.Minute,
+			enableLRU: true,
 			wantError: true,
 		},
 	}
@@ -130,7 +178,9 @@ func TestNewCacheService(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			cfg := &config.CacheConfig{
 				TidePredictionLRUSize:       tt.lruSize,
-				TidePredictionLRUTTLMinutes: int(tt.ttl.Minutes()),
+				TidePredictionLRUTTLMinutes: 15,
+				EnableLRUCache:              tt.enableLRU,
+				EnableDynamoCache:           tt.enableDyn,
 			}
 
 			// Create service directly instead of using helper function
@@ -140,15 +190,41 @@ func TestNewCacheService(t *testing.T) {
 				assert.Error(t, err)
 				assert.Nil(t, service)
 			} else {
-				assert.NoError(t, err)
-				assert.NotNil(t, service)
-				assert.NotNil(t, service.lru)
-				assert.NotNil(t, service.dynamoCache)
+				require.NoError(t, err)
+				tt.checkLayer(t, service)
 			}
 		})
 	}
 }
 
+func TestLRUCacheServiceWithoutNextLayer(t *testing.T) {
+	cfg := &config.CacheConfig{
+		TidePredictionLRUSize:       10,
+		TidePredictionLRUTTLMinutes: 15,
+	}
+
+	service, err := NewLRUCacheService(cfg, nil)
+	require.NoError(t, err)
+
+	date := time.Now()
+	record := models.TidePredictionRecord{
+		StationID:   "TEST001",
+		Date:        date.Format("2006-01-02"),
+		StationType: "R",
+	}
+	require.NoError(t, service.SavePredictionsBatch(context.Background(), []models.TidePredictionRecord{record}))
+
+	result, err := service.GetPredictions(context.Background(), "TEST001", date, models.DatumMLLW)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+
+	result, err = service.GetPredictions(context.Background(), "TEST002", date, models.DatumMLLW)
+	require.NoError(t, err)
+	assert.Nil(t, result)
+
+	assert.Equal(t, map[string]uint64{"lru_hits": 1, "lru_stale_hits": 0, "lru_misses": 1}, service.GetCacheStats())
+}
+
 func TestCacheHitAndMiss(t *testing.T) {
 	t.Parallel()
 
@@ -177,7 +253,7 @@ func TestCacheHitAndMiss(t *testing.T) {
 	}
 
 	// Test cache miss
-	result, err := service.GetPredictions(context.Background(), stationID, date)
+	result, err := service.GetPredictions(context.Background(), stationID, date, models.DatumMLLW)
 	require.NoError(t, err)
 	assert.Nil(t, result)
 
@@ -186,7 +262,7 @@ func TestCacheHitAndMiss(t *testing.T) {
 	require.NoError(t, err)
 
 	// Test cache hit
-	result, err = service.GetPredictions(context.Background(), stationID, date)
+	result, err = service.GetPredictions(context.Background(), stationID, date, models.DatumMLLW)
 	require.NoError(t, err)
 	require.NotNil(t, result)
 	assert.Equal(t, testRecord.StationID, result.StationID)
@@ -198,6 +274,38 @@ func TestCacheHitAndMiss(t *testing.T) {
 	assert.Equal(t, uint64(1), stats["lru_misses"])
 }
 
+func TestCacheKeyedByDatum(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.CacheConfig{
+		TidePredictionLRUSize:       1000,
+		TidePredictionLRUTTLMinutes: 15,
+	}
+
+	service := createTestCacheService(t, cfg)
+	service.Clear()
+
+	date := time.Now()
+	record := models.TidePredictionRecord{
+		StationID:   "TEST001",
+		Date:        date.Format("2006-01-02"),
+		StationType: "R",
+		Datum:       models.DatumMHHW,
+	}
+
+	require.NoError(t, service.SavePredictions(context.Background(), record))
+
+	result, err := service.GetPredictions(context.Background(), "TEST001", date, models.DatumMHHW)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, models.DatumMHHW, result.Datum)
+
+	// Values for another datum must not be served from this entry
+	result, err = service.GetPredictions(context.Background(), "TEST001", date, models.DatumMLLW)
+	require.NoError(t, err)
+	assert.Nil(t, result)
+}
+
 func TestCacheExpiration(t *testing.T) {
 	t.Parallel()
 
@@ -228,12 +336,12 @@ func TestCacheExpiration(t *testing.T) {
 	require.NoError(t, err)
 
 	// Immediate lookup should succeed
-	result, err := service.GetPredictions(context.Background(), stationID, date)
+	result, err := service.GetPredictions(context.Background(), stationID, date, models.DatumMLLW)
 	require.NoError(t, err)
 	require.NotNil(t, result)
 
 	// Verify we have the record in the cache
-	key := getCacheKey(stationID, date.Format("2006-01-02"))
+	key := getCacheKey(stationID, date.Format("2006-01-02"), models.DatumMLLW)
 	entry, exists := service.lru.Get(key)
 	require.True(t, exists, "Entry should exist in cache")
 	require.NotNil(t, entry)
@@ -244,7 +352,7 @@ func TestCacheExpiration(t *testing.T) {
 	t.Logf("After advance, current time: %v", mockClock.Now())
 
 	// Lookup after expiration should miss
-	result, err = service.GetPredictions(context.Background(), stationID, date)
+	result, err = service.GetPredictions(context.Background(), stationID, date, models.DatumMLLW)
 	require.NoError(t, err)
 	assert.Nil(t, result, "Expected nil result after cache expiration")
 
@@ -288,7 +396,7 @@ func TestLRUSavePredictionsBatch(t *testing.T) {
 	// Verify each record was saved in LRU cache
 	for _, record := range records {
 		date, _ := time.Parse("2006-01-02", record.Date)
-		result, err := service.GetPredictions(context.Background(), record.StationID, date)
+		result, err := service.GetPredictions(context.Background(), record.StationID, date, models.DatumMLLW)
 		require.NoError(t, err)
 		require.NotNil(t, result)
 		assert.Equal(t, record.StationID, result.StationID)
@@ -301,6 +409,7 @@ func TestDynamoDBHitFallback(t *testing.T) {
 	cfg := &config.CacheConfig{
 		TidePredictionLRUSize:       1000,
 		TidePredictionLRUTTLMinutes: 15,
+		TidePredictionDynamoTTLDays: 2,
 	}
 
 	// Create a mock DynamoDB client that returns a canned response
@@ -313,7 +422,8 @@ func TestDynamoDBHitFallback(t *testing.T) {
 			LocalTime: time.Now().Format("2006-01-02T15:04:05"),
 			Height:    1.5,
 		}},
-		TTL: time.Now().Add(24 * time.Hour).Unix(),
+		LastUpdated: time.Now().Unix(),
+		TTL:         time.Now().Add(24 * time.Hour).Unix(),
 	}
 
 	mockDynamo := &mockDynamoDBClientLRU{
@@ -328,18 +438,18 @@ func TestDynamoDBHitFallback(t *testing.T) {
 	}
 
 	service := createTestCacheService(t, cfg)
-	service.dynamoCache = NewDynamoPredictionCache(mockDynamo, cfg)
+	service.next = NewDynamoPredictionCache(mockDynamo, cfg)
 	service.Clear()
 
 	// First access should miss LRU but hit DynamoDB
 	date, _ := time.Parse("2006-01-02", testRecord.Date)
-	result, err := service.GetPredictions(context.Background(), testRecord.StationID, date)
+	result, err := service.GetPredictions(context.Background(), testRecord.StationID, date, models.DatumMLLW)
 	require.NoError(t, err)
 	require.NotNil(t, result)
 	assert.Equal(t, testRecord.StationID, result.StationID)
 
 	// Second access should hit LRU
-	result2, err := service.GetPredictions(context.Background(), testRecord.StationID, date)
+	result2, err := service.GetPredictions(context.Background(), testRecord.StationID, date, models.DatumMLLW)
 	require.NoError(t, err)
 	require.NotNil(t, result2)
 
@@ -351,6 +461,109 @@ func TestDynamoDBHitFallback(t *testing.T) {
 	assert.Equal(t, uint64(0), stats["dynamo_misses"])
 }
 
+// stubCacheLayer is a next layer returning whatever record it's given
+type stubCacheLayer struct {
+	NoopCacheService
+	mu     sync.Mutex
+	record *models.TidePredictionRecord
+	reads  int
+}
+
+func (s *stubCacheLayer) GetPredictions(ctx context.Context, stationID string, date time.Time, datum models.Datum) (*models.TidePredictionRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reads++
+	return s.record, nil
+}
+
+func (s *stubCacheLayer) set(record *models.TidePredictionRecord) {
+	s.mu.Lock()
+	s.record = record
+	s.mu.Unlock()
+}
+
+func TestLRUStaleWhileRevalidate(t *testing.T) {
+	cfg := &config.CacheConfig{
+		TidePredictionLRUSize:           10,
+		TidePredictionLRUTTLMinutes:     15,
+		TidePredictionLRUHardTTLMinutes: 60,
+	}
+	next := &stubCacheLayer{}
+	service, err := NewLRUCacheService(cfg, next)
+	require.NoError(t, err)
+	clock := &fakeClock{now: time.Now()}
+	service.clock = clock
+
+	ctx := context.Background()
+	date := time.Now()
+	key := getCacheKey("TEST001", date.Format("2006-01-02"), models.DatumMLLW)
+	record := models.TidePredictionRecord{StationID: "TEST001", Date: date.Format("2006-01-02"), StationType: "R"}
+	require.NoError(t, service.SavePredictionsBatch(ctx, []models.TidePredictionRecord{record}))
+
+	// Past the soft TTL the entry is served while it's re-read from the next layer
+	reread := record
+	reread.LastUpdated = 42
+	next.set(&reread)
+	clock.Advance(20 * time.Minute)
+	result, err := service.GetPredictions(ctx, "TEST001", date, models.DatumMLLW)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.Zero(t, result.LastUpdated)
+	assert.Eventually(t, func() bool {
+		entry, ok := service.lru.Peek(key)
+		return ok && entry.Data.LastUpdated == 42
+	}, time.Second, 5*time.Millisecond)
+
+	result, err = service.GetPredictions(ctx, "TEST001", date, models.DatumMLLW)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), result.LastUpdated)
+	assert.False(t, result.Stale)
+
+	// Entries the next layer no longer has are marked stale
+	next.set(nil)
+	clock.Advance(20 * time.Minute)
+	_, err = service.GetPredictions(ctx, "TEST001", date, models.DatumMLLW)
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		entry, ok := service.lru.Peek(key)
+		return ok && entry.Data.Stale
+	}, time.Second, 5*time.Millisecond)
+
+	result, err = service.GetPredictions(ctx, "TEST001", date, models.DatumMLLW)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.True(t, result.Stale)
+
+	// Past the hard TTL it's gone
+	clock.Advance(time.Hour)
+	result, err = service.GetPredictions(ctx, "TEST001", date, models.DatumMLLW)
+	require.NoError(t, err)
+	assert.Nil(t, result)
+
+	stats := service.GetCacheStats()
+	assert.Equal(t, uint64(1), stats["lru_hits"])
+	assert.Equal(t, uint64(3), stats["lru_stale_hits"])
+	assert.Equal(t, uint64(1), stats["lru_misses"])
+}
+
+func TestLRUSkipsStaleRecordsFromNextLayer(t *testing.T) {
+	cfg := &config.CacheConfig{TidePredictionLRUSize: 10, TidePredictionLRUTTLMinutes: 15}
+	date := time.Now()
+	next := &stubCacheLayer{record: &models.TidePredictionRecord{
+		StationID: "TEST001", Date: date.Format("2006-01-02"), StationType: "R", Stale: true,
+	}}
+	service, err := NewLRUCacheService(cfg, next)
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		result, err := service.GetPredictions(context.Background(), "TEST001", date, models.DatumMLLW)
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		assert.True(t, result.Stale)
+	}
+	assert.Equal(t, 2, next.reads, "stale records are read from the next layer until refreshed")
+}
+
 func TestConcurrentAccess(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping concurrent test in short mode")
@@ -394,7 +607,7 @@ func TestConcurrentAccess(t *testing.T) {
 						return
 					}
 				} else {
-					if _, err := service.GetPredictions(context.Background(), stationID, date); err != nil {
+					if _, err := service.GetPredictions(context.Background(), stationID, date, models.DatumMLLW); err != nil {
 						errs <- fmt.Errorf("GetPredictions error: %v", err)
 						return
 					}
@@ -440,7 +653,7 @@ func BenchmarkCacheOperations(b *testing.B) {
 
 	b.Run("GetPredictions", func(b *testing.B) {
 		for i := 0; i < b.N; i++ {
-			_, err := service.GetPredictions(context.Background(), stationID, date)
+			_, err := service.GetPredictions(context.Background(), stationID, date, models.DatumMLLW)
 			if err != nil {
 				b.Fatal(err)
 			}
@@ -0,0 +1,28 @@
+package cache
+
+import (
+	"context"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"time"
+)
+
+// NoopCacheService caches nothing. It ends a chain of cache layers, or
+// stands in for a cache when caching is disabled.
+type NoopCacheService struct{}
+
+var _ CacheService = NoopCacheService{}
+
+// GetPredictions always misses
+func (NoopCacheService) GetPredictions(ctx context.Context, stationID string, date time.Time, datum models.Datum) (*models.TidePredictionRecord, error) {
+	return nil, nil
+}
+
+// SavePredictions discards the record
+func (NoopCacheService) SavePredictions(ctx context.Context, record models.TidePredictionRecord) error {
+	return nil
+}
+
+// SavePredictionsBatch discards the records
+func (NoopCacheService) SavePredictionsBatch(ctx context.Context, records []models.TidePredictionRecord) error {
+	return nil
+}
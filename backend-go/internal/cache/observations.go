@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"fmt"
+	"github.com/bbernstein/flowebb/backend-go/internal/config"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"sync"
+	"time"
+)
+
+type observationEntry struct {
+	observation *models.WaterLevelObservation
+	expiresAt   time.Time
+}
+
+// ObservationCache holds the latest water level observation per station and
+// datum for a few minutes. It is kept apart from the prediction caches because
+// observations go stale far sooner than predictions.
+type ObservationCache struct {
+	entries map[string]observationEntry
+	mu      sync.RWMutex
+	ttl     time.Duration
+	clock   clock
+}
+
+func NewObservationCache(cacheConfig *config.CacheConfig) *ObservationCache {
+	// If no config provided, use default config
+	if cacheConfig == nil {
+		cacheConfig = config.GetCacheConfig()
+	}
+
+	return &ObservationCache{
+		entries: make(map[string]observationEntry),
+		ttl:     cacheConfig.GetObservationTTL(),
+		clock:   &systemClock{},
+	}
+}
+
+func observationKey(stationID string, datum models.Datum) string {
+	return fmt.Sprintf("%s:%s", stationID, datum.OrDefault())
+}
+
+// GetObservation returns the cached observation and whether there was an
+// unexpired entry. A nil observation with ok set means the station was
+// recently found to have no data.
+func (c *ObservationCache) GetObservation(stationID string, datum models.Datum) (*models.WaterLevelObservation, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[observationKey(stationID, datum)]
+	if !ok || !entry.expiresAt.After(c.clock.Now()) {
+		return nil, false
+	}
+	return entry.observation, true
+}
+
+// SetObservation caches an observation, or nil to record that the station has no data
+func (c *ObservationCache) SetObservation(stationID string, datum models.Datum, observation *models.WaterLevelObservation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock.Now()
+	c.entries[observationKey(stationID, datum)] = observationEntry{
+		observation: observation,
+		expiresAt:   now.Add(c.ttl),
+	}
+
+	// Drop expired entries so stations that are no longer requested don't accumulate
+	for key, entry := range c.entries {
+		if !entry.expiresAt.After(now) {
+			delete(c.entries, key)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"github.com/bbernstein/flowebb/backend-go/internal/config"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestObservationCache(t *testing.T) {
+	t.Parallel()
+
+	cache := NewObservationCache(&config.CacheConfig{ObservationTTLMinutes: 6})
+	mockClock := &fakeClock{now: time.Now()}
+	cache.clock = mockClock
+
+	observation := &models.WaterLevelObservation{
+		StationID: "9447130",
+		Timestamp: mockClock.Now().UnixMilli(),
+		Height:    4.2,
+		Datum:     models.DatumMLLW,
+		Units:     models.UnitsFeet,
+		Quality:   "p",
+	}
+
+	// Miss before anything is cached
+	_, ok := cache.GetObservation("9447130", models.DatumMLLW)
+	assert.False(t, ok)
+
+	cache.SetObservation("9447130", models.DatumMLLW, observation)
+
+	got, ok := cache.GetObservation("9447130", models.DatumMLLW)
+	require.True(t, ok)
+	assert.Equal(t, observation, got)
+
+	// Observations for another datum are separate
+	_, ok = cache.GetObservation("9447130", models.DatumNAVD88)
+	assert.False(t, ok)
+
+	// Expires after the TTL
+	mockClock.Advance(7 * time.Minute)
+	_, ok = cache.GetObservation("9447130", models.DatumMLLW)
+	assert.False(t, ok)
+}
+
+func TestObservationCacheRemembersMissingData(t *testing.T) {
+	t.Parallel()
+
+	cache := NewObservationCache(&config.CacheConfig{ObservationTTLMinutes: 6})
+
+	cache.SetObservation("9414305", models.DatumMLLW, nil)
+
+	got, ok := cache.GetObservation("9414305", models.DatumMLLW)
+	assert.True(t, ok, "a station without data should be cached")
+	assert.Nil(t, got)
+}
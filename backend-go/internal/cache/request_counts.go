@@ -0,0 +1,146 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/bbernstein/flowebb/backend-go/internal/config"
+	"sort"
+	"strconv"
+)
+
+const (
+	requestCountsTableName = "station-request-counts"
+)
+
+// RequestCounter counts the requests made for each station
+type RequestCounter interface {
+	RecordRequest(ctx context.Context, stationID string) error
+}
+
+// StationRequestCount is the number of requests made for a station
+type StationRequestCount struct {
+	StationID string `dynamodbav:"stationId"`
+	Requests  int64  `dynamodbav:"requests"`
+	TTL       int64  `dynamodbav:"ttl"`
+}
+
+// DynamoRequestCounts counts requests for each station in DynamoDB. Each
+// request pushes the station's TTL back, so DynamoDB drops the counts of
+// stations nobody has asked for in a while and they stop being warmed.
+type DynamoRequestCounts struct {
+	client DynamoDBClient
+	config *config.CacheConfig
+	clock  clock
+}
+
+var _ RequestCounter = (*DynamoRequestCounts)(nil)
+
+func NewDynamoRequestCounts(client DynamoDBClient, cacheConfig *config.CacheConfig) *DynamoRequestCounts {
+	if cacheConfig == nil {
+		cacheConfig = config.GetCacheConfig()
+	}
+	return &DynamoRequestCounts{
+		client: client,
+		config: cacheConfig,
+		clock:  &systemClock{},
+	}
+}
+
+// NewRequestCounter creates the request counter the config enables. Requests
+// are counted in DynamoDB, so they aren't counted without it.
+func NewRequestCounter(ctx context.Context, config *config.CacheConfig) (RequestCounter, error) {
+	if !config.EnableDynamoCache || !config.EnableRequestCounts {
+		return NoopRequestCounter{}, nil
+	}
+
+	dynamoClient, err := NewDynamoClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("creating DynamoDB client: %w", err)
+	}
+	return NewDynamoRequestCounts(dynamoClient, config), nil
+}
+
+// RecordRequest adds one to the station's count and pushes its TTL back
+func (c *DynamoRequestCounts) RecordRequest(ctx context.Context, stationID string) error {
+	ttl := c.clock.Now().Add(c.config.GetRequestCountTTL()).Unix()
+
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(requestCountsTableName),
+		Key: map[string]types.AttributeValue{
+			"stationId": &types.AttributeValueMemberS{Value: stationID},
+		},
+		// TTL is a reserved word
+		UpdateExpression:         aws.String("ADD requests :one SET #ttl = :ttl"),
+		ExpressionAttributeNames: map[string]string{"#ttl": "ttl"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":one": &types.AttributeValueMemberN{Value: "1"},
+			":ttl": &types.AttributeValueMemberN{Value: strconv.FormatInt(ttl, 10)},
+		},
+	}
+
+	if _, err := c.client.UpdateItem(ctx, input); err != nil {
+		return fmt.Errorf("counting request in DynamoDB: %w", err)
+	}
+	return nil
+}
+
+// TopStations returns the n most requested stations, most requested first.
+// The whole table is scanned, which is one item per station requested
+// within the TTL. Counts past their TTL that DynamoDB hasn't deleted yet are
+// left out.
+func (c *DynamoRequestCounts) TopStations(ctx context.Context, n int) ([]StationRequestCount, error) {
+	now := c.clock.Now().Unix()
+
+	var counts []StationRequestCount
+	input := &dynamodb.ScanInput{
+		TableName: aws.String(requestCountsTableName),
+	}
+	for {
+		result, err := c.client.Scan(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("scanning request counts in DynamoDB: %w", err)
+		}
+
+		var page []StationRequestCount
+		if err := attributevalue.UnmarshalListOfMaps(result.Items, &page); err != nil {
+			return nil, fmt.Errorf("unmarshaling request counts: %w", err)
+		}
+		for _, count := range page {
+			if count.TTL > now {
+				counts = append(counts, count)
+			}
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+
+	// Ties are broken by station ID so the same stations are picked each run
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Requests != counts[j].Requests {
+			return counts[i].Requests > counts[j].Requests
+		}
+		return counts[i].StationID < counts[j].StationID
+	})
+	if n < len(counts) {
+		counts = counts[:n]
+	}
+	return counts, nil
+}
+
+// NoopRequestCounter counts nothing. It stands in for a counter when
+// request counts are disabled.
+type NoopRequestCounter struct{}
+
+var _ RequestCounter = NoopRequestCounter{}
+
+// RecordRequest discards the request
+func (NoopRequestCounter) RecordRequest(ctx context.Context, stationID string) error {
+	return nil
+}
@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/bbernstein/flowebb/backend-go/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordRequest(t *testing.T) {
+	now := time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+	var input *dynamodb.UpdateItemInput
+	counts := NewDynamoRequestCounts(&mockDynamoDBClient{
+		updateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+			input = params
+			return &dynamodb.UpdateItemOutput{}, nil
+		},
+	}, &config.CacheConfig{RequestCountTTLDays: 30})
+	counts.clock = &fakeClock{now: now}
+
+	require.NoError(t, counts.RecordRequest(context.Background(), "9447130"))
+
+	require.NotNil(t, input)
+	assert.Equal(t, requestCountsTableName, *input.TableName)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "9447130"}, input.Key["stationId"])
+	assert.Equal(t, "ADD requests :one SET #ttl = :ttl", *input.UpdateExpression)
+	assert.Equal(t, &types.AttributeValueMemberN{Value: strconv.FormatInt(now.AddDate(0, 0, 30).Unix(), 10)}, input.ExpressionAttributeValues[":ttl"],
+		"expires 30 days after the request")
+
+	counts = NewDynamoRequestCounts(&mockDynamoDBClient{
+		updateItemFunc: func(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+			return nil, errors.New("throttled")
+		},
+	}, nil)
+	assert.EqualError(t, counts.RecordRequest(context.Background(), "9447130"), "counting request in DynamoDB: throttled")
+}
+
+func TestTopStations(t *testing.T) {
+	now := time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+	live := now.Add(time.Hour).Unix()
+	page := func(t *testing.T, counts ...StationRequestCount) []map[string]types.AttributeValue {
+		items := make([]map[string]types.AttributeValue, len(counts))
+		for i, count := range counts {
+			item, err := attributevalue.MarshalMap(count)
+			require.NoError(t, err)
+			items[i] = item
+		}
+		return items
+	}
+
+	scans := 0
+	counts := NewDynamoRequestCounts(&mockDynamoDBClient{
+		scanFunc: func(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+			scans++
+			if params.ExclusiveStartKey == nil {
+				return &dynamodb.ScanOutput{
+					Items: page(t,
+						StationRequestCount{StationID: "8443970", Requests: 12, TTL: live},
+						StationRequestCount{StationID: "9414290", Requests: 40, TTL: live},
+					),
+					LastEvaluatedKey: map[string]types.AttributeValue{
+						"stationId": &types.AttributeValueMemberS{Value: "9414290"},
+					},
+				}, nil
+			}
+			return &dynamodb.ScanOutput{
+				Items: page(t,
+					StationRequestCount{StationID: "9447130", Requests: 25, TTL: live},
+					StationRequestCount{StationID: "CHS-07795", Requests: 12, TTL: live},
+					StationRequestCount{StationID: "8518750", Requests: 99, TTL: now.Add(-time.Hour).Unix()},
+				),
+			}, nil
+		},
+	}, nil)
+	counts.clock = &fakeClock{now: now}
+
+	top, err := counts.TopStations(context.Background(), 3)
+	require.NoError(t, err)
+	assert.Equal(t, 2, scans, "every page is scanned")
+
+	ids := make([]string, len(top))
+	for i, count := range top {
+		ids[i] = count.StationID
+	}
+	assert.Equal(t, []string{"9414290", "9447130", "8443970"}, ids,
+		"expired counts are left out and ties go by station ID")
+
+	all, err := counts.TopStations(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Len(t, all, 4)
+}
+
+func TestNewRequestCounter(t *testing.T) {
+	counter, err := NewRequestCounter(context.Background(), &config.CacheConfig{EnableDynamoCache: false, EnableRequestCounts: true})
+	require.NoError(t, err)
+	assert.Equal(t, NoopRequestCounter{}, counter, "requests are only counted in DynamoDB")
+
+	counter, err = NewRequestCounter(context.Background(), &config.CacheConfig{EnableDynamoCache: true, EnableRequestCounts: false})
+	require.NoError(t, err)
+	assert.Equal(t, NoopRequestCounter{}, counter)
+
+	counter, err = NewRequestCounter(context.Background(), &config.CacheConfig{EnableDynamoCache: true, EnableRequestCounts: true})
+	require.NoError(t, err)
+	assert.IsType(t, &DynamoRequestCounts{}, counter)
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/bbernstein/flowebb/backend-go/internal/config"
 	"github.com/bbernstein/flowebb/backend-go/internal/models"
 	"github.com/rs/zerolog/log"
 	"io"
@@ -23,19 +24,73 @@ const (
 	cacheKey = "stations.json"
 )
 
-// S3StationCache provides caching for station lists in S3
+// S3StationCache provides caching for station lists in S3. Lists are kept
+// until the hard TTL; the soft TTL is recorded so readers know when to
+// refresh them.
 type S3StationCache struct {
 	client     S3Client
 	bucketName string
 	ttl        time.Duration
+	hardTTL    time.Duration
 	clock      clock // Use the same clock interface from cache package
 }
 
-// StationListCacheRecord represents the cached station list with metadata
+var _ StationListRecordProvider = (*S3StationCache)(nil)
+
+// NewS3StationCache creates a station list cache in the configured bucket,
+// with an S3 client from the default AWS configuration
+func NewS3StationCache(ctx context.Context, cacheConfig *config.CacheConfig) (*S3StationCache, error) {
+	if cacheConfig == nil {
+		cacheConfig = config.GetCacheConfig()
+	}
+	if cacheConfig.StationListBucket == "" {
+		return nil, fmt.Errorf("no station list bucket configured")
+	}
+
+	client, err := NewS3Client(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("creating S3 client: %w", err)
+	}
+
+	return NewS3StationCacheWithClient(client, cacheConfig), nil
+}
+
+// NewS3StationCacheWithClient creates a station list cache in the configured
+// bucket using the given client
+func NewS3StationCacheWithClient(client S3Client, cacheConfig *config.CacheConfig) *S3StationCache {
+	if cacheConfig == nil {
+		cacheConfig = config.GetCacheConfig()
+	}
+	return &S3StationCache{
+		client:     client,
+		bucketName: cacheConfig.StationListBucket,
+		ttl:        cacheConfig.GetStationListTTL(),
+		hardTTL:    cacheConfig.GetStationListHardTTL(),
+		clock:      &systemClock{},
+	}
+}
+
+// StationListCacheRecord represents the cached station list with metadata.
+// Times are in Unix seconds.
 type StationListCacheRecord struct {
 	Stations    []models.Station `json:"stations"`
 	LastUpdated int64            `json:"lastUpdated"`
-	TTL         int64            `json:"ttl"`
+	TTL         int64            `json:"ttl"`               // Soft expiry
+	HardTTL     int64            `json:"hardTtl,omitempty"` // Missing from lists saved before soft expiry
+}
+
+// Stale reports whether the list is past its soft expiry
+func (r *StationListCacheRecord) Stale(now time.Time) bool {
+	return now.Unix() > r.TTL
+}
+
+// expired reports whether the list is past its hard expiry
+func (r *StationListCacheRecord) expired(now time.Time) bool {
+	hardTTL := r.HardTTL
+	if hardTTL < r.TTL {
+		hardTTL = r.TTL
+	}
+	return now.Unix() > hardTTL
 }
 
 // StationListCacheProvider defines interface for station list caching
@@ -44,8 +99,25 @@ type StationListCacheProvider interface {
 	SaveStations(ctx context.Context, stations []models.Station) error
 }
 
-// GetStations retrieves stations from S3 cache if available and valid
+// StationListRecordProvider is implemented by station list caches that say
+// when the lists they hold were saved
+type StationListRecordProvider interface {
+	GetStationListRecord(ctx context.Context) (*StationListCacheRecord, error)
+}
+
+// GetStations retrieves stations from S3 cache until they're past the hard
+// TTL, stale or not
 func (c *S3StationCache) GetStations(ctx context.Context) ([]models.Station, error) {
+	record, err := c.GetStationListRecord(ctx)
+	if err != nil || record == nil {
+		return nil, err
+	}
+	return record.Stations, nil
+}
+
+// GetStationListRecord retrieves the cached station list along with when it
+// was saved and expires, or nil once it's past the hard TTL
+func (c *S3StationCache) GetStationListRecord(ctx context.Context) (*StationListCacheRecord, error) {
 	if c.bucketName == "" {
 		return nil, fmt.Errorf("empty bucket name")
 	}
@@ -73,12 +145,12 @@ func (c *S3StationCache) GetStations(ctx context.Context) ([]models.Station, err
 	}
 
 	// Check if cache is expired
-	if c.clock.Now().Unix() > record.TTL {
+	if record.expired(c.clock.Now()) {
 		log.Debug().Msg("Station list cache expired")
 		return nil, nil
 	}
 
-	return record.Stations, nil
+	return &record, nil
 }
 
 // SaveStations saves stations to S3 cache
@@ -93,6 +165,7 @@ func (c *S3StationCache) SaveStations(ctx context.Context, stations []models.Sta
 		Stations:    stations,
 		LastUpdated: now,
 		TTL:         now + int64(c.ttl.Seconds()),
+		HardTTL:     now + int64(c.hardTTL.Seconds()),
 	}
 
 	// Encode record
@@ -7,6 +7,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/bbernstein/flowebb/backend-go/internal/config"
 	"github.com/bbernstein/flowebb/backend-go/internal/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -310,6 +311,48 @@ func TestS3StationCache_Integration(t *testing.T) {
 	assert.Nil(t, expiredStations)
 }
 
+func TestS3StationCache_StaleWhileRevalidate(t *testing.T) {
+	now := time.Now()
+	clock := &mockClock{now: now}
+	var savedData []byte
+	mockS3 := &mockS3Client{
+		putObjectFunc: func(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+			savedData, _ = io.ReadAll(params.Body)
+			return &s3.PutObjectOutput{}, nil
+		},
+		getObjectFunc: func(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+			return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(savedData))}, nil
+		},
+	}
+	cache := createTestCache(mockS3, clock)
+	cache.hardTTL = 72 * time.Hour
+	ctx := context.Background()
+
+	require.NoError(t, cache.SaveStations(ctx, createTestStations()))
+
+	record, err := cache.GetStationListRecord(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, now.Unix(), record.LastUpdated)
+	assert.False(t, record.Stale(clock.now))
+
+	// Past the soft TTL the list is still served, marked stale
+	clock.now = now.Add(25 * time.Hour)
+	record, err = cache.GetStationListRecord(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.True(t, record.Stale(clock.now))
+	stations, err := cache.GetStations(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, createTestStations(), stations)
+
+	// Past the hard TTL it's gone
+	clock.now = now.Add(73 * time.Hour)
+	record, err = cache.GetStationListRecord(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, record)
+}
+
 func TestS3StationCache_BucketValidation(t *testing.T) {
 	mockS3 := &mockS3Client{}
 	mockClock := &mockClock{now: time.Now()}
@@ -354,3 +397,27 @@ func TestS3StationCache_ObjectKeyHandling(t *testing.T) {
 	_, _ = cache.GetStations(context.Background())
 	_ = cache.SaveStations(context.Background(), createTestStations())
 }
+
+func TestNewS3StationCache(t *testing.T) {
+	t.Run("from config", func(t *testing.T) {
+		cacheConfig := &config.CacheConfig{StationListBucket: "station-list", StationListTTLDays: 3}
+		mockS3 := &mockS3Client{
+			putObjectFunc: func(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+				assert.Equal(t, "station-list", aws.ToString(params.Bucket))
+				return &s3.PutObjectOutput{}, nil
+			},
+		}
+
+		cache := NewS3StationCacheWithClient(mockS3, cacheConfig)
+		assert.Equal(t, "station-list", cache.bucketName)
+		assert.Equal(t, 72*time.Hour, cache.ttl)
+		assert.NotNil(t, cache.clock)
+		require.NoError(t, cache.SaveStations(context.Background(), createTestStations()))
+	})
+
+	t.Run("without a bucket", func(t *testing.T) {
+		cache, err := NewS3StationCache(context.Background(), &config.CacheConfig{StationListTTLDays: 1})
+		assert.EqualError(t, err, "no station list bucket configured")
+		assert.Nil(t, cache)
+	})
+}
@@ -3,15 +3,21 @@ package cache
 import (
 	"github.com/bbernstein/flowebb/backend-go/internal/config"
 	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/internal/spatial"
 	"sync"
 	"time"
 )
 
+// StationCache holds a station list in memory. Stations past the soft TTL
+// are still served, and reported stale so they can be refreshed; they're
+// only dropped after the hard TTL.
 type StationCache struct {
 	stations    []models.Station
+	index       *spatial.Index // Rebuilt whenever the stations are replaced
 	lastUpdated time.Time
 	mu          sync.RWMutex
 	ttl         time.Duration
+	hardTTL     time.Duration
 }
 
 func NewStationCache(cacheConfig *config.CacheConfig) *StationCache {
@@ -23,8 +29,10 @@ func NewStationCache(cacheConfig *config.CacheConfig) *StationCache {
 	ttl := cacheConfig.GetStationListTTL()
 	return &StationCache{
 		stations:    make([]models.Station, 0),
+		index:       spatial.NewIndex(nil),
 		lastUpdated: time.Time{}, // Zero time to ensure first fetch
 		ttl:         ttl,
+		hardTTL:     cacheConfig.GetStationListHardTTL(),
 	}
 }
 
@@ -42,7 +50,21 @@ func (c *StationCache) GetStations() []models.Station {
 	return stations
 }
 
+// Stale reports whether the stations are past the soft TTL but still served
+func (c *StationCache) Stale() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return !c.isExpired() && time.Since(c.lastUpdated) > c.ttl
+}
+
 func (c *StationCache) SetStations(stations []models.Station) {
+	c.SetStationsUpdatedAt(stations, time.Now())
+}
+
+// SetStationsUpdatedAt replaces the stations with ones fetched at the given
+// time, as when they're loaded from a longer-lived cache
+func (c *StationCache) SetStationsUpdatedAt(stations []models.Station, updatedAt time.Time) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -51,9 +73,25 @@ func (c *StationCache) SetStations(stations []models.Station) {
 	copy(newStations, stations)
 
 	c.stations = newStations
-	c.lastUpdated = time.Now()
+	c.index = spatial.NewIndex(newStations)
+	c.lastUpdated = updatedAt
+}
+
+// NearestStations returns up to limit stations passing the filter ordered by
+// distance from the point, using the spatial index. It reports false when
+// the cache is past the hard TTL.
+func (c *StationCache) NearestStations(lat, lon float64, limit int, filter *models.StationFilter) ([]models.Station, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.isExpired() {
+		return nil, false
+	}
+
+	return c.index.NearestMatching(lat, lon, limit, filter), true
 }
 
+// isExpired reports whether the stations are past the hard TTL
 func (c *StationCache) isExpired() bool {
-	return time.Since(c.lastUpdated) > c.ttl
+	return time.Since(c.lastUpdated) > c.hardTTL
 }
@@ -115,6 +115,74 @@ func TestStationCacheExpiration(t *testing.T) {
 	assert.Nil(t, got)
 }
 
+func TestStationCacheStaleWhileRevalidate(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.CacheConfig{
+		StationListTTLDays:     1,
+		StationListHardTTLDays: 3,
+	}
+	cache := NewStationCache(cfg)
+	assert.False(t, cache.Stale(), "an empty cache has nothing stale to serve")
+
+	testStations := []models.Station{
+		{ID: "TEST001", Name: "Test Station", Latitude: 47.6062, Longitude: -122.3321, Source: models.SourceNOAA},
+	}
+
+	cache.SetStations(testStations)
+	assert.False(t, cache.Stale())
+
+	// Past the soft TTL the stations are served but stale
+	cache.SetStationsUpdatedAt(testStations, time.Now().Add(-2*24*time.Hour))
+	assert.Equal(t, testStations, cache.GetStations())
+	assert.True(t, cache.Stale())
+	_, ok := cache.NearestStations(47.6, -122.3, 1, nil)
+	assert.True(t, ok)
+
+	// Past the hard TTL they're gone
+	cache.SetStationsUpdatedAt(testStations, time.Now().Add(-4*24*time.Hour))
+	assert.Nil(t, cache.GetStations())
+	assert.False(t, cache.Stale())
+	_, ok = cache.NearestStations(47.6, -122.3, 1, nil)
+	assert.False(t, ok)
+}
+
+func TestStationCacheNearestStations(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.CacheConfig{
+		StationListTTLDays: 1,
+	}
+	cache := NewStationCache(cfg)
+
+	// Nothing has been set yet
+	_, ok := cache.NearestStations(47.6, -122.3, 1, nil)
+	assert.False(t, ok)
+
+	cache.SetStations([]models.Station{
+		{ID: "SEATTLE", Latitude: 47.6026, Longitude: -122.3393, Source: models.SourceNOAA},
+		{ID: "PORTLAND", Latitude: 45.5155, Longitude: -122.6789, Source: models.SourceNOAA},
+	})
+	got, ok := cache.NearestStations(45.6, -122.6, 1, nil)
+	require.True(t, ok)
+	require.Len(t, got, 1)
+	assert.Equal(t, "PORTLAND", got[0].ID)
+	assert.Greater(t, got[0].Distance, 0.0)
+
+	// Replacing the list rebuilds the index
+	cache.SetStations([]models.Station{
+		{ID: "BOSTON", Latitude: 42.3539, Longitude: -71.0503, Source: models.SourceNOAA},
+	})
+	got, ok = cache.NearestStations(45.6, -122.6, 5, nil)
+	require.True(t, ok)
+	require.Len(t, got, 1)
+	assert.Equal(t, "BOSTON", got[0].ID)
+
+	cache.lastUpdated = time.Now().Add(-25 * time.Hour)
+	_, ok = cache.NearestStations(45.6, -122.6, 5, nil)
+	assert.False(t, ok)
+}
+
 func TestConcurrentStationAccess(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping concurrent test in short mode")
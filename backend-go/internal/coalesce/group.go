@@ -0,0 +1,134 @@
+package coalesce
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// errNoResult is returned to callers waiting on a call that panicked
+var errNoResult = errors.New("coalesced call returned no result")
+
+// Group coalesces concurrent calls with the same key, so one call's result
+// serves every caller that arrives while it's in flight. Calls that start
+// after it returns run again. The zero value is ready to use.
+type Group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+
+	started   atomic.Uint64
+	coalesced atomic.Uint64
+}
+
+type call struct {
+	done chan struct{}
+	val  interface{}
+	err  error
+}
+
+// Stats counts the calls a Group has made and the callers it has spared one
+type Stats struct {
+	Calls     uint64 // Calls made
+	Coalesced uint64 // Callers served by another caller's call
+}
+
+// Do calls fn, unless a call with the same key is in flight, in which case
+// it waits for that call and returns its result. The result is shared, so
+// callers mustn't modify it.
+func (g *Group) Do(key string, fn func() (interface{}, error)) (interface{}, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		g.coalesced.Add(1)
+		<-c.done
+		return c.val, c.err
+	}
+	c := &call{done: make(chan struct{}), err: errNoResult}
+	g.calls[key] = c
+	g.mu.Unlock()
+	g.started.Add(1)
+
+	// Waiters are released even if fn panics
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(c.done)
+	}()
+
+	c.val, c.err = fn()
+	return c.val, c.err
+}
+
+// DoContext is Do for calls that take a context. The call runs on a context
+// detached from the caller's, with its own timeout, so a caller that gives up
+// or times out doesn't fail the callers sharing its call. A caller whose own
+// context ends first returns its error without waiting for the call.
+func (g *Group) DoContext(ctx context.Context, key string, timeout time.Duration, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	type result struct {
+		val interface{}
+		err error
+	}
+	results := make(chan result, 1)
+	go func() {
+		// A panic can't reach the caller from here, so it fails the call
+		defer func() {
+			if r := recover(); r != nil {
+				results <- result{err: fmt.Errorf("%w: %v", errNoResult, r)}
+			}
+		}()
+		val, err := g.Do(key, func() (interface{}, error) {
+			callCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+			defer cancel()
+			return fn(callCtx)
+		})
+		results <- result{val: val, err: err}
+	}()
+
+	select {
+	case r := <-results:
+		return r.val, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Stats returns the Group's counts so far
+func (g *Group) Stats() Stats {
+	return Stats{
+		Calls:     g.started.Load(),
+		Coalesced: g.coalesced.Load(),
+	}
+}
+
+// StatsReporter is implemented by services that coalesce calls, reporting
+// their Groups' counts by name
+type StatsReporter interface {
+	GetCoalescingStats() map[string]uint64
+}
+
+// LogStats logs the counts of the services that are StatsReporters. The
+// counts are the process's so far, so a warm instance's show how many calls
+// coalescing has saved it.
+func LogStats(services ...interface{}) {
+	fields := map[string]interface{}{}
+	for _, service := range services {
+		if reporter, ok := service.(StatsReporter); ok {
+			for name, count := range reporter.GetCoalescingStats() {
+				fields[name] = count
+			}
+		}
+	}
+	if len(fields) == 0 {
+		return
+	}
+	log.Info().Fields(fields).Msg("Coalescing stats")
+}
@@ -0,0 +1,209 @@
+package coalesce
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// waitForCoalesced waits until n callers are waiting on another's call
+func waitForCoalesced(t *testing.T, g *Group, n uint64) {
+	require.Eventually(t, func() bool { return g.Stats().Coalesced == n }, 2*time.Second, time.Millisecond)
+}
+
+func TestGroupDo(t *testing.T) {
+	t.Run("concurrent callers share one call", func(t *testing.T) {
+		var g Group
+		var calls atomic.Int32
+		release := make(chan struct{})
+
+		const callers = 10
+		results := make([]interface{}, callers)
+		var wg sync.WaitGroup
+		for i := 0; i < callers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				v, err := g.Do("stations", func() (interface{}, error) {
+					calls.Add(1)
+					<-release
+					return "result", nil
+				})
+				assert.NoError(t, err)
+				results[i] = v
+			}(i)
+		}
+
+		waitForCoalesced(t, &g, callers-1)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), calls.Load())
+		for _, v := range results {
+			assert.Equal(t, "result", v)
+		}
+		assert.Equal(t, Stats{Calls: 1, Coalesced: callers - 1}, g.Stats())
+	})
+
+	t.Run("errors are shared", func(t *testing.T) {
+		var g Group
+		release := make(chan struct{})
+		upstreamErr := errors.New("upstream failed")
+
+		errs := make(chan error, 2)
+		for i := 0; i < 2; i++ {
+			go func() {
+				_, err := g.Do("stations", func() (interface{}, error) {
+					<-release
+					return nil, upstreamErr
+				})
+				errs <- err
+			}()
+		}
+
+		waitForCoalesced(t, &g, 1)
+		close(release)
+		assert.ErrorIs(t, <-errs, upstreamErr)
+		assert.ErrorIs(t, <-errs, upstreamErr)
+	})
+
+	t.Run("keys are coalesced separately", func(t *testing.T) {
+		var g Group
+		for _, key := range []string{"a", "b"} {
+			v, err := g.Do(key, func() (interface{}, error) { return key, nil })
+			require.NoError(t, err)
+			assert.Equal(t, key, v)
+		}
+		assert.Equal(t, Stats{Calls: 2}, g.Stats())
+	})
+
+	t.Run("later calls run again", func(t *testing.T) {
+		var g Group
+		var calls int
+		for i := 0; i < 3; i++ {
+			_, err := g.Do("stations", func() (interface{}, error) {
+				calls++
+				return nil, nil
+			})
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("waiters are released when the call panics", func(t *testing.T) {
+		var g Group
+		release := make(chan struct{})
+
+		go func() {
+			defer func() { _ = recover() }()
+			_, _ = g.Do("stations", func() (interface{}, error) {
+				<-release
+				panic("boom")
+			})
+		}()
+		require.Eventually(t, func() bool { return g.Stats().Calls == 1 }, 2*time.Second, time.Millisecond)
+
+		errs := make(chan error, 1)
+		go func() {
+			_, err := g.Do("stations", func() (interface{}, error) { return nil, nil })
+			errs <- err
+		}()
+
+		waitForCoalesced(t, &g, 1)
+		close(release)
+		assert.ErrorIs(t, <-errs, errNoResult)
+	})
+}
+
+func TestGroupDoContext(t *testing.T) {
+	t.Run("a cancelled caller doesn't fail the others", func(t *testing.T) {
+		var g Group
+		release := make(chan struct{})
+		callErrs := make(chan error, 1)
+
+		leaderCtx, cancel := context.WithCancel(context.Background())
+		leaderErr := make(chan error, 1)
+		go func() {
+			_, err := g.DoContext(leaderCtx, "stations", time.Minute, func(ctx context.Context) (interface{}, error) {
+				<-release
+				callErrs <- ctx.Err()
+				return "result", nil
+			})
+			leaderErr <- err
+		}()
+		require.Eventually(t, func() bool { return g.Stats().Calls == 1 }, 2*time.Second, time.Millisecond)
+
+		results := make(chan interface{}, 1)
+		go func() {
+			v, err := g.DoContext(context.Background(), "stations", time.Minute, func(ctx context.Context) (interface{}, error) {
+				return nil, errors.New("should have shared the call")
+			})
+			assert.NoError(t, err)
+			results <- v
+		}()
+		waitForCoalesced(t, &g, 1)
+
+		cancel()
+		assert.ErrorIs(t, <-leaderErr, context.Canceled, "the cancelled caller stops waiting")
+
+		close(release)
+		assert.NoError(t, <-callErrs, "the call outlives its caller")
+		assert.Equal(t, "result", <-results)
+	})
+
+	t.Run("the call has its own timeout", func(t *testing.T) {
+		var g Group
+		_, err := g.DoContext(context.Background(), "stations", time.Millisecond, func(ctx context.Context) (interface{}, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("a panic fails the call", func(t *testing.T) {
+		var g Group
+		_, err := g.DoContext(context.Background(), "stations", time.Minute, func(ctx context.Context) (interface{}, error) {
+			panic("boom")
+		})
+		assert.ErrorIs(t, err, errNoResult)
+		assert.ErrorContains(t, err, "boom")
+	})
+}
+
+type statsReporter map[string]uint64
+
+func (r statsReporter) GetCoalescingStats() map[string]uint64 { return r }
+
+func TestLogStats(t *testing.T) {
+	originalLogger := log.Logger
+	defer func() { log.Logger = originalLogger }()
+	var out bytes.Buffer
+	log.Logger = zerolog.New(&out)
+
+	// Services that don't report counts, or are nil, are skipped
+	LogStats(statsReporter{"prediction_fetches": 3}, "not a reporter", nil, statsReporter{"station_list_fetches_coalesced": 2})
+
+	var logged map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &logged))
+	assert.Equal(t, map[string]interface{}{
+		"level":                          "info",
+		"message":                        "Coalescing stats",
+		"prediction_fetches":             3.0,
+		"station_list_fetches_coalesced": 2.0,
+	}, logged)
+
+	// Nothing is logged without counts
+	out.Reset()
+	LogStats("not a reporter")
+	assert.Empty(t, out.String())
+}
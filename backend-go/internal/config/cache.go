@@ -10,13 +10,32 @@ import (
 
 // CacheConfig holds all cache-related configuration
 type CacheConfig struct {
+	// Cached data is served as is until its soft TTL, then served while
+	// it's refreshed in the background until its hard TTL, then discarded.
+	// Hard TTLs shorter than the soft TTL are raised to it.
+
 	// LRU Cache settings
-	TidePredictionLRUSize       int
-	TidePredictionLRUTTLMinutes int
+	TidePredictionLRUSize           int
+	TidePredictionLRUTTLMinutes     int
+	TidePredictionLRUHardTTLMinutes int
 
 	// DynamoDB Cache settings
-	TidePredictionDynamoTTLDays int
-	StationListTTLDays          int
+	TidePredictionDynamoTTLDays     int
+	TidePredictionDynamoHardTTLDays int
+	StationListTTLDays              int
+	StationListHardTTLDays          int
+
+	// S3 bucket the station list is kept in between cold starts; not kept
+	// when empty
+	StationListBucket string
+
+	// Observation Cache settings
+	ObservationTTLMinutes int
+
+	// Requests per station are counted in DynamoDB, for warming the
+	// prediction cache for popular stations. A station's count is dropped
+	// once it has gone this long without a request.
+	RequestCountTTLDays int
 
 	// GraphQL Cache settings
 	GraphQLLRUSize       int
@@ -29,47 +48,68 @@ type CacheConfig struct {
 	// General settings
 	EnableLRUCache    bool
 	EnableDynamoCache bool
+	// Requests are only counted when DynamoDB caching is enabled too
+	EnableRequestCounts bool
 }
 
 const (
 	// Default values
-	defaultTidePredictionLRUSize    = 1000
-	defaultTidePredictionTTLMinutes = 15
-	defaultDynamoTTLDays            = 2
-	defaultStationListTTLDays       = 2
-	defaultGraphQLLRUSize           = 5000
-	defaultGraphQLTTLMinutes        = 60
-	defaultBatchSize                = 25
-	defaultMaxBatchRetries          = 3
+	defaultTidePredictionLRUSize        = 1000
+	defaultTidePredictionTTLMinutes     = 15
+	defaultTidePredictionHardTTLMinutes = 6 * 60
+	defaultDynamoTTLDays                = 2
+	defaultDynamoHardTTLDays            = 7
+	defaultStationListTTLDays           = 2
+	defaultStationListHardTTLDays       = 14
+	defaultObservationTTLMinutes        = 6
+	defaultRequestCountTTLDays          = 30
+	defaultGraphQLLRUSize               = 5000
+	defaultGraphQLTTLMinutes            = 60
+	defaultBatchSize                    = 25
+	defaultMaxBatchRetries              = 3
 )
 
 // GetCacheConfig returns the cache configuration from environment variables or defaults
 func GetCacheConfig() *CacheConfig {
 	config := &CacheConfig{
 		// Set defaults
-		TidePredictionLRUSize:       getEnvInt("CACHE_TIDE_LRU_SIZE", defaultTidePredictionLRUSize),
-		TidePredictionLRUTTLMinutes: getEnvInt("CACHE_TIDE_LRU_TTL_MINUTES", defaultTidePredictionTTLMinutes),
-		TidePredictionDynamoTTLDays: getEnvInt("CACHE_DYNAMO_TTL_DAYS", defaultDynamoTTLDays),
-		StationListTTLDays:          getEnvInt("CACHE_STATION_LIST_TTL_DAYS", defaultStationListTTLDays),
-		GraphQLLRUSize:              getEnvInt("CACHE_GRAPHQL_LRU_SIZE", defaultGraphQLLRUSize),
-		GraphQLLRUTTLMinutes:        getEnvInt("CACHE_GRAPHQL_TTL_MINUTES", defaultGraphQLTTLMinutes),
-		BatchSize:                   getEnvInt("CACHE_BATCH_SIZE", defaultBatchSize),
-		MaxBatchRetries:             getEnvInt("CACHE_MAX_BATCH_RETRIES", defaultMaxBatchRetries),
-		EnableLRUCache:              getEnvBool("CACHE_ENABLE_LRU", true),
-		EnableDynamoCache:           getEnvBool("CACHE_ENABLE_DYNAMO", true),
+		TidePredictionLRUSize:           getEnvInt("CACHE_TIDE_LRU_SIZE", defaultTidePredictionLRUSize),
+		TidePredictionLRUTTLMinutes:     getEnvInt("CACHE_TIDE_LRU_TTL_MINUTES", defaultTidePredictionTTLMinutes),
+		TidePredictionLRUHardTTLMinutes: getEnvInt("CACHE_TIDE_LRU_HARD_TTL_MINUTES", defaultTidePredictionHardTTLMinutes),
+		TidePredictionDynamoTTLDays:     getEnvInt("CACHE_DYNAMO_TTL_DAYS", defaultDynamoTTLDays),
+		TidePredictionDynamoHardTTLDays: getEnvInt("CACHE_DYNAMO_HARD_TTL_DAYS", defaultDynamoHardTTLDays),
+		StationListTTLDays:              getEnvInt("CACHE_STATION_LIST_TTL_DAYS", defaultStationListTTLDays),
+		StationListHardTTLDays:          getEnvInt("CACHE_STATION_LIST_HARD_TTL_DAYS", defaultStationListHardTTLDays),
+		StationListBucket:               os.Getenv("STATION_LIST_BUCKET"),
+		ObservationTTLMinutes:           getEnvInt("CACHE_OBSERVATION_TTL_MINUTES", defaultObservationTTLMinutes),
+		RequestCountTTLDays:             getEnvInt("CACHE_REQUEST_COUNT_TTL_DAYS", defaultRequestCountTTLDays),
+		GraphQLLRUSize:                  getEnvInt("CACHE_GRAPHQL_LRU_SIZE", defaultGraphQLLRUSize),
+		GraphQLLRUTTLMinutes:            getEnvInt("CACHE_GRAPHQL_TTL_MINUTES", defaultGraphQLTTLMinutes),
+		BatchSize:                       getEnvInt("CACHE_BATCH_SIZE", defaultBatchSize),
+		MaxBatchRetries:                 getEnvInt("CACHE_MAX_BATCH_RETRIES", defaultMaxBatchRetries),
+		EnableLRUCache:                  getEnvBool("CACHE_ENABLE_LRU", true),
+		EnableDynamoCache:               getEnvBool("CACHE_ENABLE_DYNAMO", true),
+		EnableRequestCounts:             getEnvBool("CACHE_ENABLE_REQUEST_COUNTS", true),
 	}
 
 	log.Debug().
 		Int("TidePredictionLRUSize", config.TidePredictionLRUSize).
 		Int("TidePredictionLRUTTLMinutes", config.TidePredictionLRUTTLMinutes).
+		Int("TidePredictionLRUHardTTLMinutes", config.TidePredictionLRUHardTTLMinutes).
 		Int("TidePredictionDynamoTTLDays", config.TidePredictionDynamoTTLDays).
+		Int("TidePredictionDynamoHardTTLDays", config.TidePredictionDynamoHardTTLDays).
 		Int("StationListTTLDays", config.StationListTTLDays).
+		Int("StationListHardTTLDays", config.StationListHardTTLDays).
+		Str("StationListBucket", config.StationListBucket).
+		Int("ObservationTTLMinutes", config.ObservationTTLMinutes).
+		Int("RequestCountTTLDays", config.RequestCountTTLDays).
 		Int("GraphQLLRUSize", config.GraphQLLRUSize).
 		Int("GraphQLLRUTTLMinutes", config.GraphQLLRUTTLMinutes).
 		Int("BatchSize", config.BatchSize).
 		Int("MaxBatchRetries", config.MaxBatchRetries).
 		Bool("EnableLRUCache", config.EnableLRUCache).
 		Bool("EnableDynamoCache", config.EnableDynamoCache).
+		Bool("EnableRequestCounts", config.EnableRequestCounts).
 		Msg("Cache configuration loaded")
 
 	return config
@@ -80,6 +120,14 @@ func (c *CacheConfig) GetTidePredictionLRUTTL() time.Duration {
 	return time.Duration(c.TidePredictionLRUTTLMinutes) * time.Minute
 }
 
+func (c *CacheConfig) GetTidePredictionLRUHardTTL() time.Duration {
+	return hardTTL(c.GetTidePredictionLRUTTL(), time.Duration(c.TidePredictionLRUHardTTLMinutes)*time.Minute)
+}
+
+func (c *CacheConfig) GetObservationTTL() time.Duration {
+	return time.Duration(c.ObservationTTLMinutes) * time.Minute
+}
+
 func (c *CacheConfig) GetGraphQLLRUTTL() time.Duration {
 	return time.Duration(c.GraphQLLRUTTLMinutes) * time.Minute
 }
@@ -88,10 +136,30 @@ func (c *CacheConfig) GetDynamoTTL() time.Duration {
 	return time.Duration(c.TidePredictionDynamoTTLDays) * 24 * time.Hour
 }
 
+func (c *CacheConfig) GetDynamoHardTTL() time.Duration {
+	return hardTTL(c.GetDynamoTTL(), time.Duration(c.TidePredictionDynamoHardTTLDays)*24*time.Hour)
+}
+
 func (c *CacheConfig) GetStationListTTL() time.Duration {
 	return time.Duration(c.StationListTTLDays) * 24 * time.Hour
 }
 
+func (c *CacheConfig) GetStationListHardTTL() time.Duration {
+	return hardTTL(c.GetStationListTTL(), time.Duration(c.StationListHardTTLDays)*24*time.Hour)
+}
+
+func (c *CacheConfig) GetRequestCountTTL() time.Duration {
+	return time.Duration(c.RequestCountTTLDays) * 24 * time.Hour
+}
+
+// hardTTL returns the hard TTL, raised to the soft TTL if it's shorter
+func hardTTL(soft, hard time.Duration) time.Duration {
+	if hard < soft {
+		return soft
+	}
+	return hard
+}
+
 // Helper functions to get environment variables with defaults
 func getEnvInt(key string, defaultVal int) int {
 	if val, exists := os.LookupEnv(key); exists {
@@ -103,6 +171,16 @@ func getEnvInt(key string, defaultVal int) int {
 	return defaultVal
 }
 
+func getEnvFloat(key string, defaultVal float64) float64 {
+	if val, exists := os.LookupEnv(key); exists {
+		if floatVal, err := strconv.ParseFloat(val, 64); err == nil {
+			return floatVal
+		}
+		log.Warn().Str("key", key).Msg("Invalid number value in environment variable, using default")
+	}
+	return defaultVal
+}
+
 func getEnvBool(key string, defaultVal bool) bool {
 	if val, exists := os.LookupEnv(key); exists {
 		return val == "true" || val == "1" || val == "yes"
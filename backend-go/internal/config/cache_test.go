@@ -42,6 +42,7 @@ func TestGetCacheConfig(t *testing.T) {
 		"CACHE_TIDE_LRU_TTL_MINUTES",
 		"CACHE_DYNAMO_TTL_DAYS",
 		"CACHE_STATION_LIST_TTL_DAYS",
+		"CACHE_OBSERVATION_TTL_MINUTES",
 		"CACHE_BATCH_SIZE",
 		"CACHE_MAX_BATCH_RETRIES",
 		"CACHE_ENABLE_LRU",
@@ -183,6 +184,35 @@ func TestEnvironmentOverrides(t *testing.T) {
 				assert.Equal(t, 14*24*time.Hour, c.GetDynamoTTL())
 			},
 		},
+		{
+			name: "station list bucket",
+			envVars: map[string]string{
+				"STATION_LIST_BUCKET": "flowebb-station-list-cache",
+			},
+			check: func(t *testing.T, c *CacheConfig) {
+				assert.Equal(t, "flowebb-station-list-cache", c.StationListBucket)
+			},
+		},
+		{
+			name: "observation TTL override",
+			envVars: map[string]string{
+				"CACHE_OBSERVATION_TTL_MINUTES": "3",
+			},
+			check: func(t *testing.T, c *CacheConfig) {
+				assert.Equal(t, 3*time.Minute, c.GetObservationTTL())
+			},
+		},
+		{
+			name: "request counts",
+			envVars: map[string]string{
+				"CACHE_ENABLE_REQUEST_COUNTS":  "false",
+				"CACHE_REQUEST_COUNT_TTL_DAYS": "7",
+			},
+			check: func(t *testing.T, c *CacheConfig) {
+				assert.False(t, c.EnableRequestCounts)
+				assert.Equal(t, 7*24*time.Hour, c.GetRequestCountTTL())
+			},
+		},
 		{
 			name: "invalid numeric values",
 			envVars: map[string]string{
@@ -205,10 +235,14 @@ func TestEnvironmentOverrides(t *testing.T) {
 		"CACHE_TIDE_LRU_TTL_MINUTES",
 		"CACHE_DYNAMO_TTL_DAYS",
 		"CACHE_STATION_LIST_TTL_DAYS",
+		"CACHE_OBSERVATION_TTL_MINUTES",
 		"CACHE_BATCH_SIZE",
 		"CACHE_MAX_BATCH_RETRIES",
 		"CACHE_ENABLE_LRU",
 		"CACHE_ENABLE_DYNAMO",
+		"CACHE_ENABLE_REQUEST_COUNTS",
+		"CACHE_REQUEST_COUNT_TTL_DAYS",
+		"STATION_LIST_BUCKET",
 	}
 	for _, k := range envVars {
 		originalEnv[k] = os.Getenv(k)
@@ -270,13 +304,38 @@ func TestDefaultValues(t *testing.T) {
 	assert.Equal(t, defaultTidePredictionTTLMinutes, config.TidePredictionLRUTTLMinutes)
 	assert.Equal(t, defaultDynamoTTLDays, config.TidePredictionDynamoTTLDays)
 	assert.Equal(t, defaultStationListTTLDays, config.StationListTTLDays)
+	assert.Equal(t, defaultTidePredictionHardTTLMinutes, config.TidePredictionLRUHardTTLMinutes)
+	assert.Equal(t, defaultDynamoHardTTLDays, config.TidePredictionDynamoHardTTLDays)
+	assert.Equal(t, defaultStationListHardTTLDays, config.StationListHardTTLDays)
 	assert.Equal(t, defaultBatchSize, config.BatchSize)
 	assert.Equal(t, defaultMaxBatchRetries, config.MaxBatchRetries)
 	assert.True(t, config.EnableLRUCache)
 	assert.True(t, config.EnableDynamoCache)
+	assert.Equal(t, defaultRequestCountTTLDays, config.RequestCountTTLDays)
+	assert.True(t, config.EnableRequestCounts)
 
 	// Verify helper methods return expected values
 	assert.Equal(t, time.Duration(defaultTidePredictionTTLMinutes)*time.Minute, config.GetTidePredictionLRUTTL())
 	assert.Equal(t, time.Duration(defaultDynamoTTLDays)*24*time.Hour, config.GetDynamoTTL())
 	assert.Equal(t, time.Duration(defaultStationListTTLDays)*24*time.Hour, config.GetStationListTTL())
+	assert.Equal(t, 6*time.Hour, config.GetTidePredictionLRUHardTTL())
+	assert.Equal(t, 7*24*time.Hour, config.GetDynamoHardTTL())
+	assert.Equal(t, 14*24*time.Hour, config.GetStationListHardTTL())
+	assert.Equal(t, 30*24*time.Hour, config.GetRequestCountTTL())
+}
+
+func TestHardTTLs(t *testing.T) {
+	config := &CacheConfig{
+		TidePredictionLRUTTLMinutes:     30,
+		TidePredictionLRUHardTTLMinutes: 10,
+		TidePredictionDynamoTTLDays:     2,
+		TidePredictionDynamoHardTTLDays: 5,
+		StationListTTLDays:              3,
+		StationListHardTTLDays:          0,
+	}
+
+	// Hard TTLs are never shorter than soft ones
+	assert.Equal(t, 30*time.Minute, config.GetTidePredictionLRUHardTTL())
+	assert.Equal(t, 5*24*time.Hour, config.GetDynamoHardTTL())
+	assert.Equal(t, 3*24*time.Hour, config.GetStationListHardTTL())
 }
@@ -4,6 +4,7 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"os"
+	"strings"
 	"time"
 )
 
@@ -13,6 +14,14 @@ type Config struct {
 	HTTPTimeout time.Duration
 	MaxRetries  int
 	NOAABaseURL string
+	CHSBaseURL  string // Canadian Hydrographic Service IWLS API
+	UKHOBaseURL string // UK Hydrographic Office Admiralty Tidal API
+	// UKHOAPIKey is the Admiralty API subscription key; UKHO stations are
+	// unavailable without one
+	UKHOAPIKey string
+	// SourcePriority orders the station sources, e.g. NOAA, CHS, UKHO, for
+	// choosing between co-located stations. Sources left out come last.
+	SourcePriority []string
 	// Add other common configurations here
 }
 
@@ -43,14 +52,37 @@ func WithHTTPTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithUKHOAPIKey allows setting the Admiralty Tidal API subscription key
+func WithUKHOAPIKey(key string) Option {
+	return func(c *Config) {
+		c.UKHOAPIKey = key
+	}
+}
+
+// WithSourcePriority allows setting the station source priority from a
+// comma-separated list, e.g. "NOAA,CHS,UKHO"
+func WithSourcePriority(sources string) Option {
+	return func(c *Config) {
+		c.SourcePriority = nil
+		for _, source := range strings.Split(sources, ",") {
+			if source = strings.ToUpper(strings.TrimSpace(source)); source != "" {
+				c.SourcePriority = append(c.SourcePriority, source)
+			}
+		}
+	}
+}
+
 // New creates a new configuration with default values
 func New(opts ...Option) *Config {
 	cfg := &Config{
-		Environment: "production",
-		LogLevel:    zerolog.InfoLevel,
-		HTTPTimeout: 10 * time.Second,
-		MaxRetries:  3,
-		NOAABaseURL: "https://api.tidesandcurrents.noaa.gov",
+		Environment:    "production",
+		LogLevel:       zerolog.InfoLevel,
+		HTTPTimeout:    10 * time.Second,
+		MaxRetries:     3,
+		NOAABaseURL:    "https://api.tidesandcurrents.noaa.gov",
+		CHSBaseURL:     "https://api-iwls.dfo-mpo.gc.ca",
+		UKHOBaseURL:    "https://admiraltyapi.azure-api.net/uktidalapi",
+		SourcePriority: []string{"NOAA", "CHS", "UKHO"},
 	}
 
 	// Apply options
@@ -78,6 +110,8 @@ func LoadFromEnv() *Config {
 		WithEnvironment(getEnvOrDefault("ENV", "production")),
 		WithLogLevel(getEnvOrDefault("LOG_LEVEL", "info")),
 		WithHTTPTimeout(getDurationEnvOrDefault("HTTP_TIMEOUT", 10*time.Second)),
+		WithUKHOAPIKey(os.Getenv("UKHO_API_KEY")),
+		WithSourcePriority(getEnvOrDefault("STATION_SOURCE_PRIORITY", "NOAA,CHS,UKHO")),
 	)
 }
 
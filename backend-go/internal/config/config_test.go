@@ -37,6 +37,22 @@ func TestWithHTTPTimeout(t *testing.T) {
 	assert.Equal(t, 30*time.Second, cfg.HTTPTimeout)
 }
 
+func TestWithUKHOAPIKey(t *testing.T) {
+	assert.Empty(t, New().UKHOAPIKey)
+
+	cfg := New(WithUKHOAPIKey("subscription-key"))
+
+	assert.Equal(t, "subscription-key", cfg.UKHOAPIKey)
+}
+
+func TestWithSourcePriority(t *testing.T) {
+	assert.Equal(t, []string{"NOAA", "CHS", "UKHO"}, New().SourcePriority)
+
+	cfg := New(WithSourcePriority(" chs, UKHO,,noaa "))
+
+	assert.Equal(t, []string{"CHS", "UKHO", "NOAA"}, cfg.SourcePriority)
+}
+
 func TestInitializeLogging(t *testing.T) {
 	cfg := New(WithEnvironment("local"), WithLogLevel("debug"))
 	cfg.InitializeLogging()
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// WarmConfig holds the defaults for warming the prediction cache, used when
+// a warm-up run doesn't give its own
+type WarmConfig struct {
+	// Stations to warm, in addition to the most requested ones
+	StationIDs []string
+	// Number of most requested stations to warm
+	TopStations int
+	// Days of predictions to warm, from today in each station's zone
+	Days int
+	// Stations warmed at once
+	Concurrency int
+	// Most NOAA API requests made per second
+	NOAARequestsPerSecond float64
+}
+
+const (
+	defaultWarmDays                  = 7
+	defaultWarmConcurrency           = 4
+	defaultWarmNOAARequestsPerSecond = 2
+)
+
+// GetWarmConfig returns the warm-up configuration from environment variables or defaults
+func GetWarmConfig() *WarmConfig {
+	config := &WarmConfig{
+		StationIDs:            splitList(os.Getenv("WARM_STATION_IDS")),
+		TopStations:           getEnvInt("WARM_TOP_STATIONS", 0),
+		Days:                  getEnvInt("WARM_DAYS", defaultWarmDays),
+		Concurrency:           getEnvInt("WARM_CONCURRENCY", defaultWarmConcurrency),
+		NOAARequestsPerSecond: getEnvFloat("WARM_NOAA_REQUESTS_PER_SECOND", defaultWarmNOAARequestsPerSecond),
+	}
+
+	log.Debug().
+		Strs("StationIDs", config.StationIDs).
+		Int("TopStations", config.TopStations).
+		Int("Days", config.Days).
+		Int("Concurrency", config.Concurrency).
+		Float64("NOAARequestsPerSecond", config.NOAARequestsPerSecond).
+		Msg("Warm configuration loaded")
+
+	return config
+}
+
+// splitList splits a comma-separated list, dropping empty entries
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetWarmConfig(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		config := GetWarmConfig()
+
+		assert.Empty(t, config.StationIDs)
+		assert.Equal(t, 0, config.TopStations)
+		assert.Equal(t, defaultWarmDays, config.Days)
+		assert.Equal(t, defaultWarmConcurrency, config.Concurrency)
+		assert.Equal(t, float64(defaultWarmNOAARequestsPerSecond), config.NOAARequestsPerSecond)
+	})
+
+	t.Run("overrides", func(t *testing.T) {
+		t.Setenv("WARM_STATION_IDS", " 9447130, CHS-07795,,")
+		t.Setenv("WARM_TOP_STATIONS", "25")
+		t.Setenv("WARM_DAYS", "3")
+		t.Setenv("WARM_CONCURRENCY", "8")
+		t.Setenv("WARM_NOAA_REQUESTS_PER_SECOND", "0.5")
+
+		config := GetWarmConfig()
+
+		assert.Equal(t, []string{"9447130", "CHS-07795"}, config.StationIDs)
+		assert.Equal(t, 25, config.TopStations)
+		assert.Equal(t, 3, config.Days)
+		assert.Equal(t, 8, config.Concurrency)
+		assert.Equal(t, 0.5, config.NOAARequestsPerSecond)
+	})
+}
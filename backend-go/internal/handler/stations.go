@@ -5,9 +5,11 @@ import (
 	"errors"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/bbernstein/flowebb/backend-go/internal/api"
+	"github.com/bbernstein/flowebb/backend-go/internal/coalesce"
 	"github.com/bbernstein/flowebb/backend-go/internal/models"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 type StationsHandler struct {
@@ -22,6 +24,7 @@ func NewStationsHandler(finder models.StationFinder) *StationsHandler {
 
 func (h *StationsHandler) HandleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	params := request.QueryStringParameters
+	defer coalesce.LogStats(h.stationFinder)
 
 	// Check if we're looking up by station ID or coordinates
 	if stationID, ok := params["stationId"]; ok {
@@ -35,6 +38,51 @@ func (h *StationsHandler) HandleRequest(ctx context.Context, request events.APIG
 		return api.Success(api.NewStationsResponse([]models.Station{*stationLocal}))
 	}
 
+	filter, err := api.ParseStationFilter(params)
+	if err != nil {
+		return api.Error(err.Error(), http.StatusBadRequest)
+	}
+
+	// A bounding box returns everything in a map viewport
+	if bbox, ok := params["bbox"]; ok {
+		south, west, north, east, err := api.ParseBoundingBox(bbox)
+		if err != nil {
+			return api.Error(err.Error(), http.StatusBadRequest)
+		}
+
+		// No limit unless one is given
+		stations, err := h.stationFinder.FindStationsInBounds(ctx, south, west, north, east, parseLimit(params, 0), filter)
+		if err != nil {
+			return api.Error("Error finding stations", http.StatusInternalServerError)
+		}
+
+		return api.Success(api.NewStationsResponse(stations))
+	}
+
+	// A text search, favoring stations near lat/lon when they are given
+	if query, ok := params["q"]; ok {
+		if strings.TrimSpace(query) == "" {
+			return api.Error("Search query is required", http.StatusBadRequest)
+		}
+
+		var near *models.Coordinates
+		if _, hasLat := params["lat"]; hasLat {
+			lat, lon, err := api.ParseCoordinates(params)
+			if err != nil {
+				return api.Error("Invalid coordinates", http.StatusBadRequest)
+			}
+			near = &models.Coordinates{Latitude: lat, Longitude: lon}
+		}
+
+		// Default limit to 10 if not specified
+		stations, err := h.stationFinder.SearchStations(ctx, query, near, parseLimit(params, 10), filter)
+		if err != nil {
+			return api.Error("Error searching stations", http.StatusInternalServerError)
+		}
+
+		return api.Success(api.NewStationsResponse(stations))
+	}
+
 	// Parse coordinates
 	lat, lon, err := api.ParseCoordinates(params)
 	if err != nil {
@@ -46,17 +94,21 @@ func (h *StationsHandler) HandleRequest(ctx context.Context, request events.APIG
 	}
 
 	// Default limit to 5 if not specified
-	limit := 5
-	if limitStr, ok := params["limit"]; ok {
-		if parsedLimit, err := strconv.Atoi(limitStr); err == nil {
-			limit = parsedLimit
-		}
-	}
-
-	stations, err := h.stationFinder.FindNearestStations(ctx, lat, lon, limit)
+	stations, err := h.stationFinder.FindNearestStations(ctx, lat, lon, parseLimit(params, 5), filter)
 	if err != nil {
 		return api.Error("Error finding stations", http.StatusInternalServerError)
 	}
 
 	return api.Success(api.NewStationsResponse(stations))
 }
+
+// parseLimit returns the limit parameter, or defaultLimit when it is missing
+// or not a number
+func parseLimit(params map[string]string, defaultLimit int) int {
+	if limitStr, ok := params["limit"]; ok {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil {
+			return parsedLimit
+		}
+	}
+	return defaultLimit
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/bbernstein/flowebb/backend-go/internal/models"
 	"github.com/stretchr/testify/assert"
@@ -13,8 +14,10 @@ import (
 
 // mockStationFinder implements models.StationFinder interface for testing
 type mockStationFinder struct {
-	findStationFn         func(ctx context.Context, stationID string) (*models.Station, error)
-	findNearestStationsFn func(ctx context.Context, lat, lon float64, limit int) ([]models.Station, error)
+	findStationFn          func(ctx context.Context, stationID string) (*models.Station, error)
+	findNearestStationsFn  func(ctx context.Context, lat, lon float64, limit int, filter *models.StationFilter) ([]models.Station, error)
+	findStationsInBoundsFn func(ctx context.Context, south, west, north, east float64, limit int, filter *models.StationFilter) ([]models.Station, error)
+	searchStationsFn       func(ctx context.Context, query string, near *models.Coordinates, limit int, filter *models.StationFilter) ([]models.Station, error)
 }
 
 func (m *mockStationFinder) FindStation(ctx context.Context, stationID string) (*models.Station, error) {
@@ -24,9 +27,23 @@ func (m *mockStationFinder) FindStation(ctx context.Context, stationID string) (
 	return nil, nil
 }
 
-func (m *mockStationFinder) FindNearestStations(ctx context.Context, lat, lon float64, limit int) ([]models.Station, error) {
+func (m *mockStationFinder) FindNearestStations(ctx context.Context, lat, lon float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
 	if m.findNearestStationsFn != nil {
-		return m.findNearestStationsFn(ctx, lat, lon, limit)
+		return m.findNearestStationsFn(ctx, lat, lon, limit, filter)
+	}
+	return nil, nil
+}
+
+func (m *mockStationFinder) FindStationsInBounds(ctx context.Context, south, west, north, east float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
+	if m.findStationsInBoundsFn != nil {
+		return m.findStationsInBoundsFn(ctx, south, west, north, east, limit, filter)
+	}
+	return nil, nil
+}
+
+func (m *mockStationFinder) SearchStations(ctx context.Context, query string, near *models.Coordinates, limit int, filter *models.StationFilter) ([]models.Station, error) {
+	if m.searchStationsFn != nil {
+		return m.searchStationsFn(ctx, query, near, limit, filter)
 	}
 	return nil, nil
 }
@@ -91,7 +108,7 @@ func TestStationsHandler_HandleRequest(t *testing.T) {
 			},
 			setupMock: func() models.StationFinder {
 				return &mockStationFinder{
-					findNearestStationsFn: func(ctx context.Context, lat, lon float64, limit int) ([]models.Station, error) {
+					findNearestStationsFn: func(ctx context.Context, lat, lon float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
 						return []models.Station{
 							createTestStation("TEST001"),
 							createTestStation("TEST002"),
@@ -102,6 +119,93 @@ func TestStationsHandler_HandleRequest(t *testing.T) {
 			expectedStatus: http.StatusOK,
 			wantErr:        false,
 		},
+		{
+			name: "successful stations in bounding box lookup",
+			request: events.APIGatewayProxyRequest{
+				QueryStringParameters: map[string]string{
+					"bbox": "-123.0,47.0,-122.0,48.0",
+				},
+			},
+			setupMock: func() models.StationFinder {
+				return &mockStationFinder{
+					findStationsInBoundsFn: func(ctx context.Context, south, west, north, east float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
+						if south != 47.0 || west != -123.0 || north != 48.0 || east != -122.0 || limit != 0 {
+							return nil, fmt.Errorf("unexpected bounds %f,%f,%f,%f limit %d", south, west, north, east, limit)
+						}
+						return []models.Station{createTestStation("TEST001")}, nil
+					},
+				}
+			},
+			expectedStatus: http.StatusOK,
+			wantErr:        false,
+		},
+		{
+			name: "successful station search",
+			request: events.APIGatewayProxyRequest{
+				QueryStringParameters: map[string]string{
+					"q": "Seatle",
+				},
+			},
+			setupMock: func() models.StationFinder {
+				return &mockStationFinder{
+					searchStationsFn: func(ctx context.Context, query string, near *models.Coordinates, limit int, filter *models.StationFilter) ([]models.Station, error) {
+						if query != "Seatle" || near != nil || limit != 10 {
+							return nil, fmt.Errorf("unexpected search %q near %v limit %d", query, near, limit)
+						}
+						return []models.Station{createTestStation("TEST001")}, nil
+					},
+				}
+			},
+			expectedStatus: http.StatusOK,
+			wantErr:        false,
+		},
+		{
+			name: "successful station search near a location",
+			request: events.APIGatewayProxyRequest{
+				QueryStringParameters: map[string]string{
+					"q":     "harbor",
+					"lat":   "47.6062",
+					"lon":   "-122.3321",
+					"limit": "3",
+				},
+			},
+			setupMock: func() models.StationFinder {
+				return &mockStationFinder{
+					searchStationsFn: func(ctx context.Context, query string, near *models.Coordinates, limit int, filter *models.StationFilter) ([]models.Station, error) {
+						if near == nil || near.Latitude != 47.6062 || near.Longitude != -122.3321 || limit != 3 {
+							return nil, fmt.Errorf("unexpected search %q near %v limit %d", query, near, limit)
+						}
+						return []models.Station{createTestStation("TEST001")}, nil
+					},
+				}
+			},
+			expectedStatus: http.StatusOK,
+			wantErr:        false,
+		},
+		{
+			name: "successful filtered nearest stations lookup",
+			request: events.APIGatewayProxyRequest{
+				QueryStringParameters: map[string]string{
+					"lat":         "43.6567",
+					"lon":         "-70.2467",
+					"stationType": "R",
+					"state":       "ME",
+				},
+			},
+			setupMock: func() models.StationFinder {
+				return &mockStationFinder{
+					findNearestStationsFn: func(ctx context.Context, lat, lon float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
+						want := &models.StationFilter{StationType: models.StationTypeReference, State: "ME"}
+						if filter == nil || filter.StationType != want.StationType || filter.State != want.State {
+							return nil, fmt.Errorf("unexpected filter %+v", filter)
+						}
+						return []models.Station{createTestStation("TEST001")}, nil
+					},
+				}
+			},
+			expectedStatus: http.StatusOK,
+			wantErr:        false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -173,6 +277,60 @@ func TestStationsHandler_ParameterValidation(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedError:  "Invalid parameters",
 		},
+		{
+			name: "malformed bounding box",
+			request: events.APIGatewayProxyRequest{
+				QueryStringParameters: map[string]string{
+					"bbox": "-123.0,47.0,-122.0",
+				},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid bounding box: expected west,south,east,north",
+		},
+		{
+			name: "bounding box with south above north",
+			request: events.APIGatewayProxyRequest{
+				QueryStringParameters: map[string]string{
+					"bbox": "-123.0,48.0,-122.0,47.0",
+				},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid bounding box: south 48.000000 is north of north 47.000000",
+		},
+		{
+			name: "invalid station filter",
+			request: events.APIGatewayProxyRequest{
+				QueryStringParameters: map[string]string{
+					"lat":         "47.6062",
+					"lon":         "-122.3321",
+					"stationType": "X",
+				},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid station filter: invalid station type: X",
+		},
+		{
+			name: "blank search query",
+			request: events.APIGatewayProxyRequest{
+				QueryStringParameters: map[string]string{
+					"q": " ",
+				},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Search query is required",
+		},
+		{
+			name: "search near invalid coordinates",
+			request: events.APIGatewayProxyRequest{
+				QueryStringParameters: map[string]string{
+					"q":   "Seattle",
+					"lat": "91",
+					"lon": "0",
+				},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid coordinates",
+		},
 	}
 
 	for _, tt := range tests {
@@ -234,7 +392,7 @@ func TestStationsHandler_ErrorHandling(t *testing.T) {
 			},
 			setupMock: func() models.StationFinder {
 				return &mockStationFinder{
-					findNearestStationsFn: func(ctx context.Context, lat, lon float64, limit int) ([]models.Station, error) {
+					findNearestStationsFn: func(ctx context.Context, lat, lon float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
 						return nil, assert.AnError // Simulate internal error
 					},
 				}
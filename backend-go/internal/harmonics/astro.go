@@ -0,0 +1,116 @@
+package harmonics
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// Inclination of the moon's orbit to the ecliptic (degrees)
+	lunarInclination = 5.145
+	// Obliquity of the ecliptic (degrees), as used by Schureman
+	obliquity = 23.452
+)
+
+// j2000 is the epoch used for the mean longitude polynomials below
+var j2000 = time.Date(2000, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+// AstronomicalArguments holds the fundamental angles (in degrees) needed to
+// compute equilibrium arguments and nodal corrections at a given instant.
+type AstronomicalArguments struct {
+	T  float64 // Hour angle of the mean sun
+	S  float64 // Mean longitude of the moon
+	H  float64 // Mean longitude of the sun
+	P  float64 // Longitude of the lunar perigee
+	N  float64 // Longitude of the moon's ascending node
+	P1 float64 // Longitude of the solar perigee
+
+	I       float64 // Inclination of the moon's orbit to the equator
+	Nu      float64 // Right ascension of the lunar intersection
+	Xi      float64 // Longitude in the moon's orbit of the lunar intersection
+	NuP     float64 // Term in the argument of K1
+	TwoNuPP float64 // Term in the argument of K2
+}
+
+// Rates of change of the fundamental angles in degrees per hour
+const (
+	rateT  = 15.0
+	rateS  = 481267.88123421 / (36525 * 24)
+	rateH  = 36000.76983 / (36525 * 24)
+	rateP  = 4069.0137287 / (36525 * 24)
+	rateP1 = 1.71946 / (36525 * 24)
+)
+
+// ArgumentsAt computes the astronomical arguments for the given instant
+func ArgumentsAt(t time.Time) AstronomicalArguments {
+	t = t.UTC()
+	// Julian centuries since J2000
+	c := t.Sub(j2000).Hours() / (36525 * 24)
+
+	hours := float64(t.Hour()) + float64(t.Minute())/60 + float64(t.Second())/3600 +
+		float64(t.Nanosecond())/3.6e12
+
+	args := AstronomicalArguments{
+		T:  normalizeAngle(180 + rateT*hours),
+		S:  normalizeAngle(218.3164477 + 481267.88123421*c - 0.0015786*c*c),
+		H:  normalizeAngle(280.46646 + 36000.76983*c + 0.0003032*c*c),
+		P:  normalizeAngle(83.3532465 + 4069.0137287*c - 0.0103200*c*c),
+		N:  normalizeAngle(125.04452 - 1934.136261*c + 0.0020708*c*c),
+		P1: normalizeAngle(282.93735 + 1.71946*c + 0.00046*c*c),
+	}
+
+	args.I, args.Nu, args.Xi, args.NuP, args.TwoNuPP = nodeArguments(args.N)
+
+	return args
+}
+
+// nodeArguments derives the angles that depend on the longitude of the moon's
+// node N (degrees): I, nu, xi, nu-prime and twice nu-double-prime.
+func nodeArguments(nodeLongitude float64) (bigI, nu, xi, nuP, twoNuPP float64) {
+	n := radians(nodeLongitude)
+	i := radians(lunarInclination)
+	omega := radians(obliquity)
+
+	cosI := math.Cos(i)*math.Cos(omega) - math.Sin(i)*math.Sin(omega)*math.Cos(n)
+	iRad := math.Acos(cosI)
+
+	// Schureman eqs. 224-226 via Napier's analogies
+	e1 := math.Atan(math.Cos(0.5*(omega-i))/math.Cos(0.5*(omega+i))*math.Tan(0.5*n)) - 0.5*n
+	e2 := math.Atan(math.Sin(0.5*(omega-i))/math.Sin(0.5*(omega+i))*math.Tan(0.5*n)) - 0.5*n
+	nuRad := e1 - e2
+	xiRad := -(e1 + e2)
+
+	sin2I := math.Sin(2 * iRad)
+	sinSqI := math.Pow(math.Sin(iRad), 2)
+	nuPRad := math.Atan2(sin2I*math.Sin(nuRad), sin2I*math.Cos(nuRad)+0.3347)
+	twoNuPPRad := math.Atan2(sinSqI*math.Sin(2*nuRad), sinSqI*math.Cos(2*nuRad)+0.0727)
+
+	return degrees(iRad), signedAngle(degrees(nuRad)), signedAngle(degrees(xiRad)),
+		degrees(nuPRad), degrees(twoNuPPRad)
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func degrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
+
+// signedAngle maps an angle in degrees onto (-180, 180]
+func signedAngle(deg float64) float64 {
+	deg = normalizeAngle(deg)
+	if deg > 180 {
+		deg -= 360
+	}
+	return deg
+}
+
+// normalizeAngle maps an angle in degrees onto [0, 360)
+func normalizeAngle(deg float64) float64 {
+	deg = math.Mod(deg, 360)
+	if deg < 0 {
+		deg += 360
+	}
+	return deg
+}
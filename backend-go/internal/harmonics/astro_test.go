@@ -0,0 +1,72 @@
+package harmonics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArgumentsAt_J2000(t *testing.T) {
+	// Reference values from Meeus, Astronomical Algorithms, at 2000-01-01 12:00
+	args := ArgumentsAt(time.Date(2000, time.January, 1, 12, 0, 0, 0, time.UTC))
+
+	assert.InDelta(t, 0.0, args.T, 1e-9)
+	assert.InDelta(t, 218.3165, args.S, 1e-3)
+	assert.InDelta(t, 280.4665, args.H, 1e-3)
+	assert.InDelta(t, 83.3532, args.P, 1e-3)
+	assert.InDelta(t, 125.0445, args.N, 1e-3)
+	assert.InDelta(t, 282.9374, args.P1, 1e-3)
+}
+
+func TestArgumentsAt_HourAngle(t *testing.T) {
+	args := ArgumentsAt(time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC))
+	assert.InDelta(t, 180.0, args.T, 1e-9)
+
+	// Time zone of the input must not matter
+	loc := time.FixedZone("Station", -8*3600)
+	local := ArgumentsAt(time.Date(2024, time.February, 29, 16, 0, 0, 0, loc))
+	assert.InDelta(t, args.T, local.T, 1e-9)
+	assert.InDelta(t, args.S, local.S, 1e-9)
+}
+
+func TestNodeArguments_Limits(t *testing.T) {
+	tests := []struct {
+		name  string
+		node  float64
+		wantI float64
+	}{
+		{name: "node at vernal equinox", node: 0, wantI: 28.60},
+		{name: "node at autumnal equinox", node: 180, wantI: 18.31},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bigI, nu, xi, _, _ := nodeArguments(tt.node)
+			assert.InDelta(t, tt.wantI, bigI, 0.01)
+			assert.InDelta(t, 0.0, nu, 1e-6)
+			assert.InDelta(t, 0.0, xi, 1e-6)
+		})
+	}
+
+	// nu reaches about 13 degrees, xi about 12 degrees, over a nodal cycle
+	maxNu, maxXi := 0.0, 0.0
+	for n := 0.0; n < 360; n += 0.5 {
+		_, nu, xi, _, _ := nodeArguments(n)
+		if nu > maxNu {
+			maxNu = nu
+		}
+		if xi > maxXi {
+			maxXi = xi
+		}
+	}
+	assert.InDelta(t, 13.02, maxNu, 0.05)
+	assert.InDelta(t, 12.0, maxXi, 0.1)
+}
+
+func TestNormalizeAngle(t *testing.T) {
+	assert.InDelta(t, 10.0, normalizeAngle(370), 1e-9)
+	assert.InDelta(t, 350.0, normalizeAngle(-10), 1e-9)
+	assert.InDelta(t, -10.0, signedAngle(350), 1e-9)
+	assert.InDelta(t, 180.0, signedAngle(-180), 1e-9)
+}
@@ -0,0 +1,193 @@
+package harmonics
+
+import (
+	"math"
+	"sort"
+)
+
+// Constituent describes a tidal constituent by its equilibrium argument and
+// nodal correction. The equilibrium argument V is a linear combination of the
+// astronomical arguments T, s, h, p and p1 plus a constant phase offset.
+type Constituent struct {
+	Name string
+
+	// Coefficients of T, s, h, p and p1 in the equilibrium argument
+	T, S, H, P, P1 int
+	// Constant phase offset in degrees
+	Offset float64
+
+	// nodal returns the node factor f and nodal phase correction u (degrees)
+	nodal func(a AstronomicalArguments) (f, u float64)
+}
+
+// Speed returns the angular speed of the constituent in degrees per hour
+func (c Constituent) Speed() float64 {
+	return float64(c.T)*rateT + float64(c.S)*rateS + float64(c.H)*rateH +
+		float64(c.P)*rateP + float64(c.P1)*rateP1
+}
+
+// EquilibriumArgument returns V in degrees for the given astronomical arguments
+func (c Constituent) EquilibriumArgument(a AstronomicalArguments) float64 {
+	return normalizeAngle(float64(c.T)*a.T + float64(c.S)*a.S + float64(c.H)*a.H +
+		float64(c.P)*a.P + float64(c.P1)*a.P1 + c.Offset)
+}
+
+// NodalCorrection returns the node factor f and the nodal phase correction u
+func (c Constituent) NodalCorrection(a AstronomicalArguments) (float64, float64) {
+	if c.nodal == nil {
+		return 1, 0
+	}
+	return c.nodal(a)
+}
+
+// Node factors and phase corrections after Schureman (1958)
+
+func nodalM2(a AstronomicalArguments) (float64, float64) {
+	i := radians(a.I)
+	return math.Pow(math.Cos(i/2), 4) / 0.91544, 2*a.Xi - 2*a.Nu
+}
+
+func nodalO1(a AstronomicalArguments) (float64, float64) {
+	i := radians(a.I)
+	return math.Sin(i) * math.Pow(math.Cos(i/2), 2) / 0.37988, 2*a.Xi - a.Nu
+}
+
+func nodalK1(a AstronomicalArguments) (float64, float64) {
+	i := radians(a.I)
+	nu := radians(a.Nu)
+	f := math.Sqrt(0.8965*math.Pow(math.Sin(2*i), 2) + 0.6001*math.Sin(2*i)*math.Cos(nu) + 0.1006)
+	return f, -a.NuP
+}
+
+func nodalK2(a AstronomicalArguments) (float64, float64) {
+	i := radians(a.I)
+	nu := radians(a.Nu)
+	f := math.Sqrt(19.0444*math.Pow(math.Sin(i), 4) + 2.7702*math.Pow(math.Sin(i), 2)*math.Cos(2*nu) + 0.0981)
+	return f, -a.TwoNuPP
+}
+
+func nodalJ1(a AstronomicalArguments) (float64, float64) {
+	i := radians(a.I)
+	return math.Sin(2*i) / 0.7214, -a.Nu
+}
+
+func nodalOO1(a AstronomicalArguments) (float64, float64) {
+	i := radians(a.I)
+	return math.Sin(i) * math.Pow(math.Sin(i/2), 2) / 0.01640, -2*a.Xi - a.Nu
+}
+
+func nodalMm(a AstronomicalArguments) (float64, float64) {
+	i := radians(a.I)
+	return (2.0/3.0 - math.Pow(math.Sin(i), 2)) / 0.5021, 0
+}
+
+func nodalMf(a AstronomicalArguments) (float64, float64) {
+	i := radians(a.I)
+	return math.Pow(math.Sin(i), 2) / 0.1578, -2 * a.Xi
+}
+
+func nodalL2(a AstronomicalArguments) (float64, float64) {
+	fM2, uM2 := nodalM2(a)
+	i := radians(a.I)
+	bigP := radians(a.P - a.Xi)
+	tan2 := math.Pow(math.Tan(i/2), 2)
+	invRa := math.Sqrt(1 - 12*tan2*math.Cos(2*bigP) + 36*tan2*tan2)
+	r := math.Atan2(math.Sin(2*bigP), 1/(6*tan2)-math.Cos(2*bigP))
+	return fM2 * invRa, uM2 - degrees(r)
+}
+
+func nodalM1(a AstronomicalArguments) (float64, float64) {
+	fO1, uO1 := nodalO1(a)
+	i := radians(a.I)
+	bigP := radians(a.P - a.Xi)
+	q := math.Atan2((5*math.Cos(i)-1)*math.Sin(bigP), (7*math.Cos(i)+1)*math.Cos(bigP))
+	invQa := math.Sqrt(2.310 + 1.435*math.Cos(2*bigP))
+	return fO1 * invQa, uO1 + degrees(q)
+}
+
+// nodalM3 scales the M2 correction by 1.5 for the lunar terdiurnal constituent
+func nodalM3(a AstronomicalArguments) (float64, float64) {
+	f, u := nodalM2(a)
+	return math.Pow(f, 1.5), 1.5 * u
+}
+
+// compound builds the nodal correction of a shallow-water or compound
+// constituent from the corrections of its parents raised to integer powers.
+func compound(parts map[string]int) func(a AstronomicalArguments) (float64, float64) {
+	return func(a AstronomicalArguments) (float64, float64) {
+		f, u := 1.0, 0.0
+		for name, n := range parts {
+			pf, pu := constituents[name].NodalCorrection(a)
+			f *= math.Pow(pf, math.Abs(float64(n)))
+			u += float64(n) * pu
+		}
+		return f, u
+	}
+}
+
+// constituents holds the 37 constituents NOAA publishes for its harmonic stations
+var constituents = map[string]Constituent{}
+
+func init() {
+	defs := []Constituent{
+		{Name: "M2", T: 2, S: -2, H: 2, nodal: nodalM2},
+		{Name: "S2", T: 2},
+		{Name: "N2", T: 2, S: -3, H: 2, P: 1, nodal: nodalM2},
+		{Name: "K1", T: 1, H: 1, Offset: -90, nodal: nodalK1},
+		{Name: "O1", T: 1, S: -2, H: 1, Offset: 90, nodal: nodalO1},
+		{Name: "S4", T: 4},
+		{Name: "NU2", T: 2, S: -3, H: 4, P: -1, nodal: nodalM2},
+		{Name: "S6", T: 6},
+		{Name: "MU2", T: 2, S: -4, H: 4, nodal: nodalM2},
+		{Name: "2N2", T: 2, S: -4, H: 2, P: 2, nodal: nodalM2},
+		{Name: "OO1", T: 1, S: 2, H: 1, Offset: -90, nodal: nodalOO1},
+		{Name: "LAM2", T: 2, S: -1, P: 1, Offset: 180, nodal: nodalM2},
+		{Name: "S1", T: 1},
+		{Name: "M1", T: 1, S: -1, H: 1, P: 1, Offset: -90, nodal: nodalM1},
+		{Name: "J1", T: 1, S: 1, H: 1, P: -1, Offset: -90, nodal: nodalJ1},
+		{Name: "MM", S: 1, P: -1, nodal: nodalMm},
+		{Name: "SSA", H: 2},
+		{Name: "SA", H: 1},
+		{Name: "MF", S: 2, nodal: nodalMf},
+		{Name: "RHO", T: 1, S: -3, H: 3, P: -1, Offset: 90, nodal: nodalO1},
+		{Name: "Q1", T: 1, S: -3, H: 1, P: 1, Offset: 90, nodal: nodalO1},
+		{Name: "T2", T: 2, H: -1, P1: 1},
+		{Name: "R2", T: 2, H: 1, P1: -1, Offset: 180},
+		{Name: "2Q1", T: 1, S: -4, H: 1, P: 2, Offset: 90, nodal: nodalO1},
+		{Name: "P1", T: 1, H: -1, Offset: 90},
+		{Name: "L2", T: 2, S: -1, H: 2, P: -1, Offset: 180, nodal: nodalL2},
+		{Name: "K2", T: 2, H: 2, nodal: nodalK2},
+
+		// Compound and overtide constituents
+		{Name: "M4", T: 4, S: -4, H: 4, nodal: compound(map[string]int{"M2": 2})},
+		{Name: "M6", T: 6, S: -6, H: 6, nodal: compound(map[string]int{"M2": 3})},
+		{Name: "M8", T: 8, S: -8, H: 8, nodal: compound(map[string]int{"M2": 4})},
+		{Name: "MK3", T: 3, S: -2, H: 3, Offset: -90, nodal: compound(map[string]int{"M2": 1, "K1": 1})},
+		{Name: "2MK3", T: 3, S: -4, H: 3, Offset: 90, nodal: compound(map[string]int{"M2": 2, "K1": -1})},
+		{Name: "MN4", T: 4, S: -5, H: 4, P: 1, nodal: compound(map[string]int{"M2": 1, "N2": 1})},
+		{Name: "MS4", T: 4, S: -2, H: 2, nodal: compound(map[string]int{"M2": 1})},
+		{Name: "MSF", S: 2, H: -2, nodal: compound(map[string]int{"M2": -1})},
+		{Name: "2SM2", T: 2, S: 2, H: -2, nodal: compound(map[string]int{"M2": -1})},
+		{Name: "M3", T: 3, S: -3, H: 3, nodal: nodalM3},
+	}
+
+	for _, c := range defs {
+		constituents[c.Name] = c
+	}
+}
+
+// LookupConstituent returns the definition of a named constituent
+func LookupConstituent(name string) (Constituent, bool) {
+	c, ok := constituents[name]
+	return c, ok
+}
+
+// ConstituentNames returns the names of all supported constituents, sorted
+func ConstituentNames() []string {
+	names := make([]string, 0, len(constituents))
+	for name := range constituents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
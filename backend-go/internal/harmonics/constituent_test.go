@@ -0,0 +1,117 @@
+package harmonics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstituentSpeeds(t *testing.T) {
+	// Speeds (degrees per hour) as published in NOAA's harmonic constituent tables
+	noaaSpeeds := map[string]float64{
+		"M2":   28.9841042,
+		"S2":   30.0,
+		"N2":   28.4397295,
+		"K1":   15.0410686,
+		"M4":   57.9682084,
+		"O1":   13.9430356,
+		"M6":   86.9523127,
+		"MK3":  44.0251729,
+		"S4":   60.0,
+		"MN4":  57.4238337,
+		"NU2":  28.5125831,
+		"S6":   90.0,
+		"MU2":  27.9682084,
+		"2N2":  27.8953548,
+		"OO1":  16.1391017,
+		"LAM2": 29.4556253,
+		"S1":   15.0,
+		"M1":   14.4966939,
+		"J1":   15.5854433,
+		"MM":   0.5443747,
+		"SSA":  0.0821373,
+		"SA":   0.0410686,
+		"MSF":  1.0158958,
+		"2SM2": 31.0158958,
+		"MF":   1.0980331,
+		"RHO":  13.4715145,
+		"Q1":   13.3986609,
+		"T2":   29.9589333,
+		"R2":   30.0410667,
+		"2Q1":  12.8542862,
+		"P1":   14.9589314,
+		"2MK3": 42.9271398,
+		"M8":   115.9364166,
+		"MS4":  58.9841042,
+		"L2":   29.5284789,
+		"M3":   43.4761563,
+		"K2":   30.0821373,
+	}
+
+	assert.Len(t, ConstituentNames(), len(noaaSpeeds))
+
+	for name, want := range noaaSpeeds {
+		t.Run(name, func(t *testing.T) {
+			c, ok := LookupConstituent(name)
+			require.True(t, ok)
+			assert.InDelta(t, want, c.Speed(), 5e-7)
+		})
+	}
+}
+
+func TestNodalCorrection_Limits(t *testing.T) {
+	// Extremes of the node factors over the 18.6 year nodal cycle (Schureman, Table 14)
+	tests := []struct {
+		name      string
+		atNode0   float64
+		atNode180 float64
+	}{
+		{name: "M2", atNode0: 0.963, atNode180: 1.038},
+		{name: "K1", atNode0: 1.113, atNode180: 0.882},
+		{name: "O1", atNode0: 1.183, atNode180: 0.806},
+		{name: "K2", atNode0: 1.317, atNode180: 0.748},
+		{name: "S2", atNode0: 1.0, atNode180: 1.0},
+	}
+
+	argsForNode := func(n float64) AstronomicalArguments {
+		a := AstronomicalArguments{N: n}
+		a.I, a.Nu, a.Xi, a.NuP, a.TwoNuPP = nodeArguments(n)
+		return a
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, ok := LookupConstituent(tt.name)
+			require.True(t, ok)
+
+			f0, u0 := c.NodalCorrection(argsForNode(0))
+			f180, u180 := c.NodalCorrection(argsForNode(180))
+			assert.InDelta(t, tt.atNode0, f0, 0.002)
+			assert.InDelta(t, tt.atNode180, f180, 0.002)
+			assert.InDelta(t, 0.0, u0, 1e-6)
+			assert.InDelta(t, 0.0, u180, 1e-6)
+		})
+	}
+}
+
+func TestCompoundNodalCorrection(t *testing.T) {
+	a := ArgumentsAt(j2000)
+	m2, _ := LookupConstituent("M2")
+	m4, _ := LookupConstituent("M4")
+	msf, _ := LookupConstituent("MSF")
+
+	fM2, uM2 := m2.NodalCorrection(a)
+	fM4, uM4 := m4.NodalCorrection(a)
+	fMSF, uMSF := msf.NodalCorrection(a)
+
+	assert.InDelta(t, fM2*fM2, fM4, 1e-12)
+	assert.InDelta(t, 2*uM2, uM4, 1e-12)
+	assert.InDelta(t, fM2, fMSF, 1e-12)
+	assert.InDelta(t, -uM2, uMSF, 1e-12)
+}
+
+func TestLookupConstituent_Unknown(t *testing.T) {
+	_, ok := LookupConstituent("X9")
+	assert.False(t, ok)
+}
@@ -0,0 +1,126 @@
+package harmonics
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"math"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// NOAA's published highs and lows are predicted from the same constituents,
+// so ours should agree with them to within rounding and the nodal
+// corrections' approximations
+const (
+	hiloTimeTolerance        = 10 * time.Minute
+	hiloHeightToleranceFeet  = 0.1
+	hiloHeightToleranceMeter = 0.03
+)
+
+// noaaHiLo is a datagetter response for predictions at interval=hilo in GMT,
+// as written by scripts/fetch-harmonics.sh
+type noaaHiLo struct {
+	Predictions []struct {
+		Time  string `json:"t"`
+		Value string `json:"v"`
+		Type  string `json:"type"`
+	} `json:"predictions"`
+}
+
+func loadNOAAHiLo(t *testing.T, stationID string) ([]Extreme, bool) {
+	t.Helper()
+	data, err := os.ReadFile(path.Join("testdata", "noaa", stationID+"_hilo.json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false
+	}
+	require.NoError(t, err)
+
+	var table noaaHiLo
+	require.NoError(t, json.Unmarshal(data, &table))
+	require.NotEmpty(t, table.Predictions, "hilo table for %s", stationID)
+
+	extremes := make([]Extreme, len(table.Predictions))
+	for i, p := range table.Predictions {
+		at, err := time.Parse("2006-01-02 15:04", p.Time)
+		require.NoError(t, err)
+		height, err := strconv.ParseFloat(p.Value, 64)
+		require.NoError(t, err)
+		extremes[i] = Extreme{Time: at, Height: height, High: strings.HasPrefix(p.Type, "H")}
+	}
+	return extremes, true
+}
+
+// TestEmbeddedStations_MatchNOAAHiLo checks every embedded station against
+// NOAA's published highs and lows for it, checked in under testdata/noaa
+func TestEmbeddedStations_MatchNOAAHiLo(t *testing.T) {
+	files, err := fs.Glob(embeddedData, "data/*.json")
+	require.NoError(t, err)
+	require.NotEmpty(t, files, "no embedded constituent sets; add them with scripts/fetch-harmonics.sh")
+
+	store := NewEmbeddedStore()
+	for _, file := range files {
+		stationID := strings.TrimSuffix(path.Base(file), ".json")
+		t.Run(stationID, func(t *testing.T) {
+			set, err := store.GetConstituents(stationID)
+			require.NoError(t, err)
+			predictor, err := NewPredictor(set)
+			require.NoError(t, err)
+
+			want, ok := loadNOAAHiLo(t, stationID)
+			require.True(t, ok, "no NOAA hilo table for %s in testdata/noaa; fetch it with scripts/fetch-harmonics.sh", stationID)
+			heightTolerance := hiloHeightToleranceFeet
+			if set.Units == "meters" {
+				heightTolerance = hiloHeightToleranceMeter
+			}
+
+			got := predictor.Extremes(want[0].Time.Add(-time.Hour), want[len(want)-1].Time.Add(time.Hour))
+			for _, w := range want {
+				match, found := nearestExtreme(got, w)
+				if !assert.True(t, found, "no predicted %s near %s", extremeKind(w), w.Time) {
+					continue
+				}
+				assert.LessOrEqual(t, absDuration(match.Time.Sub(w.Time)), hiloTimeTolerance,
+					"%s at %s predicted at %s", extremeKind(w), w.Time, match.Time)
+				assert.InDelta(t, w.Height, match.Height, heightTolerance,
+					"%s at %s", extremeKind(w), w.Time)
+			}
+		})
+	}
+}
+
+// nearestExtreme returns the extreme of the same kind closest in time to want
+func nearestExtreme(extremes []Extreme, want Extreme) (Extreme, bool) {
+	var nearest Extreme
+	best := time.Duration(math.MaxInt64)
+	for _, e := range extremes {
+		if e.High != want.High {
+			continue
+		}
+		if d := absDuration(e.Time.Sub(want.Time)); d < best {
+			nearest, best = e, d
+		}
+	}
+	return nearest, best != time.Duration(math.MaxInt64)
+}
+
+func extremeKind(e Extreme) string {
+	if e.High {
+		return "high"
+	}
+	return "low"
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,134 @@
+package harmonics
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Point is a predicted water level at an instant
+type Point struct {
+	Time   time.Time
+	Height float64
+}
+
+// Extreme is a predicted high or low water
+type Extreme struct {
+	Time   time.Time
+	Height float64
+	High   bool
+}
+
+type term struct {
+	constituent Constituent
+	amplitude   float64
+	phase       float64 // Greenwich phase lag (kappa), degrees
+}
+
+// Predictor computes water levels from a station's harmonic constituents
+type Predictor struct {
+	set   *ConstituentSet
+	terms []term
+}
+
+// NewPredictor creates a predictor for the given constituent set
+func NewPredictor(set *ConstituentSet) (*Predictor, error) {
+	if set == nil {
+		return nil, fmt.Errorf("constituent set is required")
+	}
+	if err := set.Validate(); err != nil {
+		return nil, err
+	}
+
+	terms := make([]term, 0, len(set.Constituents))
+	for _, hc := range set.Constituents {
+		if hc.Amplitude == 0 {
+			continue
+		}
+		terms = append(terms, term{
+			constituent: constituents[hc.Name],
+			amplitude:   hc.Amplitude,
+			phase:       hc.PhaseGMT,
+		})
+	}
+
+	return &Predictor{set: set, terms: terms}, nil
+}
+
+// HeightAt returns the predicted height above the set's datum at t
+func (p *Predictor) HeightAt(t time.Time) float64 {
+	args := ArgumentsAt(t)
+	height := p.set.MeanSeaLevel
+	for _, tm := range p.terms {
+		f, u := tm.constituent.NodalCorrection(args)
+		v := tm.constituent.EquilibriumArgument(args)
+		height += f * tm.amplitude * math.Cos(radians(v+u-tm.phase))
+	}
+	return height
+}
+
+// rateAt returns the rate of change of the height at t in units per hour
+func (p *Predictor) rateAt(t time.Time) float64 {
+	args := ArgumentsAt(t)
+	rate := 0.0
+	for _, tm := range p.terms {
+		f, u := tm.constituent.NodalCorrection(args)
+		v := tm.constituent.EquilibriumArgument(args)
+		rate -= f * tm.amplitude * radians(tm.constituent.Speed()) * math.Sin(radians(v+u-tm.phase))
+	}
+	return rate
+}
+
+// Predict returns heights from start to end (inclusive) at the given interval
+func (p *Predictor) Predict(start, end time.Time, interval time.Duration) []Point {
+	if interval <= 0 || end.Before(start) {
+		return nil
+	}
+
+	points := make([]Point, 0, int(end.Sub(start)/interval)+1)
+	for t := start; !t.After(end); t = t.Add(interval) {
+		points = append(points, Point{Time: t, Height: p.HeightAt(t)})
+	}
+	return points
+}
+
+// Extremes returns the highs and lows between start and end. Candidate turning
+// points are bracketed on a 6-minute grid and refined by bisection on the rate.
+func (p *Predictor) Extremes(start, end time.Time) []Extreme {
+	const step = 6 * time.Minute
+
+	var extremes []Extreme
+	prevTime := start
+	prevRate := p.rateAt(start)
+	for t := start.Add(step); !t.After(end); t = t.Add(step) {
+		rate := p.rateAt(t)
+		if prevRate > 0 && rate <= 0 || prevRate < 0 && rate >= 0 {
+			at := p.refineExtreme(prevTime, t, prevRate > 0)
+			extremes = append(extremes, Extreme{
+				Time:   at,
+				Height: p.HeightAt(at),
+				High:   prevRate > 0,
+			})
+		}
+		prevTime, prevRate = t, rate
+	}
+	return extremes
+}
+
+func (p *Predictor) refineExtreme(lo, hi time.Time, rising bool) time.Time {
+	for hi.Sub(lo) > time.Second {
+		mid := lo.Add(hi.Sub(lo) / 2)
+		rate := p.rateAt(mid)
+		if (rate > 0) == rising {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo.Add(hi.Sub(lo) / 2).Round(time.Minute)
+}
+
+// Units returns the units the predicted heights are expressed in
+func (p *Predictor) Units() string {
+	return p.set.Units
+}
@@ -0,0 +1,130 @@
+package harmonics
+
+import (
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadTestPredictor(t *testing.T, stationID string) *Predictor {
+	t.Helper()
+	set, err := NewFileStore(os.DirFS("testdata")).GetConstituents(stationID)
+	require.NoError(t, err)
+	predictor, err := NewPredictor(set)
+	require.NoError(t, err)
+	return predictor
+}
+
+func TestNewPredictor_Invalid(t *testing.T) {
+	_, err := NewPredictor(nil)
+	assert.Error(t, err)
+
+	_, err = NewPredictor(&ConstituentSet{StationID: "X", Units: "feet"})
+	assert.ErrorContains(t, err, "no constituents")
+}
+
+func TestPredictor_HeightAt_SingleConstituent(t *testing.T) {
+	p := loadTestPredictor(t, "M2ONLY")
+	m2, _ := LookupConstituent("M2")
+
+	at := time.Date(2024, time.July, 4, 15, 30, 0, 0, time.UTC)
+	args := ArgumentsAt(at)
+	f, u := m2.NodalCorrection(args)
+	want := 2.0 + f*math.Cos(radians(m2.EquilibriumArgument(args)+u-45.0))
+
+	assert.InDelta(t, want, p.HeightAt(at), 1e-9)
+	assert.Equal(t, "meters", p.Units())
+}
+
+func TestPredictor_Predict(t *testing.T) {
+	p := loadTestPredictor(t, "TEST001")
+	start := time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC)
+
+	points := p.Predict(start, start.Add(24*time.Hour), 6*time.Minute)
+	require.Len(t, points, 241)
+	assert.Equal(t, start, points[0].Time)
+	assert.Equal(t, start.Add(24*time.Hour), points[240].Time)
+
+	// Heights stay within MSL +/- the sum of the (nodally corrected) amplitudes
+	for _, pt := range points {
+		assert.InDelta(t, 5.0, pt.Height, 3.0*1.04+0.8+0.6*1.04+1.5*1.12+0.9*1.19)
+	}
+
+	assert.Nil(t, p.Predict(start, start.Add(-time.Hour), 6*time.Minute))
+	assert.Nil(t, p.Predict(start, start.Add(time.Hour), 0))
+}
+
+func TestPredictor_RateMatchesFiniteDifference(t *testing.T) {
+	p := loadTestPredictor(t, "TEST001")
+	at := time.Date(2024, time.May, 2, 7, 12, 0, 0, time.UTC)
+
+	dt := time.Second
+	numeric := (p.HeightAt(at.Add(dt)) - p.HeightAt(at.Add(-dt))) / (2 * dt.Hours())
+	assert.InDelta(t, numeric, p.rateAt(at), 1e-3)
+}
+
+func TestPredictor_Extremes_SingleConstituent(t *testing.T) {
+	p := loadTestPredictor(t, "M2ONLY")
+	start := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	extremes := p.Extremes(start, start.Add(3*24*time.Hour))
+
+	// A pure M2 tide turns every half period (about 6h 12.6m)
+	require.GreaterOrEqual(t, len(extremes), 11)
+	halfPeriod := 180 / 28.9841042 * float64(time.Hour)
+	for i := 1; i < len(extremes); i++ {
+		assert.NotEqual(t, extremes[i-1].High, extremes[i].High)
+		gap := extremes[i].Time.Sub(extremes[i-1].Time)
+		assert.InDelta(t, halfPeriod, float64(gap), float64(2*time.Minute))
+	}
+
+	for _, e := range extremes {
+		if e.High {
+			assert.InDelta(t, 3.0, e.Height, 0.04)
+		} else {
+			assert.InDelta(t, 1.0, e.Height, 0.04)
+		}
+	}
+}
+
+func TestPredictor_Extremes_MixedTide(t *testing.T) {
+	p := loadTestPredictor(t, "TEST001")
+	start := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(7 * 24 * time.Hour)
+	extremes := p.Extremes(start, end)
+	require.NotEmpty(t, extremes)
+
+	for i, e := range extremes {
+		// Each extreme is a turning point of the curve
+		before := p.HeightAt(e.Time.Add(-10 * time.Minute))
+		after := p.HeightAt(e.Time.Add(10 * time.Minute))
+		if e.High {
+			assert.GreaterOrEqual(t, e.Height, before, "high at %s", e.Time)
+			assert.GreaterOrEqual(t, e.Height, after, "high at %s", e.Time)
+		} else {
+			assert.LessOrEqual(t, e.Height, before, "low at %s", e.Time)
+			assert.LessOrEqual(t, e.Height, after, "low at %s", e.Time)
+		}
+		assert.Equal(t, 0, e.Time.Second())
+		if i > 0 {
+			assert.NotEqual(t, extremes[i-1].High, e.High)
+			assert.True(t, e.Time.After(extremes[i-1].Time))
+		}
+	}
+}
+
+func BenchmarkPredictor_Predict30Days(b *testing.B) {
+	set, err := NewFileStore(os.DirFS("testdata")).GetConstituents("TEST001")
+	require.NoError(b, err)
+	p, err := NewPredictor(set)
+	require.NoError(b, err)
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.Predict(start, start.AddDate(0, 0, 30), 6*time.Minute)
+	}
+}
@@ -0,0 +1,129 @@
+package harmonics
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sync"
+)
+
+// ErrConstituentsNotFound is returned when no constituent file exists for a station
+var ErrConstituentsNotFound = errors.New("harmonic constituents not found")
+
+// HarmonicConstant is the amplitude and Greenwich phase of one constituent at a station.
+// Field names follow NOAA's harcon.json so its entries can be copied verbatim.
+type HarmonicConstant struct {
+	Name      string  `json:"name"`
+	Amplitude float64 `json:"amplitude"`
+	PhaseGMT  float64 `json:"phase_GMT"`
+	Speed     float64 `json:"speed,omitempty"`
+}
+
+// ConstituentSet is the full set of harmonic constants for a station
+type ConstituentSet struct {
+	StationID    string             `json:"stationId"`
+	Name         string             `json:"name,omitempty"`
+	Units        string             `json:"units"`
+	Datum        string             `json:"datum"`
+	MeanSeaLevel float64            `json:"msl"` // Height of MSL above Datum (Z0)
+	Constituents []HarmonicConstant `json:"HarmonicConstituents"`
+}
+
+// Validate checks that the set can be used for prediction
+func (s *ConstituentSet) Validate() error {
+	if s.StationID == "" {
+		return fmt.Errorf("station ID is required")
+	}
+
+	switch s.Units {
+	case "feet", "meters":
+		// Valid units
+	default:
+		return fmt.Errorf("invalid units: %s", s.Units)
+	}
+
+	if len(s.Constituents) == 0 {
+		return fmt.Errorf("no constituents for station %s", s.StationID)
+	}
+
+	for _, hc := range s.Constituents {
+		if _, ok := constituents[hc.Name]; !ok {
+			return fmt.Errorf("unknown constituent: %s", hc.Name)
+		}
+		if hc.Amplitude < 0 {
+			return fmt.Errorf("invalid amplitude for %s: %f", hc.Name, hc.Amplitude)
+		}
+	}
+
+	return nil
+}
+
+// ConstituentStore provides harmonic constituent sets by station ID
+type ConstituentStore interface {
+	GetConstituents(stationID string) (*ConstituentSet, error)
+}
+
+//go:embed data
+var embeddedData embed.FS
+
+// FileStore loads constituent sets from <stationId>.json files in a file system
+type FileStore struct {
+	fsys  fs.FS
+	mu    sync.RWMutex
+	cache map[string]*ConstituentSet
+}
+
+var _ ConstituentStore = (*FileStore)(nil)
+
+// NewFileStore creates a store reading from the root of fsys
+func NewFileStore(fsys fs.FS) *FileStore {
+	return &FileStore{
+		fsys:  fsys,
+		cache: make(map[string]*ConstituentSet),
+	}
+}
+
+// NewEmbeddedStore creates a store over the constituent files checked into this package
+func NewEmbeddedStore() *FileStore {
+	sub, err := fs.Sub(embeddedData, "data")
+	if err != nil {
+		// The embedded directory is fixed at compile time
+		panic(err)
+	}
+	return NewFileStore(sub)
+}
+
+// GetConstituents returns the constituent set for a station
+func (s *FileStore) GetConstituents(stationID string) (*ConstituentSet, error) {
+	s.mu.RLock()
+	set, ok := s.cache[stationID]
+	s.mu.RUnlock()
+	if ok {
+		return set, nil
+	}
+
+	data, err := fs.ReadFile(s.fsys, stationID+".json")
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrConstituentsNotFound, stationID)
+		}
+		return nil, fmt.Errorf("reading constituents for %s: %w", stationID, err)
+	}
+
+	set = &ConstituentSet{}
+	if err := json.Unmarshal(data, set); err != nil {
+		return nil, fmt.Errorf("decoding constituents for %s: %w", stationID, err)
+	}
+
+	if err := set.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid constituents for %s: %w", stationID, err)
+	}
+
+	s.mu.Lock()
+	s.cache[stationID] = set
+	s.mu.Unlock()
+
+	return set, nil
+}
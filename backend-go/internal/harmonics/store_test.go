@@ -0,0 +1,111 @@
+package harmonics
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore_GetConstituents(t *testing.T) {
+	store := NewFileStore(os.DirFS("testdata"))
+
+	tests := []struct {
+		name        string
+		stationID   string
+		wantErr     error
+		errContains string
+		wantCount   int
+	}{
+		{
+			name:      "valid station",
+			stationID: "TEST001",
+			wantCount: 6,
+		},
+		{
+			name:      "missing station",
+			stationID: "9999999",
+			wantErr:   ErrConstituentsNotFound,
+		},
+		{
+			name:        "unknown constituent",
+			stationID:   "BADNAME",
+			errContains: "unknown constituent: ZZ9",
+		},
+		{
+			name:        "path traversal is rejected",
+			stationID:   "../store",
+			errContains: "reading constituents",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := store.GetConstituents(tt.stationID)
+			if tt.wantErr != nil || tt.errContains != "" {
+				require.Error(t, err)
+				if tt.wantErr != nil {
+					assert.ErrorIs(t, err, tt.wantErr)
+				}
+				if tt.errContains != "" {
+					assert.Contains(t, err.Error(), tt.errContains)
+				}
+				assert.Nil(t, set)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.stationID, set.StationID)
+			assert.Equal(t, "feet", set.Units)
+			assert.Len(t, set.Constituents, tt.wantCount)
+		})
+	}
+}
+
+func TestFileStore_CachesSets(t *testing.T) {
+	store := NewFileStore(os.DirFS("testdata"))
+
+	first, err := store.GetConstituents("TEST001")
+	require.NoError(t, err)
+	second, err := store.GetConstituents("TEST001")
+	require.NoError(t, err)
+
+	assert.Same(t, first, second)
+}
+
+func TestNewEmbeddedStore(t *testing.T) {
+	store := NewEmbeddedStore()
+
+	_, err := store.GetConstituents("not-a-station")
+	assert.ErrorIs(t, err, ErrConstituentsNotFound)
+}
+
+func TestConstituentSet_Validate(t *testing.T) {
+	valid := func() ConstituentSet {
+		return ConstituentSet{
+			StationID:    "TEST",
+			Units:        "meters",
+			Constituents: []HarmonicConstant{{Name: "M2", Amplitude: 1}},
+		}
+	}
+
+	set := valid()
+	assert.NoError(t, set.Validate())
+
+	set = valid()
+	set.StationID = ""
+	assert.ErrorContains(t, set.Validate(), "station ID is required")
+
+	set = valid()
+	set.Units = "fathoms"
+	assert.ErrorContains(t, set.Validate(), "invalid units")
+
+	set = valid()
+	set.Constituents = nil
+	assert.ErrorContains(t, set.Validate(), "no constituents")
+
+	set = valid()
+	set.Constituents[0].Amplitude = -1
+	assert.ErrorContains(t, set.Validate(), "invalid amplitude")
+}
@@ -0,0 +1,221 @@
+package met
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/bbernstein/flowebb/backend-go/internal/cache"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/pkg/http/client"
+	"github.com/rs/zerolog/log"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Service fetches the latest meteorological and water temperature readings
+// from NOAA for stations that have the sensors.
+type Service struct {
+	HttpClient *client.Client
+	Cache      *cache.ConditionsCache // Readings are not cached when nil
+}
+
+func NewService(httpClient *client.Client, conditionsCache *cache.ConditionsCache) (*Service, error) {
+	if httpClient == nil {
+		return nil, fmt.Errorf("http client is required")
+	}
+
+	return &Service{
+		HttpClient: httpClient,
+		Cache:      conditionsCache,
+	}, nil
+}
+
+// HasMetSensors reports whether the station has any sensor GetConditions reads
+func HasMetSensors(station *models.Station) bool {
+	return station.HasCapability(models.CapabilityWind) ||
+		station.HasCapability(models.CapabilityAirPressure) ||
+		station.HasCapability(models.CapabilityWaterTemperature) ||
+		station.HasCapability(models.CapabilityAirTemperature)
+}
+
+// GetConditions returns the latest readings for the station's sensors in the
+// unit system matching units: knots and Fahrenheit for feet, meters per second
+// and Celsius for meters. Pressure is always in millibars. It returns nil
+// when the station has no met sensors or none of them have recent data.
+func (s *Service) GetConditions(ctx context.Context, station *models.Station, units models.Units) (*models.MarineConditions, error) {
+	if !HasMetSensors(station) {
+		return nil, nil
+	}
+
+	units = units.OrDefault()
+	if s.Cache != nil {
+		if conditions, ok := s.Cache.GetConditions(station.ID, units); ok {
+			return conditions, nil
+		}
+	}
+
+	metric := units == models.UnitsMeters
+	conditions := &models.MarineConditions{}
+
+	var wg sync.WaitGroup
+	var errMu sync.Mutex
+	var fetchErr error
+	fetch := func(capability string, read func() error) {
+		if !station.HasCapability(capability) {
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := read(); err != nil {
+				errMu.Lock()
+				fetchErr = err
+				errMu.Unlock()
+			}
+		}()
+	}
+
+	// Each reading is written by its own goroutine
+	fetch(models.CapabilityWind, func() (err error) {
+		conditions.Wind, err = s.fetchWind(ctx, station.ID, metric)
+		return err
+	})
+	fetch(models.CapabilityAirPressure, func() (err error) {
+		conditions.AirPressure, err = s.fetchReading(ctx, station.ID, "air_pressure", metric, "mb", "mb")
+		return err
+	})
+	fetch(models.CapabilityWaterTemperature, func() (err error) {
+		conditions.WaterTemperature, err = s.fetchReading(ctx, station.ID, "water_temperature", metric, "F", "C")
+		return err
+	})
+	fetch(models.CapabilityAirTemperature, func() (err error) {
+		conditions.AirTemperature, err = s.fetchReading(ctx, station.ID, "air_temperature", metric, "F", "C")
+		return err
+	})
+	wg.Wait()
+
+	if conditions.IsEmpty() {
+		if fetchErr != nil {
+			return nil, fetchErr
+		}
+		conditions = nil
+	} else if fetchErr != nil {
+		// Report what was read; the failed readings stay nil
+		log.Warn().Err(fetchErr).
+			Str("station_id", station.ID).
+			Msg("Some met readings could not be fetched")
+	}
+
+	// Partial results are cached too so a flaky sensor isn't retried on every request
+	if s.Cache != nil {
+		s.Cache.SetConditions(station.ID, units, conditions)
+	}
+
+	return conditions, nil
+}
+
+// fetchLatest returns the latest reading of a product, or nil if NOAA has no
+// recent data for it.
+func (s *Service) fetchLatest(ctx context.Context, stationID, product string, metric bool) (*models.NoaaMetReading, int64, error) {
+	unitSystem := "english"
+	if metric {
+		unitSystem = "metric"
+	}
+
+	resp, err := s.HttpClient.Get(ctx, fmt.Sprintf("/api/prod/datagetter"+
+		"?station=%s&date=latest&product=%s&units=%s&time_zone=gmt&format=json",
+		stationID, product, unitSystem))
+	if err != nil {
+		return nil, 0, fmt.Errorf("fetching %s: %w", product, err)
+	}
+
+	var noaaResp models.NoaaMetResponse
+	if err := json.Unmarshal(resp.Body, &noaaResp); err != nil {
+		return nil, 0, fmt.Errorf("decoding %s response: %w", product, err)
+	}
+
+	if noaaResp.Error != nil {
+		log.Debug().
+			Str("station_id", stationID).
+			Str("product", product).
+			Str("message", noaaResp.Error.Message).
+			Msg("No met data from NOAA")
+		return nil, 0, nil
+	}
+
+	for i := len(noaaResp.Data) - 1; i >= 0; i-- {
+		reading := noaaResp.Data[i]
+		if reading.Value == "" && reading.Speed == "" {
+			continue
+		}
+
+		t, err := time.ParseInLocation("2006-01-02 15:04", reading.Time, time.UTC)
+		if err != nil {
+			return nil, 0, fmt.Errorf("parsing time %s: %w", reading.Time, err)
+		}
+		return &reading, t.UnixMilli(), nil
+	}
+
+	return nil, 0, nil
+}
+
+func (s *Service) fetchReading(ctx context.Context, stationID, product string, metric bool, englishUnits, metricUnits string) (*models.MetReading, error) {
+	reading, timestamp, err := s.fetchLatest(ctx, stationID, product, metric)
+	if err != nil || reading == nil {
+		return nil, err
+	}
+
+	value, err := strconv.ParseFloat(reading.Value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s value %s: %w", product, reading.Value, err)
+	}
+
+	units := englishUnits
+	if metric {
+		units = metricUnits
+	}
+
+	return &models.MetReading{
+		Timestamp: timestamp,
+		Value:     value,
+		Units:     units,
+	}, nil
+}
+
+func (s *Service) fetchWind(ctx context.Context, stationID string, metric bool) (*models.WindObservation, error) {
+	reading, timestamp, err := s.fetchLatest(ctx, stationID, "wind", metric)
+	if err != nil || reading == nil {
+		return nil, err
+	}
+
+	speed, err := strconv.ParseFloat(reading.Speed, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parsing wind speed %s: %w", reading.Speed, err)
+	}
+	direction, err := strconv.ParseFloat(reading.Direction, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parsing wind direction %s: %w", reading.Direction, err)
+	}
+
+	var gust *float64
+	if reading.Gust != "" {
+		if g, err := strconv.ParseFloat(reading.Gust, 64); err == nil {
+			gust = &g
+		}
+	}
+
+	units := "knots"
+	if metric {
+		units = "m/s"
+	}
+
+	return &models.WindObservation{
+		Timestamp:         timestamp,
+		Speed:             speed,
+		Gust:              gust,
+		Direction:         direction,
+		CardinalDirection: reading.CardinalDirection,
+		Units:             units,
+	}, nil
+}
@@ -0,0 +1,186 @@
+package met
+
+import (
+	"context"
+	"fmt"
+	"github.com/bbernstein/flowebb/backend-go/internal/cache"
+	"github.com/bbernstein/flowebb/backend-go/internal/config"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/pkg/http/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+var metResponses = map[string]string{
+	"wind":              `{"metadata":{"id":"9447130"},"data":[{"t":"2024-06-01 18:00","s":"8.94","d":"212.00","dr":"SSW","g":"12.05","f":"0,0"}]}`,
+	"air_pressure":      `{"data":[{"t":"2024-06-01 17:54","v":"1016.2","f":"0,0,0"},{"t":"2024-06-01 18:00","v":"1016.4","f":"0,0,0"}]}`,
+	"water_temperature": `{"data":[{"t":"2024-06-01 18:00","v":"","f":"0,0,0"}]}`,
+	"air_temperature":   `{"error":{"message":"No data was found. This product may not be offered at this station at the requested time."}}`,
+}
+
+func newMetServer(t *testing.T, responses map[string]string) (*httptest.Server, *sync.Map) {
+	var requests sync.Map
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		assert.Equal(t, "latest", query.Get("date"))
+		product := query.Get("product")
+		count, _ := requests.LoadOrStore(product+":"+query.Get("units"), new(int))
+		*count.(*int)++
+
+		body, ok := responses[product]
+		if !ok {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = fmt.Fprint(w, `<html>error</html>`)
+			return
+		}
+		_, _ = fmt.Fprint(w, body)
+	}))
+	return server, &requests
+}
+
+func metStation(capabilities ...string) *models.Station {
+	return &models.Station{
+		ID:           "9447130",
+		Capabilities: append([]string{models.CapabilityWaterLevel}, capabilities...),
+	}
+}
+
+func TestGetConditions(t *testing.T) {
+	server, requests := newMetServer(t, metResponses)
+	defer server.Close()
+
+	service, err := NewService(client.New(client.Options{BaseURL: server.URL, Timeout: 5 * time.Second}), nil)
+	require.NoError(t, err)
+
+	station := metStation(models.CapabilityWind, models.CapabilityAirPressure,
+		models.CapabilityWaterTemperature, models.CapabilityAirTemperature)
+
+	conditions, err := service.GetConditions(context.Background(), station, models.UnitsFeet)
+	require.NoError(t, err)
+	require.NotNil(t, conditions)
+
+	gust := 12.05
+	assert.Equal(t, &models.WindObservation{
+		Timestamp:         time.Date(2024, 6, 1, 18, 0, 0, 0, time.UTC).UnixMilli(),
+		Speed:             8.94,
+		Gust:              &gust,
+		Direction:         212,
+		CardinalDirection: "SSW",
+		Units:             "knots",
+	}, conditions.Wind)
+	assert.Equal(t, &models.MetReading{
+		Timestamp: time.Date(2024, 6, 1, 18, 0, 0, 0, time.UTC).UnixMilli(),
+		Value:     1016.4,
+		Units:     "mb",
+	}, conditions.AirPressure)
+
+	// Missing values and stations without recent data are left out
+	assert.Nil(t, conditions.WaterTemperature)
+	assert.Nil(t, conditions.AirTemperature)
+
+	_, ok := requests.Load("wind:english")
+	assert.True(t, ok)
+}
+
+func TestGetConditions_Units(t *testing.T) {
+	server, requests := newMetServer(t, map[string]string{
+		"wind":            `{"data":[{"t":"2024-06-01 18:00","s":"4.6","d":"212.00","dr":"SSW","g":""}]}`,
+		"air_temperature": `{"data":[{"t":"2024-06-01 18:00","v":"16.2"}]}`,
+	})
+	defer server.Close()
+
+	service, err := NewService(client.New(client.Options{BaseURL: server.URL, Timeout: 5 * time.Second}), nil)
+	require.NoError(t, err)
+
+	conditions, err := service.GetConditions(context.Background(),
+		metStation(models.CapabilityWind, models.CapabilityAirTemperature), models.UnitsMeters)
+	require.NoError(t, err)
+	require.NotNil(t, conditions)
+
+	assert.Equal(t, "m/s", conditions.Wind.Units)
+	assert.Nil(t, conditions.Wind.Gust)
+	assert.Equal(t, "C", conditions.AirTemperature.Units)
+	assert.Equal(t, 16.2, conditions.AirTemperature.Value)
+
+	_, ok := requests.Load("wind:metric")
+	assert.True(t, ok)
+}
+
+func TestGetConditions_OnlyFetchesStationSensors(t *testing.T) {
+	server, requests := newMetServer(t, metResponses)
+	defer server.Close()
+
+	service, err := NewService(client.New(client.Options{BaseURL: server.URL, Timeout: 5 * time.Second}), nil)
+	require.NoError(t, err)
+
+	conditions, err := service.GetConditions(context.Background(), metStation(), models.UnitsFeet)
+	require.NoError(t, err)
+	assert.Nil(t, conditions)
+
+	conditions, err = service.GetConditions(context.Background(), metStation(models.CapabilityAirPressure), models.UnitsFeet)
+	require.NoError(t, err)
+	require.NotNil(t, conditions)
+	assert.NotNil(t, conditions.AirPressure)
+	assert.Nil(t, conditions.Wind)
+
+	products := 0
+	requests.Range(func(_, _ any) bool {
+		products++
+		return true
+	})
+	assert.Equal(t, 1, products)
+}
+
+func TestGetConditions_Errors(t *testing.T) {
+	server, _ := newMetServer(t, map[string]string{
+		"air_pressure": metResponses["air_pressure"],
+	})
+	defer server.Close()
+
+	service, err := NewService(client.New(client.Options{BaseURL: server.URL, Timeout: 5 * time.Second}), nil)
+	require.NoError(t, err)
+
+	// A failed reading is left out when others succeed
+	conditions, err := service.GetConditions(context.Background(),
+		metStation(models.CapabilityWind, models.CapabilityAirPressure), models.UnitsFeet)
+	require.NoError(t, err)
+	require.NotNil(t, conditions)
+	assert.Nil(t, conditions.Wind)
+	assert.NotNil(t, conditions.AirPressure)
+
+	// And reported when nothing could be read
+	_, err = service.GetConditions(context.Background(), metStation(models.CapabilityWind), models.UnitsFeet)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "decoding wind response")
+}
+
+func TestGetConditions_Cache(t *testing.T) {
+	server, requests := newMetServer(t, metResponses)
+	defer server.Close()
+
+	service, err := NewService(client.New(client.Options{BaseURL: server.URL, Timeout: 5 * time.Second}),
+		cache.NewConditionsCache(&config.CacheConfig{ObservationTTLMinutes: 6}))
+	require.NoError(t, err)
+
+	station := metStation(models.CapabilityWind)
+	for i := 0; i < 3; i++ {
+		conditions, err := service.GetConditions(context.Background(), station, models.UnitsFeet)
+		require.NoError(t, err)
+		require.NotNil(t, conditions)
+		assert.NotNil(t, conditions.Wind)
+	}
+
+	count, ok := requests.Load("wind:english")
+	require.True(t, ok)
+	assert.Equal(t, 1, *count.(*int))
+}
+
+func TestNewService(t *testing.T) {
+	_, err := NewService(nil, nil)
+	require.Error(t, err)
+}
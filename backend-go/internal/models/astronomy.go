@@ -0,0 +1,37 @@
+package models
+
+// MoonPhase names the phase of the moon. The four principal phases are only
+// reported on the day the exact phase occurs.
+type MoonPhase string
+
+const (
+	MoonPhaseNew            MoonPhase = "NEW_MOON"
+	MoonPhaseWaxingCrescent MoonPhase = "WAXING_CRESCENT"
+	MoonPhaseFirstQuarter   MoonPhase = "FIRST_QUARTER"
+	MoonPhaseWaxingGibbous  MoonPhase = "WAXING_GIBBOUS"
+	MoonPhaseFull           MoonPhase = "FULL_MOON"
+	MoonPhaseWaningGibbous  MoonPhase = "WANING_GIBBOUS"
+	MoonPhaseLastQuarter    MoonPhase = "LAST_QUARTER"
+	MoonPhaseWaningCrescent MoonPhase = "WANING_CRESCENT"
+)
+
+// AstronomicalTime is the time of a sun or moon event
+type AstronomicalTime struct {
+	Timestamp int64  `json:"timestamp"`
+	LocalTime string `json:"localTime"`
+}
+
+// AstronomyDay holds the sun and moon events for one local calendar day at a
+// station. Events that don't happen that day, such as sunset during the
+// midnight sun or a day the moon never rises, are nil.
+type AstronomyDay struct {
+	Date             string            `json:"date"` // Local date, e.g. "2024-06-21"
+	CivilDawn        *AstronomicalTime `json:"civilDawn"`
+	Sunrise          *AstronomicalTime `json:"sunrise"`
+	Sunset           *AstronomicalTime `json:"sunset"`
+	CivilDusk        *AstronomicalTime `json:"civilDusk"`
+	Moonrise         *AstronomicalTime `json:"moonrise"`
+	Moonset          *AstronomicalTime `json:"moonset"`
+	MoonPhase        MoonPhase         `json:"moonPhase"`
+	MoonIllumination float64           `json:"moonIllumination"` // Fraction of the disk lit at local noon, 0 to 1
+}
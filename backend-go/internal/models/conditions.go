@@ -0,0 +1,50 @@
+package models
+
+// MetReading is a single meteorological or water temperature measurement
+type MetReading struct {
+	Timestamp int64   `json:"timestamp"` // Time of the measurement in milliseconds
+	Value     float64 `json:"value"`
+	Units     string  `json:"units"`
+}
+
+// WindObservation is the latest wind measured at a station
+type WindObservation struct {
+	Timestamp         int64    `json:"timestamp"` // Time of the measurement in milliseconds
+	Speed             float64  `json:"speed"`
+	Gust              *float64 `json:"gust"`
+	Direction         float64  `json:"direction"`         // Degrees true the wind is blowing from
+	CardinalDirection string   `json:"cardinalDirection"` // e.g. "SW"
+	Units             string   `json:"units"`
+}
+
+// MarineConditions are the latest readings from a station's met sensors.
+// Readings the station has no sensor for, or no recent data from, are nil.
+type MarineConditions struct {
+	Wind             *WindObservation `json:"wind"`
+	AirPressure      *MetReading      `json:"airPressure"`
+	WaterTemperature *MetReading      `json:"waterTemperature"`
+	AirTemperature   *MetReading      `json:"airTemperature"`
+}
+
+// IsEmpty reports whether none of the readings are available
+func (c *MarineConditions) IsEmpty() bool {
+	return c.Wind == nil && c.AirPressure == nil && c.WaterTemperature == nil && c.AirTemperature == nil
+}
+
+// NoaaMetReading represents one reading of a NOAA met product. Wind readings
+// use speed, direction and gust; the other products use the value.
+type NoaaMetReading struct {
+	Time              string `json:"t"`
+	Value             string `json:"v,omitempty"`
+	Speed             string `json:"s,omitempty"`
+	Direction         string `json:"d,omitempty"`
+	CardinalDirection string `json:"dr,omitempty"`
+	Gust              string `json:"g,omitempty"`
+}
+
+type NoaaMetResponse struct {
+	Data  []NoaaMetReading `json:"data"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
@@ -0,0 +1,112 @@
+package models
+
+import "math"
+
+// SlackSpeed is the speed in knots below which the current is considered slack
+const SlackSpeed = 0.2
+
+// CurrentState says which way a tidal current is running
+type CurrentState string
+
+const (
+	CurrentStateFlood CurrentState = "FLOOD" // Running in the flood direction, typically toward shore
+	CurrentStateEbb   CurrentState = "EBB"   // Running in the ebb direction, typically away from shore
+	CurrentStateSlack CurrentState = "SLACK" // Too weak to have a meaningful direction
+)
+
+// CurrentEventType identifies a turning point of a tidal current
+type CurrentEventType string
+
+const (
+	CurrentEventMaxFlood CurrentEventType = "MAX_FLOOD"
+	CurrentEventMaxEbb   CurrentEventType = "MAX_EBB"
+	CurrentEventSlack    CurrentEventType = "SLACK"
+)
+
+// CurrentPrediction is a predicted tidal current at a specific time
+type CurrentPrediction struct {
+	Timestamp int64        `json:"timestamp"`
+	LocalTime string       `json:"localTime"`
+	Velocity  float64      `json:"velocity"`  // Knots, positive on the flood and negative on the ebb
+	Speed     float64      `json:"speed"`     // Knots
+	Direction float64      `json:"direction"` // Degrees true the current is flowing toward
+	State     CurrentState `json:"state"`
+}
+
+// CurrentEvent is a predicted maximum flood, maximum ebb or slack water
+type CurrentEvent struct {
+	Type      CurrentEventType `json:"type"`
+	Timestamp int64            `json:"timestamp"`
+	LocalTime string           `json:"localTime"`
+	Speed     float64          `json:"speed"`     // Knots, near zero for slack water
+	Direction float64          `json:"direction"` // Degrees true
+}
+
+// CurrentResponse is the predicted tidal current at a current station
+type CurrentResponse struct {
+	ResponseType          string              `json:"responseType"`
+	Timestamp             int64               `json:"timestamp"`
+	LocalTime             string              `json:"localTime"`
+	StationID             string              `json:"stationId"`
+	StationName           string              `json:"stationName"`
+	Latitude              float64             `json:"latitude"`
+	Longitude             float64             `json:"longitude"`
+	StationDistance       float64             `json:"stationDistance"`
+	Bin                   *int                `json:"bin,omitempty"`
+	FloodDirection        float64             `json:"floodDirection"` // Degrees true
+	EbbDirection          float64             `json:"ebbDirection"`   // Degrees true
+	Current               *CurrentPrediction  `json:"current"`        // Interpolated for the current time, nil outside the range
+	Predictions           []CurrentPrediction `json:"predictions"`
+	Events                []CurrentEvent      `json:"events"`
+	TimeZoneOffsetSeconds int                 `json:"timeZoneOffsetSeconds"` // Standard offset, without DST
+	TimeZone              string              `json:"timeZone,omitempty"`    // IANA zone the local times are in
+}
+
+// NewCurrentPrediction classifies a signed velocity and picks the direction
+// the water is flowing toward.
+func NewCurrentPrediction(timestamp int64, localTime string, velocity, floodDirection, ebbDirection float64) CurrentPrediction {
+	direction := floodDirection
+	if velocity < 0 {
+		direction = ebbDirection
+	}
+
+	return CurrentPrediction{
+		Timestamp: timestamp,
+		LocalTime: localTime,
+		Velocity:  velocity,
+		Speed:     math.Abs(velocity),
+		Direction: direction,
+		State:     ClassifyCurrent(velocity),
+	}
+}
+
+// ClassifyCurrent returns the state of a current with the given signed velocity
+func ClassifyCurrent(velocity float64) CurrentState {
+	switch {
+	case math.Abs(velocity) < SlackSpeed:
+		return CurrentStateSlack
+	case velocity > 0:
+		return CurrentStateFlood
+	default:
+		return CurrentStateEbb
+	}
+}
+
+// NoaaCurrentPrediction represents one entry of NOAA's currents_predictions product
+type NoaaCurrentPrediction struct {
+	Time          string  `json:"Time"`
+	Type          string  `json:"Type,omitempty"` // "flood", "ebb" or "slack" for MAX_SLACK intervals
+	VelocityMajor float64 `json:"Velocity_Major"` // Knots along the major axis, negative on the ebb
+	MeanFloodDir  float64 `json:"meanFloodDir"`
+	MeanEbbDir    float64 `json:"meanEbbDir"`
+}
+
+type NoaaCurrentResponse struct {
+	CurrentPredictions *struct {
+		Units       string                  `json:"units"`
+		Predictions []NoaaCurrentPrediction `json:"cp"`
+	} `json:"current_predictions"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCurrentPrediction(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		velocity      float64
+		wantSpeed     float64
+		wantDirection float64
+		wantState     CurrentState
+	}{
+		{name: "flood", velocity: 1.8, wantSpeed: 1.8, wantDirection: 45, wantState: CurrentStateFlood},
+		{name: "ebb", velocity: -2.1, wantSpeed: 2.1, wantDirection: 225, wantState: CurrentStateEbb},
+		{name: "weak flood is slack", velocity: 0.1, wantSpeed: 0.1, wantDirection: 45, wantState: CurrentStateSlack},
+		{name: "weak ebb is slack", velocity: -0.15, wantSpeed: 0.15, wantDirection: 225, wantState: CurrentStateSlack},
+		{name: "slack threshold floods", velocity: SlackSpeed, wantSpeed: SlackSpeed, wantDirection: 45, wantState: CurrentStateFlood},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := NewCurrentPrediction(1717200000000, "2024-06-01T00:00:00", tt.velocity, 45, 225)
+			assert.Equal(t, tt.velocity, got.Velocity)
+			assert.InDelta(t, tt.wantSpeed, got.Speed, 1e-9)
+			assert.Equal(t, tt.wantDirection, got.Direction)
+			assert.Equal(t, tt.wantState, got.State)
+		})
+	}
+}
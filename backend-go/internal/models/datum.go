@@ -0,0 +1,59 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Datum is the vertical reference that tide heights are measured from
+type Datum string
+
+const (
+	DatumMLLW   Datum = "MLLW"   // Mean Lower Low Water
+	DatumMHHW   Datum = "MHHW"   // Mean Higher High Water
+	DatumMSL    Datum = "MSL"    // Mean Sea Level
+	DatumMTL    Datum = "MTL"    // Mean Tide Level
+	DatumNAVD88 Datum = "NAVD88" // North American Vertical Datum of 1988
+	DatumSTND   Datum = "STND"   // Station Datum
+
+	// DatumCD is the chart datum of sources other than NOAA: CHS's lowest
+	// normal tides and UKHO's lowest astronomical tide. Neither is MLLW,
+	// though both are near it, so their heights are never labelled MLLW.
+	DatumCD Datum = "CD"
+
+	// DefaultDatum is used when a request does not name one, matching NOAA's chart datum
+	DefaultDatum = DatumMLLW
+)
+
+// ParseDatum converts a datum name, in any case, to a Datum. An empty name
+// yields an unset Datum, leaving the choice to the station's source.
+func ParseDatum(name string) (Datum, error) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if name == "" {
+		return "", nil
+	}
+
+	datum := Datum(name)
+	if err := datum.Validate(); err != nil {
+		return "", err
+	}
+	return datum, nil
+}
+
+// Validate checks that the datum is one we can request from a source
+func (d Datum) Validate() error {
+	switch d {
+	case DatumMLLW, DatumMHHW, DatumMSL, DatumMTL, DatumNAVD88, DatumSTND, DatumCD:
+		return nil
+	default:
+		return fmt.Errorf("invalid datum: %s", d)
+	}
+}
+
+// OrDefault returns the datum, or the DefaultDatum if it is unset
+func (d Datum) OrDefault() Datum {
+	if d == "" {
+		return DefaultDatum
+	}
+	return d
+}
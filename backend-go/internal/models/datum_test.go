@@ -0,0 +1,52 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDatum(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		want    Datum
+		wantErr bool
+	}{
+		{name: "empty is unset", input: "", want: ""},
+		{name: "upper case", input: "MHHW", want: DatumMHHW},
+		{name: "lower case", input: "navd88", want: DatumNAVD88},
+		{name: "surrounding space", input: " msl ", want: DatumMSL},
+		{name: "station datum", input: "STND", want: DatumSTND},
+		{name: "mean tide level", input: "MTL", want: DatumMTL},
+		{name: "chart datum", input: "cd", want: DatumCD},
+		{name: "unknown datum", input: "WGS84", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseDatum(tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "invalid datum")
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDatumOrDefault(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, DatumMLLW, Datum("").OrDefault())
+	assert.Equal(t, DatumNAVD88, DatumNAVD88.OrDefault())
+}
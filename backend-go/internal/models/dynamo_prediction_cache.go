@@ -10,10 +10,21 @@ type TidePredictionRecord struct {
 	StationID   string           `dynamodbav:"stationId"`
 	Date        string           `dynamodbav:"date"`
 	StationType string           `dynamodbav:"stationType"` // R for reference, S for subordinate
+	Datum       Datum            `dynamodbav:"datum"`       // Empty on records cached before datums were selectable (MLLW)
+	Units       Units            `dynamodbav:"units"`       // Empty on records cached before units were recorded (feet)
 	Predictions []TidePrediction `dynamodbav:"predictions"`
 	Extremes    []TideExtreme    `dynamodbav:"extremes"`
 	LastUpdated int64            `dynamodbav:"lastUpdated"`
 	TTL         int64            `dynamodbav:"ttl"`
+	Stale       bool             `dynamodbav:"-"` // Past the soft TTL of the cache it came from; never stored
+
+	// Tidal current records, cached under their current station, have these
+	// instead of predictions and extremes. Their station type is S when the
+	// station only publishes events.
+	Currents       []CurrentPrediction `dynamodbav:"currents,omitempty"`
+	CurrentEvents  []CurrentEvent      `dynamodbav:"currentEvents,omitempty"`
+	FloodDirection float64             `dynamodbav:"floodDirection,omitempty"`
+	EbbDirection   float64             `dynamodbav:"ebbDirection,omitempty"`
 }
 
 // Validate checks if a TidePredictionRecord's fields are valid
@@ -40,6 +51,18 @@ func (r *TidePredictionRecord) Validate() error {
 		return fmt.Errorf("invalid station type: %s", r.StationType)
 	}
 
+	if r.Datum != "" {
+		if err := r.Datum.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if r.Units != "" {
+		if err := r.Units.Validate(); err != nil {
+			return err
+		}
+	}
+
 	// Validate all predictions
 	for i, pred := range r.Predictions {
 		if err := pred.Validate(); err != nil {
@@ -78,6 +78,29 @@ func TestTidePredictionRecord_Validate(t *testing.T) {
 			wantErr:     true,
 			errContains: "invalid station type",
 		},
+		{
+			name: "explicit datum",
+			modifyFunc: func(r *TidePredictionRecord) {
+				r.Datum = DatumNAVD88
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid datum",
+			modifyFunc: func(r *TidePredictionRecord) {
+				r.Datum = Datum("XYZ")
+			},
+			wantErr:     true,
+			errContains: "invalid datum",
+		},
+		{
+			name: "invalid units",
+			modifyFunc: func(r *TidePredictionRecord) {
+				r.Units = Units("fathoms")
+			},
+			wantErr:     true,
+			errContains: "invalid units",
+		},
 		{
 			name: "invalid prediction",
 			modifyFunc: func(r *TidePredictionRecord) {
@@ -2,7 +2,27 @@ package models
 
 import "context"
 
+// StationFinder looks up stations. Methods returning several stations take
+// a filter, which may be nil, applied before the limit.
 type StationFinder interface {
 	FindStation(ctx context.Context, stationID string) (*Station, error)
-	FindNearestStations(ctx context.Context, lat, lon float64, limit int) ([]Station, error)
+	FindNearestStations(ctx context.Context, lat, lon float64, limit int, filter *StationFilter) ([]Station, error)
+	// FindStationsInBounds returns the stations inside a box, nearest its
+	// center first. West is greater than east when the box crosses the
+	// antimeridian. A limit of zero or less returns them all.
+	FindStationsInBounds(ctx context.Context, south, west, north, east float64, limit int, filter *StationFilter) ([]Station, error)
+	// SearchStations returns the stations whose name, state or region match
+	// the query, best match first, tolerating partial words and typos. When
+	// near is given, closer stations rank higher.
+	SearchStations(ctx context.Context, query string, near *Coordinates, limit int, filter *StationFilter) ([]Station, error)
+}
+
+// StationMetadataFetcher loads the details published for a station beyond
+// the station list. Resources a station doesn't have come back nil or empty.
+type StationMetadataFetcher interface {
+	Datums(ctx context.Context, stationID string) (*StationDatums, error)
+	Sensors(ctx context.Context, stationID string) ([]Sensor, error)
+	FloodLevels(ctx context.Context, stationID string) (*FloodLevels, error)
+	Offsets(ctx context.Context, stationID string) (*TideOffsets, error)
+	HarmonicConstituents(ctx context.Context, stationID string) ([]HarmonicConstituent, error)
 }
@@ -0,0 +1,34 @@
+package models
+
+// ObservationStatus says where a response's water level came from
+type ObservationStatus string
+
+const (
+	ObservationStatusObserved     ObservationStatus = "OBSERVED"       // WaterLevel is a sensor reading
+	ObservationStatusNoSensorData ObservationStatus = "NO_SENSOR_DATA" // The station reports no recent water levels
+	ObservationStatusUnavailable  ObservationStatus = "UNAVAILABLE"    // Observations could not be retrieved
+)
+
+// WaterLevelObservation is a water level measured by a station's sensor
+type WaterLevelObservation struct {
+	StationID string  `json:"stationId"`
+	Timestamp int64   `json:"timestamp"` // Time of the measurement in milliseconds
+	Height    float64 `json:"height"`
+	Datum     Datum   `json:"datum"`
+	Units     Units   `json:"units"`
+	Quality   string  `json:"quality"` // "v" for verified or "p" for preliminary
+}
+
+// NoaaWaterLevel represents one reading in NOAA's water_level product
+type NoaaWaterLevel struct {
+	Time    string `json:"t"` // Time of the reading
+	Value   string `json:"v"` // Measured water level, empty when missing
+	Quality string `json:"q"` // "v" for verified or "p" for preliminary
+}
+
+type NoaaWaterLevelResponse struct {
+	Data  []NoaaWaterLevel `json:"data"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
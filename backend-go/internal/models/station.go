@@ -10,19 +10,115 @@ const (
 	SourceCHS  Source = "CHS"
 )
 
+// Station capabilities
+const (
+	CapabilityWaterLevel       = "WATER_LEVEL"
+	CapabilityTidalCurrents    = "TIDAL_CURRENTS"
+	CapabilityWind             = "WIND"
+	CapabilityAirPressure      = "AIR_PRESSURE"
+	CapabilityWaterTemperature = "WATER_TEMPERATURE"
+	CapabilityAirTemperature   = "AIR_TEMPERATURE"
+)
+
+// HeightAdjustment says how subordinate height offsets are applied
+type HeightAdjustment string
+
+const (
+	HeightAdjustmentRatio    HeightAdjustment = "R" // Multiply the reference height
+	HeightAdjustmentAdditive HeightAdjustment = "A" // Add to the reference height
+)
+
+// TideOffsets are the corrections NOAA publishes for deriving a subordinate
+// station's tides from its reference station
+type TideOffsets struct {
+	ReferenceStationID string           `json:"referenceStationId"`
+	HighTimeOffset     int              `json:"highTimeOffset"` // Minutes added to reference high tide times
+	LowTimeOffset      int              `json:"lowTimeOffset"`  // Minutes added to reference low tide times
+	HighHeightOffset   float64          `json:"highHeightOffset"`
+	LowHeightOffset    float64          `json:"lowHeightOffset"`
+	HeightAdjustment   HeightAdjustment `json:"heightAdjustment"`
+}
+
+// Validate checks if a TideOffsets' fields are valid
+func (o *TideOffsets) Validate() error {
+	if o.ReferenceStationID == "" {
+		return fmt.Errorf("reference station ID is required")
+	}
+
+	switch o.HeightAdjustment {
+	case HeightAdjustmentRatio:
+		if o.HighHeightOffset <= 0 || o.LowHeightOffset <= 0 {
+			return fmt.Errorf("height ratios must be positive")
+		}
+	case HeightAdjustmentAdditive:
+		// Any additive correction is valid
+	default:
+		return fmt.Errorf("invalid height adjustment: %s", o.HeightAdjustment)
+	}
+
+	return nil
+}
+
+// TimeOffset returns the high or low water time offset in minutes
+func (o *TideOffsets) TimeOffset(high bool) int {
+	if high {
+		return o.HighTimeOffset
+	}
+	return o.LowTimeOffset
+}
+
+// AdjustHeight applies the height correction to a reference height. The
+// highWeight (0 at low water, 1 at high water) blends the low and high
+// corrections for levels between extremes.
+func (o *TideOffsets) AdjustHeight(height, highWeight float64) float64 {
+	correction := o.LowHeightOffset + highWeight*(o.HighHeightOffset-o.LowHeightOffset)
+	if o.HeightAdjustment == HeightAdjustmentRatio {
+		return height * correction
+	}
+	return height + correction
+}
+
 type Station struct {
-	ID             string   `json:"id"`
-	Name           string   `json:"name"`
-	State          *string  `json:"state,omitempty"`
-	Region         *string  `json:"region,omitempty"`
-	Distance       float64  `json:"distance"`
-	Latitude       float64  `json:"latitude"`
-	Longitude      float64  `json:"longitude"`
-	Source         Source   `json:"source"`
-	Capabilities   []string `json:"capabilities"`
-	TimeZoneOffset int      `json:"timeZoneOffset"`
-	Level          *string  `json:"level,omitempty"`
-	StationType    *string  `json:"stationType,omitempty"`
+	ID             string       `json:"id"`
+	Name           string       `json:"name"`
+	State          *string      `json:"state,omitempty"`
+	Region         *string      `json:"region,omitempty"`
+	Distance       float64      `json:"distance"`
+	Latitude       float64      `json:"latitude"`
+	Longitude      float64      `json:"longitude"`
+	Source         Source       `json:"source"`
+	Capabilities   []string     `json:"capabilities"`
+	TimeZoneOffset int          `json:"timeZoneOffset"`     // Standard offset from UTC in seconds, without DST
+	TimeZone       string       `json:"timeZone,omitempty"` // IANA zone name, e.g. "America/Los_Angeles"
+	Level          *string      `json:"level,omitempty"`
+	StationType    *string      `json:"stationType,omitempty"`
+	Offsets        *TideOffsets `json:"offsets,omitempty"`
+	CurrentBin     *int         `json:"currentBin,omitempty"` // Depth bin predicted at current stations
+}
+
+// Coordinates is a position in decimal degrees
+type Coordinates struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// HasCapability reports whether the station provides the given capability
+func (s *Station) HasCapability(capability string) bool {
+	for _, c := range s.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// HasObservations reports whether the station reports observed water levels.
+// Subordinate stations only have predictions, never a gauge.
+func (s *Station) HasObservations() bool {
+	if s.StationType != nil && *s.StationType == StationTypeSubordinate {
+		return false
+	}
+	return s.HasCapability(CapabilityWaterLevel)
 }
 
 // Validate checks if a Station's fields are valid
@@ -54,5 +150,11 @@ func (s *Station) Validate() error {
 		return fmt.Errorf("invalid timezone offset: %d", s.TimeZoneOffset)
 	}
 
+	if s.Offsets != nil {
+		if err := s.Offsets.Validate(); err != nil {
+			return fmt.Errorf("invalid offsets: %w", err)
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,72 @@
+package models
+
+// StationDetails is a station with the detail NOAA publishes for it beyond the
+// station list. Details are loaded when first asked for, so any may be unset.
+type StationDetails struct {
+	Station
+	Datums               *StationDatums        `json:"datums,omitempty"`
+	Sensors              []Sensor              `json:"sensors,omitempty"`
+	FloodLevels          *FloodLevels          `json:"floodLevels,omitempty"`
+	HarmonicConstituents []HarmonicConstituent `json:"harmonicConstituents,omitempty"`
+}
+
+// StationDatums are the heights of a station's tidal datums above its
+// station datum (STND), for converting heights between datums
+type StationDatums struct {
+	Epoch            string       `json:"epoch,omitempty"` // National Tidal Datum Epoch, e.g. "1983-2001"
+	Units            string       `json:"units"`
+	OrthometricDatum string       `json:"orthometricDatum,omitempty"` // Usually NAVD88
+	Datums           []DatumValue `json:"datums"`
+}
+
+// DatumValue is the height of one datum above the station datum
+type DatumValue struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Value       float64 `json:"value"`
+}
+
+// Value returns the height of the named datum and whether the station has it
+func (d *StationDatums) Value(name string) (float64, bool) {
+	for _, datum := range d.Datums {
+		if datum.Name == name {
+			return datum.Value, true
+		}
+	}
+	return 0, false
+}
+
+// Sensor is one instrument at a station
+type Sensor struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	DCP       int      `json:"dcp"` // Data collection platform the sensor reports through
+	Active    bool     `json:"active"`
+	Elevation *float64 `json:"elevation,omitempty"`
+}
+
+// FloodLevels are the water levels at which NOAA's National Ocean Service and
+// the National Weather Service consider a station to be flooding. Levels a
+// service hasn't set are nil.
+type FloodLevels struct {
+	Units       string   `json:"units"`
+	Datum       Datum    `json:"datum"` // The datum the levels are heights above
+	NOSMinor    *float64 `json:"nosMinor,omitempty"`
+	NOSModerate *float64 `json:"nosModerate,omitempty"`
+	NOSMajor    *float64 `json:"nosMajor,omitempty"`
+	NWSMinor    *float64 `json:"nwsMinor,omitempty"`
+	NWSModerate *float64 `json:"nwsModerate,omitempty"`
+	NWSMajor    *float64 `json:"nwsMajor,omitempty"`
+}
+
+// HarmonicConstituent is the amplitude and phase of one tidal constituent at
+// a station
+type HarmonicConstituent struct {
+	Number      int     `json:"number"`
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Amplitude   float64 `json:"amplitude"`
+	PhaseGMT    float64 `json:"phaseGmt"`   // Degrees, relative to Greenwich
+	PhaseLocal  float64 `json:"phaseLocal"` // Degrees, relative to local standard time
+	Speed       float64 `json:"speed"`      // Degrees per hour
+}
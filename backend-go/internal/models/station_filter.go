@@ -0,0 +1,104 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Station types NOAA reports in its station list
+const (
+	StationTypeReference   = "R" // Harmonic predictions, usually with a water level gauge
+	StationTypeSubordinate = "S" // Predictions offset from a reference station
+)
+
+// StationFilter narrows the stations a finder returns. A station must match
+// every field that is set; a nil or empty filter matches every station.
+type StationFilter struct {
+	StationType     string   // StationTypeReference or StationTypeSubordinate
+	Capabilities    []string // The station must have all of them
+	State           string   // Matched without regard to case
+	Region          string   // Matched without regard to case
+	Source          Source
+	HasObservations bool // Only stations reporting observed water levels
+}
+
+// Validate checks the filter's station type, capabilities and source
+func (f *StationFilter) Validate() error {
+	if f == nil {
+		return nil
+	}
+
+	switch f.StationType {
+	case "", StationTypeReference, StationTypeSubordinate:
+	default:
+		return fmt.Errorf("invalid station type: %s", f.StationType)
+	}
+
+	for _, capability := range f.Capabilities {
+		switch capability {
+		case CapabilityWaterLevel, CapabilityTidalCurrents, CapabilityWind, CapabilityAirPressure,
+			CapabilityWaterTemperature, CapabilityAirTemperature:
+		default:
+			return fmt.Errorf("invalid capability: %s", capability)
+		}
+	}
+
+	switch f.Source {
+	case "", SourceNOAA, SourceUKHO, SourceCHS:
+	default:
+		return fmt.Errorf("invalid source: %s", f.Source)
+	}
+
+	return nil
+}
+
+// Matches reports whether the station passes the filter
+func (f *StationFilter) Matches(s *Station) bool {
+	if f == nil {
+		return true
+	}
+
+	if f.StationType != "" && (s.StationType == nil || *s.StationType != f.StationType) {
+		return false
+	}
+	for _, capability := range f.Capabilities {
+		if !s.HasCapability(capability) {
+			return false
+		}
+	}
+	if f.State != "" && (s.State == nil || !strings.EqualFold(*s.State, f.State)) {
+		return false
+	}
+	if f.Region != "" && (s.Region == nil || !strings.EqualFold(*s.Region, f.Region)) {
+		return false
+	}
+	if f.Source != "" && s.Source != f.Source {
+		return false
+	}
+	if f.HasObservations && !s.HasObservations() {
+		return false
+	}
+	return true
+}
+
+// Apply returns the stations that pass the filter. The stations are returned
+// as they are when nothing is filtered.
+func (f *StationFilter) Apply(stations []Station) []Station {
+	if f.IsEmpty() {
+		return stations
+	}
+
+	result := make([]Station, 0)
+	for i := range stations {
+		if f.Matches(&stations[i]) {
+			result = append(result, stations[i])
+		}
+	}
+	return result
+}
+
+// IsEmpty reports whether the filter matches every station
+func (f *StationFilter) IsEmpty() bool {
+	return f == nil || (f.StationType == "" && len(f.Capabilities) == 0 && f.State == "" &&
+		f.Region == "" && f.Source == "" && !f.HasObservations)
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func filterTestStations() []Station {
+	return []Station{
+		{ID: "8418150", State: stringPtr("ME"), Region: stringPtr("Casco Bay"), Source: SourceNOAA, StationType: stringPtr("R"),
+			Capabilities: []string{CapabilityWaterLevel, CapabilityWind}},
+		{ID: "8417208", State: stringPtr("ME"), Region: stringPtr("Casco Bay"), Source: SourceNOAA, StationType: stringPtr("S"),
+			Capabilities: []string{CapabilityWaterLevel}},
+		{ID: "8443970", State: stringPtr("MA"), Region: stringPtr("Boston Harbor"), Source: SourceNOAA, StationType: stringPtr("R"),
+			Capabilities: []string{CapabilityWaterLevel}},
+		{ID: "ACT0091", State: stringPtr("ME"), Source: SourceNOAA,
+			Capabilities: []string{CapabilityTidalCurrents}},
+		{ID: "0113", Source: SourceUKHO, Capabilities: []string{CapabilityWaterLevel}},
+	}
+}
+
+func TestStationFilter_Apply(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter *StationFilter
+		want   []string
+	}{
+		{"nil filter", nil, []string{"8418150", "8417208", "8443970", "ACT0091", "0113"}},
+		{"empty filter", &StationFilter{}, []string{"8418150", "8417208", "8443970", "ACT0091", "0113"}},
+		{"reference stations", &StationFilter{StationType: StationTypeReference}, []string{"8418150", "8443970"}},
+		{"subordinate stations", &StationFilter{StationType: StationTypeSubordinate}, []string{"8417208"}},
+		{"state ignores case", &StationFilter{State: "me"}, []string{"8418150", "8417208", "ACT0091"}},
+		{"region", &StationFilter{Region: "casco bay"}, []string{"8418150", "8417208"}},
+		{"source", &StationFilter{Source: SourceUKHO}, []string{"0113"}},
+		{"every capability required", &StationFilter{Capabilities: []string{CapabilityWaterLevel, CapabilityWind}}, []string{"8418150"}},
+		{"has observations", &StationFilter{HasObservations: true}, []string{"8418150", "8443970", "0113"}},
+		{"fields combine", &StationFilter{State: "ME", StationType: StationTypeReference}, []string{"8418150"}},
+		{"nothing matches", &StationFilter{State: "WA"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.filter.Apply(filterTestStations())
+			ids := make([]string, len(got))
+			for i, s := range got {
+				ids[i] = s.ID
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
+
+func TestStationFilter_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  *StationFilter
+		wantErr string
+	}{
+		{"nil filter", nil, ""},
+		{"valid filter", &StationFilter{StationType: "R", Capabilities: []string{CapabilityWind}, Source: SourceCHS}, ""},
+		{"invalid station type", &StationFilter{StationType: "X"}, "invalid station type: X"},
+		{"invalid capability", &StationFilter{Capabilities: []string{"TSUNAMI"}}, "invalid capability: TSUNAMI"},
+		{"invalid source", &StationFilter{Source: "BOM"}, "invalid source: BOM"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestStationFilter_IsEmpty(t *testing.T) {
+	var filter *StationFilter
+	assert.True(t, filter.IsEmpty())
+	assert.True(t, (&StationFilter{}).IsEmpty())
+	assert.True(t, (&StationFilter{Capabilities: []string{}}).IsEmpty())
+	assert.False(t, (&StationFilter{HasObservations: true}).IsEmpty())
+	assert.False(t, (&StationFilter{Region: "Puget Sound"}).IsEmpty())
+}
@@ -168,6 +168,61 @@ func TestStationValidation(t *testing.T) {
 			wantError: true,
 			errorMsg:  "invalid timezone offset",
 		},
+		{
+			name: "valid subordinate offsets",
+			station: Station{
+				ID:             "TEST005",
+				Name:           "Subordinate",
+				Latitude:       47.6062,
+				Longitude:      -122.3321,
+				Source:         SourceNOAA,
+				TimeZoneOffset: -28800,
+				Offsets: &TideOffsets{
+					ReferenceStationID: "9447130",
+					HighTimeOffset:     -6,
+					LowTimeOffset:      12,
+					HighHeightOffset:   1.05,
+					LowHeightOffset:    0.98,
+					HeightAdjustment:   HeightAdjustmentRatio,
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "offsets without reference station",
+			station: Station{
+				ID:             "TEST006",
+				Name:           "Subordinate",
+				Latitude:       47.6062,
+				Longitude:      -122.3321,
+				Source:         SourceNOAA,
+				TimeZoneOffset: -28800,
+				Offsets: &TideOffsets{
+					HeightAdjustment: HeightAdjustmentAdditive,
+				},
+			},
+			wantError: true,
+			errorMsg:  "reference station ID is required",
+		},
+		{
+			name: "non-positive height ratio",
+			station: Station{
+				ID:             "TEST007",
+				Name:           "Subordinate",
+				Latitude:       47.6062,
+				Longitude:      -122.3321,
+				Source:         SourceNOAA,
+				TimeZoneOffset: -28800,
+				Offsets: &TideOffsets{
+					ReferenceStationID: "9447130",
+					HighHeightOffset:   0,
+					LowHeightOffset:    1,
+					HeightAdjustment:   HeightAdjustmentRatio,
+				},
+			},
+			wantError: true,
+			errorMsg:  "height ratios must be positive",
+		},
 	}
 
 	for _, tt := range tests {
@@ -310,3 +365,53 @@ func BenchmarkStationSerialization(b *testing.B) {
 		}
 	})
 }
+
+func TestTideOffsetsAdjustHeight(t *testing.T) {
+	t.Parallel()
+
+	ratio := &TideOffsets{
+		ReferenceStationID: "9447130",
+		HighHeightOffset:   1.2,
+		LowHeightOffset:    0.8,
+		HeightAdjustment:   HeightAdjustmentRatio,
+	}
+	additive := &TideOffsets{
+		ReferenceStationID: "9447130",
+		HighHeightOffset:   0.5,
+		LowHeightOffset:    -0.1,
+		HeightAdjustment:   HeightAdjustmentAdditive,
+	}
+
+	tests := []struct {
+		name       string
+		offsets    *TideOffsets
+		height     float64
+		highWeight float64
+		want       float64
+	}{
+		{"ratio at high water", ratio, 10, 1, 12},
+		{"ratio at low water", ratio, 10, 0, 8},
+		{"ratio halfway", ratio, 10, 0.5, 10},
+		{"additive at high water", additive, 10, 1, 10.5},
+		{"additive at low water", additive, 10, 0, 9.9},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.InDelta(t, tt.want, tt.offsets.AdjustHeight(tt.height, tt.highWeight), 1e-9)
+		})
+	}
+}
+
+func TestStationHasCapability(t *testing.T) {
+	t.Parallel()
+
+	station := Station{Capabilities: []string{CapabilityWaterLevel, CapabilityWind}}
+
+	assert.True(t, station.HasCapability(CapabilityWaterLevel))
+	assert.True(t, station.HasCapability(CapabilityWind))
+	assert.False(t, station.HasCapability(CapabilityAirTemperature))
+	assert.False(t, (&Station{}).HasCapability(CapabilityWaterLevel))
+}
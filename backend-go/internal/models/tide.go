@@ -35,21 +35,94 @@ func (tp TidePrediction) GetTimestamp() int64 {
 
 // ExtendedTideResponse represents the full tide response including predictions and extremes
 type ExtendedTideResponse struct {
-	ResponseType          string           `json:"responseType"`
-	Timestamp             int64            `json:"timestamp"`
-	LocalTime             string           `json:"localTime"` // Add this field
-	WaterLevel            *float64         `json:"waterLevel"`
-	PredictedLevel        *float64         `json:"predictedLevel"`
-	NearestStation        string           `json:"nearestStation"`
-	Location              *string          `json:"location"`
-	Latitude              float64          `json:"latitude"`
-	Longitude             float64          `json:"longitude"`
-	StationDistance       float64          `json:"stationDistance"`
-	TideType              *TideType        `json:"tideType"`
-	CalculationMethod     string           `json:"calculationMethod"`
-	Extremes              []TideExtreme    `json:"extremes"`
-	Predictions           []TidePrediction `json:"predictions"`
-	TimeZoneOffsetSeconds *int             `json:"timeZoneOffsetSeconds"`
+	ResponseType          string            `json:"responseType"`
+	Timestamp             int64             `json:"timestamp"`
+	LocalTime             string            `json:"localTime"`  // Add this field
+	WaterLevel            *float64          `json:"waterLevel"` // Observed level, nil unless ObservationStatus is OBSERVED
+	PredictedLevel        *float64          `json:"predictedLevel"`
+	Residual              *float64          `json:"residual"`              // Observed minus predicted at the observation time, nil when the predictions don't cover it
+	ObservationAgeSeconds *int64            `json:"observationAgeSeconds"` // Age of the observation
+	ObservationStatus     ObservationStatus `json:"observationStatus"`
+	Conditions            *MarineConditions `json:"conditions"` // Latest met readings, nil for stations without met sensors
+	NearestStation        string            `json:"nearestStation"`
+	Location              *string           `json:"location"`
+	Latitude              float64           `json:"latitude"`
+	Longitude             float64           `json:"longitude"`
+	StationDistance       float64           `json:"stationDistance"`
+	TideType              *TideType         `json:"tideType"`
+	CalculationMethod     string            `json:"calculationMethod"`
+	Datum                 Datum             `json:"datum"`
+	Units                 Units             `json:"units"`
+	Extremes              []TideExtreme     `json:"extremes"`
+	Predictions           []TidePrediction  `json:"predictions"`
+	Days                  []TideDaySummary  `json:"days"`                      // Summary of each local day of the range
+	Astronomy             []AstronomyDay    `json:"astronomy"`                 // Sun and moon events for each local day of the range
+	TimeZoneOffsetSeconds *int              `json:"timeZoneOffsetSeconds"`     // Standard offset, without DST
+	TimeZone              string            `json:"timeZone,omitempty"`        // IANA zone the local times are in
+	BlendedStations       []BlendedStation  `json:"blendedStations,omitempty"` // Stations a blended estimate was made from
+	Freshness             *DataFreshness    `json:"freshness,omitempty"`       // How old the predictions are
+}
+
+// FreshnessStatus says whether a response's predictions are up to date
+type FreshnessStatus string
+
+const (
+	FreshnessFresh FreshnessStatus = "FRESH" // Just fetched, or cached within the soft TTL
+	FreshnessStale FreshnessStatus = "STALE" // Cached past the soft TTL and being refreshed
+)
+
+// DataFreshness describes the predictions a response was made from
+type DataFreshness struct {
+	Status     FreshnessStatus `json:"status"`
+	UpdatedAt  int64           `json:"updatedAt"`  // When the oldest of them was fetched, in milliseconds
+	AgeSeconds int64           `json:"ageSeconds"` // Age of the oldest of them
+}
+
+// Merge combines the freshness of predictions from two stations, which is
+// stale if either is and as old as the older. The zero value merges to the
+// other.
+func (f DataFreshness) Merge(other DataFreshness) DataFreshness {
+	if f.Status == "" {
+		return other
+	}
+	if other.Status == FreshnessStale {
+		f.Status = FreshnessStale
+	}
+	if other.AgeSeconds > f.AgeSeconds {
+		f.UpdatedAt, f.AgeSeconds = other.UpdatedAt, other.AgeSeconds
+	}
+	return f
+}
+
+// BlendedStation is one of the stations a blended tide estimate for a point
+// was made from, with its share of the estimate
+type BlendedStation struct {
+	StationID string  `json:"stationId"`
+	Name      string  `json:"name"`
+	Source    Source  `json:"source"`
+	Distance  float64 `json:"distance"` // Kilometers from the point
+	Weight    float64 `json:"weight"`   // Weights of a blend's stations sum to 1
+}
+
+// SpringNeap says where a day falls in the fortnightly cycle of tidal range
+type SpringNeap string
+
+const (
+	SpringNeapSpring       SpringNeap = "SPRING"       // Near new or full moon, when the range is largest
+	SpringNeapNeap         SpringNeap = "NEAP"         // Near the quarter moons, when the range is smallest
+	SpringNeapIntermediate SpringNeap = "INTERMEDIATE" // Between the two
+)
+
+// TideDaySummary summarizes the tides of one local calendar day. Values that
+// need an extreme the day doesn't have are nil.
+type TideDaySummary struct {
+	Date            string       `json:"date"`            // Local date, e.g. "2024-06-21"
+	HigherHigh      *TideExtreme `json:"higherHigh"`      // Highest high tide of the day
+	LowerLow        *TideExtreme `json:"lowerLow"`        // Lowest low tide of the day
+	Range           *float64     `json:"range"`           // Higher high minus lower low
+	MeanLevel       *float64     `json:"meanLevel"`       // Mean predicted level over the day
+	RangePercentile *float64     `json:"rangePercentile"` // Rank of the day's range among the days returned, 0 to 100
+	SpringNeap      SpringNeap   `json:"springNeap"`
 }
 
 // NoaaPrediction represents the raw NOAA API prediction response
@@ -475,6 +475,29 @@ func TestExtendedTideResponse_Validate_Additional(t *testing.T) {
 	}
 }
 
+func TestDataFreshness_Merge(t *testing.T) {
+	fresh := DataFreshness{Status: FreshnessFresh, UpdatedAt: 2000, AgeSeconds: 10}
+	stale := DataFreshness{Status: FreshnessStale, UpdatedAt: 1000, AgeSeconds: 11}
+	older := DataFreshness{Status: FreshnessFresh, UpdatedAt: 500, AgeSeconds: 12}
+
+	tests := []struct {
+		name  string
+		a, b  DataFreshness
+		merge DataFreshness
+	}{
+		{"zero value", DataFreshness{}, fresh, fresh},
+		{"stale wins", fresh, stale, stale},
+		{"stale is kept", stale, fresh, stale},
+		{"older is kept", stale, older, DataFreshness{Status: FreshnessStale, UpdatedAt: 500, AgeSeconds: 12}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.merge, tt.a.Merge(tt.b))
+		})
+	}
+}
+
 // Add benchmarks for validation
 func BenchmarkTideExtreme_Validate(b *testing.B) {
 	extreme := TideExtreme{
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Units is the unit of length tide heights are expressed in
+type Units string
+
+const (
+	UnitsFeet   Units = "feet"
+	UnitsMeters Units = "meters"
+
+	// DefaultUnits is used when a request does not name any
+	DefaultUnits = UnitsFeet
+
+	metersPerFoot = 0.3048
+)
+
+// ParseUnits converts a unit name, in any case, to Units. NOAA's "english"
+// and "metric" are accepted as aliases, and an empty name yields DefaultUnits.
+func ParseUnits(name string) (Units, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "":
+		return DefaultUnits, nil
+	case "feet", "ft", "english":
+		return UnitsFeet, nil
+	case "meters", "m", "metric":
+		return UnitsMeters, nil
+	default:
+		return "", fmt.Errorf("invalid units: %s", name)
+	}
+}
+
+// Validate checks that the units are supported
+func (u Units) Validate() error {
+	switch u {
+	case UnitsFeet, UnitsMeters:
+		return nil
+	default:
+		return fmt.Errorf("invalid units: %s", u)
+	}
+}
+
+// OrDefault returns the units, or DefaultUnits if they are unset
+func (u Units) OrDefault() Units {
+	if u == "" {
+		return DefaultUnits
+	}
+	return u
+}
+
+// ConvertHeight converts a height between units. Unset units are taken to be
+// the DefaultUnits.
+func ConvertHeight(height float64, from, to Units) float64 {
+	from, to = from.OrDefault(), to.OrDefault()
+	switch {
+	case from == to:
+		return height
+	case from == UnitsFeet && to == UnitsMeters:
+		return height * metersPerFoot
+	default:
+		return height / metersPerFoot
+	}
+}
+
+// ConvertHeights returns copies of the predictions and extremes with their
+// heights converted between units, rounded to the millimeter or thousandth of
+// a foot NOAA publishes.
+func ConvertHeights(predictions []TidePrediction, extremes []TideExtreme, from, to Units) ([]TidePrediction, []TideExtreme) {
+	if from.OrDefault() == to.OrDefault() {
+		return predictions, extremes
+	}
+
+	convertedPredictions := make([]TidePrediction, len(predictions))
+	for i, p := range predictions {
+		p.Height = roundThousandths(ConvertHeight(p.Height, from, to))
+		convertedPredictions[i] = p
+	}
+
+	convertedExtremes := make([]TideExtreme, len(extremes))
+	for i, e := range extremes {
+		e.Height = roundThousandths(ConvertHeight(e.Height, from, to))
+		convertedExtremes[i] = e
+	}
+
+	return convertedPredictions, convertedExtremes
+}
+
+func roundThousandths(h float64) float64 {
+	return math.Round(h*1000) / 1000
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUnits(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		want    Units
+		wantErr bool
+	}{
+		{name: "empty uses default", input: "", want: UnitsFeet},
+		{name: "feet", input: "feet", want: UnitsFeet},
+		{name: "english alias", input: "English", want: UnitsFeet},
+		{name: "meters", input: "METERS", want: UnitsMeters},
+		{name: "metric alias", input: "metric", want: UnitsMeters},
+		{name: "unknown units", input: "fathoms", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseUnits(tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "invalid units")
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestConvertHeight(t *testing.T) {
+	t.Parallel()
+
+	assert.InDelta(t, 3.048, ConvertHeight(10, UnitsFeet, UnitsMeters), 1e-12)
+	assert.InDelta(t, 10, ConvertHeight(3.048, UnitsMeters, UnitsFeet), 1e-12)
+	assert.Equal(t, 2.5, ConvertHeight(2.5, UnitsMeters, UnitsMeters))
+	assert.InDelta(t, 0.3048, ConvertHeight(1, "", UnitsMeters), 1e-12, "unset units are feet")
+}
+
+func TestConvertHeights(t *testing.T) {
+	t.Parallel()
+
+	predictions := []TidePrediction{{Timestamp: 1, Height: 10}}
+	extremes := []TideExtreme{{Type: TideTypeHigh, Timestamp: 1, Height: -1}}
+
+	gotPredictions, gotExtremes := ConvertHeights(predictions, extremes, UnitsFeet, UnitsMeters)
+	assert.Equal(t, 3.048, gotPredictions[0].Height)
+	assert.Equal(t, -0.305, gotExtremes[0].Height)
+
+	// The inputs are left untouched so cached records are never modified
+	assert.Equal(t, 10.0, predictions[0].Height)
+	assert.Equal(t, -1.0, extremes[0].Height)
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// WindowCondition says which side of the threshold the water must be on
+type WindowCondition string
+
+const (
+	WindowAbove WindowCondition = "ABOVE"
+	WindowBelow WindowCondition = "BELOW"
+)
+
+// TideWindowQuery describes the windows to search for. Threshold is in Units
+// above Datum.
+type TideWindowQuery struct {
+	Condition    WindowCondition
+	Threshold    float64
+	MinDuration  time.Duration // Shorter windows are left out
+	DaylightOnly bool          // Only count the time between sunrise and sunset
+	Datum        Datum
+	Units        Units
+}
+
+// Validate checks the query, treating an unset datum and units as the defaults
+func (q TideWindowQuery) Validate() error {
+	if q.Condition != WindowAbove && q.Condition != WindowBelow {
+		return fmt.Errorf("invalid window condition: %q", q.Condition)
+	}
+	if q.MinDuration < 0 {
+		return fmt.Errorf("minimum duration cannot be negative")
+	}
+	if err := q.Datum.OrDefault().Validate(); err != nil {
+		return err
+	}
+	return q.Units.OrDefault().Validate()
+}
+
+// TideWindow is a contiguous span of time the water level meets a condition
+type TideWindow struct {
+	StartTimestamp  int64   `json:"startTimestamp"`
+	StartLocalTime  string  `json:"startLocalTime"`
+	EndTimestamp    int64   `json:"endTimestamp"`
+	EndLocalTime    string  `json:"endLocalTime"`
+	DurationMinutes int     `json:"durationMinutes"`
+	PeakHeight      float64 `json:"peakHeight"` // Lowest level in a BELOW window, highest in an ABOVE window
+}
@@ -0,0 +1,367 @@
+// Package search finds stations by name, state and region the way people
+// type them: whole words, the start of a word, or a word with a typo or two.
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/internal/spatial"
+)
+
+// field is the part of a station a term was taken from. Matches on the name
+// count for more than matches on the region or state.
+type field int
+
+const (
+	fieldName field = iota
+	fieldRegion
+	fieldState
+	fieldID
+)
+
+var fieldWeights = map[field]float64{
+	fieldName:   1.0,
+	fieldRegion: 0.8,
+	fieldState:  0.7,
+	fieldID:     1.0,
+}
+
+// Scores for how a query word matches a station word
+const (
+	exactScore      = 1.0
+	prefixScore     = 0.5 // Plus up to 0.5 as the query covers more of the word
+	typoScore       = 0.7 // Less 0.2 for each further edit
+	typoPrefixScore = 0.5 // Less 0.15 for each further edit
+)
+
+// Bonuses for a query matching the whole station name, or its first words
+const (
+	nameBonus       = 0.5
+	namePrefixBonus = 0.25
+)
+
+// A station this many kilometers from the point searched near gets half the
+// boost of one right at it
+const nearScaleKm = 100.0
+
+// How much being near the point searched near can raise a score
+const nearWeight = 0.5
+
+type posting struct {
+	station int
+	field   field
+}
+
+// Index is a word index over a fixed list of stations. It is safe for
+// concurrent use once built.
+type Index struct {
+	stations []models.Station
+	names    []string             // Normalized station names
+	postings map[string][]posting // Word -> stations containing it
+	terms    []string             // Distinct words, sorted
+}
+
+// NewIndex builds an index over the stations' names, states, regions and
+// IDs. The stations are copied.
+func NewIndex(stations []models.Station) *Index {
+	idx := &Index{
+		stations: make([]models.Station, len(stations)),
+		names:    make([]string, len(stations)),
+		postings: make(map[string][]posting),
+	}
+	copy(idx.stations, stations)
+
+	for i, s := range idx.stations {
+		idx.names[i] = strings.Join(Tokenize(s.Name), " ")
+		idx.add(i, fieldName, s.Name)
+		idx.add(i, fieldID, s.ID)
+		if s.Region != nil {
+			idx.add(i, fieldRegion, *s.Region)
+		}
+		if s.State != nil {
+			idx.add(i, fieldState, *s.State)
+		}
+	}
+
+	idx.terms = make([]string, 0, len(idx.postings))
+	for term := range idx.postings {
+		idx.terms = append(idx.terms, term)
+	}
+	sort.Strings(idx.terms)
+	return idx
+}
+
+func (idx *Index) add(station int, f field, text string) {
+	for _, term := range Tokenize(text) {
+		postings := idx.postings[term]
+		// A word repeated in a field only needs to be recorded once
+		if n := len(postings); n > 0 && postings[n-1] == (posting{station, f}) {
+			continue
+		}
+		idx.postings[term] = append(postings, posting{station, f})
+	}
+}
+
+// Len returns the number of stations in the index
+func (idx *Index) Len() int {
+	return len(idx.stations)
+}
+
+// Search returns the stations passing the filter that match every word of
+// the query, best match first. When near is given, closer stations rank
+// higher and their distances in kilometers are filled in. A limit of zero or
+// less returns every match.
+func (idx *Index) Search(query string, near *models.Coordinates, limit int, filter *models.StationFilter) []models.Station {
+	words := Tokenize(query)
+	if len(words) == 0 {
+		return []models.Station{}
+	}
+
+	// Every word of the query must match some word of the station
+	var scores map[int]float64
+	for _, word := range words {
+		wordScores := idx.matchWord(word)
+		if scores == nil {
+			scores = wordScores
+			continue
+		}
+		for station, score := range scores {
+			if wordScore, ok := wordScores[station]; ok {
+				scores[station] = score + wordScore
+			} else {
+				delete(scores, station)
+			}
+		}
+	}
+
+	phrase := strings.Join(words, " ")
+	type hit struct {
+		station models.Station
+		score   float64
+	}
+	hits := make([]hit, 0, len(scores))
+	for i, score := range scores {
+		station := idx.stations[i]
+		if !filter.Matches(&station) {
+			continue
+		}
+		score /= float64(len(words))
+
+		switch {
+		case idx.names[i] == phrase:
+			score += nameBonus
+		case strings.HasPrefix(idx.names[i], phrase+" "):
+			score += namePrefixBonus
+		}
+
+		if near != nil {
+			station.Distance = spatial.Distance(near.Latitude, near.Longitude, station.Latitude, station.Longitude)
+			score *= 1 + nearWeight/(1+station.Distance/nearScaleKm)
+		}
+		hits = append(hits, hit{station: station, score: score})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].score != hits[j].score {
+			return hits[i].score > hits[j].score
+		}
+		if hits[i].station.Name != hits[j].station.Name {
+			return hits[i].station.Name < hits[j].station.Name
+		}
+		return hits[i].station.ID < hits[j].station.ID
+	})
+
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	result := make([]models.Station, len(hits))
+	for i, h := range hits {
+		result[i] = h.station
+	}
+	return result
+}
+
+// matchWord returns the best score of the query word against each station
+func (idx *Index) matchWord(word string) map[int]float64 {
+	scores := make(map[int]float64)
+	for _, term := range idx.terms {
+		score, ok := termScore(word, term)
+		if !ok {
+			continue
+		}
+		for _, p := range idx.postings[term] {
+			weighted := score * fieldWeights[p.field]
+			if weighted > scores[p.station] {
+				scores[p.station] = weighted
+			}
+		}
+	}
+	return scores
+}
+
+// termScore scores a query word against an indexed word, reporting false when
+// they don't match
+func termScore(word, term string) (float64, bool) {
+	if word == term {
+		return exactScore, true
+	}
+
+	w, t := []rune(word), []rune(term)
+	if len(w) < len(t) && strings.HasPrefix(term, word) {
+		return prefixScore + (1-prefixScore)*float64(len(w))/float64(len(t)), true
+	}
+
+	allowed := maxEdits(len(w))
+	if allowed == 0 {
+		return 0, false
+	}
+
+	if abs(len(w)-len(t)) <= allowed {
+		if d := editDistance(w, t, allowed); d <= allowed {
+			return typoScore - 0.2*float64(d-1), true
+		}
+	}
+
+	// The query may be the start of the word with a typo in it, as when
+	// someone is still typing. Compare against starts of the word around the
+	// query's length, since the typo may have added or dropped a letter.
+	best := allowed + 1
+	for n := len(w) - allowed; n <= len(w)+allowed; n++ {
+		if n < 1 || n >= len(t) {
+			continue
+		}
+		if d := editDistance(w, t[:n], allowed); d < best {
+			best = d
+		}
+	}
+	if best <= allowed {
+		return typoPrefixScore - 0.15*float64(best-1), true
+	}
+	return 0, false
+}
+
+// maxEdits is the number of typos tolerated in a query word of n letters.
+// Short words must match exactly, or nearly everything would match them.
+func maxEdits(n int) int {
+	switch {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance returns the number of insertions, deletions, substitutions and
+// transpositions of adjacent letters turning a into b. It gives up and
+// returns limit+1 once the distance must exceed limit.
+func editDistance(a, b []rune, limit int) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(b)]
+}
+
+// Tokenize splits text into lowercase words without accents, so "Rivière-du-
+// Loup" and "riviere du loup" give the same words
+func Tokenize(text string) []string {
+	var words []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+
+	for _, r := range strings.ToLower(text) {
+		if folded, ok := accents[r]; ok {
+			r = folded
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			word.WriteRune(r)
+		} else if r != '\'' && r != '’' {
+			// Apostrophes are dropped so "St. John's" matches "johns"
+			flush()
+		}
+	}
+	flush()
+	return words
+}
+
+// NameSimilarity scores how alike two station names are, from 0 for no words
+// in common to 1 for the same words. Words match when they're equal or a typo
+// apart, so agencies' spellings of the same place score highly, e.g.
+// "Point Atkinson" and "POINT ATKINSON, B.C.".
+func NameSimilarity(a, b string) float64 {
+	wordsA, wordsB := Tokenize(a), Tokenize(b)
+	if len(wordsA) == 0 || len(wordsB) == 0 {
+		return 0
+	}
+
+	used := make([]bool, len(wordsB))
+	matched := 0
+	for _, wa := range wordsA {
+		ra := []rune(wa)
+		for j, wb := range wordsB {
+			if used[j] {
+				continue
+			}
+			rb := []rune(wb)
+			allowed := maxEdits(min(len(ra), len(rb)))
+			if wa == wb || editDistance(ra, rb, allowed) <= allowed {
+				used[j] = true
+				matched++
+				break
+			}
+		}
+	}
+	return 2 * float64(matched) / float64(len(wordsA)+len(wordsB))
+}
+
+// accents maps the accented letters found in North American and European
+// station names to their plain forms
+var accents = map[rune]rune{
+	'à': 'a', 'á': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a', 'å': 'a',
+	'ç': 'c',
+	'è': 'e', 'é': 'e', 'ê': 'e', 'ë': 'e',
+	'ì': 'i', 'í': 'i', 'î': 'i', 'ï': 'i',
+	'ñ': 'n',
+	'ò': 'o', 'ó': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o', 'ø': 'o',
+	'ù': 'u', 'ú': 'u', 'û': 'u', 'ü': 'u',
+	'ý': 'y', 'ÿ': 'y',
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,205 @@
+package search
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func station(id, name, state, region string, lat, lon float64) models.Station {
+	return models.Station{
+		ID:        id,
+		Name:      name,
+		State:     &state,
+		Region:    &region,
+		Latitude:  lat,
+		Longitude: lon,
+	}
+}
+
+func testStations() []models.Station {
+	return []models.Station{
+		station("9447130", "Seattle", "WA", "Puget Sound", 47.6026, -122.3393),
+		station("9446484", "Tacoma", "WA", "Puget Sound", 47.2690, -122.4130),
+		station("9444900", "Port Townsend", "WA", "Puget Sound", 48.1129, -122.7595),
+		station("9443090", "Neah Bay", "WA", "Strait of Juan de Fuca", 48.3708, -124.6019),
+		station("8443970", "Boston", "MA", "Boston Harbor", 42.3539, -71.0503),
+		station("8447930", "Woods Hole", "MA", "Buzzards Bay", 41.5236, -70.6711),
+		station("8418150", "Portland", "ME", "Casco Bay", 43.6567, -70.2467),
+		station("9439040", "Astoria", "OR", "Columbia River", 46.2073, -123.7683),
+		station("8454000", "Providence", "RI", "Narragansett Bay", 41.8071, -71.4012),
+		station("8452660", "Newport", "RI", "Narragansett Bay", 41.5050, -71.3267),
+		station("9410170", "San Diego", "CA", "San Diego Bay", 32.7142, -117.1736),
+		station("9435380", "South Beach", "OR", "Yaquina Bay", 44.6254, -124.0449),
+		station("02985", "Rivière-du-Loup", "QC", "St. Lawrence", 47.8453, -69.5653),
+		station("00990", "St. John's", "NL", "Avalon Peninsula", 47.5667, -52.7167),
+	}
+}
+
+func names(stations []models.Station) []string {
+	result := make([]string, len(stations))
+	for i, s := range stations {
+		result[i] = s.Name
+	}
+	return result
+}
+
+func TestIndex_Search(t *testing.T) {
+	idx := NewIndex(testStations())
+
+	tests := []struct {
+		name  string
+		query string
+		limit int
+		want  []string
+	}{
+		{"exact name", "Seattle", 0, []string{"Seattle"}},
+		{"case and spacing", "  SEATTLE ", 0, []string{"Seattle"}},
+		{"prefix", "sea", 0, []string{"Seattle"}},
+		{"prefix of a later word", "towns", 0, []string{"Port Townsend"}},
+		{"typo", "Seatle", 0, []string{"Seattle"}},
+		{"transposed letters", "Astorai", 0, []string{"Astoria"}},
+		{"typo while typing", "Tacm", 0, []string{"Tacoma"}},
+		{"two typos in a long word", "Provdence", 0, []string{"Providence"}},
+		{"name and region", "Boston Harbor", 0, []string{"Boston"}},
+		{"every word must match", "Boston Bay", 0, []string{}},
+		{"region", "narragansett", 0, []string{"Newport", "Providence"}},
+		{"state outranks the start of a word", "RI", 0, []string{"Newport", "Providence", "Rivière-du-Loup", "Astoria"}},
+		{"exact name outranks prefixes", "Port", 0, []string{"Port Townsend", "Portland"}},
+		{"station ID", "8443970", 0, []string{"Boston"}},
+		{"accents", "riviere du loup", 0, []string{"Rivière-du-Loup"}},
+		{"apostrophe", "st johns", 0, []string{"St. John's"}},
+		{"limit", "narragansett", 1, []string{"Newport"}},
+		{"short words must match exactly", "bsy", 0, []string{}},
+		{"no words", " - ", 0, []string{}},
+		{"no match", "Honolulu", 0, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := idx.Search(tt.query, nil, tt.limit, nil)
+			assert.Equal(t, tt.want, names(got))
+		})
+	}
+}
+
+func TestIndex_SearchNear(t *testing.T) {
+	idx := NewIndex(testStations())
+
+	t.Run("nearby matches rank first", func(t *testing.T) {
+		// Every bay matches equally well on its region
+		seattle := &models.Coordinates{Latitude: 47.6, Longitude: -122.3}
+		got := idx.Search("bay", seattle, 3, nil)
+		assert.Equal(t, []string{"Neah Bay", "South Beach", "San Diego"}, names(got))
+		for i := 1; i < len(got); i++ {
+			assert.Greater(t, got[i].Distance, got[i-1].Distance)
+		}
+
+		boston := &models.Coordinates{Latitude: 42.35, Longitude: -71.05}
+		got = idx.Search("bay", boston, 3, nil)
+		assert.Equal(t, "Providence", got[0].Name)
+	})
+
+	t.Run("a better match outranks a nearer one", func(t *testing.T) {
+		seattle := &models.Coordinates{Latitude: 47.6, Longitude: -122.3}
+		got := idx.Search("Boston", seattle, 0, nil)
+		assert.Equal(t, []string{"Boston"}, names(got))
+		assert.InDelta(t, 3990, got[0].Distance, 50)
+	})
+
+	t.Run("distance is left empty without a point", func(t *testing.T) {
+		got := idx.Search("Seattle", nil, 0, nil)
+		assert.Equal(t, 0.0, got[0].Distance)
+	})
+}
+
+func TestIndex_SearchFilter(t *testing.T) {
+	idx := NewIndex(testStations())
+
+	got := idx.Search("bay", nil, 0, &models.StationFilter{State: "RI"})
+	assert.Equal(t, []string{"Newport", "Providence"}, names(got))
+
+	// The filter applies before the limit
+	got = idx.Search("bay", nil, 1, &models.StationFilter{State: "ME"})
+	assert.Equal(t, []string{"Portland"}, names(got))
+}
+
+func TestIndex_StationsAreCopied(t *testing.T) {
+	stations := testStations()
+	idx := NewIndex(stations)
+	assert.Equal(t, len(stations), idx.Len())
+
+	stations[0].Name = "changed"
+	got := idx.Search("Seattle", nil, 0, nil)
+	assert.Equal(t, []string{"Seattle"}, names(got))
+	got[0].Name = "changed again"
+	assert.Equal(t, []string{"Seattle"}, names(idx.Search("Seattle", nil, 0, nil)))
+}
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t, []string{"riviere", "du", "loup"}, Tokenize("Rivière-du-Loup"))
+	assert.Equal(t, []string{"st", "johns"}, Tokenize("St. John's"))
+	assert.Equal(t, []string{"sandy", "hook", "8531680"}, Tokenize("Sandy Hook (8531680)"))
+	assert.Empty(t, Tokenize(" , "))
+}
+
+func TestNameSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"Point Atkinson", "Point Atkinson", 1},
+		{"Point Atkinson", "POINT ATKINSON, B.C.", 2 * 2.0 / 6},
+		{"Friday Harbor", "Friday Harbour", 1},
+		{"St. John's", "St Johns", 1},
+		{"Seattle", "Victoria", 0},
+		{"", "Seattle", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%s", tt.a, tt.b), func(t *testing.T) {
+			assert.InDelta(t, tt.want, NameSimilarity(tt.a, tt.b), 1e-9)
+			assert.InDelta(t, tt.want, NameSimilarity(tt.b, tt.a), 1e-9)
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		limit int
+		want  int
+	}{
+		{"seattle", "seattle", 2, 0},
+		{"seatle", "seattle", 2, 1},
+		{"seattel", "seattle", 2, 1},
+		{"tacoma", "tacmoa", 2, 1},
+		{"boston", "austin", 2, 3},
+		{"", "bay", 5, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%s", tt.a, tt.b), func(t *testing.T) {
+			assert.Equal(t, tt.want, editDistance([]rune(tt.a), []rune(tt.b), tt.limit))
+		})
+	}
+}
+
+func BenchmarkIndex_Search(b *testing.B) {
+	stations := make([]models.Station, 0, 3000)
+	for i := 0; len(stations) < 3000; i++ {
+		for _, s := range testStations() {
+			s.ID = fmt.Sprintf("%s-%d", s.ID, i)
+			s.Name = fmt.Sprintf("%s %d", s.Name, i)
+			stations = append(stations, s)
+		}
+	}
+	idx := NewIndex(stations)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		idx.Search("Provdence", nil, 10, nil)
+	}
+}
@@ -0,0 +1,95 @@
+package spatial
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+)
+
+// Bounds is a latitude/longitude box, such as a map viewport. West is
+// greater than East when the box crosses the antimeridian.
+type Bounds struct {
+	South, West, North, East float64
+}
+
+// NewBounds validates a box and brings its longitudes into [-180, 180).
+// Longitudes outside that range, as maps report after panning around the
+// world, are wrapped; a box 360 degrees or more wide covers every longitude.
+func NewBounds(south, west, north, east float64) (Bounds, error) {
+	for _, v := range []float64{south, west, north, east} {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return Bounds{}, fmt.Errorf("invalid bounds: %f, %f, %f, %f", south, west, north, east)
+		}
+	}
+	if south < -90 || north > 90 {
+		return Bounds{}, fmt.Errorf("invalid latitude range: %f to %f", south, north)
+	}
+	if south > north {
+		return Bounds{}, fmt.Errorf("south %f is north of north %f", south, north)
+	}
+
+	if east-west >= 360 {
+		return Bounds{South: south, West: -180, North: north, East: 180}, nil
+	}
+	return Bounds{South: south, West: normalizeLongitude(west), North: north, East: normalizeLongitude(east)}, nil
+}
+
+// CrossesAntimeridian reports whether the box spans longitude 180
+func (b Bounds) CrossesAntimeridian() bool {
+	return b.West > b.East
+}
+
+// Contains reports whether the point is inside the box, edges included
+func (b Bounds) Contains(lat, lon float64) bool {
+	if lat < b.South || lat > b.North {
+		return false
+	}
+	if b.CrossesAntimeridian() {
+		return lon >= b.West || lon <= b.East
+	}
+	return lon >= b.West && lon <= b.East
+}
+
+// Center returns the middle of the box
+func (b Bounds) Center() (lat, lon float64) {
+	width := b.East - b.West
+	if b.CrossesAntimeridian() {
+		width += 360
+	}
+	return (b.South + b.North) / 2, normalizeLongitude(b.West + width/2)
+}
+
+// StationsInBounds returns the stations inside the box ordered by distance
+// from its center, with that distance filled in. A limit of zero or less
+// returns them all; otherwise the stations nearest the center are kept.
+func StationsInBounds(stations []models.Station, bounds Bounds, limit int) []models.Station {
+	centerLat, centerLon := bounds.Center()
+
+	result := make([]models.Station, 0)
+	for _, s := range stations {
+		if bounds.Contains(s.Latitude, s.Longitude) {
+			s.Distance = Distance(centerLat, centerLon, s.Latitude, s.Longitude)
+			result = append(result, s)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Distance < result[j].Distance
+	})
+
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// normalizeLongitude wraps a longitude onto [-180, 180)
+func normalizeLongitude(lon float64) float64 {
+	lon = math.Mod(lon+180, 360)
+	if lon < 0 {
+		lon += 360
+	}
+	return lon - 180
+}
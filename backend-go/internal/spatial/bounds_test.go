@@ -0,0 +1,119 @@
+package spatial
+
+import (
+	"math"
+	"testing"
+
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBounds(t *testing.T) {
+	tests := []struct {
+		name                     string
+		south, west, north, east float64
+		want                     Bounds
+		wantErr                  bool
+	}{
+		{"ordinary box", 47, -123, 48, -122, Bounds{47, -123, 48, -122}, false},
+		{"crosses the antimeridian", 50, 170, 55, -170, Bounds{50, 170, 55, -170}, false},
+		{"panned past 180", 50, 170, 55, 190, Bounds{50, 170, 55, -170}, false},
+		{"panned past -180", 20, -200, 25, -150, Bounds{20, 160, 25, -150}, false},
+		{"whole world", -90, -180, 90, 180, Bounds{-90, -180, 90, 180}, false},
+		{"wider than the world", -60, -400, 60, 400, Bounds{-60, -180, 60, 180}, false},
+		{"south above north", 48, -123, 47, -122, Bounds{}, true},
+		{"latitude out of range", -91, -123, 48, -122, Bounds{}, true},
+		{"not a number", math.NaN(), -123, 48, -122, Bounds{}, true},
+		{"infinite", 47, math.Inf(-1), 48, -122, Bounds{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewBounds(tt.south, tt.west, tt.north, tt.east)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBounds_Contains(t *testing.T) {
+	puget := Bounds{South: 47, West: -123, North: 48, East: -122}
+	aleutians := Bounds{South: 50, West: 170, North: 55, East: -170}
+
+	tests := []struct {
+		name     string
+		bounds   Bounds
+		lat, lon float64
+		want     bool
+	}{
+		{"inside", puget, 47.6, -122.3, true},
+		{"on the edge", puget, 47, -123, true},
+		{"east of the box", puget, 47.6, -121.9, false},
+		{"north of the box", puget, 48.1, -122.3, false},
+		{"west of the antimeridian", aleutians, 52.8, 173.2, true},
+		{"east of the antimeridian", aleutians, 51.9, -176.6, true},
+		{"outside a crossing box", aleutians, 52, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.bounds.Contains(tt.lat, tt.lon))
+		})
+	}
+}
+
+func TestBounds_Center(t *testing.T) {
+	lat, lon := Bounds{South: 47, West: -123, North: 48, East: -122}.Center()
+	assert.InDelta(t, 47.5, lat, 1e-9)
+	assert.InDelta(t, -122.5, lon, 1e-9)
+
+	lat, lon = Bounds{South: 50, West: 170, North: 54, East: -170}.Center()
+	assert.InDelta(t, 52, lat, 1e-9)
+	assert.InDelta(t, -180, lon, 1e-9)
+}
+
+func TestStationsInBounds(t *testing.T) {
+	stations := []models.Station{
+		{ID: "SEATTLE", Latitude: 47.6026, Longitude: -122.3393},
+		{ID: "TACOMA", Latitude: 47.2690, Longitude: -122.4130},
+		{ID: "PORTLAND", Latitude: 45.5155, Longitude: -122.6789},
+		{ID: "ADAK", Latitude: 51.8633, Longitude: -176.6320},
+		{ID: "ATTU", Latitude: 52.8300, Longitude: 173.1800},
+	}
+
+	t.Run("ordered from the center", func(t *testing.T) {
+		bounds, err := NewBounds(47, -123, 48, -122)
+		require.NoError(t, err)
+
+		got := StationsInBounds(stations, bounds, 0)
+		assert.Equal(t, []string{"SEATTLE", "TACOMA"}, stationIDs(got))
+		for _, s := range got {
+			assert.Greater(t, s.Distance, 0.0)
+		}
+	})
+
+	t.Run("limit keeps the closest", func(t *testing.T) {
+		bounds, err := NewBounds(47, -123, 48, -122)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"SEATTLE"}, stationIDs(StationsInBounds(stations, bounds, 1)))
+	})
+
+	t.Run("across the antimeridian", func(t *testing.T) {
+		bounds, err := NewBounds(50, 170, 55, 190)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"ADAK", "ATTU"}, stationIDs(StationsInBounds(stations, bounds, 0)))
+	})
+
+	t.Run("empty viewport", func(t *testing.T) {
+		bounds, err := NewBounds(0, -140, 1, -139)
+		require.NoError(t, err)
+		got := StationsInBounds(stations, bounds, 0)
+		assert.NotNil(t, got)
+		assert.Empty(t, got)
+	})
+}
@@ -0,0 +1,195 @@
+// Package spatial indexes stations by position for nearest station queries.
+// Positions are stored as points on the unit sphere, so straight-line
+// distance between points orders them the same way as distance over the
+// earth, and there is no seam at the antimeridian or trouble at the poles.
+package spatial
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+)
+
+const earthRadiusKm = 6371.0
+
+type point [3]float64
+
+type item struct {
+	point   point
+	station int // Index into Index.stations
+}
+
+// Index is a k-d tree over a fixed list of stations. It is safe for
+// concurrent use once built.
+type Index struct {
+	stations []models.Station
+	// The tree is laid out in place: the node for items[lo:hi] is at the
+	// middle, with its left subtree before it and its right subtree after.
+	items []item
+}
+
+// NewIndex builds an index over the stations. The stations are copied.
+func NewIndex(stations []models.Station) *Index {
+	idx := &Index{
+		stations: make([]models.Station, len(stations)),
+		items:    make([]item, len(stations)),
+	}
+	copy(idx.stations, stations)
+	for i, s := range stations {
+		idx.items[i] = item{point: toPoint(s.Latitude, s.Longitude), station: i}
+	}
+	build(idx.items, 0)
+	return idx
+}
+
+// Len returns the number of stations in the index
+func (idx *Index) Len() int {
+	return len(idx.stations)
+}
+
+// Nearest returns up to limit stations ordered by distance from the point,
+// with their distances in kilometers filled in
+func (idx *Index) Nearest(lat, lon float64, limit int) []models.Station {
+	return idx.NearestMatching(lat, lon, limit, nil)
+}
+
+// NearestMatching is Nearest over only the stations passing the filter
+func (idx *Index) NearestMatching(lat, lon float64, limit int, filter *models.StationFilter) []models.Station {
+	if limit <= 0 || len(idx.items) == 0 {
+		return nil
+	}
+
+	q := query{target: toPoint(lat, lon), limit: limit}
+	if !filter.IsEmpty() {
+		q.filter = filter
+	}
+	best := &neighbors{}
+	idx.search(0, len(idx.items), 0, q, best)
+
+	found := make([]neighbor, len(*best))
+	copy(found, *best)
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].chord2 < found[j].chord2
+	})
+
+	result := make([]models.Station, len(found))
+	for i, n := range found {
+		station := idx.stations[n.station]
+		station.Distance = Distance(lat, lon, station.Latitude, station.Longitude)
+		result[i] = station
+	}
+	return result
+}
+
+// query is a nearest neighbor search in progress
+type query struct {
+	target point
+	limit  int
+	filter *models.StationFilter // Nil when every station matches
+}
+
+func (idx *Index) search(lo, hi, depth int, q query, best *neighbors) {
+	if lo >= hi {
+		return
+	}
+
+	mid := (lo + hi) / 2
+	node := idx.items[mid]
+	if q.filter == nil || q.filter.Matches(&idx.stations[node.station]) {
+		best.offer(neighbor{station: node.station, chord2: chordSquared(q.target, node.point)}, q.limit)
+	}
+
+	// Search the side of the split holding the target first, then the other
+	// side only if it could hold something closer than the worst kept so far
+	axis := depth % 3
+	diff := q.target[axis] - node.point[axis]
+	nearLo, nearHi, farLo, farHi := lo, mid, mid+1, hi
+	if diff > 0 {
+		nearLo, nearHi, farLo, farHi = mid+1, hi, lo, mid
+	}
+
+	idx.search(nearLo, nearHi, depth+1, q, best)
+	if len(*best) < q.limit || diff*diff < (*best)[0].chord2 {
+		idx.search(farLo, farHi, depth+1, q, best)
+	}
+}
+
+// build arranges items into a k-d tree, splitting on x, y and z in turn
+func build(items []item, depth int) {
+	if len(items) <= 1 {
+		return
+	}
+
+	axis := depth % 3
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].point[axis] < items[j].point[axis]
+	})
+
+	mid := len(items) / 2
+	build(items[:mid], depth+1)
+	build(items[mid+1:], depth+1)
+}
+
+// Distance returns the great-circle distance between two points in kilometers
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*
+			math.Sin(dLon/2)*math.Sin(dLon/2)
+	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+	return earthRadiusKm * c
+}
+
+func toPoint(lat, lon float64) point {
+	latRad, lonRad := toRadians(lat), toRadians(lon)
+	return point{
+		math.Cos(latRad) * math.Cos(lonRad),
+		math.Cos(latRad) * math.Sin(lonRad),
+		math.Sin(latRad),
+	}
+}
+
+func chordSquared(a, b point) float64 {
+	dx, dy, dz := a[0]-b[0], a[1]-b[1], a[2]-b[2]
+	return dx*dx + dy*dy + dz*dz
+}
+
+func toRadians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+type neighbor struct {
+	station int
+	chord2  float64
+}
+
+// neighbors is a max-heap on distance, so the worst candidate is at the top
+// and can be replaced as closer ones are found
+type neighbors []neighbor
+
+func (n neighbors) Len() int            { return len(n) }
+func (n neighbors) Less(i, j int) bool  { return n[i].chord2 > n[j].chord2 }
+func (n neighbors) Swap(i, j int)       { n[i], n[j] = n[j], n[i] }
+func (n *neighbors) Push(x interface{}) { *n = append(*n, x.(neighbor)) }
+func (n *neighbors) Pop() interface{} {
+	old := *n
+	last := old[len(old)-1]
+	*n = old[:len(old)-1]
+	return last
+}
+
+// offer keeps the candidate if fewer than limit are held or it is closer
+// than the worst of them
+func (n *neighbors) offer(candidate neighbor, limit int) {
+	if len(*n) < limit {
+		heap.Push(n, candidate)
+		return
+	}
+	if candidate.chord2 < (*n)[0].chord2 {
+		(*n)[0] = candidate
+		heap.Fix(n, 0)
+	}
+}
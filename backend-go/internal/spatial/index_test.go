@@ -0,0 +1,178 @@
+package spatial
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// randomStations spreads n stations over the globe, including the poles and
+// both sides of the antimeridian
+func randomStations(n int, seed int64) []models.Station {
+	r := rand.New(rand.NewSource(seed))
+	stations := make([]models.Station, n)
+	for i := range stations {
+		stations[i] = models.Station{
+			ID:        fmt.Sprintf("S%04d", i),
+			Latitude:  r.Float64()*180 - 90,
+			Longitude: r.Float64()*360 - 180,
+		}
+	}
+	return stations
+}
+
+// bruteForceNearest returns the IDs of the limit closest stations by scanning
+func bruteForceNearest(stations []models.Station, lat, lon float64, limit int) []string {
+	sorted := make([]models.Station, len(stations))
+	copy(sorted, stations)
+	sort.Slice(sorted, func(i, j int) bool {
+		return Distance(lat, lon, sorted[i].Latitude, sorted[i].Longitude) <
+			Distance(lat, lon, sorted[j].Latitude, sorted[j].Longitude)
+	})
+	if limit > len(sorted) {
+		limit = len(sorted)
+	}
+	ids := make([]string, limit)
+	for i := range ids {
+		ids[i] = sorted[i].ID
+	}
+	return ids
+}
+
+func stationIDs(stations []models.Station) []string {
+	ids := make([]string, len(stations))
+	for i, s := range stations {
+		ids[i] = s.ID
+	}
+	return ids
+}
+
+func TestIndex_NearestMatchesScan(t *testing.T) {
+	stations := randomStations(3000, 1)
+	idx := NewIndex(stations)
+	require.Equal(t, len(stations), idx.Len())
+
+	queries := []struct {
+		name     string
+		lat, lon float64
+	}{
+		{"Seattle", 47.6062, -122.3321},
+		{"west of the antimeridian", 51.8, 179.9},
+		{"east of the antimeridian", -17.7, -179.95},
+		{"north pole", 90, 0},
+		{"south pole", -89.9, 45},
+		{"null island", 0, 0},
+	}
+
+	for _, q := range queries {
+		for _, limit := range []int{1, 5, 50} {
+			t.Run(fmt.Sprintf("%s limit %d", q.name, limit), func(t *testing.T) {
+				got := idx.Nearest(q.lat, q.lon, limit)
+				assert.Equal(t, bruteForceNearest(stations, q.lat, q.lon, limit), stationIDs(got))
+
+				for i, s := range got {
+					assert.InDelta(t, Distance(q.lat, q.lon, s.Latitude, s.Longitude), s.Distance, 1e-9)
+					if i > 0 {
+						assert.GreaterOrEqual(t, s.Distance, got[i-1].Distance)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestIndex_Nearest(t *testing.T) {
+	stations := []models.Station{
+		{ID: "SEATTLE", Latitude: 47.6026, Longitude: -122.3393},
+		{ID: "TACOMA", Latitude: 47.2690, Longitude: -122.4130},
+		{ID: "PORTLAND", Latitude: 45.5155, Longitude: -122.6789},
+		{ID: "ADAK", Latitude: 51.8633, Longitude: -176.6320},
+		{ID: "ATTU", Latitude: 52.8300, Longitude: 173.1800},
+	}
+	idx := NewIndex(stations)
+
+	tests := []struct {
+		name     string
+		lat, lon float64
+		limit    int
+		want     []string
+	}{
+		{"closest first", 47.5, -122.4, 2, []string{"SEATTLE", "TACOMA"}},
+		{"across the antimeridian", 52.5, 179.5, 2, []string{"ADAK", "ATTU"}},
+		{"limit larger than the index", 47.5, -122.4, 10, []string{"SEATTLE", "TACOMA", "PORTLAND", "ADAK", "ATTU"}},
+		{"zero limit", 47.5, -122.4, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := idx.Nearest(tt.lat, tt.lon, tt.limit)
+			if tt.want == nil {
+				assert.Empty(t, got)
+				return
+			}
+			assert.Equal(t, tt.want, stationIDs(got))
+		})
+	}
+
+	t.Run("empty index", func(t *testing.T) {
+		assert.Empty(t, NewIndex(nil).Nearest(47.5, -122.4, 5))
+	})
+
+	t.Run("stations are copied", func(t *testing.T) {
+		got := idx.Nearest(47.5, -122.4, 1)
+		got[0].Name = "changed"
+		assert.Empty(t, idx.Nearest(47.5, -122.4, 1)[0].Name)
+		assert.Empty(t, stations[0].Name)
+	})
+}
+
+func TestIndex_NearestMatching(t *testing.T) {
+	stations := randomStations(3000, 2)
+	for i := range stations {
+		stationType := models.StationTypeSubordinate
+		if i%7 == 0 {
+			stationType = models.StationTypeReference
+		}
+		stations[i].StationType = &stationType
+	}
+	idx := NewIndex(stations)
+	filter := &models.StationFilter{StationType: models.StationTypeReference}
+	reference := filter.Apply(stations)
+
+	for _, limit := range []int{1, 5, 50} {
+		t.Run(fmt.Sprintf("limit %d", limit), func(t *testing.T) {
+			got := idx.NearestMatching(47.6062, -122.3321, limit, filter)
+			assert.Len(t, got, limit)
+			assert.Equal(t, bruteForceNearest(reference, 47.6062, -122.3321, limit), stationIDs(got))
+		})
+	}
+
+	t.Run("nothing matches", func(t *testing.T) {
+		assert.Empty(t, idx.NearestMatching(47.6062, -122.3321, 5, &models.StationFilter{State: "ME"}))
+	})
+
+	t.Run("empty filter", func(t *testing.T) {
+		assert.Equal(t, stationIDs(idx.Nearest(0, 0, 5)), stationIDs(idx.NearestMatching(0, 0, 5, &models.StationFilter{})))
+	})
+}
+
+func TestDistance(t *testing.T) {
+	// Seattle to Portland is about 235 km
+	assert.InDelta(t, 235, Distance(47.6062, -122.3321, 45.5155, -122.6789), 5)
+	assert.Equal(t, 0.0, Distance(47.6062, -122.3321, 47.6062, -122.3321))
+	// A degree of longitude on the equator, either side of the antimeridian
+	assert.InDelta(t, 111.19, Distance(0, 179.5, 0, -179.5), 0.01)
+}
+
+func BenchmarkNewIndex(b *testing.B) {
+	stations := randomStations(3000, 1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewIndex(stations)
+	}
+}
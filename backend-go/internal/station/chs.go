@@ -0,0 +1,156 @@
+package station
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"strings"
+
+	"github.com/bbernstein/flowebb/backend-go/internal/cache"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/pkg/http/client"
+)
+
+// CHSStationIDPrefix namespaces CHS station codes, which would otherwise be
+// indistinguishable from other agencies' numeric IDs
+const CHSStationIDPrefix = "CHS-"
+
+// CHS IWLS time series codes
+const (
+	chsSeriesObserved      = "wlo"      // Observed water levels
+	chsSeriesPredicted     = "wlp"      // Predicted water levels
+	chsSeriesPredictedHiLo = "wlp-hilo" // Predicted high and low waters
+)
+
+// chsRegions names the CHS regions stations are grouped by
+var chsRegions = map[string]string{
+	"PAC": "Pacific",
+	"CNA": "Central and Arctic",
+	"QUE": "Quebec",
+	"ATL": "Atlantic",
+}
+
+// CHSStationFinder finds the Canadian Hydrographic Service's tide stations
+// through its Integrated Water Level System (IWLS) API. Only stations with
+// predictions are listed.
+type CHSStationFinder struct {
+	*stationList
+	httpClient *client.Client
+}
+
+var _ models.StationFinder = (*CHSStationFinder)(nil)
+
+// NewCHSStationFinder creates a finder for CHS stations. The client's base URL
+// is the IWLS API's, e.g. https://api-iwls.dfo-mpo.gc.ca.
+func NewCHSStationFinder(httpClient *client.Client, memCache *cache.StationCache) *CHSStationFinder {
+	f := &CHSStationFinder{httpClient: httpClient}
+	f.stationList = newStationList("CHS station", memCache, f.fetchStationList)
+	return f
+}
+
+// fetchStationList fetches the station list from the IWLS API and fills
+// the memory cache with it
+func (f *CHSStationFinder) fetchStationList(ctx context.Context) ([]models.Station, error) {
+	log.Debug().Msg("Cache MISS for CHS station list, fetching from IWLS API")
+
+	resp, err := f.httpClient.Get(ctx, "/api/v1/stations")
+	if err != nil {
+		return nil, fmt.Errorf("fetching CHS stations: %w", err)
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("no response from CHS API")
+	}
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("fetching CHS stations: status %d", resp.StatusCode)
+	}
+
+	var chsStations []chsStation
+	if err := json.Unmarshal(resp.Body, &chsStations); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	stations := make([]models.Station, 0, len(chsStations))
+	for _, s := range chsStations {
+		if station, ok := s.toStation(); ok {
+			stations = append(stations, station)
+		}
+	}
+
+	f.setStations(stations)
+	return stations, nil
+}
+
+// chsStation is one station in the IWLS station list. The province, region
+// and time zone are only present in some API versions.
+type chsStation struct {
+	ID           string  `json:"id"` // IWLS's internal ID, used in data URLs
+	Code         string  `json:"code"`
+	OfficialName string  `json:"officialName"`
+	Latitude     float64 `json:"latitude"`
+	Longitude    float64 `json:"longitude"`
+	ProvinceCode string  `json:"provinceCode"`
+	RegionCode   string  `json:"chsRegionCode"`
+	TimeZoneCode string  `json:"timeZoneCode"`
+	TimeSeries   []struct {
+		Code string `json:"code"`
+	} `json:"timeSeries"`
+}
+
+func (s chsStation) hasSeries(code string) bool {
+	for _, series := range s.TimeSeries {
+		if series.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// toStation converts the station, reporting false for stations without
+// predictions. Heights are published in metres above chart datum.
+func (s chsStation) toStation() (models.Station, bool) {
+	if s.Code == "" || !(s.hasSeries(chsSeriesPredicted) || s.hasSeries(chsSeriesPredictedHiLo)) {
+		return models.Station{}, false
+	}
+
+	zoneName, offset := zoneForPosition(s.Latitude, s.Longitude, s.TimeZoneCode)
+
+	capabilities := []string{}
+	if s.hasSeries(chsSeriesObserved) {
+		capabilities = append(capabilities, models.CapabilityWaterLevel)
+	}
+
+	var state, region *string
+	if s.ProvinceCode != "" {
+		provinceCode := s.ProvinceCode
+		state = &provinceCode
+	}
+	if name, ok := chsRegions[strings.ToUpper(s.RegionCode)]; ok {
+		region = &name
+	}
+
+	// CHS publishes full predictions for every station it lists
+	stationType := models.StationTypeReference
+	return models.Station{
+		ID:             CHSStationIDPrefix + s.Code,
+		Name:           s.OfficialName,
+		State:          state,
+		Region:         region,
+		Latitude:       s.Latitude,
+		Longitude:      s.Longitude,
+		Source:         models.SourceCHS,
+		Capabilities:   capabilities,
+		TimeZoneOffset: offset,
+		TimeZone:       zoneName,
+		StationType:    &stationType,
+	}, true
+}
+
+// CHSStationCode returns the CHS code of a station ID, and whether the ID is
+// a CHS station's
+func CHSStationCode(stationID string) (string, bool) {
+	if !strings.HasPrefix(stationID, CHSStationIDPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(stationID, CHSStationIDPrefix), true
+}
@@ -0,0 +1,153 @@
+package station
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/pkg/http/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCHSStationFinder(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/chs_stations.json")
+	require.NoError(t, err)
+
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "/api/v1/stations", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(fixture)
+	}))
+	defer srv.Close()
+
+	finder := NewCHSStationFinder(client.New(client.Options{
+		BaseURL: srv.URL,
+		Timeout: 5 * time.Second,
+	}), nil)
+	ctx := context.Background()
+
+	t.Run("find station", func(t *testing.T) {
+		station, err := finder.FindStation(ctx, "CHS-07795")
+		require.NoError(t, err)
+
+		assert.Equal(t, "Point Atkinson", station.Name)
+		assert.Equal(t, models.SourceCHS, station.Source)
+		assert.Equal(t, []string{models.CapabilityWaterLevel}, station.Capabilities)
+		assert.Equal(t, "America/Vancouver", station.TimeZone)
+		assert.Equal(t, -8*3600, station.TimeZoneOffset)
+		require.NotNil(t, station.State)
+		assert.Equal(t, "BC", *station.State)
+		require.NotNil(t, station.Region)
+		assert.Equal(t, "Pacific", *station.Region)
+		require.NotNil(t, station.StationType)
+		assert.Equal(t, models.StationTypeReference, *station.StationType)
+		assert.True(t, station.HasObservations())
+	})
+
+	t.Run("canadian time zones", func(t *testing.T) {
+		tests := []struct {
+			id     string
+			zone   string
+			offset int
+		}{
+			{"CHS-07120", "America/Vancouver", -8 * 3600},
+			{"CHS-05975", "America/Winnipeg", -6 * 3600},
+			{"CHS-00490", "America/Halifax", -4 * 3600},
+			{"CHS-00905", "America/St_Johns", -3*3600 - 1800},
+		}
+		for _, tt := range tests {
+			station, err := finder.FindStation(ctx, tt.id)
+			require.NoError(t, err)
+			assert.Equal(t, tt.zone, station.TimeZone, tt.id)
+			assert.Equal(t, tt.offset, station.TimeZoneOffset, tt.id)
+		}
+	})
+
+	t.Run("predictions without a gauge", func(t *testing.T) {
+		station, err := finder.FindStation(ctx, "CHS-07654")
+		require.NoError(t, err)
+		assert.Empty(t, station.Capabilities)
+		assert.Nil(t, station.State)
+		assert.Nil(t, station.Region)
+		assert.False(t, station.HasObservations())
+	})
+
+	t.Run("stations without predictions are left out", func(t *testing.T) {
+		_, err := finder.FindStation(ctx, "CHS-13988")
+		assert.EqualError(t, err, "CHS station not found: CHS-13988")
+	})
+
+	t.Run("codes are namespaced", func(t *testing.T) {
+		_, err := finder.FindStation(ctx, "07795")
+		assert.Error(t, err)
+	})
+
+	t.Run("nearest stations", func(t *testing.T) {
+		// Between Victoria and Vancouver, nearer Victoria
+		stations, err := finder.FindNearestStations(ctx, 48.6, -123.3, 2, nil)
+		require.NoError(t, err)
+		require.Len(t, stations, 2)
+		assert.Equal(t, "CHS-07120", stations[0].ID)
+		assert.Equal(t, "CHS-07795", stations[1].ID)
+	})
+
+	t.Run("in bounds", func(t *testing.T) {
+		stations, err := finder.FindStationsInBounds(ctx, 40, -70, 50, -50, 0, nil)
+		require.NoError(t, err)
+		ids := make([]string, len(stations))
+		for i, s := range stations {
+			ids[i] = s.ID
+		}
+		assert.ElementsMatch(t, []string{"CHS-00490", "CHS-00905"}, ids)
+	})
+
+	t.Run("search", func(t *testing.T) {
+		stations, err := finder.SearchStations(ctx, "st johns", nil, 0, nil)
+		require.NoError(t, err)
+		require.NotEmpty(t, stations)
+		assert.Equal(t, "CHS-00905", stations[0].ID)
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		stations, err := finder.FindNearestStations(ctx, 49, -123, 10, &models.StationFilter{HasObservations: true})
+		require.NoError(t, err)
+		for _, s := range stations {
+			assert.NotEqual(t, "CHS-07654", s.ID)
+		}
+	})
+
+	// The list is fetched once and served from memory afterwards
+	assert.Equal(t, 1, requests)
+}
+
+func TestCHSStationFinder_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	finder := NewCHSStationFinder(client.New(client.Options{
+		BaseURL:    srv.URL,
+		Timeout:    5 * time.Second,
+		MaxRetries: 1,
+	}), nil)
+
+	_, err := finder.FindStation(context.Background(), "CHS-07795")
+	assert.EqualError(t, err, "getting CHS station list: fetching CHS stations: status 503")
+}
+
+func TestCHSStationCode(t *testing.T) {
+	code, ok := CHSStationCode("CHS-07795")
+	assert.True(t, ok)
+	assert.Equal(t, "07795", code)
+
+	_, ok = CHSStationCode("9447130")
+	assert.False(t, ok)
+}
@@ -0,0 +1,344 @@
+package station
+
+import (
+	"context"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bbernstein/flowebb/backend-go/internal/coalesce"
+	"github.com/bbernstein/flowebb/backend-go/internal/config"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/internal/search"
+	"github.com/bbernstein/flowebb/backend-go/internal/spatial"
+	"github.com/bbernstein/flowebb/backend-go/pkg/http/client"
+)
+
+// Stations from different sources closer than this, with names at least this
+// similar, are taken to be the same place
+const (
+	defaultDuplicateDistanceKm     = 1.0
+	defaultDuplicateNameSimilarity = 0.5
+)
+
+// Kilometers per degree of latitude, for ruling out duplicates cheaply
+const kmPerDegreeLatitude = 111.2
+
+type FinderFactory interface {
+	NewFinder(cfg *config.Config, noaaOpts ...NOAAOption) (models.StationFinder, error)
+}
+
+type DefaultFinderFactory struct{}
+
+func (f *DefaultFinderFactory) NewFinder(cfg *config.Config, noaaOpts ...NOAAOption) (models.StationFinder, error) {
+	return NewDefaultStationFinder(cfg, noaaOpts...), nil
+}
+
+// StationProvider is one source's finder, registered with a
+// CompositeStationFinder
+type StationProvider struct {
+	Source models.Source
+	// IDPrefix starts the IDs of the source's stations, e.g. "CHS-". IDs
+	// without a registered prefix go to the provider without one.
+	IDPrefix string
+	Finder   models.StationFinder
+}
+
+// CompositeStationFinder finds stations from several sources at once,
+// merging their results. A station at the same place as one from a
+// higher-priority source, with a similar name, is left out. Stations are
+// found by ID from the source their ID's prefix names, or from a "SOURCE:"
+// namespace such as "noaa:9447130".
+type CompositeStationFinder struct {
+	providers []StationProvider // Highest priority first
+
+	duplicateDistanceKm     float64
+	duplicateNameSimilarity float64
+}
+
+var _ models.StationFinder = (*CompositeStationFinder)(nil)
+
+type CompositeOption func(*CompositeStationFinder)
+
+// WithSourcePriority orders the sources for choosing between co-located
+// stations. Sources left out follow in the order they were registered.
+func WithSourcePriority(sources ...models.Source) CompositeOption {
+	return func(f *CompositeStationFinder) {
+		rank := func(source models.Source) int {
+			for i, s := range sources {
+				if s == source {
+					return i
+				}
+			}
+			return len(sources)
+		}
+		sort.SliceStable(f.providers, func(i, j int) bool {
+			return rank(f.providers[i].Source) < rank(f.providers[j].Source)
+		})
+	}
+}
+
+// WithDuplicateDistance sets how close, in kilometers, stations from
+// different sources must be to be duplicates
+func WithDuplicateDistance(km float64) CompositeOption {
+	return func(f *CompositeStationFinder) {
+		f.duplicateDistanceKm = km
+	}
+}
+
+// WithDuplicateNameSimilarity sets how alike, from 0 to 1, co-located
+// stations' names must be to be duplicates
+func WithDuplicateNameSimilarity(similarity float64) CompositeOption {
+	return func(f *CompositeStationFinder) {
+		f.duplicateNameSimilarity = similarity
+	}
+}
+
+// NewCompositeStationFinder creates a finder over the providers, which take
+// priority in the order given unless WithSourcePriority says otherwise
+func NewCompositeStationFinder(providers []StationProvider, opts ...CompositeOption) *CompositeStationFinder {
+	f := &CompositeStationFinder{
+		providers:               append([]StationProvider(nil), providers...),
+		duplicateDistanceKm:     defaultDuplicateDistanceKm,
+		duplicateNameSimilarity: defaultDuplicateNameSimilarity,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// NewDefaultStationFinder creates a finder over every source the config
+// enables: NOAA and CHS, and UKHO when an Admiralty API key is configured.
+// The NOAA finder is created with noaaOpts.
+func NewDefaultStationFinder(cfg *config.Config, noaaOpts ...NOAAOption) *CompositeStationFinder {
+	newClient := func(baseURL string, headers map[string]string) *client.Client {
+		return client.New(client.Options{
+			BaseURL:    baseURL,
+			Timeout:    cfg.HTTPTimeout,
+			MaxRetries: cfg.MaxRetries,
+			Headers:    headers,
+		})
+	}
+
+	// Each finder keeps its own station list, so none share a cache
+	noaaFinder, _ := NewNOAAStationFinder(newClient(cfg.NOAABaseURL, nil), nil, noaaOpts...)
+	providers := []StationProvider{
+		{Source: models.SourceNOAA, Finder: noaaFinder},
+		{Source: models.SourceCHS, IDPrefix: CHSStationIDPrefix, Finder: NewCHSStationFinder(newClient(cfg.CHSBaseURL, nil), nil)},
+	}
+	if cfg.UKHOAPIKey != "" {
+		ukhoClient := newClient(cfg.UKHOBaseURL, map[string]string{UKHOAPIKeyHeader: cfg.UKHOAPIKey})
+		providers = append(providers, StationProvider{Source: models.SourceUKHO, IDPrefix: UKHOStationIDPrefix, Finder: NewUKHOStationFinder(ukhoClient, nil)})
+	}
+
+	priority := make([]models.Source, len(cfg.SourcePriority))
+	for i, source := range cfg.SourcePriority {
+		priority[i] = models.Source(source)
+	}
+	return NewCompositeStationFinder(providers, WithSourcePriority(priority...))
+}
+
+func (f *CompositeStationFinder) FindStation(ctx context.Context, stationID string) (*models.Station, error) {
+	provider, providerID, ok := f.route(stationID)
+	if !ok {
+		return nil, fmt.Errorf("no station source for ID: %s", stationID)
+	}
+	return provider.Finder.FindStation(ctx, providerID)
+}
+
+func (f *CompositeStationFinder) FindNearestStations(ctx context.Context, lat, lon float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Validate coordinates
+	if lat < -90 || lat > 90 {
+		return nil, fmt.Errorf("invalid latitude: %f", lat)
+	}
+	if lon < -180 || lon > 180 {
+		return nil, fmt.Errorf("invalid longitude: %f", lon)
+	}
+
+	stations, err := f.fanOut(filter, func(finder models.StationFinder) ([]models.Station, error) {
+		return finder.FindNearestStations(ctx, lat, lon, limit, filter)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = defaultNearestLimit
+	}
+	sort.SliceStable(stations, func(i, j int) bool {
+		return stations[i].Distance < stations[j].Distance
+	})
+	if len(stations) > limit {
+		stations = stations[:limit]
+	}
+	return stations, nil
+}
+
+func (f *CompositeStationFinder) FindStationsInBounds(ctx context.Context, south, west, north, east float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	bounds, err := spatial.NewBounds(south, west, north, east)
+	if err != nil {
+		return nil, err
+	}
+
+	stations, err := f.fanOut(filter, func(finder models.StationFinder) ([]models.Station, error) {
+		return finder.FindStationsInBounds(ctx, south, west, north, east, limit, filter)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return spatial.StationsInBounds(stations, bounds, limit), nil
+}
+
+func (f *CompositeStationFinder) SearchStations(ctx context.Context, query string, near *models.Coordinates, limit int, filter *models.StationFilter) ([]models.Station, error) {
+	if err := validateSearch(query, near, filter); err != nil {
+		return nil, err
+	}
+
+	stations, err := f.fanOut(filter, func(finder models.StationFinder) ([]models.Station, error) {
+		return finder.SearchStations(ctx, query, near, limit, filter)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Each source ranks only its own matches, so they're ranked again together
+	return searchIndex(search.NewIndex(stations), query, near, limit, filter), nil
+}
+
+// route returns the provider for a station ID and the ID it knows the
+// station by
+func (f *CompositeStationFinder) route(stationID string) (*StationProvider, string, bool) {
+	if source, id, ok := strings.Cut(stationID, ":"); ok {
+		for i := range f.providers {
+			provider := &f.providers[i]
+			if strings.EqualFold(string(provider.Source), source) {
+				if !strings.HasPrefix(id, provider.IDPrefix) {
+					id = provider.IDPrefix + id
+				}
+				return provider, id, true
+			}
+		}
+	}
+
+	var unprefixed *StationProvider
+	for i := range f.providers {
+		provider := &f.providers[i]
+		if provider.IDPrefix == "" {
+			if unprefixed == nil {
+				unprefixed = provider
+			}
+		} else if strings.HasPrefix(stationID, provider.IDPrefix) {
+			return provider, stationID, true
+		}
+	}
+	if unprefixed != nil {
+		return unprefixed, stationID, true
+	}
+	return nil, "", false
+}
+
+// fanOut runs the query against each provider the filter allows at once,
+// returning their stations, highest priority first, without duplicates. A
+// source that fails is left out; the query only fails when they all do.
+func (f *CompositeStationFinder) fanOut(filter *models.StationFilter, query func(models.StationFinder) ([]models.Station, error)) ([]models.Station, error) {
+	var providers []StationProvider
+	for _, provider := range f.providers {
+		if filter == nil || filter.Source == "" || filter.Source == provider.Source {
+			providers = append(providers, provider)
+		}
+	}
+
+	results := make([][]models.Station, len(providers))
+	errs := make([]error, len(providers))
+	var wg sync.WaitGroup
+	for i, provider := range providers {
+		wg.Add(1)
+		go func(i int, finder models.StationFinder) {
+			defer wg.Done()
+			results[i], errs[i] = query(finder)
+		}(i, provider.Finder)
+	}
+	wg.Wait()
+
+	stations := make([]models.Station, 0)
+	var firstErr error
+	failed := 0
+	for i, provider := range providers {
+		if errs[i] != nil {
+			log.Warn().Err(errs[i]).
+				Str("source", string(provider.Source)).
+				Msg("Error finding stations from source")
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+			failed++
+			continue
+		}
+		stations = append(stations, results[i]...)
+	}
+	if failed > 0 && failed == len(providers) {
+		return nil, firstErr
+	}
+
+	if len(providers)-failed > 1 {
+		stations = f.removeDuplicates(stations)
+	}
+	return stations, nil
+}
+
+// GetCoalescingStats returns the coalescing counts of the providers' finders
+// that keep them, each named for its source, e.g. chs_station_list_fetches
+func (f *CompositeStationFinder) GetCoalescingStats() map[string]uint64 {
+	stats := map[string]uint64{}
+	for _, provider := range f.providers {
+		reporter, ok := provider.Finder.(coalesce.StatsReporter)
+		if !ok {
+			continue
+		}
+		for name, count := range reporter.GetCoalescingStats() {
+			stats[strings.ToLower(string(provider.Source))+"_"+name] = count
+		}
+	}
+	return stats
+}
+
+// removeDuplicates drops stations at the same place as an earlier station
+// from another source with a similar name
+func (f *CompositeStationFinder) removeDuplicates(stations []models.Station) []models.Station {
+	maxLatitudeDelta := f.duplicateDistanceKm / kmPerDegreeLatitude
+	result := make([]models.Station, 0, len(stations))
+	for _, s := range stations {
+		duplicate := false
+		for _, kept := range result {
+			if kept.Source == s.Source || math.Abs(kept.Latitude-s.Latitude) > maxLatitudeDelta {
+				continue
+			}
+			if spatial.Distance(kept.Latitude, kept.Longitude, s.Latitude, s.Longitude) <= f.duplicateDistanceKm &&
+				search.NameSimilarity(kept.Name, s.Name) >= f.duplicateNameSimilarity {
+				log.Debug().
+					Str("station_id", s.ID).
+					Str("duplicate_of", kept.ID).
+					Msg("Leaving out duplicate station")
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			result = append(result, s)
+		}
+	}
+	return result
+}
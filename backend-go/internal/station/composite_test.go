@@ -0,0 +1,275 @@
+package station
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bbernstein/flowebb/backend-go/internal/config"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/internal/search"
+	"github.com/bbernstein/flowebb/backend-go/internal/spatial"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listFinder finds stations in a fixed list, or fails with err
+type listFinder struct {
+	stations []models.Station
+	err      error
+	calls    atomic.Int32
+}
+
+func (f *listFinder) FindStation(ctx context.Context, stationID string) (*models.Station, error) {
+	f.calls.Add(1)
+	for _, s := range f.stations {
+		if s.ID == stationID {
+			return &s, nil
+		}
+	}
+	return nil, fmt.Errorf("station not found: %s", stationID)
+}
+
+func (f *listFinder) FindNearestStations(ctx context.Context, lat, lon float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
+	f.calls.Add(1)
+	if f.err != nil {
+		return nil, f.err
+	}
+	if limit <= 0 {
+		limit = defaultNearestLimit
+	}
+	return nearestStations(filter.Apply(f.stations), lat, lon, limit), nil
+}
+
+func (f *listFinder) FindStationsInBounds(ctx context.Context, south, west, north, east float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
+	f.calls.Add(1)
+	if f.err != nil {
+		return nil, f.err
+	}
+	bounds, err := spatial.NewBounds(south, west, north, east)
+	if err != nil {
+		return nil, err
+	}
+	return spatial.StationsInBounds(filter.Apply(f.stations), bounds, limit), nil
+}
+
+func (f *listFinder) SearchStations(ctx context.Context, query string, near *models.Coordinates, limit int, filter *models.StationFilter) ([]models.Station, error) {
+	f.calls.Add(1)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return searchIndex(search.NewIndex(f.stations), query, near, limit, filter), nil
+}
+
+// Stations around the Strait of Georgia, where NOAA and CHS both list some
+// of the same places
+func compositeTestFinders() (*listFinder, *listFinder) {
+	noaa := &listFinder{stations: []models.Station{
+		{ID: "9449880", Name: "Friday Harbor", Latitude: 48.5453, Longitude: -123.0125, Source: models.SourceNOAA},
+		{ID: "9449424", Name: "Cherry Point", Latitude: 48.8633, Longitude: -122.7583, Source: models.SourceNOAA},
+		{ID: "9449679", Name: "Point Roberts", Latitude: 48.9817, Longitude: -123.0783, Source: models.SourceNOAA},
+	}}
+	chs := &listFinder{stations: []models.Station{
+		// The same gauge as NOAA's Friday Harbor, a few hundred meters away
+		{ID: "CHS-07330", Name: "Friday Harbour", Latitude: 48.5467, Longitude: -123.0100, Source: models.SourceCHS},
+		// Close to Point Roberts, but a different place
+		{ID: "CHS-07577", Name: "Tsawwassen", Latitude: 48.9842, Longitude: -123.0800, Source: models.SourceCHS},
+		{ID: "CHS-07795", Name: "Point Atkinson", Latitude: 49.337, Longitude: -123.253, Source: models.SourceCHS},
+	}}
+	return noaa, chs
+}
+
+func compositeTestFinder(opts ...CompositeOption) (*CompositeStationFinder, *listFinder, *listFinder) {
+	noaa, chs := compositeTestFinders()
+	return NewCompositeStationFinder([]StationProvider{
+		{Source: models.SourceNOAA, Finder: noaa},
+		{Source: models.SourceCHS, IDPrefix: CHSStationIDPrefix, Finder: chs},
+	}, opts...), noaa, chs
+}
+
+func stationIDs(stations []models.Station) []string {
+	ids := make([]string, len(stations))
+	for i, s := range stations {
+		ids[i] = s.ID
+	}
+	return ids
+}
+
+func TestCompositeStationFinder_FindStation(t *testing.T) {
+	finder, _, _ := compositeTestFinder()
+	ctx := context.Background()
+
+	tests := []struct {
+		id      string
+		want    string
+		wantErr string
+	}{
+		{id: "9449880", want: "9449880"},
+		{id: "CHS-07795", want: "CHS-07795"},
+		{id: "noaa:9449880", want: "9449880"},
+		{id: "CHS:07795", want: "CHS-07795"},
+		{id: "chs:CHS-07795", want: "CHS-07795"},
+		// Prefixes of unregistered sources go to the unprefixed source
+		{id: "UKHO-0014", wantErr: "station not found: UKHO-0014"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			station, err := finder.FindStation(ctx, tt.id)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, station.ID)
+		})
+	}
+
+	t.Run("no source for the ID", func(t *testing.T) {
+		_, chs := compositeTestFinders()
+		finder := NewCompositeStationFinder([]StationProvider{{Source: models.SourceCHS, IDPrefix: CHSStationIDPrefix, Finder: chs}})
+		_, err := finder.FindStation(ctx, "9449880")
+		assert.EqualError(t, err, "no station source for ID: 9449880")
+	})
+}
+
+func TestCompositeStationFinder_FindNearestStations(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("merged by distance without duplicates", func(t *testing.T) {
+		finder, _, _ := compositeTestFinder()
+		stations, err := finder.FindNearestStations(ctx, 48.55, -123.01, 10, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"9449880", "9449424", "9449679", "CHS-07577", "CHS-07795"}, stationIDs(stations))
+	})
+
+	t.Run("source priority", func(t *testing.T) {
+		finder, _, _ := compositeTestFinder(WithSourcePriority(models.SourceCHS))
+		stations, err := finder.FindNearestStations(ctx, 48.55, -123.01, 1, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"CHS-07330"}, stationIDs(stations))
+	})
+
+	t.Run("duplicate thresholds", func(t *testing.T) {
+		finder, _, _ := compositeTestFinder(WithDuplicateDistance(0.1))
+		stations, err := finder.FindNearestStations(ctx, 48.55, -123.01, 2, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"CHS-07330", "9449880"}, stationIDs(stations), "too far apart to be duplicates")
+
+		finder, _, _ = compositeTestFinder(WithDuplicateNameSimilarity(1.1))
+		stations, err = finder.FindNearestStations(ctx, 48.55, -123.01, 2, nil)
+		require.NoError(t, err)
+		assert.Len(t, stations, 2, "names never similar enough")
+	})
+
+	t.Run("default limit", func(t *testing.T) {
+		finder, _, _ := compositeTestFinder()
+		stations, err := finder.FindNearestStations(ctx, 48.55, -123.01, 0, nil)
+		require.NoError(t, err)
+		assert.Len(t, stations, defaultNearestLimit)
+	})
+
+	t.Run("source filter only asks that source", func(t *testing.T) {
+		finder, noaa, chs := compositeTestFinder()
+		stations, err := finder.FindNearestStations(ctx, 48.55, -123.01, 10, &models.StationFilter{Source: models.SourceCHS})
+		require.NoError(t, err)
+		assert.Len(t, stations, 3)
+		assert.Zero(t, noaa.calls.Load())
+		assert.Equal(t, int32(1), chs.calls.Load())
+	})
+
+	t.Run("a failing source is left out", func(t *testing.T) {
+		finder, noaa, _ := compositeTestFinder()
+		noaa.err = errors.New("NOAA is down")
+		stations, err := finder.FindNearestStations(ctx, 48.55, -123.01, 10, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"CHS-07330", "CHS-07577", "CHS-07795"}, stationIDs(stations))
+	})
+
+	t.Run("every source failing", func(t *testing.T) {
+		finder, noaa, chs := compositeTestFinder()
+		noaa.err = errors.New("NOAA is down")
+		chs.err = errors.New("CHS is down")
+		_, err := finder.FindNearestStations(ctx, 48.55, -123.01, 10, nil)
+		assert.EqualError(t, err, "NOAA is down")
+	})
+
+	t.Run("invalid coordinates", func(t *testing.T) {
+		finder, noaa, _ := compositeTestFinder()
+		_, err := finder.FindNearestStations(ctx, 91, 0, 10, nil)
+		assert.EqualError(t, err, "invalid latitude: 91.000000")
+		assert.Zero(t, noaa.calls.Load())
+	})
+}
+
+func TestCompositeStationFinder_FindStationsInBounds(t *testing.T) {
+	finder, _, _ := compositeTestFinder()
+	ctx := context.Background()
+
+	stations, err := finder.FindStationsInBounds(ctx, 48.5, -123.1, 49.0, -122.7, 0, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"9449880", "9449424", "9449679", "CHS-07577"}, stationIDs(stations))
+	for i := 1; i < len(stations); i++ {
+		assert.LessOrEqual(t, stations[i-1].Distance, stations[i].Distance)
+	}
+
+	stations, err = finder.FindStationsInBounds(ctx, 48.5, -123.1, 49.0, -122.7, 2, nil)
+	require.NoError(t, err)
+	assert.Len(t, stations, 2)
+
+	_, err = finder.FindStationsInBounds(ctx, 49.0, -123.1, 48.5, -122.7, 0, nil)
+	assert.Error(t, err)
+}
+
+func TestCompositeStationFinder_SearchStations(t *testing.T) {
+	finder, _, _ := compositeTestFinder()
+	ctx := context.Background()
+
+	stations, err := finder.SearchStations(ctx, "point", nil, 0, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"9449424", "9449679", "CHS-07795"}, stationIDs(stations))
+
+	stations, err = finder.SearchStations(ctx, "friday harbor", nil, 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"9449880"}, stationIDs(stations), "CHS's Friday Harbour is a duplicate")
+
+	stations, err = finder.SearchStations(ctx, "point", &models.Coordinates{Latitude: 49.3, Longitude: -123.2}, 1, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"CHS-07795"}, stationIDs(stations), "ranked together, nearest first")
+
+	_, err = finder.SearchStations(ctx, " ", nil, 0, nil)
+	assert.EqualError(t, err, "search query is required")
+}
+
+func TestNewDefaultStationFinder(t *testing.T) {
+	sources := func(f *CompositeStationFinder) []models.Source {
+		result := make([]models.Source, len(f.providers))
+		for i, p := range f.providers {
+			result[i] = p.Source
+		}
+		return result
+	}
+
+	finder := NewDefaultStationFinder(config.New())
+	assert.Equal(t, []models.Source{models.SourceNOAA, models.SourceCHS}, sources(finder), "UKHO needs an API key")
+
+	finder = NewDefaultStationFinder(config.New(config.WithUKHOAPIKey("key"), config.WithSourcePriority("UKHO,CHS")))
+	assert.Equal(t, []models.Source{models.SourceUKHO, models.SourceCHS, models.SourceNOAA}, sources(finder))
+}
+
+func TestCompositeStationFinder_GetCoalescingStats(t *testing.T) {
+	noaa, _ := compositeTestFinders()
+	chs := NewCHSStationFinder(nil, nil)
+	finder := NewCompositeStationFinder([]StationProvider{
+		{Source: models.SourceNOAA, Finder: noaa},
+		{Source: models.SourceCHS, IDPrefix: CHSStationIDPrefix, Finder: chs},
+	})
+
+	// Finders without counts are left out
+	assert.Equal(t, map[string]uint64{
+		"chs_station_list_fetches":           0,
+		"chs_station_list_fetches_coalesced": 0,
+	}, finder.GetCoalescingStats())
+}
@@ -0,0 +1,216 @@
+package station
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/bbernstein/flowebb/backend-go/internal/cache"
+	"github.com/bbernstein/flowebb/backend-go/internal/coalesce"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/internal/search"
+	"github.com/bbernstein/flowebb/backend-go/internal/spatial"
+)
+
+// Finders each have one station list, so concurrent fetches share one key
+const stationListKey = "stations"
+
+// Refreshes of a stale list have their own key, so one started during a
+// fetch isn't served that fetch's result
+const stationListRefreshKey = "stations-refresh"
+
+// stationListFetchTimeout bounds a shared station list fetch, which runs
+// apart from the request that started it
+const stationListFetchTimeout = time.Minute
+
+// stationList answers station queries from one source's station list, kept
+// in memory with its spatial and search indexes. Finders embed it and
+// supply the fetch that loads the list from their source.
+type stationList struct {
+	// Names the stations in logs and errors, e.g. "CHS station"
+	kind     string
+	memCache *cache.StationCache
+	// Rebuilt whenever the station list is loaded; guarded by cacheMutex
+	searchIndex *search.Index
+	cacheMutex  sync.RWMutex
+	// Coalesces concurrent fetches and refreshes of the station list
+	listFetches coalesce.Group
+	// Loads the list on a cache miss, storing it with setStations
+	fetch func(ctx context.Context) ([]models.Station, error)
+	// Reloads a stale list; fetch is used when nil
+	refresh func(ctx context.Context) ([]models.Station, error)
+}
+
+func newStationList(kind string, memCache *cache.StationCache, fetch func(ctx context.Context) ([]models.Station, error)) *stationList {
+	if memCache == nil {
+		memCache = cache.NewStationCache(nil) // Use default config
+	}
+
+	return &stationList{
+		kind:     kind,
+		memCache: memCache,
+		fetch:    fetch,
+	}
+}
+
+func (l *stationList) FindNearestStations(ctx context.Context, lat, lon float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Validate coordinates
+	if lat < -90 || lat > 90 {
+		return nil, fmt.Errorf("invalid latitude: %f", lat)
+	}
+	if lon < -180 || lon > 180 {
+		return nil, fmt.Errorf("invalid longitude: %f", lon)
+	}
+
+	stations, err := l.getStationList(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting %s list: %w", l.kind, err)
+	}
+
+	// getStationList has just filled the memory cache, so its index is current
+	l.cacheMutex.RLock()
+	defer l.cacheMutex.RUnlock()
+	return nearestFromCache(l.memCache, stations, lat, lon, limit, filter), nil
+}
+
+func (l *stationList) FindStationsInBounds(ctx context.Context, south, west, north, east float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	bounds, err := spatial.NewBounds(south, west, north, east)
+	if err != nil {
+		return nil, err
+	}
+
+	stations, err := l.getStationList(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting %s list: %w", l.kind, err)
+	}
+
+	return spatial.StationsInBounds(filter.Apply(stations), bounds, limit), nil
+}
+
+func (l *stationList) SearchStations(ctx context.Context, query string, near *models.Coordinates, limit int, filter *models.StationFilter) ([]models.Station, error) {
+	if err := validateSearch(query, near, filter); err != nil {
+		return nil, err
+	}
+
+	if _, err := l.getStationList(ctx); err != nil {
+		return nil, fmt.Errorf("getting %s list: %w", l.kind, err)
+	}
+
+	l.cacheMutex.RLock()
+	defer l.cacheMutex.RUnlock()
+	return searchIndex(l.searchIndex, query, near, limit, filter), nil
+}
+
+func (l *stationList) FindStation(ctx context.Context, stationID string) (*models.Station, error) {
+	stations, err := l.getStationList(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting %s list: %w", l.kind, err)
+	}
+
+	for _, station := range stations {
+		if station.ID == stationID {
+			return &station, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s not found: %s", l.kind, stationID)
+}
+
+func (l *stationList) getStationList(ctx context.Context) ([]models.Station, error) {
+	// Check memory cache first
+	l.cacheMutex.RLock()
+	stations := l.memCache.GetStations()
+	l.cacheMutex.RUnlock()
+
+	if stations != nil {
+		log.Debug().Str("stations", l.kind).Msg("Memory cache HIT for station list")
+		l.ensureSearchIndex(stations)
+		if l.memCache.Stale() {
+			l.refreshStationList()
+		}
+		return stations, nil
+	}
+
+	// Concurrent misses share one fetch, which isn't cancelled with the
+	// caller that started it
+	result, err := l.listFetches.DoContext(ctx, stationListKey, stationListFetchTimeout, func(ctx context.Context) (interface{}, error) {
+		return l.fetch(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]models.Station), nil
+}
+
+// refreshStationList fetches a stale station list again in the background,
+// sharing any refresh already under way. The stale list is served meanwhile,
+// and after a failure until the cache drops it at the hard TTL.
+func (l *stationList) refreshStationList() {
+	refresh := l.refresh
+	if refresh == nil {
+		refresh = l.fetch
+	}
+
+	go func() {
+		_, err := l.listFetches.DoContext(context.Background(), stationListRefreshKey, stationListFetchTimeout, func(ctx context.Context) (interface{}, error) {
+			log.Debug().Str("stations", l.kind).Msg("Refreshing stale station list")
+			return refresh(ctx)
+		})
+		if err != nil {
+			log.Warn().Err(err).Str("stations", l.kind).Msg("Error refreshing stale station list")
+		}
+	}()
+}
+
+// setStations fills the memory cache with a freshly fetched station list
+func (l *stationList) setStations(stations []models.Station) {
+	l.setStationsUpdatedAt(stations, time.Now())
+}
+
+// setStationsUpdatedAt fills the memory cache with a station list saved at
+// updatedAt, so a list loaded from a shared cache keeps its staleness
+func (l *stationList) setStationsUpdatedAt(stations []models.Station, updatedAt time.Time) {
+	l.cacheMutex.Lock()
+	defer l.cacheMutex.Unlock()
+	l.memCache.SetStationsUpdatedAt(stations, updatedAt)
+	l.searchIndex = search.NewIndex(stations)
+}
+
+// ensureSearchIndex builds the search index from a cached station list when
+// the finder has none yet, as when another finder filled a shared cache. The
+// write lock is only taken to build it, so cache hits don't serialize.
+func (l *stationList) ensureSearchIndex(stations []models.Station) {
+	l.cacheMutex.RLock()
+	built := l.searchIndex != nil
+	l.cacheMutex.RUnlock()
+	if built {
+		return
+	}
+
+	l.cacheMutex.Lock()
+	defer l.cacheMutex.Unlock()
+	if l.searchIndex == nil {
+		l.searchIndex = search.NewIndex(stations)
+	}
+}
+
+// GetCoalescingStats returns the number of station list fetches made, and of
+// callers served by another caller's fetch
+func (l *stationList) GetCoalescingStats() map[string]uint64 {
+	stats := l.listFetches.Stats()
+	return map[string]uint64{
+		"station_list_fetches":           stats.Calls,
+		"station_list_fetches_coalesced": stats.Coalesced,
+	}
+}
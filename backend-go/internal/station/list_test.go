@@ -0,0 +1,66 @@
+package station
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStationList(t *testing.T) {
+	stations := []models.Station{
+		{ID: "1", Name: "Seattle", Latitude: 47.6026, Longitude: -122.3393},
+		{ID: "2", Name: "Tacoma", Latitude: 47.2690, Longitude: -122.4138},
+	}
+
+	fetches := 0
+	var list *stationList
+	list = newStationList("test station", nil, func(ctx context.Context) ([]models.Station, error) {
+		fetches++
+		list.setStations(stations)
+		return stations, nil
+	})
+	ctx := context.Background()
+
+	t.Run("find station", func(t *testing.T) {
+		station, err := list.FindStation(ctx, "2")
+		require.NoError(t, err)
+		assert.Equal(t, "Tacoma", station.Name)
+	})
+
+	t.Run("station not found", func(t *testing.T) {
+		_, err := list.FindStation(ctx, "3")
+		assert.EqualError(t, err, "test station not found: 3")
+	})
+
+	t.Run("nearest stations", func(t *testing.T) {
+		nearest, err := list.FindNearestStations(ctx, 47.25, -122.4, 1, nil)
+		require.NoError(t, err)
+		require.Len(t, nearest, 1)
+		assert.Equal(t, "2", nearest[0].ID)
+	})
+
+	t.Run("search", func(t *testing.T) {
+		results, err := list.SearchStations(ctx, "seattle", nil, 0, nil)
+		require.NoError(t, err)
+		require.NotEmpty(t, results)
+		assert.Equal(t, "1", results[0].ID)
+	})
+
+	t.Run("list fetched once", func(t *testing.T) {
+		assert.Equal(t, 1, fetches)
+		assert.Equal(t, uint64(1), list.GetCoalescingStats()["station_list_fetches"])
+	})
+}
+
+func TestStationList_FetchError(t *testing.T) {
+	list := newStationList("test station", nil, func(ctx context.Context) ([]models.Station, error) {
+		return nil, errors.New("unavailable")
+	})
+
+	_, err := list.FindStation(context.Background(), "1")
+	assert.EqualError(t, err, "getting test station list: unavailable")
+}
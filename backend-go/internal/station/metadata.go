@@ -0,0 +1,315 @@
+package station
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bbernstein/flowebb/backend-go/internal/config"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/pkg/http/client"
+)
+
+// NOAA station detail resources, relative to the station's mdapi URL
+const (
+	resourceDatums      = "datums.json?units=english"
+	resourceSensors     = "sensors.json"
+	resourceFloodLevels = "floodlevels.json"
+	resourceOffsets     = "tidepredoffsets.json"
+	resourceHarmonics   = "harcon.json?units=english"
+)
+
+type metadataEntry struct {
+	value     interface{} // Nil when NOAA has no such resource for the station
+	expiresAt time.Time
+}
+
+// NOAAMetadataFetcher loads the details NOAA's mdapi publishes for each
+// station. Each resource is fetched the first time it is asked for and kept
+// for as long as the station list, since both change just as rarely.
+type NOAAMetadataFetcher struct {
+	httpClient *client.Client
+	ttl        time.Duration
+	entries    map[string]metadataEntry
+	mu         sync.RWMutex
+	now        func() time.Time
+}
+
+var _ models.StationMetadataFetcher = (*NOAAMetadataFetcher)(nil)
+
+func NewNOAAMetadataFetcher(httpClient *client.Client, cacheConfig *config.CacheConfig) *NOAAMetadataFetcher {
+	// If no config provided, use default config
+	if cacheConfig == nil {
+		cacheConfig = config.GetCacheConfig()
+	}
+
+	return &NOAAMetadataFetcher{
+		httpClient: httpClient,
+		ttl:        cacheConfig.GetStationListTTL(),
+		entries:    make(map[string]metadataEntry),
+		now:        time.Now,
+	}
+}
+
+// Datums returns the station's tidal datums, or nil if it has none
+func (f *NOAAMetadataFetcher) Datums(ctx context.Context, stationID string) (*models.StationDatums, error) {
+	value, err := f.load(ctx, stationID, resourceDatums, decodeDatums)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	return value.(*models.StationDatums), nil
+}
+
+// Sensors returns the station's instruments
+func (f *NOAAMetadataFetcher) Sensors(ctx context.Context, stationID string) ([]models.Sensor, error) {
+	value, err := f.load(ctx, stationID, resourceSensors, decodeSensors)
+	if err != nil || value == nil {
+		return []models.Sensor{}, err
+	}
+	return value.([]models.Sensor), nil
+}
+
+// FloodLevels returns the station's flood thresholds, or nil if none are set
+func (f *NOAAMetadataFetcher) FloodLevels(ctx context.Context, stationID string) (*models.FloodLevels, error) {
+	value, err := f.load(ctx, stationID, resourceFloodLevels, decodeFloodLevels)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	return value.(*models.FloodLevels), nil
+}
+
+// Offsets returns a subordinate station's offsets from its reference
+// station, or nil for a reference station
+func (f *NOAAMetadataFetcher) Offsets(ctx context.Context, stationID string) (*models.TideOffsets, error) {
+	value, err := f.load(ctx, stationID, resourceOffsets, func(body []byte) (interface{}, error) {
+		offsets, err := DecodeTideOffsets(body)
+		if err != nil {
+			return nil, err
+		}
+		// Reference stations have no offsets
+		if offsets.ReferenceStationID == "" {
+			return nil, nil
+		}
+		if err := offsets.Validate(); err != nil {
+			return nil, err
+		}
+		return offsets, nil
+	})
+	if err != nil || value == nil {
+		return nil, err
+	}
+	return value.(*models.TideOffsets), nil
+}
+
+// HarmonicConstituents returns the constituents NOAA predicts the station's
+// tides from. Subordinate stations have none.
+func (f *NOAAMetadataFetcher) HarmonicConstituents(ctx context.Context, stationID string) ([]models.HarmonicConstituent, error) {
+	value, err := f.load(ctx, stationID, resourceHarmonics, decodeHarmonicConstituents)
+	if err != nil || value == nil {
+		return []models.HarmonicConstituent{}, err
+	}
+	return value.([]models.HarmonicConstituent), nil
+}
+
+// Details loads every detail resource for the station
+func (f *NOAAMetadataFetcher) Details(ctx context.Context, station models.Station) (*models.StationDetails, error) {
+	details := &models.StationDetails{Station: station}
+	var err error
+
+	if details.Datums, err = f.Datums(ctx, station.ID); err != nil {
+		return nil, err
+	}
+	if details.Sensors, err = f.Sensors(ctx, station.ID); err != nil {
+		return nil, err
+	}
+	if details.FloodLevels, err = f.FloodLevels(ctx, station.ID); err != nil {
+		return nil, err
+	}
+	if details.HarmonicConstituents, err = f.HarmonicConstituents(ctx, station.ID); err != nil {
+		return nil, err
+	}
+	if details.Offsets == nil {
+		if details.Offsets, err = f.Offsets(ctx, station.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return details, nil
+}
+
+// load returns a resource from the cache, fetching and decoding it on a miss.
+// Resources NOAA doesn't have for the station are cached as nil.
+func (f *NOAAMetadataFetcher) load(ctx context.Context, stationID, resource string, decode func([]byte) (interface{}, error)) (interface{}, error) {
+	key := stationID + "/" + resource
+
+	f.mu.RLock()
+	entry, ok := f.entries[key]
+	f.mu.RUnlock()
+	if ok && entry.expiresAt.After(f.now()) {
+		return entry.value, nil
+	}
+
+	resp, err := f.httpClient.Get(ctx, fmt.Sprintf("/mdapi/prod/webapi/stations/%s/%s", stationID, resource))
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s for station %s: %w", resource, stationID, err)
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("no response from NOAA API")
+	}
+
+	var value interface{}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		log.Debug().Str("station_id", stationID).Str("resource", resource).Msg("Station has no metadata resource")
+	case resp.StatusCode >= http.StatusBadRequest:
+		return nil, fmt.Errorf("fetching %s for station %s: status %d", resource, stationID, resp.StatusCode)
+	default:
+		value, err = decode(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("decoding %s for station %s: %w", resource, stationID, err)
+		}
+	}
+
+	f.mu.Lock()
+	f.entries[key] = metadataEntry{value: value, expiresAt: f.now().Add(f.ttl)}
+	f.mu.Unlock()
+
+	return value, nil
+}
+
+func decodeDatums(body []byte) (interface{}, error) {
+	var noaaDatums struct {
+		Epoch            string              `json:"epoch"`
+		Units            string              `json:"units"`
+		OrthometricDatum string              `json:"OrthometricDatum"`
+		Datums           []models.DatumValue `json:"datums"`
+	}
+	if err := json.Unmarshal(body, &noaaDatums); err != nil {
+		return nil, err
+	}
+	if len(noaaDatums.Datums) == 0 {
+		return nil, nil
+	}
+
+	return &models.StationDatums{
+		Epoch:            noaaDatums.Epoch,
+		Units:            noaaDatums.Units,
+		OrthometricDatum: noaaDatums.OrthometricDatum,
+		Datums:           noaaDatums.Datums,
+	}, nil
+}
+
+func decodeSensors(body []byte) (interface{}, error) {
+	var noaaSensors struct {
+		Sensors []struct {
+			SensorID  string   `json:"sensorID"`
+			Name      string   `json:"name"`
+			DCP       int      `json:"dcp"`
+			Status    int      `json:"status"` // 1 while the sensor is reporting
+			Elevation *float64 `json:"elevation"`
+		} `json:"sensors"`
+	}
+	if err := json.Unmarshal(body, &noaaSensors); err != nil {
+		return nil, err
+	}
+
+	sensors := make([]models.Sensor, len(noaaSensors.Sensors))
+	for i, s := range noaaSensors.Sensors {
+		sensors[i] = models.Sensor{
+			ID:        s.SensorID,
+			Name:      s.Name,
+			DCP:       s.DCP,
+			Active:    s.Status == 1,
+			Elevation: s.Elevation,
+		}
+	}
+	return sensors, nil
+}
+
+// decodeFloodLevels reads NOAA's flood thresholds, which are heights in feet
+// above the station datum
+func decodeFloodLevels(body []byte) (interface{}, error) {
+	var noaaLevels struct {
+		NOSMinor    *float64 `json:"nos_minor"`
+		NOSModerate *float64 `json:"nos_moderate"`
+		NOSMajor    *float64 `json:"nos_major"`
+		NWSMinor    *float64 `json:"nws_minor"`
+		NWSModerate *float64 `json:"nws_moderate"`
+		NWSMajor    *float64 `json:"nws_major"`
+	}
+	if err := json.Unmarshal(body, &noaaLevels); err != nil {
+		return nil, err
+	}
+
+	levels := &models.FloodLevels{
+		Units:       "feet",
+		Datum:       models.DatumSTND,
+		NOSMinor:    noaaLevels.NOSMinor,
+		NOSModerate: noaaLevels.NOSModerate,
+		NOSMajor:    noaaLevels.NOSMajor,
+		NWSMinor:    noaaLevels.NWSMinor,
+		NWSModerate: noaaLevels.NWSModerate,
+		NWSMajor:    noaaLevels.NWSMajor,
+	}
+	if levels.NOSMinor == nil && levels.NOSModerate == nil && levels.NOSMajor == nil &&
+		levels.NWSMinor == nil && levels.NWSModerate == nil && levels.NWSMajor == nil {
+		return nil, nil
+	}
+	return levels, nil
+}
+
+func decodeHarmonicConstituents(body []byte) (interface{}, error) {
+	var noaaHarmonics struct {
+		Constituents []struct {
+			Number      int     `json:"number"`
+			Name        string  `json:"name"`
+			Description string  `json:"description"`
+			Amplitude   float64 `json:"amplitude"`
+			PhaseGMT    float64 `json:"phase_GMT"`
+			PhaseLocal  float64 `json:"phase_local"`
+			Speed       float64 `json:"speed"`
+		} `json:"HarmonicConstituents"`
+	}
+	if err := json.Unmarshal(body, &noaaHarmonics); err != nil {
+		return nil, err
+	}
+
+	constituents := make([]models.HarmonicConstituent, len(noaaHarmonics.Constituents))
+	for i, c := range noaaHarmonics.Constituents {
+		constituents[i] = models.HarmonicConstituent(c)
+	}
+	return constituents, nil
+}
+
+// noaaTideOffsets is the body of NOAA's tidepredoffsets.json resource
+type noaaTideOffsets struct {
+	RefStationID         string  `json:"refStationId"`
+	HeightOffsetHighTide float64 `json:"heightOffsetHighTide"`
+	HeightOffsetLowTide  float64 `json:"heightOffsetLowTide"`
+	TimeOffsetHighTide   float64 `json:"timeOffsetHighTide"`
+	TimeOffsetLowTide    float64 `json:"timeOffsetLowTide"`
+	HeightAdjustedType   string  `json:"heightAdjustedType"`
+}
+
+// DecodeTideOffsets reads NOAA's tidepredoffsets.json resource. The offsets
+// are not validated.
+func DecodeTideOffsets(body []byte) (*models.TideOffsets, error) {
+	var noaaOffsets noaaTideOffsets
+	if err := json.Unmarshal(body, &noaaOffsets); err != nil {
+		return nil, err
+	}
+
+	return &models.TideOffsets{
+		ReferenceStationID: noaaOffsets.RefStationID,
+		HighTimeOffset:     int(math.Round(noaaOffsets.TimeOffsetHighTide)),
+		LowTimeOffset:      int(math.Round(noaaOffsets.TimeOffsetLowTide)),
+		HighHeightOffset:   noaaOffsets.HeightOffsetHighTide,
+		LowHeightOffset:    noaaOffsets.HeightOffsetLowTide,
+		HeightAdjustment:   models.HeightAdjustment(noaaOffsets.HeightAdjustedType),
+	}, nil
+}
@@ -0,0 +1,210 @@
+package station
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/pkg/http/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Trimmed responses from NOAA's mdapi for Seattle (9447130) and its
+// subordinate station at Shilshole Bay (9447265)
+var metadataFixtures = map[string]string{
+	"/mdapi/prod/webapi/stations/9447130/datums.json": `{
+		"epoch": "1983-2001", "units": "feet", "OrthometricDatum": "NAVD88",
+		"datums": [
+			{"name": "MHHW", "description": "Mean Higher-High Water", "value": 18.05},
+			{"name": "MSL", "description": "Mean Sea Level", "value": 13.39},
+			{"name": "MLLW", "description": "Mean Lower-Low Water", "value": 6.64}
+		]}`,
+	"/mdapi/prod/webapi/stations/9447130/sensors.json": `{"sensors": [
+		{"sensorID": "A1", "name": "Aquatrak", "dcp": 1, "status": 1, "elevation": 4.2},
+		{"sensorID": "D1", "name": "Wind", "dcp": 1, "status": 0}
+	]}`,
+	"/mdapi/prod/webapi/stations/9447130/floodlevels.json": `{
+		"nos_minor": 20.2, "nos_moderate": 20.9, "nos_major": null,
+		"nws_minor": 20.5, "nws_moderate": null, "nws_major": null}`,
+	"/mdapi/prod/webapi/stations/9447130/tidepredoffsets.json": `{"refStationId": ""}`,
+	"/mdapi/prod/webapi/stations/9447130/harcon.json": `{"HarmonicConstituents": [
+		{"number": 1, "name": "M2", "description": "Principal lunar semidiurnal constituent",
+		 "amplitude": 3.51, "phase_GMT": 11.2, "phase_local": 251.1, "speed": 28.984104}
+	]}`,
+	"/mdapi/prod/webapi/stations/9447265/floodlevels.json": `{}`,
+	"/mdapi/prod/webapi/stations/9447265/tidepredoffsets.json": `{
+		"refStationId": "9447130", "heightOffsetHighTide": 0.98, "heightOffsetLowTide": 0.97,
+		"timeOffsetHighTide": 4, "timeOffsetLowTide": 5.6, "heightAdjustedType": "R"}`,
+}
+
+func newMetadataTestServer(t *testing.T) (*httptest.Server, map[string]int) {
+	requests := make(map[string]int)
+	var mu sync.Mutex
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+
+		if strings.HasSuffix(r.URL.Path, "/9999999/sensors.json") {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		body, ok := metadataFixtures[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, requests
+}
+
+func newTestMetadataFetcher(srv *httptest.Server) *NOAAMetadataFetcher {
+	return NewNOAAMetadataFetcher(client.New(client.Options{
+		BaseURL:    srv.URL,
+		Timeout:    5 * time.Second,
+		MaxRetries: 1,
+	}), nil)
+}
+
+func TestNOAAMetadataFetcher_Resources(t *testing.T) {
+	srv, _ := newMetadataTestServer(t)
+	fetcher := newTestMetadataFetcher(srv)
+	ctx := context.Background()
+
+	t.Run("datums", func(t *testing.T) {
+		datums, err := fetcher.Datums(ctx, "9447130")
+		require.NoError(t, err)
+		require.NotNil(t, datums)
+		assert.Equal(t, "1983-2001", datums.Epoch)
+		assert.Equal(t, "NAVD88", datums.OrthometricDatum)
+		assert.Len(t, datums.Datums, 3)
+		mllw, ok := datums.Value("MLLW")
+		assert.True(t, ok)
+		assert.Equal(t, 6.64, mllw)
+	})
+
+	t.Run("sensors", func(t *testing.T) {
+		sensors, err := fetcher.Sensors(ctx, "9447130")
+		require.NoError(t, err)
+		require.Len(t, sensors, 2)
+		assert.Equal(t, "A1", sensors[0].ID)
+		assert.True(t, sensors[0].Active)
+		require.NotNil(t, sensors[0].Elevation)
+		assert.Equal(t, 4.2, *sensors[0].Elevation)
+		assert.False(t, sensors[1].Active)
+		assert.Nil(t, sensors[1].Elevation)
+	})
+
+	t.Run("flood levels", func(t *testing.T) {
+		levels, err := fetcher.FloodLevels(ctx, "9447130")
+		require.NoError(t, err)
+		require.NotNil(t, levels)
+		assert.Equal(t, models.DatumSTND, levels.Datum)
+		assert.Equal(t, 20.2, *levels.NOSMinor)
+		assert.Nil(t, levels.NOSMajor)
+		assert.Equal(t, 20.5, *levels.NWSMinor)
+	})
+
+	t.Run("harmonic constituents", func(t *testing.T) {
+		constituents, err := fetcher.HarmonicConstituents(ctx, "9447130")
+		require.NoError(t, err)
+		require.Len(t, constituents, 1)
+		assert.Equal(t, "M2", constituents[0].Name)
+		assert.Equal(t, 11.2, constituents[0].PhaseGMT)
+		assert.Equal(t, 28.984104, constituents[0].Speed)
+	})
+
+	t.Run("reference station has no offsets", func(t *testing.T) {
+		offsets, err := fetcher.Offsets(ctx, "9447130")
+		require.NoError(t, err)
+		assert.Nil(t, offsets)
+	})
+
+	t.Run("subordinate station offsets", func(t *testing.T) {
+		offsets, err := fetcher.Offsets(ctx, "9447265")
+		require.NoError(t, err)
+		require.NotNil(t, offsets)
+		assert.Equal(t, "9447130", offsets.ReferenceStationID)
+		assert.Equal(t, 4, offsets.HighTimeOffset)
+		assert.Equal(t, 6, offsets.LowTimeOffset)
+		assert.Equal(t, models.HeightAdjustmentRatio, offsets.HeightAdjustment)
+	})
+
+	t.Run("missing resources are empty", func(t *testing.T) {
+		datums, err := fetcher.Datums(ctx, "9447265")
+		require.NoError(t, err)
+		assert.Nil(t, datums)
+
+		levels, err := fetcher.FloodLevels(ctx, "9447265")
+		require.NoError(t, err)
+		assert.Nil(t, levels)
+
+		constituents, err := fetcher.HarmonicConstituents(ctx, "9447265")
+		require.NoError(t, err)
+		assert.Empty(t, constituents)
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		_, err := fetcher.Sensors(ctx, "9999999")
+		assert.EqualError(t, err, "fetching sensors.json for station 9999999: status 500")
+	})
+}
+
+func TestNOAAMetadataFetcher_Caching(t *testing.T) {
+	srv, requests := newMetadataTestServer(t)
+	fetcher := newTestMetadataFetcher(srv)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	fetcher.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	const datumsPath = "/mdapi/prod/webapi/stations/9447130/datums.json"
+	const missingPath = "/mdapi/prod/webapi/stations/9447265/datums.json"
+
+	// Nothing is fetched until asked for
+	assert.Empty(t, requests)
+
+	for i := 0; i < 3; i++ {
+		_, err := fetcher.Datums(ctx, "9447130")
+		require.NoError(t, err)
+		_, err = fetcher.Datums(ctx, "9447265")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, requests[datumsPath])
+	assert.Equal(t, 1, requests[missingPath], "missing resources are cached too")
+	assert.Len(t, requests, 2)
+
+	now = now.Add(fetcher.ttl + time.Second)
+	_, err := fetcher.Datums(ctx, "9447130")
+	require.NoError(t, err)
+	assert.Equal(t, 2, requests[datumsPath])
+}
+
+func TestNOAAMetadataFetcher_Details(t *testing.T) {
+	srv, _ := newMetadataTestServer(t)
+	fetcher := newTestMetadataFetcher(srv)
+
+	details, err := fetcher.Details(context.Background(), models.Station{ID: "9447130", Name: "Seattle"})
+	require.NoError(t, err)
+	assert.Equal(t, "Seattle", details.Name)
+	assert.NotNil(t, details.Datums)
+	assert.Len(t, details.Sensors, 2)
+	assert.NotNil(t, details.FloodLevels)
+	assert.Len(t, details.HarmonicConstituents, 1)
+	assert.Nil(t, details.Offsets)
+
+	details, err = fetcher.Details(context.Background(), models.Station{ID: "9447265"})
+	require.NoError(t, err)
+	require.NotNil(t, details.Offsets)
+	assert.Equal(t, "9447130", details.Offsets.ReferenceStationID)
+}
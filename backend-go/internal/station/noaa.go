@@ -5,140 +5,132 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/rs/zerolog/log"
-	"math"
 	"sort"
 	"strconv"
-	"sync"
+	"strings"
+	"time"
 
 	"github.com/bbernstein/flowebb/backend-go/internal/cache"
+	"github.com/bbernstein/flowebb/backend-go/internal/config"
 	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/internal/search"
+	"github.com/bbernstein/flowebb/backend-go/internal/spatial"
+	"github.com/bbernstein/flowebb/backend-go/internal/timezone"
 	"github.com/bbernstein/flowebb/backend-go/pkg/http/client"
 )
 
-type FinderFactory interface {
-	NewFinder(httpClient *client.Client, memCache *cache.StationCache) (*NOAAStationFinder, error)
-}
-
-type DefaultFinderFactory struct{}
+// Number of stations returned by nearest station queries without a limit
+const defaultNearestLimit = 5
 
-func (f *DefaultFinderFactory) NewFinder(httpClient *client.Client, memCache *cache.StationCache) (*NOAAStationFinder, error) {
-	return NewNOAAStationFinder(httpClient, memCache)
-}
+// Number of stations returned by text searches without a limit
+const defaultSearchLimit = 10
 
 type NOAAStationFinder struct {
+	*stationList
 	httpClient *client.Client
-	memCache   *cache.StationCache
 	s3Cache    cache.StationListCacheProvider
-	cacheMutex sync.RWMutex
 }
 
 var _ models.StationFinder = (*NOAAStationFinder)(nil)
 
-func NewNOAAStationFinder(httpClient *client.Client, memCache *cache.StationCache) (*NOAAStationFinder, error) {
-	if memCache == nil {
-		memCache = cache.NewStationCache(nil) // Use default config
-	}
+type NOAAOption func(*NOAAStationFinder)
 
-	return &NOAAStationFinder{
-		httpClient: httpClient,
-		memCache:   memCache,
-	}, nil
+// WithS3Cache keeps the station list in a shared cache, such as an S3
+// bucket, so cold starts needn't fetch it from NOAA
+func WithS3Cache(s3Cache cache.StationListCacheProvider) NOAAOption {
+	return func(f *NOAAStationFinder) {
+		f.s3Cache = s3Cache
+	}
 }
 
-func (f *NOAAStationFinder) FindNearestStations(ctx context.Context, lat, lon float64, limit int) ([]models.Station, error) {
-	// Validate coordinates
-	if lat < -90 || lat > 90 {
-		return nil, fmt.Errorf("invalid latitude: %f", lat)
-	}
-	if lon < -180 || lon > 180 {
-		return nil, fmt.Errorf("invalid longitude: %f", lon)
+// StationListCacheOptions returns the options keeping the station list in
+// the configured S3 bucket. There are none when no bucket is configured or
+// its client can't be created, and the list is fetched from NOAA instead.
+func StationListCacheOptions(ctx context.Context, cacheConfig *config.CacheConfig) []NOAAOption {
+	if cacheConfig == nil || cacheConfig.StationListBucket == "" {
+		return nil
 	}
 
-	// Get all stations
-	stations, err := f.getStationList(ctx)
+	s3Cache, err := cache.NewS3StationCache(ctx, cacheConfig)
 	if err != nil {
-		return nil, fmt.Errorf("getting station list: %w", err)
+		log.Warn().Err(err).Msg("Station list S3 cache unavailable")
+		return nil
 	}
+	return []NOAAOption{WithS3Cache(s3Cache)}
+}
 
-	// Calculate distances and sort
-	type stationDistance struct {
-		station  models.Station
-		distance float64
+func NewNOAAStationFinder(httpClient *client.Client, memCache *cache.StationCache, opts ...NOAAOption) (*NOAAStationFinder, error) {
+	f := &NOAAStationFinder{httpClient: httpClient}
+	f.stationList = newStationList("station", memCache, f.fetchStationList)
+	f.refresh = f.refreshStationList
+	for _, opt := range opts {
+		opt(f)
 	}
+	return f, nil
+}
 
-	stationDistances := make([]stationDistance, len(stations))
-	for i, station := range stations {
-		distance := calculateDistance(lat, lon, station.Latitude, station.Longitude)
-		stationDistances[i] = stationDistance{
-			station:  station,
-			distance: distance,
+// fetchStationList loads the station list from the S3 cache, or from NOAA
+// when it isn't there, and fills the memory cache with it. A stale list from
+// S3 is served while it's refreshed.
+func (f *NOAAStationFinder) fetchStationList(ctx context.Context) ([]models.Station, error) {
+	if stations := f.loadS3StationList(ctx); stations != nil {
+		if f.memCache.Stale() {
+			f.stationList.refreshStationList()
 		}
+		return stations, nil
 	}
-
-	// Sort by distance
-	sort.Slice(stationDistances, func(i, j int) bool {
-		return stationDistances[i].distance < stationDistances[j].distance
-	})
-
-	// Limit results and convert back to Station slice
-	if limit <= 0 {
-		limit = 5 // Default limit if not specified
-	}
-	if limit > len(stationDistances) {
-		limit = len(stationDistances)
-	}
-
-	result := make([]models.Station, limit)
-	for i := 0; i < limit; i++ {
-		station := stationDistances[i].station
-		station.Distance = stationDistances[i].distance // Add distance to result
-		result[i] = station
-	}
-
-	return result, nil
+	return f.fetchNOAAStationList(ctx)
 }
 
-func (f *NOAAStationFinder) FindStation(ctx context.Context, stationID string) (*models.Station, error) {
-	stations, err := f.getStationList(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("getting station list: %w", err)
-	}
-
-	for _, station := range stations {
-		if station.ID == stationID {
-			return &station, nil
-		}
+// refreshStationList reloads a stale station list from S3 if another
+// instance has already refreshed it there, or else from NOAA
+func (f *NOAAStationFinder) refreshStationList(ctx context.Context) ([]models.Station, error) {
+	if stations := f.loadS3StationList(ctx); stations != nil && !f.memCache.Stale() {
+		return stations, nil
 	}
-
-	return nil, fmt.Errorf("station not found: %s", stationID)
+	return f.fetchNOAAStationList(ctx)
 }
 
-func (f *NOAAStationFinder) getStationList(ctx context.Context) ([]models.Station, error) {
-	// Check memory cache first
-	f.cacheMutex.RLock()
-	stations := f.memCache.GetStations()
-	f.cacheMutex.RUnlock()
-
-	if stations != nil {
-		log.Debug().Msg("Memory cache HIT for station list")
-		return stations, nil
+// loadS3StationList fills the memory cache from the S3 cache, keeping the
+// time the list was saved so its staleness carries over. It returns nil when
+// S3 has no list.
+func (f *NOAAStationFinder) loadS3StationList(ctx context.Context) []models.Station {
+	if f.s3Cache == nil {
+		return nil
 	}
 
-	// Check S3 cache if available
-	if f.s3Cache != nil {
-		stations, err := f.s3Cache.GetStations(ctx)
+	var stations []models.Station
+	updatedAt := time.Now()
+	if records, ok := f.s3Cache.(cache.StationListRecordProvider); ok {
+		record, err := records.GetStationListRecord(ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("Error getting stations from S3 cache")
+			return nil
+		}
+		if record != nil {
+			stations = record.Stations
+			updatedAt = time.Unix(record.LastUpdated, 0)
+		}
+	} else {
+		var err error
+		stations, err = f.s3Cache.GetStations(ctx)
 		if err != nil {
 			log.Error().Err(err).Msg("Error getting stations from S3 cache")
-		} else if stations != nil {
-			log.Debug().Msg("S3 cache HIT for station list")
-			// Update memory cache
-			f.cacheMutex.Lock()
-			f.memCache.SetStations(stations)
-			f.cacheMutex.Unlock()
-			return stations, nil
+			return nil
 		}
 	}
+	if stations == nil {
+		return nil
+	}
+
+	log.Debug().Msg("S3 cache HIT for station list")
+	f.setStationsUpdatedAt(stations, updatedAt)
+	return stations
+}
 
+// fetchNOAAStationList fetches the station list from NOAA, saves it to S3
+// and fills the memory cache with it
+func (f *NOAAStationFinder) fetchNOAAStationList(ctx context.Context) ([]models.Station, error) {
 	log.Debug().Msg("Cache MISS for station list, fetching from NOAA API")
 
 	// Fetch from NOAA API
@@ -168,8 +160,10 @@ func (f *NOAAStationFinder) getStationList(ctx context.Context) ([]models.Statio
 		return nil, fmt.Errorf("decoding response: %w", err)
 	}
 
+	sensorCapabilities := f.fetchSensorCapabilities(ctx)
+
 	// Convert to Station objects
-	stations = make([]models.Station, len(noaaResp.Stations))
+	stations := make([]models.Station, len(noaaResp.Stations))
 	for i, s := range noaaResp.Stations {
 		var level, stationType *string
 		if s.Level != "" {
@@ -181,6 +175,7 @@ func (f *NOAAStationFinder) getStationList(ctx context.Context) ([]models.Statio
 			stationType = &stationTypeValue
 		}
 
+		timeZoneOffset := parseTimeZoneOffset(s.TimeZoneCorr)
 		stations[i] = models.Station{
 			ID:             s.ID,
 			Name:           s.Name,
@@ -189,8 +184,9 @@ func (f *NOAAStationFinder) getStationList(ctx context.Context) ([]models.Statio
 			Latitude:       s.Lat,
 			Longitude:      s.Lon,
 			Source:         models.SourceNOAA,
-			Capabilities:   []string{"WATER_LEVEL"},
-			TimeZoneOffset: parseTimeZoneOffset(s.TimeZoneCorr),
+			Capabilities:   append([]string{models.CapabilityWaterLevel}, sensorCapabilities[s.ID]...),
+			TimeZoneOffset: timeZoneOffset,
+			TimeZone:       timezone.ZoneName(s.Lat, s.Lon, timeZoneOffset),
 			Level:          level,
 			StationType:    stationType,
 		}
@@ -205,13 +201,130 @@ func (f *NOAAStationFinder) getStationList(ctx context.Context) ([]models.Statio
 		}()
 	}
 
-	f.cacheMutex.Lock()
-	f.memCache.SetStations(stations)
-	f.cacheMutex.Unlock()
-
+	f.setStations(stations)
 	return stations, nil
 }
 
+// sensorStationTypes maps NOAA metadata station types to the capability of
+// the sensor stations of that type have
+var sensorStationTypes = []struct {
+	stationType string
+	capability  string
+}{
+	{"wind", models.CapabilityWind},
+	{"airpressure", models.CapabilityAirPressure},
+	{"watertemp", models.CapabilityWaterTemperature},
+	{"airtemp", models.CapabilityAirTemperature},
+}
+
+// fetchSensorCapabilities returns the met sensor capabilities of each station
+// by ID. A sensor type that cannot be listed is left out rather than failing
+// the whole station list.
+func (f *NOAAStationFinder) fetchSensorCapabilities(ctx context.Context) map[string][]string {
+	capabilities := make(map[string][]string)
+	for _, sensor := range sensorStationTypes {
+		resp, err := f.httpClient.Get(ctx, "/mdapi/prod/webapi/stations.json?type="+sensor.stationType)
+		if err != nil {
+			log.Warn().Err(err).Str("type", sensor.stationType).Msg("Error fetching sensor stations")
+			continue
+		}
+
+		var sensorResp struct {
+			Stations []struct {
+				ID string `json:"id"`
+			} `json:"stations"`
+		}
+		if err := json.Unmarshal(resp.Body, &sensorResp); err != nil {
+			log.Warn().Err(err).Str("type", sensor.stationType).Msg("Error decoding sensor stations")
+			continue
+		}
+
+		for _, station := range sensorResp.Stations {
+			capabilities[station.ID] = append(capabilities[station.ID], sensor.capability)
+		}
+	}
+	return capabilities
+}
+
+// nearestFromCache returns up to limit stations passing the filter ordered by
+// distance from the point using the cache's spatial index, or by scanning
+// stations if the cache has expired.
+func nearestFromCache(memCache *cache.StationCache, stations []models.Station, lat, lon float64, limit int, filter *models.StationFilter) []models.Station {
+	if limit <= 0 {
+		limit = defaultNearestLimit
+	}
+	if nearest, ok := memCache.NearestStations(lat, lon, limit, filter); ok {
+		return nearest
+	}
+	return nearestStations(filter.Apply(stations), lat, lon, limit)
+}
+
+// nearestStations returns up to limit stations ordered by distance from the
+// point, with their distances filled in. It scans every station.
+func nearestStations(stations []models.Station, lat, lon float64, limit int) []models.Station {
+	type stationDistance struct {
+		station  models.Station
+		distance float64
+	}
+
+	stationDistances := make([]stationDistance, len(stations))
+	for i, station := range stations {
+		distance := calculateDistance(lat, lon, station.Latitude, station.Longitude)
+		stationDistances[i] = stationDistance{
+			station:  station,
+			distance: distance,
+		}
+	}
+
+	// Sort by distance
+	sort.Slice(stationDistances, func(i, j int) bool {
+		return stationDistances[i].distance < stationDistances[j].distance
+	})
+
+	// Limit results and convert back to Station slice
+	if limit <= 0 {
+		limit = defaultNearestLimit
+	}
+	if limit > len(stationDistances) {
+		limit = len(stationDistances)
+	}
+
+	result := make([]models.Station, limit)
+	for i := 0; i < limit; i++ {
+		station := stationDistances[i].station
+		station.Distance = stationDistances[i].distance // Add distance to result
+		result[i] = station
+	}
+
+	return result
+}
+
+// validateSearch checks the query, the point to favor results near and the
+// filter
+func validateSearch(query string, near *models.Coordinates, filter *models.StationFilter) error {
+	if strings.TrimSpace(query) == "" {
+		return fmt.Errorf("search query is required")
+	}
+	if near != nil {
+		if near.Latitude < -90 || near.Latitude > 90 {
+			return fmt.Errorf("invalid latitude: %f", near.Latitude)
+		}
+		if near.Longitude < -180 || near.Longitude > 180 {
+			return fmt.Errorf("invalid longitude: %f", near.Longitude)
+		}
+	}
+	return filter.Validate()
+}
+
+// searchIndex runs a text search, returning defaultSearchLimit results when
+// no limit is given
+func searchIndex(idx *search.Index, query string, near *models.Coordinates, limit int, filter *models.StationFilter) []models.Station {
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	return idx.Search(query, near, limit, filter)
+}
+
 func parseTimeZoneOffset(tzCorr string) int {
 	offset, err := strconv.Atoi(tzCorr)
 	if err != nil {
@@ -221,17 +334,5 @@ func parseTimeZoneOffset(tzCorr string) int {
 }
 
 func calculateDistance(lat1, lon1, lat2, lon2 float64) float64 {
-	const earthRadius = 6371.0 // km
-
-	dLat := toRadians(lat2 - lat1)
-	dLon := toRadians(lon2 - lon1)
-	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
-		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*
-			math.Sin(dLon/2)*math.Sin(dLon/2)
-	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
-	return earthRadius * c
-}
-
-func toRadians(deg float64) float64 {
-	return deg * math.Pi / 180
+	return spatial.Distance(lat1, lon1, lat2, lon2)
 }
@@ -0,0 +1,102 @@
+package station
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"strings"
+
+	"github.com/bbernstein/flowebb/backend-go/internal/cache"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/internal/timezone"
+	"github.com/bbernstein/flowebb/backend-go/pkg/http/client"
+)
+
+// NOAACurrentStationFinder finds NOAA current prediction stations. They are
+// kept apart from the tide station list because they have no water level
+// predictions.
+type NOAACurrentStationFinder struct {
+	*stationList
+	httpClient *client.Client
+}
+
+var _ models.StationFinder = (*NOAACurrentStationFinder)(nil)
+
+func NewNOAACurrentStationFinder(httpClient *client.Client, memCache *cache.StationCache) *NOAACurrentStationFinder {
+	f := &NOAACurrentStationFinder{httpClient: httpClient}
+	f.stationList = newStationList("current station", memCache, f.fetchStationList)
+	return f
+}
+
+// fetchStationList fetches the current station list from NOAA and fills the
+// memory cache with it
+func (f *NOAACurrentStationFinder) fetchStationList(ctx context.Context) ([]models.Station, error) {
+	log.Debug().Msg("Cache MISS for current station list, fetching from NOAA API")
+
+	resp, err := f.httpClient.Get(ctx, "/mdapi/prod/webapi/stations.json?type=currentpredictions")
+	if err != nil {
+		return nil, fmt.Errorf("fetching current stations: %w", err)
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("no response from NOAA API")
+	}
+
+	var noaaResp struct {
+		Stations []struct {
+			ID             string          `json:"id"`
+			Name           string          `json:"name"`
+			State          string          `json:"state"`
+			Lat            float64         `json:"lat"`
+			Lng            float64         `json:"lng"`
+			Type           string          `json:"type"`
+			CurrentBin     *int            `json:"currbin"`
+			TimeZoneOffset json.RawMessage `json:"timezone_offset"` // Hours, sometimes quoted
+		} `json:"stations"`
+	}
+
+	if err := json.Unmarshal(resp.Body, &noaaResp); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	stations := make([]models.Station, len(noaaResp.Stations))
+	for i, s := range noaaResp.Stations {
+		var state, stationType *string
+		if s.State != "" {
+			stateValue := s.State
+			state = &stateValue
+		}
+		if s.Type != "" {
+			stationTypeValue := s.Type
+			stationType = &stationTypeValue
+		}
+
+		timeZoneOffset := parseTimeZoneOffset(strings.Trim(string(s.TimeZoneOffset), `"`))
+		stations[i] = models.Station{
+			ID:             s.ID,
+			Name:           s.Name,
+			State:          state,
+			Latitude:       s.Lat,
+			Longitude:      s.Lng,
+			Source:         models.SourceNOAA,
+			Capabilities:   []string{models.CapabilityTidalCurrents},
+			TimeZoneOffset: timeZoneOffset,
+			TimeZone:       timezone.ZoneName(s.Lat, s.Lng, timeZoneOffset),
+			StationType:    stationType,
+			CurrentBin:     s.CurrentBin,
+		}
+	}
+
+	f.setStations(stations)
+	return stations, nil
+}
+
+// GetCoalescingStats returns the number of current station list fetches
+// made, and of callers served by another caller's fetch
+func (f *NOAACurrentStationFinder) GetCoalescingStats() map[string]uint64 {
+	stats := f.listFetches.Stats()
+	return map[string]uint64{
+		"current_station_list_fetches":           stats.Calls,
+		"current_station_list_fetches_coalesced": stats.Coalesced,
+	}
+}
@@ -0,0 +1,88 @@
+package station
+
+import (
+	"context"
+	"fmt"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bbernstein/flowebb/backend-go/pkg/http/client"
+)
+
+const currentStationsResponse = `{"count":3,"units":null,"stations":[
+	{"id":"PUG1515","name":"Tacoma Narrows","lat":47.2750,"lng":-122.5500,"type":"H","currbin":11,"timezone_offset":"-8","state":"WA"},
+	{"id":"PUG1524","name":"Point Defiance","lat":47.3050,"lng":-122.5267,"type":"S","currbin":1,"timezone_offset":-8},
+	{"id":"ACT0091","name":"Eastport","lat":44.9000,"lng":-66.9833,"type":"S","timezone_offset":"-5","state":"ME"}
+]}`
+
+func TestNOAACurrentStationFinder(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "/mdapi/prod/webapi/stations.json", r.URL.Path)
+		assert.Equal(t, "currentpredictions", r.URL.Query().Get("type"))
+		_, _ = fmt.Fprint(w, currentStationsResponse)
+	}))
+	defer srv.Close()
+
+	finder := NewNOAACurrentStationFinder(client.New(client.Options{
+		BaseURL: srv.URL,
+		Timeout: 5 * time.Second,
+	}), nil)
+
+	t.Run("find station", func(t *testing.T) {
+		station, err := finder.FindStation(context.Background(), "PUG1515")
+		require.NoError(t, err)
+
+		assert.Equal(t, "Tacoma Narrows", station.Name)
+		assert.Equal(t, []string{models.CapabilityTidalCurrents}, station.Capabilities)
+		assert.Equal(t, -8*3600, station.TimeZoneOffset)
+		require.NotNil(t, station.CurrentBin)
+		assert.Equal(t, 11, *station.CurrentBin)
+		require.NotNil(t, station.State)
+		assert.Equal(t, "WA", *station.State)
+	})
+
+	t.Run("unquoted offset and missing state", func(t *testing.T) {
+		station, err := finder.FindStation(context.Background(), "PUG1524")
+		require.NoError(t, err)
+
+		assert.Equal(t, -8*3600, station.TimeZoneOffset)
+		assert.Nil(t, station.State)
+	})
+
+	t.Run("missing bin", func(t *testing.T) {
+		station, err := finder.FindStation(context.Background(), "ACT0091")
+		require.NoError(t, err)
+		assert.Nil(t, station.CurrentBin)
+	})
+
+	t.Run("unknown station", func(t *testing.T) {
+		_, err := finder.FindStation(context.Background(), "9447130")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "current station not found")
+	})
+
+	t.Run("nearest stations", func(t *testing.T) {
+		stations, err := finder.FindNearestStations(context.Background(), 47.28, -122.55, 2, nil)
+		require.NoError(t, err)
+		require.Len(t, stations, 2)
+
+		assert.Equal(t, "PUG1515", stations[0].ID)
+		assert.Equal(t, "PUG1524", stations[1].ID)
+		assert.Less(t, stations[0].Distance, stations[1].Distance)
+	})
+
+	t.Run("invalid coordinates", func(t *testing.T) {
+		_, err := finder.FindNearestStations(context.Background(), 91, 0, 1, nil)
+		require.Error(t, err)
+	})
+
+	// The list is fetched once and served from memory afterwards
+	assert.Equal(t, 1, requests)
+}
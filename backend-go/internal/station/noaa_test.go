@@ -1,14 +1,21 @@
 package station
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/bbernstein/flowebb/backend-go/internal/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"io"
+	"math/rand"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
@@ -53,6 +60,7 @@ func createTestStation(id string) models.Station {
 		Source:         models.SourceNOAA,
 		Capabilities:   []string{"WATER_LEVEL"},
 		TimeZoneOffset: -8 * 3600,
+		TimeZone:       "America/Los_Angeles",
 		Level:          &level,
 		StationType:    &stationType,
 	}
@@ -283,7 +291,7 @@ func TestFindNearestStations(t *testing.T) {
 			finder, err := NewNOAAStationFinder(httpClient, nil)
 			require.NoError(t, err)
 
-			got, err := finder.FindNearestStations(context.Background(), tt.lat, tt.lon, tt.limit)
+			got, err := finder.FindNearestStations(context.Background(), tt.lat, tt.lon, tt.limit, nil)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -311,6 +319,230 @@ func TestFindNearestStations(t *testing.T) {
 	}
 }
 
+func TestFindStationsInBounds(t *testing.T) {
+	stations := []models.Station{
+		createTestStation("SEATTLE"),
+		createTestStation("PORTLAND"),
+		createTestStation("ADAK"),
+		createTestStation("ATTU"),
+	}
+	stations[1].Latitude, stations[1].Longitude = 45.5155, -122.6789
+	stations[2].Latitude, stations[2].Longitude = 51.8633, -176.6320
+	stations[3].Latitude, stations[3].Longitude = 52.8300, 173.1800
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(createNOAAResponse(stations)))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name                     string
+		south, west, north, east float64
+		limit                    int
+		want                     []string
+		wantErr                  bool
+	}{
+		{"Puget Sound", 47, -123, 48, -122, 0, []string{"SEATTLE"}, false},
+		{"Pacific Northwest, closest to center first", 45, -124, 48, -122, 0, []string{"PORTLAND", "SEATTLE"}, false},
+		{"limit", 45, -124, 48, -122, 1, []string{"PORTLAND"}, false},
+		{"across the antimeridian", 50, 170, 55, -170, 0, []string{"ADAK", "ATTU"}, false},
+		{"empty viewport", 0, -140, 1, -139, 0, []string{}, false},
+		{"south above north", 48, -123, 47, -122, 0, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := client.New(client.Options{
+				BaseURL: srv.URL,
+				Timeout: 5 * time.Second,
+			})
+
+			finder, err := NewNOAAStationFinder(httpClient, nil)
+			require.NoError(t, err)
+
+			got, err := finder.FindStationsInBounds(context.Background(), tt.south, tt.west, tt.north, tt.east, tt.limit, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			ids := make([]string, len(got))
+			for i, s := range got {
+				ids[i] = s.ID
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
+
+func TestSearchStations(t *testing.T) {
+	stations := []models.Station{
+		createTestStation("9447130"),
+		createTestStation("9446484"),
+		createTestStation("9444900"),
+	}
+	stations[0].Name = "Seattle"
+	stations[1].Name, stations[1].Latitude, stations[1].Longitude = "Tacoma", 47.2690, -122.4130
+	stations[2].Name, stations[2].Latitude, stations[2].Longitude = "Port Townsend", 48.1129, -122.7595
+
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/mdapi/prod/webapi/tidepredstations.json" {
+			requests++
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(createNOAAResponse(stations)))
+	}))
+	defer srv.Close()
+
+	httpClient := client.New(client.Options{
+		BaseURL: srv.URL,
+		Timeout: 5 * time.Second,
+	})
+	finder, err := NewNOAAStationFinder(httpClient, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		query   string
+		near    *models.Coordinates
+		limit   int
+		want    []string
+		wantErr bool
+	}{
+		{name: "typo in the name", query: "Seatle", want: []string{"9447130"}},
+		{name: "region matches every station, nearest first", query: "puget", near: &models.Coordinates{Latitude: 48.1, Longitude: -122.8}, want: []string{"9444900", "9447130", "9446484"}},
+		{name: "limit", query: "puget", near: &models.Coordinates{Latitude: 48.1, Longitude: -122.8}, limit: 1, want: []string{"9444900"}},
+		{name: "no match", query: "Boston", want: []string{}},
+		{name: "blank query", query: "  ", wantErr: true},
+		{name: "invalid near", query: "Seattle", near: &models.Coordinates{Latitude: 91}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := finder.SearchStations(context.Background(), tt.query, tt.near, tt.limit, nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			ids := make([]string, len(got))
+			for i, s := range got {
+				ids[i] = s.ID
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+
+	// The index is built once, when the station list loads
+	assert.Equal(t, 1, requests)
+
+	t.Run("index built from a shared memory cache", func(t *testing.T) {
+		memCache := cache.NewStationCache(nil)
+		memCache.SetStations(stations)
+
+		finder, err := NewNOAAStationFinder(httpClient, memCache)
+		require.NoError(t, err)
+
+		// Concurrent hits share the index built by the first
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				got, err := finder.SearchStations(context.Background(), "tacoma", nil, 0, nil)
+				assert.NoError(t, err)
+				if assert.Len(t, got, 1) {
+					assert.Equal(t, "9446484", got[0].ID)
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, 1, requests)
+	})
+}
+
+func TestFindNearestStations_Filter(t *testing.T) {
+	stations := []models.Station{
+		createTestStation("REFERENCE"),
+		createTestStation("SUBORDINATE"),
+		createTestStation("MAINE"),
+	}
+	subordinate := models.StationTypeSubordinate
+	stations[1].StationType = &subordinate
+	stations[1].Latitude -= 0.01
+	maine := "ME"
+	stations[2].State = &maine
+	stations[2].Latitude, stations[2].Longitude = 43.6567, -70.2467
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(createNOAAResponse(stations)))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		filter  *models.StationFilter
+		want    []string
+		wantErr bool
+	}{
+		{"no filter", nil, []string{"SUBORDINATE", "REFERENCE"}, false},
+		{"reference stations only", &models.StationFilter{StationType: models.StationTypeReference}, []string{"REFERENCE", "MAINE"}, false},
+		{"state", &models.StationFilter{State: "ME"}, []string{"MAINE"}, false},
+		{"nothing matches", &models.StationFilter{Source: models.SourceCHS}, []string{}, false},
+		{"invalid filter", &models.StationFilter{StationType: "X"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpClient := client.New(client.Options{
+				BaseURL: srv.URL,
+				Timeout: 5 * time.Second,
+			})
+
+			finder, err := NewNOAAStationFinder(httpClient, nil)
+			require.NoError(t, err)
+
+			got, err := finder.FindNearestStations(context.Background(), 47.6, -122.3, 2, tt.filter)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			ids := make([]string, len(got))
+			for i, s := range got {
+				ids[i] = s.ID
+			}
+			assert.Equal(t, tt.want, ids)
+
+			// Bounding box and text searches filter the same way
+			var wantAll []string
+			for _, s := range tt.filter.Apply(stations) {
+				wantAll = append(wantAll, s.ID)
+			}
+
+			inBounds, err := finder.FindStationsInBounds(context.Background(), 40, -125, 50, -70, 0, tt.filter)
+			require.NoError(t, err)
+			assert.Len(t, inBounds, len(wantAll))
+			for _, s := range inBounds {
+				assert.Contains(t, wantAll, s.ID)
+			}
+
+			found, err := finder.SearchStations(context.Background(), "test station", nil, 0, tt.filter)
+			require.NoError(t, err)
+			assert.Len(t, found, len(wantAll))
+			for _, s := range found {
+				assert.Contains(t, wantAll, s.ID)
+			}
+		})
+	}
+}
+
 func TestParseTimeZoneOffset(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -486,13 +718,66 @@ func BenchmarkFindNearestStations(b *testing.B) {
 	lat, lon := 47.6062, -122.3321
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, err := finder.FindNearestStations(context.Background(), lat, lon, 2)
+		_, err := finder.FindNearestStations(context.Background(), lat, lon, 2, nil)
 		if err != nil {
 			b.Fatal(err)
 		}
 	}
 }
 
+// benchmarkStations spreads about as many stations as NOAA lists over the
+// coasts of North America and the Pacific
+func benchmarkStations(n int) []models.Station {
+	r := rand.New(rand.NewSource(1))
+	stations := make([]models.Station, n)
+	for i := range stations {
+		stations[i] = models.Station{
+			ID:        fmt.Sprintf("%07d", i),
+			Latitude:  r.Float64()*60 + 10,
+			Longitude: r.Float64()*120 - 180,
+			Source:    models.SourceNOAA,
+		}
+	}
+	return stations
+}
+
+func BenchmarkNearestStations_LinearScan(b *testing.B) {
+	stations := benchmarkStations(3000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		nearestStations(stations, 47.6062, -122.3321, 5)
+	}
+}
+
+func BenchmarkNearestStations_SpatialIndex(b *testing.B) {
+	stations := benchmarkStations(3000)
+	memCache := cache.NewStationCache(nil)
+	memCache.SetStations(stations)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		nearestFromCache(memCache, stations, 47.6062, -122.3321, 5, nil)
+	}
+}
+
+func TestNearestFromCache(t *testing.T) {
+	stations := benchmarkStations(3000)
+	memCache := cache.NewStationCache(nil)
+	memCache.SetStations(stations)
+
+	for _, limit := range []int{0, 1, 5, 25} {
+		t.Run(fmt.Sprintf("limit %d", limit), func(t *testing.T) {
+			assert.Equal(t, nearestStations(stations, 47.6062, -122.3321, limit),
+				nearestFromCache(memCache, stations, 47.6062, -122.3321, limit, nil))
+		})
+	}
+
+	t.Run("expired cache scans the list", func(t *testing.T) {
+		expired := cache.NewStationCache(&config.CacheConfig{StationListTTLDays: 0})
+		got := nearestFromCache(expired, stations[:2], 47.6062, -122.3321, 5, nil)
+		assert.Len(t, got, 2)
+	})
+}
+
 func BenchmarkParseTimeZoneOffset(b *testing.B) {
 	offset := "-8"
 	b.ResetTimer()
@@ -579,3 +864,263 @@ func TestS3CacheScenarios(t *testing.T) {
 		})
 	}
 }
+
+// memoryS3 is an in-memory stand-in for S3, keyed by bucket and key
+type memoryS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+var _ cache.S3Client = (*memoryS3)(nil)
+
+func newMemoryS3() *memoryS3 {
+	return &memoryS3{objects: map[string][]byte{}}
+}
+
+func (m *memoryS3) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	body, ok := m.objects[aws.ToString(params.Bucket)+"/"+aws.ToString(params.Key)]
+	if !ok {
+		return nil, &types.NoSuchKey{}
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(body))}, nil
+}
+
+func (m *memoryS3) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	body, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[aws.ToString(params.Bucket)+"/"+aws.ToString(params.Key)] = body
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (m *memoryS3) len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.objects)
+}
+
+func TestS3StationCache_ColdStart(t *testing.T) {
+	testStation := createTestStation("TEST001")
+
+	var mu sync.Mutex
+	stationListRequests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/mdapi/prod/webapi/tidepredstations.json" {
+			mu.Lock()
+			stationListRequests++
+			mu.Unlock()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(createNOAAResponse([]models.Station{testStation})))
+	}))
+	defer srv.Close()
+
+	bucket := newMemoryS3()
+	s3Cache := cache.NewS3StationCacheWithClient(bucket, &config.CacheConfig{
+		StationListBucket:  "station-list",
+		StationListTTLDays: 1,
+	})
+	newFinder := func() *NOAAStationFinder {
+		finder, err := NewNOAAStationFinder(client.New(client.Options{
+			BaseURL: srv.URL,
+			Timeout: 5 * time.Second,
+		}), cache.NewStationCache(nil), WithS3Cache(s3Cache))
+		require.NoError(t, err)
+		return finder
+	}
+
+	// The first instance fetches the list from NOAA and keeps it in S3
+	station, err := newFinder().FindStation(context.Background(), "TEST001")
+	require.NoError(t, err)
+	assert.Equal(t, testStation.Name, station.Name)
+	require.Eventually(t, func() bool { return bucket.len() == 1 }, 2*time.Second, 10*time.Millisecond)
+
+	// A cold instance, with nothing in memory, reads it from S3
+	station, err = newFinder().FindStation(context.Background(), "TEST001")
+	require.NoError(t, err)
+	assert.Equal(t, testStation.Name, station.Name)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, stationListRequests)
+}
+
+func TestGetStationList_CoalescesConcurrentMisses(t *testing.T) {
+	testStation := createTestStation("TEST001")
+
+	release := make(chan struct{})
+	var mu sync.Mutex
+	stationListRequests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/mdapi/prod/webapi/tidepredstations.json" {
+			mu.Lock()
+			stationListRequests++
+			mu.Unlock()
+			<-release
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(createNOAAResponse([]models.Station{testStation})))
+	}))
+	defer srv.Close()
+
+	finder, err := NewNOAAStationFinder(client.New(client.Options{
+		BaseURL: srv.URL,
+		Timeout: 5 * time.Second,
+	}), cache.NewStationCache(nil))
+	require.NoError(t, err)
+
+	const callers = 10
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			station, err := finder.FindStation(context.Background(), "TEST001")
+			assert.NoError(t, err)
+			if station != nil {
+				assert.Equal(t, testStation.Name, station.Name)
+			}
+		}()
+	}
+
+	// Hold NOAA's response until every caller has joined the first fetch
+	require.Eventually(t, func() bool {
+		return finder.GetCoalescingStats()["station_list_fetches_coalesced"] == callers-1
+	}, 2*time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, stationListRequests)
+	assert.Equal(t, map[string]uint64{
+		"station_list_fetches":           1,
+		"station_list_fetches_coalesced": callers - 1,
+	}, finder.GetCoalescingStats())
+}
+
+func TestGetStationList_ServesStaleWhileRefreshing(t *testing.T) {
+	testStation := createTestStation("TEST001")
+
+	var mu sync.Mutex
+	noaaDown := true
+	stationListRequests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		down := noaaDown
+		if r.URL.Path == "/mdapi/prod/webapi/tidepredstations.json" {
+			stationListRequests++
+		}
+		mu.Unlock()
+		if down {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(createNOAAResponse([]models.Station{testStation})))
+	}))
+	defer srv.Close()
+
+	// S3 holds a list saved three days ago, past the soft TTL but not the hard
+	cacheConfig := &config.CacheConfig{
+		StationListBucket:      "station-list",
+		StationListTTLDays:     1,
+		StationListHardTTLDays: 7,
+	}
+	saved := time.Now().Add(-3 * 24 * time.Hour)
+	staleStation := createTestStation("TEST001")
+	staleStation.Name = "Old Name"
+	record, err := json.Marshal(cache.StationListCacheRecord{
+		Stations:    []models.Station{staleStation},
+		LastUpdated: saved.Unix(),
+		TTL:         saved.Add(24 * time.Hour).Unix(),
+		HardTTL:     saved.Add(7 * 24 * time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+	bucket := newMemoryS3()
+	_, err = bucket.PutObject(context.Background(), &s3.PutObjectInput{
+		Bucket: aws.String("station-list"),
+		Key:    aws.String("stations.json"),
+		Body:   bytes.NewReader(record),
+	})
+	require.NoError(t, err)
+
+	finder, err := NewNOAAStationFinder(client.New(client.Options{
+		BaseURL:    srv.URL,
+		Timeout:    5 * time.Second,
+		MaxRetries: 1,
+	}), cache.NewStationCache(cacheConfig), WithS3Cache(cache.NewS3StationCacheWithClient(bucket, cacheConfig)))
+	require.NoError(t, err)
+
+	// With NOAA down the stale list is still served, and kept after the
+	// refresh fails
+	station, err := finder.FindStation(context.Background(), "TEST001")
+	require.NoError(t, err)
+	assert.Equal(t, "Old Name", station.Name)
+	require.Eventually(t, func() bool {
+		return finder.GetCoalescingStats()["station_list_fetches"] == 2
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.True(t, finder.memCache.Stale())
+
+	// Once NOAA is back the next request's refresh replaces it
+	mu.Lock()
+	noaaDown = false
+	mu.Unlock()
+	station, err = finder.FindStation(context.Background(), "TEST001")
+	require.NoError(t, err)
+	assert.Equal(t, "Old Name", station.Name)
+	require.Eventually(t, func() bool { return !finder.memCache.Stale() }, 2*time.Second, 10*time.Millisecond)
+
+	station, err = finder.FindStation(context.Background(), "TEST001")
+	require.NoError(t, err)
+	assert.Equal(t, testStation.Name, station.Name)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 2, stationListRequests)
+}
+
+func TestStationListCacheOptions(t *testing.T) {
+	assert.Empty(t, StationListCacheOptions(context.Background(), nil))
+	assert.Empty(t, StationListCacheOptions(context.Background(), &config.CacheConfig{StationListTTLDays: 1}))
+}
+
+func TestSensorCapabilities(t *testing.T) {
+	testStations := []models.Station{createTestStation("9447130"), createTestStation("9414305")}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("type") {
+		case "":
+			_, _ = w.Write([]byte(createNOAAResponse(testStations)))
+		case "wind", "airtemp":
+			_, _ = w.Write([]byte(`{"count":1,"stations":[{"id":"9447130","name":"Seattle"}]}`))
+		case "watertemp":
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`<html>error</html>`))
+		default:
+			_, _ = w.Write([]byte(`{"count":0,"stations":[]}`))
+		}
+	}))
+	defer srv.Close()
+
+	finder, err := NewNOAAStationFinder(client.New(client.Options{
+		BaseURL: srv.URL,
+		Timeout: 5 * time.Second,
+	}), nil)
+	require.NoError(t, err)
+
+	seattle, err := finder.FindStation(context.Background(), "9447130")
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.CapabilityWaterLevel, models.CapabilityWind, models.CapabilityAirTemperature}, seattle.Capabilities)
+
+	// Stations without sensors only have predictions
+	other, err := finder.FindStation(context.Background(), "9414305")
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.CapabilityWaterLevel}, other.Capabilities)
+}
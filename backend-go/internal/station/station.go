@@ -1 +1,28 @@
 package station
+
+import (
+	"github.com/rs/zerolog/log"
+
+	"github.com/bbernstein/flowebb/backend-go/internal/timezone"
+)
+
+// zoneForPosition returns the time zone name and standard offset for a
+// station at the position. The position decides the zone, as for NOAA
+// stations; the agency's own zone name is only a fallback for stations
+// outside the known regions, and is dropped when it isn't a known zone.
+func zoneForPosition(lat, lon float64, fallback string) (string, int) {
+	zoneName, ok := timezone.Lookup(lat, lon)
+	if !ok {
+		zoneName = fallback
+	}
+	location := timezone.Location(zoneName, 0)
+	if location.String() != zoneName {
+		log.Warn().
+			Str("zone", fallback).
+			Float64("lat", lat).
+			Float64("lon", lon).
+			Msg("No time zone region for station, using UTC")
+		zoneName = ""
+	}
+	return zoneName, timezone.StandardOffset(location)
+}
@@ -0,0 +1,140 @@
+package station
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"strings"
+	"unicode"
+
+	"github.com/bbernstein/flowebb/backend-go/internal/cache"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/pkg/http/client"
+)
+
+// UKHOStationIDPrefix namespaces Admiralty station IDs, which would otherwise
+// be indistinguishable from other agencies' numeric IDs
+const UKHOStationIDPrefix = "UKHO-"
+
+// UKHOAPIKeyHeader carries the Admiralty API subscription key. Clients for
+// the UKHO finder and prediction fetcher send it with every request.
+const UKHOAPIKeyHeader = "Ocp-Apim-Subscription-Key"
+
+// UKHOStationFinder finds the UK Hydrographic Office's tide stations through
+// the Admiralty Tidal API. The API only publishes high and low waters.
+type UKHOStationFinder struct {
+	*stationList
+	httpClient *client.Client
+}
+
+var _ models.StationFinder = (*UKHOStationFinder)(nil)
+
+// NewUKHOStationFinder creates a finder for Admiralty stations. The client's
+// base URL is the Tidal API's, e.g.
+// https://admiraltyapi.azure-api.net/uktidalapi, and it must send the
+// subscription key in the UKHOAPIKeyHeader header.
+func NewUKHOStationFinder(httpClient *client.Client, memCache *cache.StationCache) *UKHOStationFinder {
+	f := &UKHOStationFinder{httpClient: httpClient}
+	f.stationList = newStationList("UKHO station", memCache, f.fetchStationList)
+	return f
+}
+
+// fetchStationList fetches the station list from the Admiralty API and fills
+// the memory cache with it
+func (f *UKHOStationFinder) fetchStationList(ctx context.Context) ([]models.Station, error) {
+	log.Debug().Msg("Cache MISS for UKHO station list, fetching from Admiralty API")
+
+	resp, err := f.httpClient.Get(ctx, "/api/V1/Stations")
+	if err != nil {
+		return nil, fmt.Errorf("fetching UKHO stations: %w", err)
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("no response from UKHO API")
+	}
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("fetching UKHO stations: status %d", resp.StatusCode)
+	}
+
+	var collection struct {
+		Features []ukhoStation `json:"features"`
+	}
+	if err := json.Unmarshal(resp.Body, &collection); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	stations := make([]models.Station, 0, len(collection.Features))
+	for _, s := range collection.Features {
+		if station, ok := s.toStation(); ok {
+			stations = append(stations, station)
+		}
+	}
+
+	f.setStations(stations)
+	return stations, nil
+}
+
+// ukhoStation is one GeoJSON feature in the Admiralty station list
+type ukhoStation struct {
+	Geometry struct {
+		Coordinates []float64 `json:"coordinates"` // Longitude, latitude
+	} `json:"geometry"`
+	Properties struct {
+		ID      string `json:"Id"`
+		Name    string `json:"Name"`
+		Country string `json:"Country"`
+	} `json:"properties"`
+}
+
+// toStation converts the station, reporting false for features without an ID
+// or position. Stations have no gauge data and no station type, as the API
+// doesn't say which are standard and which secondary ports.
+func (s ukhoStation) toStation() (models.Station, bool) {
+	if s.Properties.ID == "" || len(s.Geometry.Coordinates) < 2 {
+		return models.Station{}, false
+	}
+	lat, lon := s.Geometry.Coordinates[1], s.Geometry.Coordinates[0]
+
+	zoneName, offset := zoneForPosition(lat, lon, "Europe/London")
+
+	var region *string
+	if s.Properties.Country != "" {
+		country := s.Properties.Country
+		region = &country
+	}
+
+	return models.Station{
+		ID:             UKHOStationIDPrefix + s.Properties.ID,
+		Name:           ukhoStationName(s.Properties.Name),
+		Region:         region,
+		Latitude:       lat,
+		Longitude:      lon,
+		Source:         models.SourceUKHO,
+		Capabilities:   []string{},
+		TimeZoneOffset: offset,
+		TimeZone:       zoneName,
+	}, true
+}
+
+// ukhoStationName converts the API's upper-case names to title case, e.g.
+// "ST. MARY'S (ISLES OF SCILLY)" to "St. Mary's (Isles Of Scilly)"
+func ukhoStationName(name string) string {
+	runes := []rune(strings.ToLower(strings.TrimSpace(name)))
+	startOfWord := true
+	for i, r := range runes {
+		if startOfWord && unicode.IsLetter(r) {
+			runes[i] = unicode.ToUpper(r)
+		}
+		startOfWord = unicode.IsSpace(r) || r == '(' || r == '-' || r == '/'
+	}
+	return string(runes)
+}
+
+// UKHOStationCode returns the Admiralty ID of a station ID, and whether the
+// ID is a UKHO station's
+func UKHOStationCode(stationID string) (string, bool) {
+	if !strings.HasPrefix(stationID, UKHOStationIDPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(stationID, UKHOStationIDPrefix), true
+}
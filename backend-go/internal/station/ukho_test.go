@@ -0,0 +1,163 @@
+package station
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/pkg/http/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUKHOStationFinder(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/ukho_stations.json")
+	require.NoError(t, err)
+
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "/api/V1/Stations", r.URL.Path)
+		if r.Header.Get(UKHOAPIKeyHeader) != "test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(fixture)
+	}))
+	defer srv.Close()
+
+	finder := NewUKHOStationFinder(client.New(client.Options{
+		BaseURL: srv.URL,
+		Timeout: 5 * time.Second,
+		Headers: map[string]string{UKHOAPIKeyHeader: "test-key"},
+	}), nil)
+	ctx := context.Background()
+
+	t.Run("find station", func(t *testing.T) {
+		station, err := finder.FindStation(ctx, "UKHO-0014")
+		require.NoError(t, err)
+
+		assert.Equal(t, "Plymouth (Devonport)", station.Name)
+		assert.Equal(t, models.SourceUKHO, station.Source)
+		assert.InDelta(t, 50.3683, station.Latitude, 1e-9)
+		assert.InDelta(t, -4.185, station.Longitude, 1e-9)
+		assert.Equal(t, "Europe/London", station.TimeZone)
+		assert.Equal(t, 0, station.TimeZoneOffset)
+		require.NotNil(t, station.Region)
+		assert.Equal(t, "England", *station.Region)
+		assert.Nil(t, station.State)
+		assert.Nil(t, station.StationType)
+		assert.Empty(t, station.Capabilities)
+		assert.False(t, station.HasObservations())
+		assert.NoError(t, station.Validate())
+	})
+
+	t.Run("time zones", func(t *testing.T) {
+		tests := []struct {
+			id   string
+			zone string
+		}{
+			{"UKHO-0224", "Europe/London"},
+			{"UKHO-0490", "Europe/London"},
+			{"UKHO-0634", "Europe/Dublin"},
+			{"UKHO-1604", "Europe/Jersey"},
+		}
+		for _, tt := range tests {
+			station, err := finder.FindStation(ctx, tt.id)
+			require.NoError(t, err)
+			assert.Equal(t, tt.zone, station.TimeZone, tt.id)
+		}
+	})
+
+	t.Run("features without an ID are left out", func(t *testing.T) {
+		_, err := finder.FindStation(ctx, "UKHO-")
+		assert.EqualError(t, err, "UKHO station not found: UKHO-")
+	})
+
+	t.Run("IDs are namespaced", func(t *testing.T) {
+		_, err := finder.FindStation(ctx, "0014")
+		assert.Error(t, err)
+	})
+
+	t.Run("nearest stations", func(t *testing.T) {
+		// Off Start Point: Plymouth, then Jersey across the Channel
+		stations, err := finder.FindNearestStations(ctx, 50.2, -3.6, 2, nil)
+		require.NoError(t, err)
+		require.Len(t, stations, 2)
+		assert.Equal(t, "UKHO-0014", stations[0].ID)
+		assert.Equal(t, "UKHO-1604", stations[1].ID)
+	})
+
+	t.Run("in bounds", func(t *testing.T) {
+		stations, err := finder.FindStationsInBounds(ctx, 53, -7, 56, -5, 0, nil)
+		require.NoError(t, err)
+		ids := make([]string, len(stations))
+		for i, s := range stations {
+			ids[i] = s.ID
+		}
+		assert.ElementsMatch(t, []string{"UKHO-0490", "UKHO-0634"}, ids)
+	})
+
+	t.Run("search", func(t *testing.T) {
+		stations, err := finder.SearchStations(ctx, "st marys", nil, 0, nil)
+		require.NoError(t, err)
+		require.NotEmpty(t, stations)
+		assert.Equal(t, "UKHO-0001", stations[0].ID)
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		stations, err := finder.FindNearestStations(ctx, 54, -4, 10, &models.StationFilter{Source: models.SourceUKHO, Region: "Scotland"})
+		require.NoError(t, err)
+		require.Len(t, stations, 1)
+		assert.Equal(t, "UKHO-0224", stations[0].ID)
+	})
+
+	// The list is fetched once and served from memory afterwards
+	assert.Equal(t, 1, requests)
+}
+
+func TestUKHOStationFinder_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The Admiralty API's response to a missing or invalid key
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	finder := NewUKHOStationFinder(client.New(client.Options{
+		BaseURL:    srv.URL,
+		Timeout:    5 * time.Second,
+		MaxRetries: 1,
+	}), nil)
+
+	_, err := finder.FindStation(context.Background(), "UKHO-0014")
+	assert.EqualError(t, err, "getting UKHO station list: fetching UKHO stations: status 401")
+}
+
+func TestUKHOStationName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"PLYMOUTH (DEVONPORT)", "Plymouth (Devonport)"},
+		{"ST. MARY'S (ISLES OF SCILLY)", "St. Mary's (Isles Of Scilly)"},
+		{"BURNHAM-ON-CROUCH", "Burnham-On-Crouch"},
+		{" LEITH ", "Leith"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ukhoStationName(tt.name))
+	}
+}
+
+func TestUKHOStationCode(t *testing.T) {
+	code, ok := UKHOStationCode("UKHO-0014")
+	assert.True(t, ok)
+	assert.Equal(t, "0014", code)
+
+	_, ok = UKHOStationCode("CHS-07795")
+	assert.False(t, ok)
+}
@@ -0,0 +1,355 @@
+package tide
+
+import (
+	"context"
+	"fmt"
+	"github.com/bbernstein/flowebb/backend-go/internal/astronomy"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/internal/timezone"
+	"github.com/rs/zerolog/log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Number of stations blended when no count is given
+const defaultBlendStations = 3
+
+// MaxBlendStations is the most stations a blended estimate can be made from
+const MaxBlendStations = 10
+
+// Weights fall off with the square of the distance from the point
+const blendDistancePower = 2
+
+// A station this close to the point, in kilometers, is taken to be at it
+const blendExactDistanceKm = 0.01
+
+// Extremes at neighbouring stations further apart than this aren't the same tide
+const maxExtremeMatchGap = 3 * time.Hour
+
+// Interval of blended predictions, as for interpolated extremes
+const blendIntervalMillis = 6 * 60 * 1000
+
+// blendedCurve is one station's whole-day curve, in feet
+type blendedCurve struct {
+	station     models.Station
+	predictions []models.TidePrediction // Nil when only extremes are published
+	extremes    []models.TideExtreme
+	freshness   models.DataFreshness
+	weight      float64
+	offsets     []timeOffset // From the blended highs and lows to the curve's, in time order
+}
+
+// timeOffset is how far after the blended high or low at a time the curve's
+// matching one is, in milliseconds
+type timeOffset struct {
+	at     int64
+	offset int64
+}
+
+// heightAt returns the curve's height at a time
+func (c *blendedCurve) heightAt(timestamp int64) float64 {
+	if c.predictions == nil {
+		return interpolateExtremes(c.extremes, timestamp)
+	}
+	return interpolatePredictions(c.predictions, timestamp)
+}
+
+// alignedHeightAt returns the curve's height at the same stage of the tide
+// as the blended curve at a time, so stations whose tides arrive at
+// different times rise and fall together rather than flattening the blend
+func (c *blendedCurve) alignedHeightAt(timestamp int64) float64 {
+	return c.heightAt(timestamp + c.offsetAt(timestamp))
+}
+
+// offsetAt returns the curve's time offset at a time, interpolated between
+// its matched highs and lows and held beyond the first and last
+func (c *blendedCurve) offsetAt(timestamp int64) int64 {
+	if len(c.offsets) == 0 {
+		return 0
+	}
+	idx := sort.Search(len(c.offsets), func(i int) bool { return c.offsets[i].at >= timestamp })
+	if idx == 0 {
+		return c.offsets[0].offset
+	}
+	if idx == len(c.offsets) {
+		return c.offsets[idx-1].offset
+	}
+	prev, next := c.offsets[idx-1], c.offsets[idx]
+	if next.at == prev.at {
+		return prev.offset
+	}
+	ratio := float64(timestamp-prev.at) / float64(next.at-prev.at)
+	return prev.offset + int64(math.Round(float64(next.offset-prev.offset)*ratio))
+}
+
+// GetBlendedTide estimates the tide at a point from the stationCount nearest
+// stations, defaulting to 3. The times and heights of their matching highs
+// and lows are weighted by inverse distance. Each station's curve is then
+// shifted to line its highs and lows up with the blended ones, and the
+// heights weighted the same way. Stations whose predictions can't be had are
+// left out. Local times follow the nearest station's zone.
+func (s *Service) GetBlendedTide(ctx context.Context, lat, lon float64, stationCount int, startTimeStr, endTimeStr *string, datum models.Datum, units models.Units) (*models.ExtendedTideResponse, error) {
+	if lat < -90 || lat > 90 {
+		return nil, fmt.Errorf("invalid latitude: %f", lat)
+	}
+	if lon < -180 || lon > 180 {
+		return nil, fmt.Errorf("invalid longitude: %f", lon)
+	}
+	if stationCount <= 0 {
+		stationCount = defaultBlendStations
+	}
+	if stationCount > MaxBlendStations {
+		return nil, fmt.Errorf("cannot blend more than %d stations", MaxBlendStations)
+	}
+
+	datum = datum.OrDefault()
+	if err := datum.Validate(); err != nil {
+		return nil, err
+	}
+	units = units.OrDefault()
+	if err := units.Validate(); err != nil {
+		return nil, err
+	}
+
+	stations, err := s.StationFinder.FindNearestStations(ctx, lat, lon, stationCount, nil)
+	if err != nil {
+		return nil, fmt.Errorf("finding nearest stations: %w", err)
+	}
+	if len(stations) == 0 {
+		return nil, fmt.Errorf("no stations found near coordinates")
+	}
+
+	nearest := stations[0]
+	location := timezone.ForStation(&nearest)
+	now := time.Now().In(location)
+
+	startTime, endTime, err := parseTimeRange(startTimeStr, endTimeStr, now, location)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate date range
+	daysDataAllowed := time.Duration(30)
+	if endTime.Sub(startTime) > daysDataAllowed*24*time.Hour {
+		return nil, NewInvalidRangeError(fmt.Sprintf("date range cannot exceed %d days", daysDataAllowed))
+	}
+
+	curves, err := s.getBlendedCurves(ctx, stations, startTime, endTime, datum)
+	if err != nil {
+		return nil, err
+	}
+	// The nearest station left in the blend stands for the point
+	nearest = curves[0].station
+
+	// Blend whole days, as the summaries cover them. The extremes are blended
+	// first, as the curves are aligned by them.
+	allExtremes := blendExtremes(curves, location)
+	allPredictions := blendPredictions(curves, startOfDay(startTime, location).UnixMilli(),
+		startOfDay(endTime, location).AddDate(0, 0, 1).UnixMilli(), location)
+
+	startTimestamp := startTime.Unix() * 1000
+	endTimestamp := endTime.Unix() * 1000
+	filteredPredictions := filterTimestamps(allPredictions, startTimestamp, endTimestamp)
+	filteredExtremes := filterExtremes(allExtremes, startTimestamp, endTimestamp)
+
+	var level float64
+	for _, c := range curves {
+		level += c.weight * c.alignedHeightAt(now.UnixMilli())
+	}
+
+	// Heights are blended in feet, then converted for the response
+	filteredPredictions, filteredExtremes = models.ConvertHeights(filteredPredictions, filteredExtremes, models.UnitsFeet, units)
+	level = models.ConvertHeight(level, models.UnitsFeet, units)
+
+	// The response describes the point; an observation at one station isn't
+	// the water level there, so none is given
+	response, err := newTideResponse(&nearest, now, level, filteredPredictions, filteredExtremes, "blended", datum, units)
+	if err != nil {
+		return nil, err
+	}
+	response.Latitude = lat
+	response.Longitude = lon
+	response.BlendedStations = make([]models.BlendedStation, len(curves))
+	var freshness models.DataFreshness
+	for i, c := range curves {
+		freshness = freshness.Merge(c.freshness)
+		response.BlendedStations[i] = models.BlendedStation{
+			StationID: c.station.ID,
+			Name:      c.station.Name,
+			Source:    c.station.Source,
+			Distance:  c.station.Distance,
+			Weight:    c.weight,
+		}
+	}
+	response.Freshness = &freshness
+
+	if s.Conditions != nil {
+		conditions, err := s.Conditions.GetConditions(ctx, &nearest, units)
+		if err != nil {
+			// Conditions are supplementary, so the tide response is still returned
+			log.Warn().Err(err).
+				Str("station_id", nearest.ID).
+				Msg("Error fetching met conditions")
+		}
+		response.Conditions = conditions
+	}
+
+	summaryPredictions, summaryExtremes := models.ConvertHeights(allPredictions, allExtremes, models.UnitsFeet, units)
+	response.Days = summarizeDays(summaryPredictions, summaryExtremes, startTime, endTime, location)
+	response.Astronomy = astronomy.Days(lat, lon, startTime, endTime, location)
+
+	return response, nil
+}
+
+// getBlendedCurves fetches the stations' curves for the range at once and
+// weights them. The nearest station's error is returned when none succeed.
+func (s *Service) getBlendedCurves(ctx context.Context, stations []models.Station, startTime, endTime time.Time, datum models.Datum) ([]*blendedCurve, error) {
+	// A station at the point needs no blending
+	if stations[0].Distance < blendExactDistanceKm {
+		stations = stations[:1]
+	}
+
+	curves := make([]*blendedCurve, len(stations))
+	errs := make([]error, len(stations))
+	var wg sync.WaitGroup
+	for i := range stations {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			station := stations[i]
+			location := timezone.ForStation(&station)
+			predictions, extremes, _, freshness, err := s.getTideCurve(ctx, &station, startTime.In(location), endTime.In(location), location, datum)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			curves[i] = &blendedCurve{station: station, predictions: predictions, extremes: extremes, freshness: freshness}
+		}(i)
+	}
+	wg.Wait()
+
+	result := make([]*blendedCurve, 0, len(curves))
+	for i, c := range curves {
+		if errs[i] != nil {
+			log.Warn().Err(errs[i]).
+				Str("station_id", stations[i].ID).
+				Msg("Leaving station out of blend")
+			continue
+		}
+		result = append(result, c)
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("getting predictions for %s: %w", stations[0].ID, errs[0])
+	}
+
+	distances := make([]float64, len(result))
+	for i, c := range result {
+		distances[i] = c.station.Distance
+	}
+	for i, w := range inverseDistanceWeights(distances) {
+		result[i].weight = w
+	}
+	return result, nil
+}
+
+// inverseDistanceWeights returns weights summing to 1 for the distances,
+// falling off with the square of the distance
+func inverseDistanceWeights(distances []float64) []float64 {
+	weights := make([]float64, len(distances))
+	var total float64
+	for i, d := range distances {
+		weights[i] = 1 / math.Pow(math.Max(d, blendExactDistanceKm), blendDistancePower)
+		total += weights[i]
+	}
+	for i := range weights {
+		weights[i] /= total
+	}
+	return weights
+}
+
+// blendPredictions blends the curves' heights at even intervals from start
+// up to end, each curve aligned by the offsets blendExtremes recorded
+func blendPredictions(curves []*blendedCurve, start, end int64, location *time.Location) []models.TidePrediction {
+	predictions := make([]models.TidePrediction, 0, (end-start)/blendIntervalMillis+1)
+	for t := start; t <= end; t += blendIntervalMillis {
+		var height float64
+		for _, c := range curves {
+			height += c.weight * c.alignedHeightAt(t)
+		}
+		predictions = append(predictions, models.TidePrediction{
+			Timestamp: t,
+			LocalTime: formatLocalTime(t, location),
+			Height:    roundHeight(height),
+		})
+	}
+	return predictions
+}
+
+// blendExtremes blends each of the first curve's highs and lows with the
+// matching high or low of the other curves, the nearest of the same type
+// within maxExtremeMatchGap. Curves without a match are left out of that
+// extreme's blend. Each curve's offsets are set to how far its matched highs
+// and lows are from the blended ones.
+func blendExtremes(curves []*blendedCurve, location *time.Location) []models.TideExtreme {
+	reference := curves[0]
+	for _, c := range curves {
+		c.offsets = nil
+	}
+
+	extremes := make([]models.TideExtreme, 0, len(reference.extremes))
+	matches := make([]int64, len(curves))
+	for _, e := range reference.extremes {
+		totalWeight := reference.weight
+		timestamp := reference.weight * float64(e.Timestamp)
+		height := reference.weight * e.Height
+		matches[0] = e.Timestamp
+		for i, c := range curves[1:] {
+			matches[i+1] = -1
+			match, ok := matchingExtreme(c.extremes, e)
+			if !ok {
+				continue
+			}
+			matches[i+1] = match.Timestamp
+			totalWeight += c.weight
+			timestamp += c.weight * float64(match.Timestamp)
+			height += c.weight * match.Height
+		}
+
+		// Times are rounded to the minute, as published
+		blendedTime := time.UnixMilli(int64(timestamp / totalWeight)).Round(time.Minute).UnixMilli()
+		extremes = append(extremes, models.TideExtreme{
+			Type:      e.Type,
+			Timestamp: blendedTime,
+			LocalTime: formatLocalTime(blendedTime, location),
+			Height:    roundHeight(height / totalWeight),
+		})
+		for i, c := range curves {
+			if matches[i] >= 0 {
+				c.offsets = append(c.offsets, timeOffset{at: blendedTime, offset: matches[i] - blendedTime})
+			}
+		}
+	}
+	return extremes
+}
+
+// matchingExtreme returns the extreme of the same type nearest in time to e,
+// if one is within maxExtremeMatchGap
+func matchingExtreme(extremes []models.TideExtreme, e models.TideExtreme) (models.TideExtreme, bool) {
+	var best models.TideExtreme
+	bestGap := maxExtremeMatchGap.Milliseconds() + 1
+	for _, candidate := range extremes {
+		if candidate.Type != e.Type {
+			continue
+		}
+		gap := candidate.Timestamp - e.Timestamp
+		if gap < 0 {
+			gap = -gap
+		}
+		if gap < bestGap {
+			best, bestGap = candidate, gap
+		}
+	}
+	return best, bestGap <= maxExtremeMatchGap.Milliseconds()
+}
@@ -0,0 +1,249 @@
+package tide
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cosineFetcher predicts a semidiurnal tide for each station, in feet: a
+// cosine with a 12 hour period, highest at highHour and 2*amplitude above
+// its lows. Stations without a curve fail.
+type cosineFetcher map[string]struct {
+	highHour  float64
+	amplitude float64
+}
+
+func (f cosineFetcher) Units() models.Units {
+	return models.UnitsFeet
+}
+
+func (f cosineFetcher) FetchPredictions(ctx context.Context, station *models.Station, start, end time.Time, location *time.Location, datum models.Datum) ([]models.TidePrediction, []models.TideExtreme, error) {
+	curve, ok := f[station.ID]
+	if !ok {
+		return nil, nil, NewSourceAPIError(station.Source, "no predictions for "+station.ID, nil)
+	}
+
+	height := func(t time.Time) float64 {
+		hours := float64(t.Sub(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC))) / float64(time.Hour)
+		return curve.amplitude * (1 + math.Cos(2*math.Pi*(hours-curve.highHour)/12))
+	}
+
+	var predictions []models.TidePrediction
+	for t := start; t.Before(end); t = t.Add(6 * time.Minute) {
+		predictions = append(predictions, models.TidePrediction{Timestamp: t.UnixMilli(), LocalTime: formatLocalTime(t.UnixMilli(), location), Height: height(t)})
+	}
+	var extremes []models.TideExtreme
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		for i, tideType := range []models.TideType{models.TideTypeHigh, models.TideTypeLow, models.TideTypeHigh, models.TideTypeLow} {
+			t := day.Add(time.Duration((curve.highHour + 6*float64(i)) * float64(time.Hour)))
+			extremes = append(extremes, models.TideExtreme{Type: tideType, Timestamp: t.UnixMilli(), LocalTime: formatLocalTime(t.UnixMilli(), location), Height: height(t)})
+		}
+	}
+	return predictions, extremes, nil
+}
+
+func blendTestService(stations []models.Station, fetcher cosineFetcher) (*Service, *int) {
+	requestedLimit := new(int)
+	return &Service{
+		StationFinder: &mockStationFinder2{
+			findNearestStationsFn: func(ctx context.Context, lat, lon float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
+				*requestedLimit = limit
+				if limit < len(stations) {
+					return stations[:limit], nil
+				}
+				return stations, nil
+			},
+		},
+		PredictionCache:    &mockStationService2{},
+		PredictionFetchers: map[models.Source]PredictionFetcher{models.SourceCHS: fetcher},
+	}, requestedLimit
+}
+
+func blendTestStation(id string, distance float64) models.Station {
+	stationType := models.StationTypeReference
+	return models.Station{
+		ID:          id,
+		Name:        "Station " + id,
+		Latitude:    49.0,
+		Longitude:   -123.0,
+		Distance:    distance,
+		Source:      models.SourceCHS,
+		TimeZone:    "UTC",
+		StationType: &stationType,
+	}
+}
+
+func TestGetBlendedTide(t *testing.T) {
+	fetcher := cosineFetcher{
+		"A": {highHour: 6, amplitude: 5}, // Highs of 10 ft at 06:00
+		"B": {highHour: 7, amplitude: 3}, // Highs of 6 ft at 07:00
+	}
+	start, end := stringPtr("2026-07-01T00:00:00"), stringPtr("2026-07-01T23:59:59")
+
+	t.Run("weighted by inverse distance", func(t *testing.T) {
+		service, requestedLimit := blendTestService([]models.Station{blendTestStation("A", 1), blendTestStation("B", 3)}, fetcher)
+
+		response, err := service.GetBlendedTide(context.Background(), 49.01, -123.01, 0, start, end, models.DatumMLLW, models.UnitsFeet)
+		require.NoError(t, err)
+
+		assert.Equal(t, defaultBlendStations, *requestedLimit)
+		assert.Equal(t, "blended", response.CalculationMethod)
+		assert.Equal(t, "A", response.NearestStation)
+		assert.Equal(t, 49.01, response.Latitude, "the response is for the point")
+		assert.Equal(t, -123.01, response.Longitude)
+		assert.Equal(t, models.ObservationStatusUnavailable, response.ObservationStatus)
+
+		// 1/1² and 1/3², normalized
+		require.Len(t, response.BlendedStations, 2)
+		assert.Equal(t, "A", response.BlendedStations[0].StationID)
+		assert.InDelta(t, 0.9, response.BlendedStations[0].Weight, 1e-9)
+		assert.Equal(t, 3.0, response.BlendedStations[1].Distance)
+		assert.InDelta(t, 0.1, response.BlendedStations[1].Weight, 1e-9)
+		require.NotNil(t, response.Freshness)
+		assert.Equal(t, models.FreshnessFresh, response.Freshness.Status)
+
+		// Highs at 06:00 and 07:00 blend to 06:06, 0.9*10 + 0.1*6 ft
+		require.Len(t, response.Extremes, 3)
+		high := response.Extremes[0]
+		assert.Equal(t, models.TideTypeHigh, high.Type)
+		assert.Equal(t, "2026-07-01T06:06:00", high.LocalTime)
+		assert.InDelta(t, 9.6, high.Height, 1e-9)
+
+		// Each curve is shifted to its own high, so the blended curve's high is
+		// the blended high
+		for _, p := range response.Predictions {
+			if p.LocalTime == "2026-07-01T06:06:00" {
+				assert.InDelta(t, 9.6, p.Height, 1e-9)
+			}
+			assert.Equal(t, roundHeight(p.Height), p.Height, "rounded like fetched heights")
+		}
+		require.Len(t, response.Days, 1)
+		require.NotNil(t, response.Days[0].HigherHigh)
+	})
+
+	t.Run("a station at the point isn't blended", func(t *testing.T) {
+		service, _ := blendTestService([]models.Station{blendTestStation("A", 0), blendTestStation("B", 3)}, fetcher)
+
+		response, err := service.GetBlendedTide(context.Background(), 49, -123, 2, start, end, models.DatumMLLW, models.UnitsFeet)
+		require.NoError(t, err)
+		require.Len(t, response.BlendedStations, 1)
+		assert.Equal(t, 1.0, response.BlendedStations[0].Weight)
+		assert.Equal(t, "2026-07-01T06:00:00", response.Extremes[0].LocalTime)
+	})
+
+	t.Run("stations without predictions are left out", func(t *testing.T) {
+		service, _ := blendTestService([]models.Station{blendTestStation("C", 0.5), blendTestStation("A", 1), blendTestStation("B", 3)}, fetcher)
+
+		response, err := service.GetBlendedTide(context.Background(), 49, -123, 3, start, end, models.DatumMLLW, models.UnitsFeet)
+		require.NoError(t, err)
+		assert.Equal(t, "A", response.NearestStation)
+		require.Len(t, response.BlendedStations, 2)
+		assert.InDelta(t, 0.9, response.BlendedStations[0].Weight, 1e-9)
+	})
+
+	t.Run("no station has predictions", func(t *testing.T) {
+		service, _ := blendTestService([]models.Station{blendTestStation("C", 0.5)}, fetcher)
+
+		_, err := service.GetBlendedTide(context.Background(), 49, -123, 3, start, end, models.DatumMLLW, models.UnitsFeet)
+		var sourceErr *SourceAPIError
+		assert.True(t, errors.As(err, &sourceErr))
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		service, _ := blendTestService([]models.Station{blendTestStation("A", 1)}, fetcher)
+
+		_, err := service.GetBlendedTide(context.Background(), 91, -123, 3, start, end, models.DatumMLLW, models.UnitsFeet)
+		assert.EqualError(t, err, "invalid latitude: 91.000000")
+
+		_, err = service.GetBlendedTide(context.Background(), 49, -123, 11, start, end, models.DatumMLLW, models.UnitsFeet)
+		assert.EqualError(t, err, "cannot blend more than 10 stations")
+
+		service, _ = blendTestService(nil, fetcher)
+		_, err = service.GetBlendedTide(context.Background(), 49, -123, 3, start, end, models.DatumMLLW, models.UnitsFeet)
+		assert.EqualError(t, err, "no stations found near coordinates")
+	})
+}
+
+func TestInverseDistanceWeights(t *testing.T) {
+	assert.InDeltaSlice(t, []float64{0.8, 0.2}, inverseDistanceWeights([]float64{1, 2}), 1e-9)
+	assert.InDeltaSlice(t, []float64{1.0 / 3, 1.0 / 3, 1.0 / 3}, inverseDistanceWeights([]float64{4, 4, 4}), 1e-9)
+	assert.InDelta(t, 1, inverseDistanceWeights([]float64{0, 5})[0], 1e-5)
+}
+
+func TestBlendExtremes(t *testing.T) {
+	at := func(hour, minute int) int64 {
+		return time.Date(2026, 7, 1, hour, minute, 0, 0, time.UTC).UnixMilli()
+	}
+	curves := []*blendedCurve{
+		{weight: 0.5, extremes: []models.TideExtreme{
+			{Type: models.TideTypeHigh, Timestamp: at(6, 0), Height: 10},
+			{Type: models.TideTypeLow, Timestamp: at(12, 0), Height: 0},
+		}},
+		{weight: 0.3, extremes: []models.TideExtreme{
+			// Its high is too far from the first curve's to be the same tide
+			{Type: models.TideTypeHigh, Timestamp: at(10, 0), Height: 8},
+			{Type: models.TideTypeLow, Timestamp: at(13, 0), Height: 2},
+		}},
+		{weight: 0.2, extremes: []models.TideExtreme{
+			{Type: models.TideTypeHigh, Timestamp: at(7, 0), Height: 6},
+		}},
+	}
+
+	extremes := blendExtremes(curves, time.UTC)
+	require.Len(t, extremes, 2)
+
+	// The high blends the first and third curves, reweighted to 5/7 and 2/7
+	assert.Equal(t, "2026-07-01T06:17:00", extremes[0].LocalTime)
+	assert.Equal(t, roundHeight((0.5*10+0.2*6)/0.7), extremes[0].Height)
+
+	// The low blends the first two, 5/8 and 3/8
+	assert.Equal(t, "2026-07-01T12:23:00", extremes[1].LocalTime)
+	assert.InDelta(t, 0.3*2/0.8, extremes[1].Height, 1e-9)
+
+	// Each curve is offset to its matched highs and lows
+	high, low := extremes[0].Timestamp, extremes[1].Timestamp
+	minutes := func(m int64) int64 { return m * time.Minute.Milliseconds() }
+	assert.Equal(t, []timeOffset{{at: high, offset: minutes(-17)}, {at: low, offset: minutes(-23)}}, curves[0].offsets)
+	assert.Equal(t, []timeOffset{{at: low, offset: minutes(37)}}, curves[1].offsets)
+	assert.Equal(t, []timeOffset{{at: high, offset: minutes(43)}}, curves[2].offsets)
+}
+
+func TestBlendPredictions_AlignsPhase(t *testing.T) {
+	// Equal tides two hours apart, equally weighted
+	fetcher := cosineFetcher{
+		"A": {highHour: 6, amplitude: 5},
+		"B": {highHour: 8, amplitude: 5},
+	}
+	start := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 1)
+	var curves []*blendedCurve
+	for _, id := range []string{"A", "B"} {
+		predictions, extremes, err := fetcher.FetchPredictions(context.Background(), &models.Station{ID: id}, start, end, time.UTC, models.DatumMLLW)
+		require.NoError(t, err)
+		curves = append(curves, &blendedCurve{predictions: predictions, extremes: extremes, weight: 0.5})
+	}
+
+	extremes := blendExtremes(curves, time.UTC)
+	predictions := blendPredictions(curves, start.UnixMilli(), end.UnixMilli(), time.UTC)
+
+	highest := predictions[0]
+	for _, p := range predictions {
+		if p.Height > highest.Height {
+			highest = p
+		}
+	}
+
+	// Summing at the same times would peak at 07:00 with only
+	// 5*(1+cos(30°)) ft; aligned, the full 10 ft range survives
+	assert.Equal(t, "2026-07-01T07:00:00", extremes[0].LocalTime)
+	assert.Equal(t, "2026-07-01T07:00:00", highest.LocalTime)
+	assert.InDelta(t, 10, highest.Height, 1e-9)
+	assert.InDelta(t, extremes[0].Height, highest.Height, 1e-9)
+}
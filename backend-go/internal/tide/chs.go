@@ -0,0 +1,203 @@
+package tide
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/internal/station"
+	"github.com/bbernstein/flowebb/backend-go/pkg/http/client"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Longest range of data IWLS serves in one request
+const chsMaxRequestDays = 7
+
+// CHSPredictionFetcher fetches predictions for Canadian Hydrographic Service
+// stations from its IWLS API. CHS publishes heights in metres above chart
+// datum, the lowest normal tide, which is served as models.DatumCD. No other
+// datum is available; MLLW is rejected rather than approximated by it.
+type CHSPredictionFetcher struct {
+	HttpClient *client.Client
+
+	// IWLS station IDs by CHS code, looked up on first use
+	stationIDs sync.Map
+}
+
+var _ PredictionFetcher = (*CHSPredictionFetcher)(nil)
+var _ SingleDatumFetcher = (*CHSPredictionFetcher)(nil)
+
+// NewCHSPredictionFetcher creates a fetcher whose client's base URL is the
+// IWLS API's, e.g. https://api-iwls.dfo-mpo.gc.ca
+func NewCHSPredictionFetcher(httpClient *client.Client) *CHSPredictionFetcher {
+	return &CHSPredictionFetcher{HttpClient: httpClient}
+}
+
+func (f *CHSPredictionFetcher) Units() models.Units {
+	return models.UnitsMeters
+}
+
+func (f *CHSPredictionFetcher) Datum() models.Datum {
+	return models.DatumCD
+}
+
+func (f *CHSPredictionFetcher) FetchPredictions(ctx context.Context, localStation *models.Station, start, end time.Time, location *time.Location, datum models.Datum) ([]models.TidePrediction, []models.TideExtreme, error) {
+	if datum != models.DatumCD {
+		return nil, nil, &UnsupportedDatumError{Source: models.SourceCHS, Datum: datum}
+	}
+
+	code, ok := station.CHSStationCode(localStation.ID)
+	if !ok {
+		return nil, nil, fmt.Errorf("not a CHS station: %s", localStation.ID)
+	}
+
+	iwlsID, err := f.iwlsStationID(ctx, code)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var predictions []models.TidePrediction
+	values, err := f.fetchSeries(ctx, iwlsID, "wlp", start, end)
+	if err != nil {
+		// Extremes alone are enough to interpolate a curve
+		log.Warn().Err(err).
+			Str("station_id", localStation.ID).
+			Msg("Error fetching predictions from CHS")
+	} else {
+		predictions = make([]models.TidePrediction, len(values))
+		for i, v := range values {
+			predictions[i] = models.TidePrediction{
+				Timestamp: v.timestamp,
+				LocalTime: formatLocalTime(v.timestamp, location),
+				Height:    v.height,
+			}
+		}
+	}
+
+	values, err = f.fetchSeries(ctx, iwlsID, "wlp-hilo", start, end)
+	if err != nil {
+		if len(predictions) == 0 {
+			return nil, nil, err
+		}
+		log.Warn().Err(err).
+			Str("station_id", localStation.ID).
+			Msg("Error fetching extremes from CHS")
+	}
+
+	extremes := make([]models.TideExtreme, len(values))
+	for i, v := range values {
+		extremes[i] = models.TideExtreme{
+			Type:      chsExtremeType(values, i),
+			Timestamp: v.timestamp,
+			LocalTime: formatLocalTime(v.timestamp, location),
+			Height:    v.height,
+		}
+	}
+
+	log.Debug().
+		Str("station_id", localStation.ID).
+		Time("start", start).
+		Time("end", end).
+		Msg("Fetched predictions from CHS")
+
+	return predictions, extremes, nil
+}
+
+// iwlsStationID returns the ID IWLS uses for the station with a CHS code
+func (f *CHSPredictionFetcher) iwlsStationID(ctx context.Context, code string) (string, error) {
+	if id, ok := f.stationIDs.Load(code); ok {
+		return id.(string), nil
+	}
+
+	resp, err := f.HttpClient.Get(ctx, "/api/v1/stations?code="+code)
+	if err != nil {
+		return "", NewSourceAPIError(models.SourceCHS, "error making HTTP request for station", err)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return "", NewSourceAPIError(models.SourceCHS, fmt.Sprintf("station lookup returned status %d", resp.StatusCode), nil)
+	}
+
+	var stations []struct {
+		ID   string `json:"id"`
+		Code string `json:"code"`
+	}
+	if err := json.Unmarshal(resp.Body, &stations); err != nil {
+		return "", NewSourceAPIError(models.SourceCHS, "error decoding station response", err)
+	}
+
+	for _, s := range stations {
+		if s.Code == code {
+			f.stationIDs.Store(code, s.ID)
+			return s.ID, nil
+		}
+	}
+	return "", fmt.Errorf("CHS station not found: %s", code)
+}
+
+type chsValue struct {
+	timestamp int64
+	height    float64
+}
+
+// fetchSeries fetches a time series a week at a time, in order of time
+func (f *CHSPredictionFetcher) fetchSeries(ctx context.Context, iwlsID, seriesCode string, start, end time.Time) ([]chsValue, error) {
+	var values []chsValue
+	for from := start; from.Before(end); from = from.AddDate(0, 0, chsMaxRequestDays) {
+		to := from.AddDate(0, 0, chsMaxRequestDays)
+		if to.After(end) {
+			to = end
+		}
+
+		resp, err := f.HttpClient.Get(ctx, fmt.Sprintf("/api/v1/stations/%s/data?time-series-code=%s&from=%s&to=%s",
+			iwlsID, seriesCode, from.UTC().Format(time.RFC3339), to.UTC().Format(time.RFC3339)))
+		if err != nil {
+			return nil, NewSourceAPIError(models.SourceCHS, "error making HTTP request for "+seriesCode, err)
+		}
+		if resp.StatusCode >= http.StatusBadRequest {
+			return nil, NewSourceAPIError(models.SourceCHS, fmt.Sprintf("%s request returned status %d", seriesCode, resp.StatusCode), nil)
+		}
+
+		var data []struct {
+			EventDate string  `json:"eventDate"`
+			Value     float64 `json:"value"`
+		}
+		if err := json.Unmarshal(resp.Body, &data); err != nil {
+			return nil, NewSourceAPIError(models.SourceCHS, "error decoding "+seriesCode+" response", err)
+		}
+
+		for _, d := range data {
+			t, err := time.Parse(time.RFC3339, d.EventDate)
+			if err != nil {
+				return nil, fmt.Errorf("parsing time %s: %w", d.EventDate, err)
+			}
+			timestamp := t.UnixMilli()
+			// Requests share their boundaries, so a value can be returned twice
+			if len(values) > 0 && timestamp <= values[len(values)-1].timestamp {
+				continue
+			}
+			values = append(values, chsValue{timestamp: timestamp, height: d.Value})
+		}
+	}
+	return values, nil
+}
+
+// chsExtremeType tells highs from lows, which IWLS doesn't label, by
+// comparing each with its neighbours
+func chsExtremeType(values []chsValue, i int) models.TideType {
+	var neighbour float64
+	switch {
+	case i+1 < len(values):
+		neighbour = values[i+1].height
+	case i > 0:
+		neighbour = values[i-1].height
+	default:
+		return ""
+	}
+	if values[i].height > neighbour {
+		return models.TideTypeHigh
+	}
+	return models.TideTypeLow
+}
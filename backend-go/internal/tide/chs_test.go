@@ -0,0 +1,275 @@
+package tide
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/pkg/http/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const chsTestStationID = "5cebf1de3d0f4a073c4bb94c"
+
+// chsTestServer serves IWLS responses for Point Atkinson from testdata, which
+// cover 2026-07-01 and 2026-07-02 in Pacific time, trimmed to each request's
+// range the way IWLS does. Series listed in failing return errors.
+type chsTestServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []*http.Request
+	failing  map[string]bool
+}
+
+func newCHSTestServer(t *testing.T) *chsTestServer {
+	series := map[string][]map[string]interface{}{}
+	for code, file := range map[string]string{"wlp": "testdata/chs_wlp.json", "wlp-hilo": "testdata/chs_wlp_hilo.json"} {
+		body, err := os.ReadFile(file)
+		require.NoError(t, err)
+		var values []map[string]interface{}
+		require.NoError(t, json.Unmarshal(body, &values))
+		series[code] = values
+	}
+
+	srv := &chsTestServer{failing: map[string]bool{}}
+	srv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		srv.mu.Lock()
+		srv.requests = append(srv.requests, r)
+		failing := srv.failing[r.URL.Query().Get("time-series-code")]
+		srv.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/stations":
+			if r.URL.Query().Get("code") == "07795" {
+				_ = json.NewEncoder(w).Encode([]map[string]string{{"id": chsTestStationID, "code": "07795"}})
+				return
+			}
+			_, _ = w.Write([]byte("[]"))
+		case "/api/v1/stations/" + chsTestStationID + "/data":
+			if failing {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			from, _ := time.Parse(time.RFC3339, r.URL.Query().Get("from"))
+			to, _ := time.Parse(time.RFC3339, r.URL.Query().Get("to"))
+			values := make([]map[string]interface{}, 0)
+			for _, v := range series[r.URL.Query().Get("time-series-code")] {
+				eventDate, _ := time.Parse(time.RFC3339, v["eventDate"].(string))
+				if !eventDate.Before(from) && !eventDate.After(to) {
+					values = append(values, v)
+				}
+			}
+			_ = json.NewEncoder(w).Encode(values)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func (s *chsTestServer) seriesRequests(code string) []*http.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var result []*http.Request
+	for _, r := range s.requests {
+		if r.URL.Query().Get("time-series-code") == code {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+func (s *chsTestServer) client() *client.Client {
+	return client.New(client.Options{BaseURL: s.URL, Timeout: 5 * time.Second, MaxRetries: 1})
+}
+
+func chsTestStation() *models.Station {
+	stationType := models.StationTypeReference
+	return &models.Station{
+		ID:             "CHS-07795",
+		Name:           "Point Atkinson",
+		Latitude:       49.337,
+		Longitude:      -123.253,
+		Source:         models.SourceCHS,
+		TimeZoneOffset: -8 * 3600,
+		TimeZone:       "America/Vancouver",
+		StationType:    &stationType,
+	}
+}
+
+func TestCHSPredictionFetcher(t *testing.T) {
+	vancouver, err := time.LoadLocation("America/Vancouver")
+	require.NoError(t, err)
+	start := time.Date(2026, 7, 1, 0, 0, 0, 0, vancouver)
+	end := start.AddDate(0, 0, 2)
+
+	t.Run("predictions and extremes", func(t *testing.T) {
+		srv := newCHSTestServer(t)
+		fetcher := NewCHSPredictionFetcher(srv.client())
+		assert.Equal(t, models.UnitsMeters, fetcher.Units())
+
+		predictions, extremes, err := fetcher.FetchPredictions(context.Background(), chsTestStation(), start, end, vancouver, models.DatumCD)
+		require.NoError(t, err)
+
+		require.Len(t, predictions, 193, "every 15 minutes, both ends included")
+		assert.Equal(t, start.UnixMilli(), predictions[0].Timestamp)
+		assert.Equal(t, "2026-07-01T00:00:00", predictions[0].LocalTime)
+		assert.Equal(t, end.UnixMilli(), predictions[len(predictions)-1].Timestamp)
+
+		require.Len(t, extremes, 8)
+		assert.Equal(t, models.TideExtreme{
+			Type:      models.TideTypeHigh,
+			Timestamp: time.Date(2026, 7, 1, 9, 44, 0, 0, time.UTC).UnixMilli(),
+			LocalTime: "2026-07-01T02:44:00",
+			Height:    4.95,
+		}, extremes[0])
+		for i, e := range extremes {
+			want := models.TideTypeHigh
+			if i%2 == 1 {
+				want = models.TideTypeLow
+			}
+			assert.Equal(t, want, e.Type, e.LocalTime)
+		}
+
+		require.Len(t, srv.seriesRequests("wlp"), 1)
+		query := srv.seriesRequests("wlp")[0].URL.Query()
+		assert.Equal(t, "2026-07-01T07:00:00Z", query.Get("from"))
+		assert.Equal(t, "2026-07-03T07:00:00Z", query.Get("to"))
+
+		// The IWLS station ID is looked up once
+		_, _, err = fetcher.FetchPredictions(context.Background(), chsTestStation(), start, end, vancouver, models.DatumCD)
+		require.NoError(t, err)
+		assert.Len(t, srv.seriesRequests(""), 1)
+	})
+
+	t.Run("long ranges are fetched a week at a time", func(t *testing.T) {
+		srv := newCHSTestServer(t)
+		fetcher := NewCHSPredictionFetcher(srv.client())
+
+		predictions, _, err := fetcher.FetchPredictions(context.Background(), chsTestStation(), start, start.AddDate(0, 0, 10), vancouver, models.DatumCD)
+		require.NoError(t, err)
+		assert.Len(t, predictions, 193)
+
+		requests := srv.seriesRequests("wlp")
+		require.Len(t, requests, 2)
+		assert.Equal(t, "2026-07-08T07:00:00Z", requests[0].URL.Query().Get("to"))
+		assert.Equal(t, "2026-07-08T07:00:00Z", requests[1].URL.Query().Get("from"))
+		assert.Equal(t, "2026-07-11T07:00:00Z", requests[1].URL.Query().Get("to"))
+	})
+
+	t.Run("extremes alone when predictions fail", func(t *testing.T) {
+		srv := newCHSTestServer(t)
+		srv.failing["wlp"] = true
+		fetcher := NewCHSPredictionFetcher(srv.client())
+
+		predictions, extremes, err := fetcher.FetchPredictions(context.Background(), chsTestStation(), start, end, vancouver, models.DatumCD)
+		require.NoError(t, err)
+		assert.Nil(t, predictions)
+		assert.Len(t, extremes, 8)
+	})
+
+	t.Run("upstream failure", func(t *testing.T) {
+		srv := newCHSTestServer(t)
+		srv.failing["wlp"] = true
+		srv.failing["wlp-hilo"] = true
+		fetcher := NewCHSPredictionFetcher(srv.client())
+
+		_, _, err := fetcher.FetchPredictions(context.Background(), chsTestStation(), start, end, vancouver, models.DatumCD)
+		var sourceErr *SourceAPIError
+		require.True(t, errors.As(err, &sourceErr))
+		assert.Equal(t, models.SourceCHS, sourceErr.Source)
+		assert.EqualError(t, err, "CHS API error: wlp-hilo request returned status 500")
+	})
+
+	t.Run("only chart datum", func(t *testing.T) {
+		fetcher := NewCHSPredictionFetcher(newCHSTestServer(t).client())
+		assert.Equal(t, models.DatumCD, fetcher.Datum())
+
+		// Chart datum isn't MLLW, so it isn't served as MLLW either
+		for _, datum := range []models.Datum{models.DatumMLLW, models.DatumNAVD88} {
+			_, _, err := fetcher.FetchPredictions(context.Background(), chsTestStation(), start, end, vancouver, datum)
+			var datumErr *UnsupportedDatumError
+			require.True(t, errors.As(err, &datumErr))
+			assert.EqualError(t, err, fmt.Sprintf("CHS predictions are not available relative to %s", datum))
+		}
+	})
+
+	t.Run("unknown station", func(t *testing.T) {
+		fetcher := NewCHSPredictionFetcher(newCHSTestServer(t).client())
+
+		station := chsTestStation()
+		station.ID = "CHS-99999"
+		_, _, err := fetcher.FetchPredictions(context.Background(), station, start, end, vancouver, models.DatumCD)
+		assert.EqualError(t, err, "CHS station not found: 99999")
+
+		station.ID = "9447130"
+		_, _, err = fetcher.FetchPredictions(context.Background(), station, start, end, vancouver, models.DatumCD)
+		assert.EqualError(t, err, "not a CHS station: 9447130")
+	})
+}
+
+func TestGetCurrentTideForStation_CHS(t *testing.T) {
+	srv := newCHSTestServer(t)
+	saved := make(chan []models.TidePredictionRecord, 1)
+	service := &Service{
+		// Nothing should be asked of NOAA
+		HttpClient: client.New(client.Options{BaseURL: "http://127.0.0.1:0", Timeout: time.Second, MaxRetries: 1}),
+		StationFinder: &mockStationFinder2{
+			findStationFn: func(ctx context.Context, stationID string) (*models.Station, error) {
+				return chsTestStation(), nil
+			},
+		},
+		PredictionCache: &mockStationService2{
+			savePredictionsBatchFn: func(ctx context.Context, records []models.TidePredictionRecord) error {
+				saved <- records
+				return nil
+			},
+		},
+		PredictionFetchers: map[models.Source]PredictionFetcher{
+			models.SourceCHS: NewCHSPredictionFetcher(srv.client()),
+		},
+	}
+
+	// Requests that don't name a datum get CHS's chart datum
+	response, err := service.GetCurrentTideForStation(context.Background(), "CHS-07795",
+		stringPtr("2026-07-01T00:00:00"), stringPtr("2026-07-01T12:00:00"), "", models.UnitsFeet)
+	require.NoError(t, err)
+
+	assert.Equal(t, models.DatumCD, response.Datum)
+	assert.Equal(t, "CHS API", response.CalculationMethod)
+	assert.Equal(t, "America/Vancouver", response.TimeZone)
+	assert.Equal(t, models.UnitsFeet, response.Units)
+	assert.Equal(t, models.ObservationStatusUnavailable, response.ObservationStatus)
+	require.NotNil(t, response.Freshness)
+	assert.Equal(t, models.FreshnessFresh, response.Freshness.Status, "just fetched")
+	require.NotEmpty(t, response.Extremes)
+	assert.Equal(t, "2026-07-01T02:44:00", response.Extremes[0].LocalTime)
+	assert.InDelta(t, 4.95/0.3048, response.Extremes[0].Height, 0.001, "CHS metres are converted to feet")
+
+	select {
+	case records := <-saved:
+		require.NotEmpty(t, records)
+		for _, r := range records {
+			assert.Equal(t, models.UnitsMeters, r.Units, "records are cached in the source's units")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("predictions were not saved to the cache")
+	}
+
+	// Chart datum isn't served as MLLW
+	_, err = service.GetCurrentTideForStation(context.Background(), "CHS-07795",
+		stringPtr("2026-07-01T00:00:00"), stringPtr("2026-07-01T12:00:00"), models.DatumMLLW, models.UnitsFeet)
+	var datumErr *UnsupportedDatumError
+	assert.True(t, errors.As(err, &datumErr))
+}
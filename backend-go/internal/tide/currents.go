@@ -0,0 +1,406 @@
+package tide
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/internal/timezone"
+	"github.com/rs/zerolog/log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+var _ CurrentService = (*Service)(nil)
+
+func (s *Service) GetCurrents(ctx context.Context, lat, lon float64, startTimeStr, endTimeStr *string) (*models.CurrentResponse, error) {
+	if lat < -90 || lat > 90 {
+		return nil, fmt.Errorf("invalid latitude: %f", lat)
+	}
+	if lon < -180 || lon > 180 {
+		return nil, fmt.Errorf("invalid longitude: %f", lon)
+	}
+	if s.CurrentStationFinder == nil {
+		return nil, fmt.Errorf("current station finder is not configured")
+	}
+
+	stations, err := s.CurrentStationFinder.FindNearestStations(ctx, lat, lon, 1, nil)
+	if err != nil {
+		return nil, fmt.Errorf("finding nearest current station: %w", err)
+	}
+	if len(stations) == 0 {
+		return nil, fmt.Errorf("no current stations found near coordinates")
+	}
+
+	response, err := s.GetCurrentsForStation(ctx, stations[0].ID, startTimeStr, endTimeStr)
+	if err != nil {
+		return nil, fmt.Errorf("getting currents: %w", err)
+	}
+	response.StationDistance = stations[0].Distance
+
+	return response, nil
+}
+
+func (s *Service) GetCurrentsForStation(ctx context.Context, stationID string, startTimeStr, endTimeStr *string) (*models.CurrentResponse, error) {
+	log.Debug().Str("station_id", stationID).Msg("Getting currents for station")
+
+	if s.CurrentStationFinder == nil {
+		return nil, fmt.Errorf("current station finder is not configured")
+	}
+
+	station, err := s.CurrentStationFinder.FindStation(ctx, stationID)
+	if err != nil {
+		return nil, fmt.Errorf("finding current station: %w", err)
+	}
+
+	location := timezone.ForStation(station)
+	now := time.Now().In(location)
+
+	startTime, endTime, err := parseTimeRange(startTimeStr, endTimeStr, now, location)
+	if err != nil {
+		return nil, err
+	}
+
+	daysDataAllowed := time.Duration(30)
+	if endTime.Sub(startTime) > daysDataAllowed*24*time.Hour {
+		return nil, NewInvalidRangeError(fmt.Sprintf("date range cannot exceed %d days", daysDataAllowed))
+	}
+
+	records, err := s.getCurrentsForDateRange(ctx, station, startOfDay(startTime, location), startOfDay(endTime, location), location)
+	if err != nil {
+		return nil, err
+	}
+
+	var predictions []models.CurrentPrediction
+	var events []models.CurrentEvent
+	for _, r := range records {
+		predictions = append(predictions, r.Currents...)
+		events = append(events, r.CurrentEvents...)
+	}
+	floodDirection, ebbDirection := records[0].FloodDirection, records[0].EbbDirection
+
+	startMillis, endMillis := startTime.UnixMilli(), endTime.UnixMilli()
+	filteredPredictions := make([]models.CurrentPrediction, 0, len(predictions))
+	for _, p := range predictions {
+		if p.Timestamp >= startMillis && p.Timestamp <= endMillis {
+			filteredPredictions = append(filteredPredictions, p)
+		}
+	}
+	filteredEvents := make([]models.CurrentEvent, 0, len(events))
+	for _, e := range events {
+		if e.Timestamp >= startMillis && e.Timestamp <= endMillis {
+			filteredEvents = append(filteredEvents, e)
+		}
+	}
+
+	return &models.CurrentResponse{
+		ResponseType:          "currents",
+		Timestamp:             now.UnixMilli(),
+		LocalTime:             now.Format("2006-01-02T15:04:05"),
+		StationID:             station.ID,
+		StationName:           station.Name,
+		Latitude:              station.Latitude,
+		Longitude:             station.Longitude,
+		StationDistance:       station.Distance,
+		Bin:                   station.CurrentBin,
+		FloodDirection:        floodDirection,
+		EbbDirection:          ebbDirection,
+		Current:               interpolateCurrent(predictions, now.UnixMilli(), floodDirection, ebbDirection, location),
+		Predictions:           filteredPredictions,
+		Events:                filteredEvents,
+		TimeZoneOffsetSeconds: station.TimeZoneOffset,
+		TimeZone:              location.String(),
+	}, nil
+}
+
+// currentsRecordID is the ID a current station's currents are cached under,
+// apart from tide predictions. Each bin has its own currents.
+func currentsRecordID(station *models.Station) string {
+	id := "currents:" + station.ID
+	if station.CurrentBin != nil {
+		id += fmt.Sprintf("#%d", *station.CurrentBin)
+	}
+	return id
+}
+
+// getCurrentsForDateRange returns a record of the currents for each day from
+// startDate to endDate, from the prediction cache where it has them and from
+// NOAA otherwise. Stale days are served while they're refreshed.
+func (s *Service) getCurrentsForDateRange(ctx context.Context, station *models.Station, startDate, endDate time.Time, location *time.Location) ([]*models.TidePredictionRecord, error) {
+	recordID := currentsRecordID(station)
+	var records []*models.TidePredictionRecord
+	var missingDates, staleDates []time.Time
+	for date := startDate; !date.After(endDate); date = date.AddDate(0, 0, 1) {
+		record, err := s.PredictionCache.GetPredictions(ctx, recordID, date, "")
+		if err != nil {
+			log.Error().Err(err).
+				Str("station_id", station.ID).
+				Time("date", date).
+				Msg("Error getting currents from cache")
+		}
+		if record == nil {
+			missingDates = append(missingDates, date)
+			continue
+		}
+		records = append(records, record)
+		if record.Stale {
+			staleDates = append(staleDates, date)
+		}
+	}
+
+	if len(staleDates) > 0 {
+		inBackground(ctx, func() {
+			if _, err := s.fetchCurrentRecords(context.Background(), station, staleDates, location); err != nil {
+				log.Warn().Err(err).
+					Str("station_id", station.ID).
+					Msg("Error refreshing stale currents")
+			}
+		})
+	}
+
+	if len(missingDates) > 0 {
+		fetched, err := s.fetchCurrentRecords(ctx, station, missingDates, location)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, fetched...)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Date < records[j].Date
+	})
+	return records, nil
+}
+
+// fetchCurrentRecords fetches the currents for the span of the dates from
+// NOAA, sharing the fetch with concurrent callers, and caches a record of
+// each date's in the background
+func (s *Service) fetchCurrentRecords(ctx context.Context, station *models.Station, dates []time.Time, location *time.Location) ([]*models.TidePredictionRecord, error) {
+	minDate, maxDate := dateSpan(dates)
+	key := fmt.Sprintf("%s|%s|%s|currents", currentsRecordID(station),
+		minDate.Format("2006-01-02"), maxDate.Format("2006-01-02"))
+	result, err := s.predictionFetches.DoContext(ctx, key, predictionFetchTimeout, func(ctx context.Context) (interface{}, error) {
+		return s.fetchCurrents(ctx, station, minDate, maxDate, location)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	records := newCurrentRecords(station, dates, result.(*fetchedCurrents), location)
+	inBackground(ctx, func() {
+		s.savePredictionRecords(station, records)
+	})
+	return records, nil
+}
+
+// fetchedCurrents is the result of one currents fetch, shared by the callers
+// it was coalesced for
+type fetchedCurrents struct {
+	predictions    []models.CurrentPrediction
+	events         []models.CurrentEvent
+	floodDirection float64
+	ebbDirection   float64
+}
+
+// fetchCurrents fetches the currents for whole days from NOAA. Subordinate
+// current stations only publish maximum flood, maximum ebb and slack events,
+// so the events are returned alone when the 6-minute curve can't be fetched.
+func (s *Service) fetchCurrents(ctx context.Context, station *models.Station, startDate, endDate time.Time, location *time.Location) (*fetchedCurrents, error) {
+	beginStr := startDate.Format("20060102")
+	endStr := endDate.Format("20060102")
+
+	rawPredictions, err := s.fetchNoaaCurrents(ctx, station, beginStr, endStr, "6")
+	if err != nil {
+		log.Warn().Err(err).
+			Str("station_id", station.ID).
+			Msg("Error fetching current predictions from NOAA, using events alone")
+		rawPredictions = nil
+	}
+	rawEvents, err := s.fetchNoaaCurrents(ctx, station, beginStr, endStr, "MAX_SLACK")
+	if err != nil {
+		return nil, err
+	}
+
+	fetched := &fetchedCurrents{}
+	if len(rawPredictions) > 0 {
+		fetched.floodDirection, fetched.ebbDirection = rawPredictions[0].MeanFloodDir, rawPredictions[0].MeanEbbDir
+	} else if len(rawEvents) > 0 {
+		fetched.floodDirection, fetched.ebbDirection = rawEvents[0].MeanFloodDir, rawEvents[0].MeanEbbDir
+	}
+
+	if fetched.predictions, err = currentPredictions(rawPredictions, location); err != nil {
+		return nil, err
+	}
+	if fetched.events, err = currentEvents(rawEvents, location); err != nil {
+		return nil, err
+	}
+	return fetched, nil
+}
+
+// newCurrentRecords splits fetched currents into a record for each of the
+// dates, by local day
+func newCurrentRecords(station *models.Station, dates []time.Time, fetched *fetchedCurrents, location *time.Location) []*models.TidePredictionRecord {
+	predictionsByDay := make(map[string][]models.CurrentPrediction)
+	for _, p := range fetched.predictions {
+		day := time.UnixMilli(p.Timestamp).In(location).Format("2006-01-02")
+		predictionsByDay[day] = append(predictionsByDay[day], p)
+	}
+	eventsByDay := make(map[string][]models.CurrentEvent)
+	for _, e := range fetched.events {
+		day := time.UnixMilli(e.Timestamp).In(location).Format("2006-01-02")
+		eventsByDay[day] = append(eventsByDay[day], e)
+	}
+
+	stationType := models.StationTypeReference
+	if len(fetched.predictions) == 0 {
+		stationType = models.StationTypeSubordinate
+	}
+	now := time.Now().Unix()
+	records := make([]*models.TidePredictionRecord, len(dates))
+	for i, date := range dates {
+		dateStr := date.Format("2006-01-02")
+		records[i] = &models.TidePredictionRecord{
+			StationID:      currentsRecordID(station),
+			Date:           dateStr,
+			StationType:    stationType,
+			Currents:       predictionsByDay[dateStr],
+			CurrentEvents:  eventsByDay[dateStr],
+			FloodDirection: fetched.floodDirection,
+			EbbDirection:   fetched.ebbDirection,
+			LastUpdated:    now,
+		}
+	}
+	return records
+}
+
+// fetchNoaaCurrents fetches the currents_predictions product at a 6-minute
+// interval, or the maximum flood, maximum ebb and slack events for "MAX_SLACK".
+func (s *Service) fetchNoaaCurrents(ctx context.Context, station *models.Station, beginDate, endDate, interval string) ([]models.NoaaCurrentPrediction, error) {
+	path := fmt.Sprintf("/api/prod/datagetter"+
+		"?station=%s&begin_date=%s&end_date=%s&product=currents_predictions"+
+		"&units=english&time_zone=lst_ldt&format=json&interval=%s",
+		station.ID, beginDate, endDate, interval)
+	if station.CurrentBin != nil {
+		path += fmt.Sprintf("&bin=%d", *station.CurrentBin)
+	}
+
+	resp, err := s.HttpClient.Get(ctx, path)
+	if err != nil {
+		return nil, NewNoaaAPIError("error making HTTP request for currents", err)
+	}
+
+	log.Debug().Msgf("Fetched currents from noaa: station=%s begin_date=%s end_date=%s interval=%s",
+		station.ID, beginDate, endDate, interval)
+
+	var noaaResp models.NoaaCurrentResponse
+	if err := json.Unmarshal(resp.Body, &noaaResp); err != nil {
+		return nil, NewNoaaAPIError("error decoding currents response", err)
+	}
+
+	if noaaResp.Error != nil {
+		return nil, NewNoaaAPIError(noaaResp.Error.Message, nil)
+	}
+	if noaaResp.CurrentPredictions == nil {
+		return nil, nil
+	}
+
+	return noaaResp.CurrentPredictions.Predictions, nil
+}
+
+func currentPredictions(raw []models.NoaaCurrentPrediction, location *time.Location) ([]models.CurrentPrediction, error) {
+	predictions := make([]models.CurrentPrediction, len(raw))
+	var previous int64
+	for i, p := range raw {
+		timestamp, err := parseNoaaTime(p.Time, location, previous)
+		if err != nil {
+			return nil, err
+		}
+		predictions[i] = models.NewCurrentPrediction(timestamp, formatLocalTime(timestamp, location),
+			p.VelocityMajor, p.MeanFloodDir, p.MeanEbbDir)
+		previous = timestamp
+	}
+
+	sort.Slice(predictions, func(i, j int) bool {
+		return predictions[i].Timestamp < predictions[j].Timestamp
+	})
+
+	return predictions, nil
+}
+
+// currentEvents converts NOAA's MAX_SLACK entries. Slack water has no
+// direction of its own, so it takes the direction of the current that follows.
+func currentEvents(raw []models.NoaaCurrentPrediction, location *time.Location) ([]models.CurrentEvent, error) {
+	events := make([]models.CurrentEvent, 0, len(raw))
+	var previous int64
+	for _, e := range raw {
+		var eventType models.CurrentEventType
+		direction := e.MeanFloodDir
+		switch strings.ToLower(e.Type) {
+		case "flood":
+			eventType = models.CurrentEventMaxFlood
+		case "ebb":
+			eventType = models.CurrentEventMaxEbb
+			direction = e.MeanEbbDir
+		case "slack":
+			eventType = models.CurrentEventSlack
+		default:
+			log.Warn().Str("type", e.Type).Msg("Skipping unknown current event type")
+			continue
+		}
+
+		timestamp, err := parseNoaaTime(e.Time, location, previous)
+		if err != nil {
+			return nil, err
+		}
+		previous = timestamp
+
+		events = append(events, models.CurrentEvent{
+			Type:      eventType,
+			Timestamp: timestamp,
+			LocalTime: formatLocalTime(timestamp, location),
+			Speed:     math.Abs(e.VelocityMajor),
+			Direction: direction,
+		})
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Timestamp < events[j].Timestamp
+	})
+
+	for i := range events {
+		if events[i].Type != models.CurrentEventSlack {
+			continue
+		}
+		for _, next := range events[i+1:] {
+			if next.Type != models.CurrentEventSlack {
+				events[i].Direction = next.Direction
+				break
+			}
+		}
+	}
+
+	return events, nil
+}
+
+// interpolateCurrent returns the current at timestamp, or nil if it falls
+// outside the predictions.
+func interpolateCurrent(predictions []models.CurrentPrediction, timestamp int64, floodDirection, ebbDirection float64, location *time.Location) *models.CurrentPrediction {
+	idx := sort.Search(len(predictions), func(i int) bool {
+		return predictions[i].Timestamp >= timestamp
+	})
+	if idx == len(predictions) || (idx == 0 && predictions[0].Timestamp != timestamp) {
+		return nil
+	}
+
+	velocity := predictions[idx].Velocity
+	if predictions[idx].Timestamp != timestamp {
+		prev, next := predictions[idx-1], predictions[idx]
+		ratio := float64(timestamp-prev.Timestamp) / float64(next.Timestamp-prev.Timestamp)
+		velocity = math.Round((prev.Velocity+ratio*(next.Velocity-prev.Velocity))*100) / 100
+	}
+
+	current := models.NewCurrentPrediction(timestamp, formatLocalTime(timestamp, location), velocity, floodDirection, ebbDirection)
+	return &current
+}
@@ -0,0 +1,308 @@
+package tide
+
+import (
+	"context"
+	"fmt"
+	"github.com/bbernstein/flowebb/backend-go/internal/cache"
+	"github.com/bbernstein/flowebb/backend-go/internal/config"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/pkg/http/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func currentStation() *models.Station {
+	bin := 11
+	return &models.Station{
+		ID:             "PUG1515",
+		Name:           "Tacoma Narrows",
+		Latitude:       47.275,
+		Longitude:      -122.55,
+		Source:         models.SourceNOAA,
+		Capabilities:   []string{models.CapabilityTidalCurrents},
+		TimeZoneOffset: -8 * 3600,
+		CurrentBin:     &bin,
+	}
+}
+
+func newCurrentsServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		assert.Equal(t, "currents_predictions", query.Get("product"))
+		assert.Equal(t, "PUG1515", query.Get("station"))
+		assert.Equal(t, "11", query.Get("bin"))
+
+		switch query.Get("interval") {
+		case "6":
+			_, _ = fmt.Fprint(w, `{"current_predictions":{"units":"knots","cp":[
+				{"Time":"2024-06-01 00:00","Velocity_Major":2.10,"meanFloodDir":190,"meanEbbDir":10,"Bin":"11"},
+				{"Time":"2024-06-01 00:06","Velocity_Major":0.15,"meanFloodDir":190,"meanEbbDir":10,"Bin":"11"},
+				{"Time":"2024-06-01 00:12","Velocity_Major":-1.80,"meanFloodDir":190,"meanEbbDir":10,"Bin":"11"},
+				{"Time":"2024-06-02 00:06","Velocity_Major":-1.00,"meanFloodDir":190,"meanEbbDir":10,"Bin":"11"}
+			]}}`)
+		case "MAX_SLACK":
+			_, _ = fmt.Fprint(w, `{"current_predictions":{"units":"knots","cp":[
+				{"Type":"slack","Time":"2024-06-01 02:41","Velocity_Major":0.0,"meanFloodDir":190,"meanEbbDir":10},
+				{"Type":"ebb","Time":"2024-06-01 05:58","Velocity_Major":-4.21,"meanFloodDir":190,"meanEbbDir":10},
+				{"Type":"slack","Time":"2024-06-01 09:12","Velocity_Major":0.0,"meanFloodDir":190,"meanEbbDir":10},
+				{"Type":"flood","Time":"2024-06-01 12:30","Velocity_Major":3.05,"meanFloodDir":190,"meanEbbDir":10}
+			]}}`)
+		default:
+			t.Errorf("unexpected interval %q", query.Get("interval"))
+		}
+	}))
+}
+
+func TestGetCurrentsForStation(t *testing.T) {
+	server := newCurrentsServer(t)
+	defer server.Close()
+
+	service := &Service{
+		HttpClient: client.New(client.Options{BaseURL: server.URL, Timeout: 5 * time.Second}),
+		CurrentStationFinder: &mockStationFinder2{
+			findStationFn: func(_ context.Context, stationID string) (*models.Station, error) {
+				return currentStation(), nil
+			},
+		},
+		PredictionCache: &mockStationService2{},
+	}
+
+	response, err := service.GetCurrentsForStation(context.Background(), "PUG1515",
+		stringPtr("2024-06-01T00:00:00"), stringPtr("2024-06-01T23:59:59"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "currents", response.ResponseType)
+	assert.Equal(t, "PUG1515", response.StationID)
+	assert.Equal(t, 190.0, response.FloodDirection)
+	assert.Equal(t, 10.0, response.EbbDirection)
+	require.NotNil(t, response.Bin)
+	assert.Equal(t, 11, *response.Bin)
+
+	// The prediction after the requested range is dropped
+	require.Len(t, response.Predictions, 3)
+	assert.Equal(t, "2024-06-01T00:00:00", response.Predictions[0].LocalTime)
+	assert.Equal(t, models.CurrentStateFlood, response.Predictions[0].State)
+	assert.Equal(t, 190.0, response.Predictions[0].Direction)
+	assert.Equal(t, models.CurrentStateSlack, response.Predictions[1].State)
+	assert.Equal(t, models.CurrentStateEbb, response.Predictions[2].State)
+	assert.Equal(t, 1.8, response.Predictions[2].Speed)
+	assert.Equal(t, 10.0, response.Predictions[2].Direction)
+
+	require.Len(t, response.Events, 4)
+	wantTypes := []models.CurrentEventType{
+		models.CurrentEventSlack, models.CurrentEventMaxEbb, models.CurrentEventSlack, models.CurrentEventMaxFlood,
+	}
+	wantDirections := []float64{10, 10, 190, 190}
+	for i, e := range response.Events {
+		assert.Equal(t, wantTypes[i], e.Type)
+		assert.Equal(t, wantDirections[i], e.Direction, "slack takes the direction of the current that follows")
+	}
+	assert.Equal(t, 4.21, response.Events[1].Speed)
+	assert.Equal(t, "2024-06-01T05:58:00", response.Events[1].LocalTime)
+
+	// The range is in the past, so there is no current reading
+	assert.Nil(t, response.Current)
+}
+
+func TestGetCurrentsForStation_Cached(t *testing.T) {
+	var requests atomic.Int32
+	currents := newCurrentsServer(t)
+	defer currents.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		currents.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	predictionCache, err := cache.NewLRUCacheService(config.GetCacheConfig(), nil)
+	require.NoError(t, err)
+	service := &Service{
+		HttpClient: client.New(client.Options{BaseURL: server.URL, Timeout: 5 * time.Second}),
+		CurrentStationFinder: &mockStationFinder2{
+			findStationFn: func(_ context.Context, stationID string) (*models.Station, error) {
+				return currentStation(), nil
+			},
+		},
+		PredictionCache: predictionCache,
+	}
+
+	start, end := stringPtr("2024-06-01T00:00:00"), stringPtr("2024-06-01T23:59:59")
+	first, err := service.GetCurrentsForStation(context.Background(), "PUG1515", start, end)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), requests.Load(), "the curve and the events")
+
+	// Saved in the background, under the station's bin
+	require.Eventually(t, func() bool {
+		record, _ := predictionCache.GetPredictions(context.Background(), "currents:PUG1515#11",
+			time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), "")
+		return record != nil
+	}, 2*time.Second, 10*time.Millisecond)
+
+	second, err := service.GetCurrentsForStation(context.Background(), "PUG1515", start, end)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), requests.Load(), "served from the cache")
+	assert.Equal(t, first.Predictions, second.Predictions)
+	assert.Equal(t, first.Events, second.Events)
+	assert.Equal(t, 190.0, second.FloodDirection)
+	assert.Equal(t, 10.0, second.EbbDirection)
+}
+
+func TestGetCurrentsForStation_EventsOnly(t *testing.T) {
+	// Subordinate current stations have no 6-minute curve
+	currents := newCurrentsServer(t)
+	defer currents.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("interval") == "6" {
+			_, _ = fmt.Fprint(w, `{"error":{"message":"No Predictions data was found."}}`)
+			return
+		}
+		currents.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	saved := make(chan []models.TidePredictionRecord, 1)
+	service := &Service{
+		HttpClient: client.New(client.Options{BaseURL: server.URL, Timeout: 5 * time.Second}),
+		CurrentStationFinder: &mockStationFinder2{
+			findStationFn: func(_ context.Context, stationID string) (*models.Station, error) {
+				return currentStation(), nil
+			},
+		},
+		PredictionCache: &mockStationService2{
+			savePredictionsBatchFn: func(ctx context.Context, records []models.TidePredictionRecord) error {
+				saved <- records
+				return nil
+			},
+		},
+	}
+
+	response, err := service.GetCurrentsForStation(context.Background(), "PUG1515",
+		stringPtr("2024-06-01T00:00:00"), stringPtr("2024-06-01T23:59:59"))
+	require.NoError(t, err)
+	assert.Empty(t, response.Predictions)
+	assert.Nil(t, response.Current)
+	assert.Len(t, response.Events, 4)
+	assert.Equal(t, 190.0, response.FloodDirection, "from the events")
+	assert.Equal(t, 10.0, response.EbbDirection)
+
+	select {
+	case records := <-saved:
+		require.Len(t, records, 1)
+		assert.Equal(t, "currents:PUG1515#11", records[0].StationID)
+		assert.Equal(t, models.StationTypeSubordinate, records[0].StationType)
+		assert.Len(t, records[0].CurrentEvents, 4)
+	case <-time.After(2 * time.Second):
+		t.Fatal("currents were not saved to the cache")
+	}
+}
+
+func TestGetCurrentsForStation_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		finder  models.StationFinder
+		body    string
+		end     string
+		wantErr string
+	}{
+		{
+			name:    "no current station finder",
+			wantErr: "current station finder is not configured",
+		},
+		{
+			name: "unknown station",
+			finder: &mockStationFinder2{
+				findStationFn: func(_ context.Context, stationID string) (*models.Station, error) {
+					return nil, fmt.Errorf("current station not found: %s", stationID)
+				},
+			},
+			wantErr: "current station not found",
+		},
+		{
+			name:    "NOAA error",
+			body:    `{"error":{"message":"No Predictions data was found."}}`,
+			wantErr: "No Predictions data was found",
+		},
+		{
+			name:    "range too long",
+			end:     "2024-08-01T00:00:00",
+			wantErr: "date range cannot exceed 30 days",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			finder := tt.finder
+			if finder == nil && tt.name != "no current station finder" {
+				finder = &mockStationFinder2{
+					findStationFn: func(_ context.Context, _ string) (*models.Station, error) {
+						return currentStation(), nil
+					},
+				}
+			}
+
+			end := "2024-06-02T00:00:00"
+			if tt.end != "" {
+				end = tt.end
+			}
+
+			service := &Service{
+				HttpClient:           client.New(client.Options{BaseURL: server.URL, Timeout: 5 * time.Second}),
+				CurrentStationFinder: finder,
+				PredictionCache:      &mockStationService2{},
+			}
+
+			_, err := service.GetCurrentsForStation(context.Background(), "PUG1515", stringPtr("2024-06-01T00:00:00"), &end)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestInterpolateCurrent(t *testing.T) {
+	t0 := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	predictions := []models.CurrentPrediction{
+		models.NewCurrentPrediction(t0.UnixMilli(), "", 1.0, 190, 10),
+		models.NewCurrentPrediction(t0.Add(6*time.Minute).UnixMilli(), "", -1.0, 190, 10),
+	}
+
+	tests := []struct {
+		name      string
+		at        time.Time
+		want      *float64
+		wantState models.CurrentState
+	}{
+		{name: "before predictions", at: t0.Add(-time.Minute)},
+		{name: "after predictions", at: t0.Add(7 * time.Minute)},
+		{name: "on a prediction", at: t0, want: float64Ptr(1.0), wantState: models.CurrentStateFlood},
+		{name: "turning", at: t0.Add(3 * time.Minute), want: float64Ptr(0), wantState: models.CurrentStateSlack},
+		{name: "between", at: t0.Add(4 * time.Minute), want: float64Ptr(-0.33), wantState: models.CurrentStateEbb},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := interpolateCurrent(predictions, tt.at.UnixMilli(), 190, 10, time.UTC)
+			if tt.want == nil {
+				assert.Nil(t, got)
+				return
+			}
+
+			require.NotNil(t, got)
+			assert.InDelta(t, *tt.want, got.Velocity, 1e-9)
+			assert.Equal(t, tt.wantState, got.State)
+		})
+	}
+}
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
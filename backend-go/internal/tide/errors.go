@@ -1,6 +1,9 @@
 package tide
 
-import "fmt"
+import (
+	"fmt"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+)
 
 // NoaaAPIError represents an error from the NOAA API
 type NoaaAPIError struct {
@@ -27,6 +30,45 @@ func NewNoaaAPIError(message string, err error) *NoaaAPIError {
 	}
 }
 
+// SourceAPIError represents an error from the API of a tide data source other
+// than NOAA
+type SourceAPIError struct {
+	Source  models.Source
+	Message string
+	Err     error
+}
+
+func (e *SourceAPIError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s API error: %s: %v", e.Source, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%s API error: %s", e.Source, e.Message)
+}
+
+func (e *SourceAPIError) Unwrap() error {
+	return e.Err
+}
+
+// NewSourceAPIError creates a new source API error
+func NewSourceAPIError(source models.Source, message string, err error) *SourceAPIError {
+	return &SourceAPIError{
+		Source:  source,
+		Message: message,
+		Err:     err,
+	}
+}
+
+// UnsupportedDatumError is returned when a station's source doesn't publish
+// heights relative to the requested datum
+type UnsupportedDatumError struct {
+	Source models.Source
+	Datum  models.Datum
+}
+
+func (e *UnsupportedDatumError) Error() string {
+	return fmt.Sprintf("%s predictions are not available relative to %s", e.Source, e.Datum)
+}
+
 // Error when user requests data for too much data
 type InvalidRangeError struct {
 	Message string
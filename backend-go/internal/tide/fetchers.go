@@ -0,0 +1,29 @@
+package tide
+
+import (
+	"github.com/bbernstein/flowebb/backend-go/internal/config"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/internal/station"
+	"github.com/bbernstein/flowebb/backend-go/pkg/http/client"
+)
+
+// NewPredictionFetchers creates the fetchers for every non-NOAA source the
+// config enables: CHS, and UKHO when an Admiralty API key is configured
+func NewPredictionFetchers(cfg *config.Config) map[models.Source]PredictionFetcher {
+	newClient := func(baseURL string, headers map[string]string) *client.Client {
+		return client.New(client.Options{
+			BaseURL:    baseURL,
+			Timeout:    cfg.HTTPTimeout,
+			MaxRetries: cfg.MaxRetries,
+			Headers:    headers,
+		})
+	}
+
+	fetchers := map[models.Source]PredictionFetcher{
+		models.SourceCHS: NewCHSPredictionFetcher(newClient(cfg.CHSBaseURL, nil)),
+	}
+	if cfg.UKHOAPIKey != "" {
+		fetchers[models.SourceUKHO] = NewUKHOPredictionFetcher(newClient(cfg.UKHOBaseURL, map[string]string{station.UKHOAPIKeyHeader: cfg.UKHOAPIKey}))
+	}
+	return fetchers
+}
@@ -0,0 +1,19 @@
+package tide
+
+import (
+	"testing"
+
+	"github.com/bbernstein/flowebb/backend-go/internal/config"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPredictionFetchers(t *testing.T) {
+	fetchers := NewPredictionFetchers(config.New())
+	assert.IsType(t, &CHSPredictionFetcher{}, fetchers[models.SourceCHS])
+	assert.NotContains(t, fetchers, models.SourceUKHO, "UKHO needs an API key")
+	assert.NotContains(t, fetchers, models.SourceNOAA)
+
+	fetchers = NewPredictionFetchers(config.New(config.WithUKHOAPIKey("key")))
+	assert.IsType(t, &UKHOPredictionFetcher{}, fetchers[models.SourceUKHO])
+}
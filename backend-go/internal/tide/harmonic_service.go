@@ -0,0 +1,211 @@
+package tide
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/bbernstein/flowebb/backend-go/internal/harmonics"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/internal/timezone"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+// Harmonic predictions are computed locally, so they are not bound by NOAA's 30-day limit
+const harmonicMaxRangeDays = 366
+
+// HarmonicService predicts tides for reference stations from their harmonic
+// constituents, without calling NOAA.
+type HarmonicService struct {
+	StationFinder models.StationFinder
+	Constituents  harmonics.ConstituentStore
+}
+
+var _ TideService = (*HarmonicService)(nil)
+
+// NewHarmonicService creates a harmonic tide service. If store is nil the
+// constituent sets embedded in the harmonics package are used.
+func NewHarmonicService(stationFinder models.StationFinder, store harmonics.ConstituentStore) (*HarmonicService, error) {
+	if stationFinder == nil {
+		return nil, fmt.Errorf("station finder is required")
+	}
+	if store == nil {
+		store = harmonics.NewEmbeddedStore()
+	}
+
+	return &HarmonicService{
+		StationFinder: stationFinder,
+		Constituents:  store,
+	}, nil
+}
+
+func (s *HarmonicService) GetCurrentTide(ctx context.Context, lat, lon float64, startTimeStr, endTimeStr *string, datum models.Datum, units models.Units) (*models.ExtendedTideResponse, error) {
+	if lat < -90 || lat > 90 {
+		return nil, fmt.Errorf("invalid latitude: %f", lat)
+	}
+	if lon < -180 || lon > 180 {
+		return nil, fmt.Errorf("invalid longitude: %f", lon)
+	}
+
+	stations, err := s.StationFinder.FindNearestStations(ctx, lat, lon, 1, nil)
+	if err != nil {
+		return nil, fmt.Errorf("finding nearest station: %w", err)
+	}
+	if len(stations) == 0 {
+		return nil, fmt.Errorf("no stations found near coordinates")
+	}
+
+	response, err := s.GetCurrentTideForStation(ctx, stations[0].ID, startTimeStr, endTimeStr, datum, units)
+	if err != nil {
+		return nil, fmt.Errorf("getting current tide: %w", err)
+	}
+
+	return response, nil
+}
+
+func (s *HarmonicService) GetCurrentTideForStation(ctx context.Context, stationID string, startTimeStr, endTimeStr *string, datum models.Datum, units models.Units) (*models.ExtendedTideResponse, error) {
+	log.Debug().Str("station_id", stationID).Msg("Computing harmonic tide for station")
+
+	datum = datum.OrDefault()
+	if err := datum.Validate(); err != nil {
+		return nil, err
+	}
+	units = units.OrDefault()
+	if err := units.Validate(); err != nil {
+		return nil, err
+	}
+
+	localStation, err := s.StationFinder.FindStation(ctx, stationID)
+	if err != nil {
+		return nil, fmt.Errorf("finding localStation: %w", err)
+	}
+
+	location := timezone.ForStation(localStation)
+	now := time.Now().In(location)
+
+	startTime, endTime, err := parseTimeRange(startTimeStr, endTimeStr, now, location)
+	if err != nil {
+		return nil, err
+	}
+
+	if endTime.Sub(startTime) > harmonicMaxRangeDays*24*time.Hour {
+		return nil, NewInvalidRangeError(fmt.Sprintf("date range cannot exceed %d days", harmonicMaxRangeDays))
+	}
+
+	set, err := s.Constituents.GetConstituents(localStation.ID)
+	if err != nil {
+		return nil, fmt.Errorf("loading harmonic constituents: %w", err)
+	}
+
+	predictor, err := harmonics.NewPredictor(set)
+	if err != nil {
+		return nil, fmt.Errorf("creating harmonic predictor: %w", err)
+	}
+
+	datumOffset, err := harmonicDatumOffset(set, datum)
+	if err != nil {
+		return nil, err
+	}
+	height := func(h float64) float64 {
+		return roundHeight(models.ConvertHeight(h+datumOffset, models.Units(predictor.Units()), units))
+	}
+
+	predictions, extremes := harmonicCurve(predictor, startTime, endTime, location, height)
+	currentLevel := height(predictor.HeightAt(now))
+
+	response, err := newTideResponse(localStation, now, currentLevel, predictions, extremes, "Harmonic", datum, units)
+	if err != nil {
+		return nil, err
+	}
+	response.Days = summarizeDays(predictions, extremes, startTime, endTime, location)
+
+	return response, nil
+}
+
+// harmonicDatumOffset returns what to add to predicted heights to express them
+// relative to datum. Constituent sets predict heights above their own datum and
+// record the height of MSL above it, so only those two datums are available.
+func harmonicDatumOffset(set *harmonics.ConstituentSet, datum models.Datum) (float64, error) {
+	switch {
+	case datum == models.Datum(set.Datum):
+		return 0, nil
+	case datum == models.DatumMSL:
+		return -set.MeanSeaLevel, nil
+	default:
+		return 0, fmt.Errorf("datum %s is not available from harmonic constituents for station %s", datum, set.StationID)
+	}
+}
+
+// harmonicCurve predicts the water level every 6 minutes from start to end,
+// and the highs and lows between them, with height applied to each
+func harmonicCurve(predictor *harmonics.Predictor, start, end time.Time, location *time.Location, height func(float64) float64) ([]models.TidePrediction, []models.TideExtreme) {
+	points := predictor.Predict(start, end, 6*time.Minute)
+	predictions := make([]models.TidePrediction, len(points))
+	for i, p := range points {
+		timestamp := p.Time.UnixMilli()
+		predictions[i] = models.TidePrediction{
+			Timestamp: timestamp,
+			LocalTime: formatLocalTime(timestamp, location),
+			Height:    height(p.Height),
+		}
+	}
+
+	turningPoints := predictor.Extremes(start, end)
+	extremes := make([]models.TideExtreme, len(turningPoints))
+	for i, e := range turningPoints {
+		tideType := models.TideTypeLow
+		if e.High {
+			tideType = models.TideTypeHigh
+		}
+		timestamp := e.Time.UnixMilli()
+		extremes[i] = models.TideExtreme{
+			Type:      tideType,
+			Timestamp: timestamp,
+			LocalTime: formatLocalTime(timestamp, location),
+			Height:    height(e.Height),
+		}
+	}
+	return predictions, extremes
+}
+
+// harmonicPredictor returns a predictor for a reference station from
+// its harmonic constituents, and what to add to its heights for the datum.
+// It returns false for stations without constituents, and for datums they
+// can't be expressed in, which are left to NOAA.
+func (s *Service) harmonicPredictor(station *models.Station, datum models.Datum) (*harmonics.Predictor, float64, bool) {
+	if s.Harmonics == nil || (station.StationType != nil && *station.StationType == "S") {
+		return nil, 0, false
+	}
+
+	set, err := s.Harmonics.GetConstituents(station.ID)
+	if err != nil {
+		if !errors.Is(err, harmonics.ErrConstituentsNotFound) {
+			log.Warn().Err(err).Str("station_id", station.ID).Msg("Error loading harmonic constituents")
+		}
+		return nil, 0, false
+	}
+	predictor, err := harmonics.NewPredictor(set)
+	if err != nil {
+		log.Warn().Err(err).Str("station_id", station.ID).Msg("Error creating harmonic predictor")
+		return nil, 0, false
+	}
+	datumOffset, err := harmonicDatumOffset(set, datum)
+	if err != nil {
+		return nil, 0, false
+	}
+	return predictor, datumOffset, true
+}
+
+// harmonicPredictions predicts a station's tides from start to end, in its
+// constituents' units, when harmonicPredictor can
+func (s *Service) harmonicPredictions(station *models.Station, start, end time.Time, location *time.Location, datum models.Datum) ([]models.TidePrediction, []models.TideExtreme, models.Units, bool) {
+	predictor, datumOffset, ok := s.harmonicPredictor(station, datum)
+	if !ok {
+		return nil, nil, "", false
+	}
+
+	predictions, extremes := harmonicCurve(predictor, start, end, location, func(h float64) float64 {
+		return roundHeight(h + datumOffset)
+	})
+	return predictions, extremes, models.Units(predictor.Units()), true
+}
@@ -0,0 +1,236 @@
+package tide
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/bbernstein/flowebb/backend-go/internal/harmonics"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/pkg/http/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+const testConstituents = `{
+  "stationId": "TEST001",
+  "units": "feet",
+  "datum": "MLLW",
+  "msl": 6.0,
+  "HarmonicConstituents": [
+    { "name": "M2", "amplitude": 3.4, "phase_GMT": 10.0 },
+    { "name": "S2", "amplitude": 0.8, "phase_GMT": 40.0 },
+    { "name": "K1", "amplitude": 2.5, "phase_GMT": 255.0 },
+    { "name": "O1", "amplitude": 1.4, "phase_GMT": 235.0 }
+  ]
+}`
+
+func newTestHarmonicService(t *testing.T) *HarmonicService {
+	t.Helper()
+	store := harmonics.NewFileStore(fstest.MapFS{
+		"TEST001.json": &fstest.MapFile{Data: []byte(testConstituents)},
+	})
+	finder := &mockStationFinder2{
+		findStationFn: func(ctx context.Context, stationID string) (*models.Station, error) {
+			if stationID != "TEST001" && stationID != "NOCONST" {
+				return nil, errors.New("station not found")
+			}
+			station := createTestStation(-8 * 3600)
+			station.ID = stationID
+			return station, nil
+		},
+		findNearestStationsFn: func(ctx context.Context, lat, lon float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
+			return []models.Station{*createTestStation(-8 * 3600)}, nil
+		},
+	}
+
+	service, err := NewHarmonicService(finder, store)
+	require.NoError(t, err)
+	return service
+}
+
+func TestNewHarmonicService(t *testing.T) {
+	_, err := NewHarmonicService(nil, nil)
+	assert.ErrorContains(t, err, "station finder is required")
+
+	service, err := NewHarmonicService(&mockStationFinder2{}, nil)
+	require.NoError(t, err)
+	assert.NotNil(t, service.Constituents)
+}
+
+func TestHarmonicService_GetCurrentTideForStation(t *testing.T) {
+	service := newTestHarmonicService(t)
+
+	response, err := service.GetCurrentTideForStation(context.Background(), "TEST001",
+		stringPtr("2024-01-01T00:00:00"), stringPtr("2024-01-02T00:00:00"), models.DatumMLLW, models.UnitsFeet)
+	require.NoError(t, err)
+
+	assert.Equal(t, "tide", response.ResponseType)
+	assert.Equal(t, "Harmonic", response.CalculationMethod)
+	assert.Equal(t, "TEST001", response.NearestStation)
+	require.Len(t, response.Predictions, 241)
+	assert.Equal(t, "2024-01-01T00:00:00", response.Predictions[0].LocalTime)
+	assert.Equal(t, "2024-01-02T00:00:00", response.Predictions[240].LocalTime)
+
+	// A mixed semidiurnal tide has three or four turning points a day
+	assert.GreaterOrEqual(t, len(response.Extremes), 3)
+	assert.LessOrEqual(t, len(response.Extremes), 5)
+	for i, e := range response.Extremes {
+		assert.Contains(t, []models.TideType{models.TideTypeHigh, models.TideTypeLow}, e.Type)
+		if i > 0 {
+			assert.NotEqual(t, response.Extremes[i-1].Type, e.Type)
+		}
+	}
+
+	// Harmonic predictions have no sensor data behind them
+	assert.Nil(t, response.WaterLevel)
+	assert.Equal(t, models.ObservationStatusUnavailable, response.ObservationStatus)
+	require.NotNil(t, response.PredictedLevel)
+	assert.InDelta(t, 6.0, *response.PredictedLevel, 3.4*1.04+0.8+2.5*1.12+1.4*1.19)
+}
+
+func TestHarmonicService_LongRange(t *testing.T) {
+	service := newTestHarmonicService(t)
+
+	// Ranges beyond NOAA's 30 days are allowed
+	response, err := service.GetCurrentTideForStation(context.Background(), "TEST001",
+		stringPtr("2024-01-01T00:00:00"), stringPtr("2024-03-01T00:00:00"), models.DatumMLLW, models.UnitsFeet)
+	require.NoError(t, err)
+	assert.Len(t, response.Predictions, 60*240+1)
+
+	_, err = service.GetCurrentTideForStation(context.Background(), "TEST001",
+		stringPtr("2024-01-01T00:00:00"), stringPtr("2025-06-01T00:00:00"), models.DatumMLLW, models.UnitsFeet)
+	var rangeErr *InvalidRangeError
+	assert.ErrorAs(t, err, &rangeErr)
+}
+
+func TestHarmonicService_Datum(t *testing.T) {
+	service := newTestHarmonicService(t)
+	ctx := context.Background()
+	start, end := stringPtr("2024-01-01T00:00:00"), stringPtr("2024-01-02T00:00:00")
+
+	mllw, err := service.GetCurrentTideForStation(ctx, "TEST001", start, end, models.DatumMLLW, models.UnitsFeet)
+	require.NoError(t, err)
+	assert.Equal(t, models.DatumMLLW, mllw.Datum)
+
+	// MSL heights are the MLLW heights less the set's MSL (6 ft)
+	msl, err := service.GetCurrentTideForStation(ctx, "TEST001", start, end, models.DatumMSL, models.UnitsFeet)
+	require.NoError(t, err)
+	assert.Equal(t, models.DatumMSL, msl.Datum)
+	require.Len(t, msl.Predictions, len(mllw.Predictions))
+	for i := range msl.Predictions {
+		assert.InDelta(t, mllw.Predictions[i].Height-6.0, msl.Predictions[i].Height, 0.0011)
+	}
+
+	_, err = service.GetCurrentTideForStation(ctx, "TEST001", start, end, models.DatumNAVD88, models.UnitsFeet)
+	assert.ErrorContains(t, err, "datum NAVD88 is not available")
+
+	_, err = service.GetCurrentTideForStation(ctx, "TEST001", start, end, models.Datum("BOGUS"), models.UnitsFeet)
+	assert.ErrorContains(t, err, "invalid datum")
+}
+
+func TestHarmonicService_Units(t *testing.T) {
+	service := newTestHarmonicService(t)
+	ctx := context.Background()
+	start, end := stringPtr("2024-01-01T00:00:00"), stringPtr("2024-01-02T00:00:00")
+
+	feet, err := service.GetCurrentTideForStation(ctx, "TEST001", start, end, models.DatumMLLW, models.UnitsFeet)
+	require.NoError(t, err)
+	assert.Equal(t, models.UnitsFeet, feet.Units)
+
+	meters, err := service.GetCurrentTideForStation(ctx, "TEST001", start, end, models.DatumMLLW, models.UnitsMeters)
+	require.NoError(t, err)
+	assert.Equal(t, models.UnitsMeters, meters.Units)
+	require.Len(t, meters.Predictions, len(feet.Predictions))
+	for i := range meters.Predictions {
+		assert.InDelta(t, feet.Predictions[i].Height*0.3048, meters.Predictions[i].Height, 0.001)
+	}
+}
+
+func TestHarmonicService_Errors(t *testing.T) {
+	service := newTestHarmonicService(t)
+	ctx := context.Background()
+
+	_, err := service.GetCurrentTideForStation(ctx, "NOCONST", nil, nil, models.DatumMLLW, models.UnitsFeet)
+	assert.ErrorIs(t, err, harmonics.ErrConstituentsNotFound)
+
+	_, err = service.GetCurrentTideForStation(ctx, "MISSING", nil, nil, models.DatumMLLW, models.UnitsFeet)
+	assert.ErrorContains(t, err, "station not found")
+
+	_, err = service.GetCurrentTideForStation(ctx, "TEST001", stringPtr("not-a-time"), nil, models.DatumMLLW, models.UnitsFeet)
+	assert.ErrorContains(t, err, "parsing start time")
+
+	_, err = service.GetCurrentTide(ctx, 95, 0, nil, nil, models.DatumMLLW, models.UnitsFeet)
+	assert.ErrorContains(t, err, "invalid latitude")
+}
+
+func TestHarmonicService_GetCurrentTide(t *testing.T) {
+	service := newTestHarmonicService(t)
+
+	response, err := service.GetCurrentTide(context.Background(), 47.6, -122.3, nil, nil, models.DatumMLLW, models.UnitsFeet)
+	require.NoError(t, err)
+	assert.Equal(t, "TEST001", response.NearestStation)
+
+	// Default range is the current local day
+	now := time.Now().In(time.FixedZone("Station", -8*3600))
+	assert.Equal(t, now.Format("2006-01-02")+"T00:00:00", response.Predictions[0].LocalTime)
+}
+
+func TestGetCurrentTideForStation_Harmonics(t *testing.T) {
+	var noaaPredictions atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("product") == "predictions" {
+			noaaPredictions.Add(1)
+		}
+		_, _ = fmt.Fprint(w, `{"error":{"message":"No data was found."}}`)
+	}))
+	defer server.Close()
+
+	service := &Service{
+		HttpClient: client.New(client.Options{BaseURL: server.URL, Timeout: 5 * time.Second, MaxRetries: 1}),
+		StationFinder: &mockStationFinder2{
+			findStationFn: func(ctx context.Context, stationID string) (*models.Station, error) {
+				station := createTestStation(-8 * 3600)
+				station.ID = stationID
+				return station, nil
+			},
+		},
+		PredictionCache: &mockStationService2{},
+		Harmonics: harmonics.NewFileStore(fstest.MapFS{
+			"TEST001.json": &fstest.MapFile{Data: []byte(testConstituents)},
+		}),
+	}
+	start, end := stringPtr("2026-07-01T00:00:00"), stringPtr("2026-07-02T00:00:00")
+
+	// Stations with constituents are predicted without NOAA
+	response, err := service.GetCurrentTideForStation(context.Background(), "TEST001", start, end, models.DatumMLLW, models.UnitsFeet)
+	require.NoError(t, err)
+	assert.Equal(t, "Harmonic", response.CalculationMethod)
+	assert.NotEmpty(t, response.Predictions)
+	assert.Len(t, response.Extremes, 4, "mixed semidiurnal")
+	assert.Zero(t, noaaPredictions.Load())
+
+	// As are they relative to MSL, from the constituents' mean level
+	msl, err := service.GetCurrentTideForStation(context.Background(), "TEST001", start, end, models.DatumMSL, models.UnitsFeet)
+	require.NoError(t, err)
+	assert.InDelta(t, response.Extremes[0].Height-6.0, msl.Extremes[0].Height, 0.001)
+	assert.Zero(t, noaaPredictions.Load())
+
+	// Other datums, and stations without constituents, are left to NOAA
+	for _, request := range []struct {
+		stationID string
+		datum     models.Datum
+	}{
+		{"TEST001", models.DatumNAVD88},
+		{"NOCONST", models.DatumMLLW},
+	} {
+		before := noaaPredictions.Load()
+		_, _ = service.GetCurrentTideForStation(context.Background(), request.stationID, start, end, request.datum, models.UnitsFeet)
+		assert.Greater(t, noaaPredictions.Load(), before, "%s relative to %s", request.stationID, request.datum)
+	}
+}
@@ -7,12 +7,56 @@ import (
 )
 
 type TideService interface {
-	GetCurrentTide(ctx context.Context, lat, lon float64, startTimeStr, endTimeStr *string) (*models.ExtendedTideResponse, error)
-	GetCurrentTideForStation(ctx context.Context, stationID string, startTimeStr, endTimeStr *string) (*models.ExtendedTideResponse, error)
+	GetCurrentTide(ctx context.Context, lat, lon float64, startTimeStr, endTimeStr *string, datum models.Datum, units models.Units) (*models.ExtendedTideResponse, error)
+	GetCurrentTideForStation(ctx context.Context, stationID string, startTimeStr, endTimeStr *string, datum models.Datum, units models.Units) (*models.ExtendedTideResponse, error)
+}
+
+// BlendedTideService estimates the tide at a point between stations from
+// several of the nearest
+type BlendedTideService interface {
+	GetBlendedTide(ctx context.Context, lat, lon float64, stationCount int, startTimeStr, endTimeStr *string, datum models.Datum, units models.Units) (*models.ExtendedTideResponse, error)
+}
+
+// CurrentService predicts tidal currents at current prediction stations
+type CurrentService interface {
+	GetCurrents(ctx context.Context, lat, lon float64, startTimeStr, endTimeStr *string) (*models.CurrentResponse, error)
+	GetCurrentsForStation(ctx context.Context, stationID string, startTimeStr, endTimeStr *string) (*models.CurrentResponse, error)
+}
+
+// WindowService finds when the water is above or below a level
+type WindowService interface {
+	GetTideWindows(ctx context.Context, stationID string, startTimeStr, endTimeStr *string, query models.TideWindowQuery) ([]models.TideWindow, error)
+}
+
+// ConditionsProvider supplies the latest met readings for a station
+type ConditionsProvider interface {
+	GetConditions(ctx context.Context, station *models.Station, units models.Units) (*models.MarineConditions, error)
+}
+
+// PredictionFetcher fetches tide predictions for stations from a source other
+// than NOAA, covering start up to end. Heights are in Units, relative to the
+// datum. Sources that only publish high and low waters return nil predictions.
+type PredictionFetcher interface {
+	FetchPredictions(ctx context.Context, station *models.Station, start, end time.Time, location *time.Location, datum models.Datum) ([]models.TidePrediction, []models.TideExtreme, error)
+	Units() models.Units
+}
+
+// ExtremesOnlyFetcher is implemented by PredictionFetchers whose source only
+// publishes high and low waters. Their curves are interpolated from extremes
+// fetched a day either side of the range, as for NOAA subordinate stations.
+type ExtremesOnlyFetcher interface {
+	ExtremesOnly() bool
+}
+
+// SingleDatumFetcher is implemented by PredictionFetchers whose source only
+// publishes heights relative to one datum. Requests for its stations that
+// don't name a datum get that one.
+type SingleDatumFetcher interface {
+	Datum() models.Datum
 }
 
 type CacheProvider interface {
-	GetPredictions(ctx context.Context, stationID string, date time.Time) (*models.TidePredictionRecord, error)
+	GetPredictions(ctx context.Context, stationID string, date time.Time, datum models.Datum) (*models.TidePredictionRecord, error)
 	SavePredictions(ctx context.Context, record models.TidePredictionRecord) error
 	SavePredictionsBatch(ctx context.Context, records []models.TidePredictionRecord) error
 	GetCacheStats() map[string]uint64
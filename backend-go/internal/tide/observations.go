@@ -0,0 +1,129 @@
+package tide
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/rs/zerolog/log"
+	"strconv"
+	"time"
+)
+
+// getLatestObservation returns the station's most recent water level relative
+// to datum and how it was obtained. The observation is nil unless the status is
+// ObservationStatusObserved.
+func (s *Service) getLatestObservation(ctx context.Context, station *models.Station, datum models.Datum) (*models.WaterLevelObservation, models.ObservationStatus) {
+	// Subordinate stations only have predictions, never a gauge
+	if station.StationType != nil && *station.StationType == models.StationTypeSubordinate {
+		return nil, models.ObservationStatusNoSensorData
+	}
+
+	// Only NOAA's water levels are fetched so far
+	if station.Source != "" && station.Source != models.SourceNOAA {
+		return nil, models.ObservationStatusUnavailable
+	}
+
+	if s.ObservationCache != nil {
+		if observation, ok := s.ObservationCache.GetObservation(station.ID, datum); ok {
+			return observation, observationStatus(observation)
+		}
+	}
+
+	observation, err := s.fetchNoaaWaterLevel(ctx, station.ID, datum)
+	if err != nil {
+		log.Warn().Err(err).
+			Str("station_id", station.ID).
+			Msg("Error fetching water level observation from NOAA")
+		return nil, models.ObservationStatusUnavailable
+	}
+
+	if s.ObservationCache != nil {
+		s.ObservationCache.SetObservation(station.ID, datum, observation)
+	}
+
+	return observation, observationStatus(observation)
+}
+
+func observationStatus(observation *models.WaterLevelObservation) models.ObservationStatus {
+	if observation == nil {
+		return models.ObservationStatusNoSensorData
+	}
+	return models.ObservationStatusObserved
+}
+
+// fetchNoaaWaterLevel fetches the latest 6-minute verified or preliminary
+// water level in feet. It returns nil without an error when NOAA has no
+// recent data for the station.
+func (s *Service) fetchNoaaWaterLevel(ctx context.Context, stationID string, datum models.Datum) (*models.WaterLevelObservation, error) {
+	resp, err := s.HttpClient.Get(ctx, fmt.Sprintf("/api/prod/datagetter"+
+		"?station=%s&date=latest&product=water_level&datum=%s"+
+		"&units=english&time_zone=gmt&format=json",
+		stationID, datum))
+	if err != nil {
+		return nil, NewNoaaAPIError("error making HTTP request for water level", err)
+	}
+
+	var noaaResp models.NoaaWaterLevelResponse
+	if err := json.Unmarshal(resp.Body, &noaaResp); err != nil {
+		return nil, NewNoaaAPIError("error decoding water level response", err)
+	}
+
+	if noaaResp.Error != nil {
+		// NOAA reports stations without a sensor, or without recent data, as an error
+		log.Debug().
+			Str("station_id", stationID).
+			Str("message", noaaResp.Error.Message).
+			Msg("No water level data from NOAA")
+		return nil, nil
+	}
+
+	// Use the most recent reading that has a value
+	for i := len(noaaResp.Data) - 1; i >= 0; i-- {
+		reading := noaaResp.Data[i]
+		if reading.Value == "" {
+			continue
+		}
+
+		timestamp, err := parseNoaaTime(reading.Time, time.UTC, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		height, err := strconv.ParseFloat(reading.Value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing water level %s: %w", reading.Value, err)
+		}
+
+		return &models.WaterLevelObservation{
+			StationID: stationID,
+			Timestamp: timestamp,
+			Height:    height,
+			Datum:     datum,
+			Units:     models.UnitsFeet,
+			Quality:   reading.Quality,
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// applyObservation reports the observation on the response in the requested
+// units, along with its residual against the prediction for the same time.
+// The residual is left out when there's no prediction for that time.
+func applyObservation(response *models.ExtendedTideResponse, observation *models.WaterLevelObservation, status models.ObservationStatus, predictedAtObservation *float64, now time.Time, units models.Units) {
+	response.ObservationStatus = status
+	if observation == nil {
+		return
+	}
+
+	level := roundHeight(models.ConvertHeight(observation.Height, observation.Units, units))
+	age := (now.UnixMilli() - observation.Timestamp) / 1000
+
+	response.WaterLevel = &level
+	response.ObservationAgeSeconds = &age
+	if predictedAtObservation != nil {
+		residual := roundHeight(models.ConvertHeight(observation.Height-*predictedAtObservation, observation.Units, units))
+		response.Residual = &residual
+	}
+}
@@ -0,0 +1,251 @@
+package tide
+
+import (
+	"context"
+	"fmt"
+	"github.com/bbernstein/flowebb/backend-go/internal/cache"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/pkg/http/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFetchNoaaWaterLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    *models.WaterLevelObservation
+		wantErr string
+	}{
+		{
+			name: "latest reading",
+			body: `{"metadata":{"id":"9447130"},"data":[` +
+				`{"t":"2024-06-01 17:54","v":"6.120","s":"0.010","f":"0,0,0,0","q":"p"},` +
+				`{"t":"2024-06-01 18:00","v":"6.254","s":"0.012","f":"0,0,0,0","q":"p"}]}`,
+			want: &models.WaterLevelObservation{
+				StationID: "9447130",
+				Timestamp: time.Date(2024, 6, 1, 18, 0, 0, 0, time.UTC).UnixMilli(),
+				Height:    6.254,
+				Datum:     models.DatumMLLW,
+				Units:     models.UnitsFeet,
+				Quality:   "p",
+			},
+		},
+		{
+			name: "skips readings without a value",
+			body: `{"data":[{"t":"2024-06-01 17:54","v":"6.120","q":"v"},{"t":"2024-06-01 18:00","v":"","q":""}]}`,
+			want: &models.WaterLevelObservation{
+				StationID: "9447130",
+				Timestamp: time.Date(2024, 6, 1, 17, 54, 0, 0, time.UTC).UnixMilli(),
+				Height:    6.12,
+				Datum:     models.DatumMLLW,
+				Units:     models.UnitsFeet,
+				Quality:   "v",
+			},
+		},
+		{
+			name: "station without a sensor",
+			body: `{"error":{"message":"No data was found. This product may not be offered at this station at the requested time."}}`,
+		},
+		{
+			name: "no readings",
+			body: `{"data":[]}`,
+		},
+		{
+			name:    "malformed response",
+			body:    `not json`,
+			wantErr: "error decoding water level response",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "water_level", r.URL.Query().Get("product"))
+				assert.Equal(t, "latest", r.URL.Query().Get("date"))
+				assert.Equal(t, "MLLW", r.URL.Query().Get("datum"))
+				_, _ = fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			service := &Service{
+				HttpClient: client.New(client.Options{BaseURL: server.URL, Timeout: 5 * time.Second}),
+			}
+
+			observation, err := service.fetchNoaaWaterLevel(context.Background(), "9447130", models.DatumMLLW)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, observation)
+		})
+	}
+}
+
+func TestGetLatestObservation(t *testing.T) {
+	referenceType := "R"
+	subordinateType := "S"
+
+	tests := []struct {
+		name         string
+		stationType  *string
+		handler      http.HandlerFunc
+		wantStatus   models.ObservationStatus
+		wantRequests int
+	}{
+		{
+			name:        "observed",
+			stationType: &referenceType,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = fmt.Fprint(w, `{"data":[{"t":"2024-06-01 18:00","v":"6.254","q":"p"}]}`)
+			},
+			wantStatus:   models.ObservationStatusObserved,
+			wantRequests: 1,
+		},
+		{
+			name:        "no sensor data is remembered",
+			stationType: &referenceType,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = fmt.Fprint(w, `{"error":{"message":"No data was found."}}`)
+			},
+			wantStatus:   models.ObservationStatusNoSensorData,
+			wantRequests: 1,
+		},
+		{
+			name:        "failed requests are retried",
+			stationType: &referenceType,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+				_, _ = fmt.Fprint(w, `<html>Bad Gateway</html>`)
+			},
+			wantStatus:   models.ObservationStatusUnavailable,
+			wantRequests: 2,
+		},
+		{
+			name:        "subordinate stations have no sensor",
+			stationType: &subordinateType,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				t.Error("subordinate stations should not be queried")
+			},
+			wantStatus:   models.ObservationStatusNoSensorData,
+			wantRequests: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				tt.handler(w, r)
+			}))
+			defer server.Close()
+
+			service := &Service{
+				HttpClient:       client.New(client.Options{BaseURL: server.URL, Timeout: 5 * time.Second}),
+				ObservationCache: cache.NewObservationCache(nil),
+			}
+			station := &models.Station{ID: "9447130", StationType: tt.stationType}
+
+			for i := 0; i < 2; i++ {
+				observation, status := service.getLatestObservation(context.Background(), station, models.DatumMLLW)
+				assert.Equal(t, tt.wantStatus, status)
+				assert.Equal(t, status == models.ObservationStatusObserved, observation != nil)
+			}
+			assert.Equal(t, tt.wantRequests, requests)
+		})
+	}
+}
+
+func TestGetCurrentTideForStation_Observation(t *testing.T) {
+	now := time.Now().UTC()
+	observedAt := now.Add(-5 * time.Minute)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "water_level", r.URL.Query().Get("product"))
+		_, _ = fmt.Fprintf(w, `{"data":[{"t":"%s","v":"5.400","q":"p"}]}`, observedAt.Format("2006-01-02 15:04"))
+	}))
+	defer server.Close()
+
+	// A flat 5 ft prediction, so the residual is independent of timing
+	predictionCache := &mockStationService2{
+		getPredictionsFn: func(_ context.Context, stationID string, date time.Time) (*models.TidePredictionRecord, error) {
+			record := &models.TidePredictionRecord{
+				StationID:   stationID,
+				Date:        date.Format("2006-01-02"),
+				StationType: "R",
+			}
+			for ts := date; ts.Before(date.AddDate(0, 0, 1)); ts = ts.Add(6 * time.Minute) {
+				record.Predictions = append(record.Predictions, models.TidePrediction{
+					Timestamp: ts.UnixMilli(),
+					LocalTime: formatLocalTime(ts.UnixMilli(), time.UTC),
+					Height:    5.0,
+				})
+			}
+			return record, nil
+		},
+	}
+
+	pastStart := now.AddDate(0, 0, -10).Format("2006-01-02T00:00:00")
+	pastEnd := now.AddDate(0, 0, -9).Format("2006-01-02T00:00:00")
+
+	tests := []struct {
+		name         string
+		start, end   *string
+		units        models.Units
+		wantLevel    float64
+		wantResidual *float64
+	}{
+		{name: "feet", units: models.UnitsFeet, wantLevel: 5.4, wantResidual: float64Ptr(0.4)},
+		{name: "meters", units: models.UnitsMeters, wantLevel: 1.646, wantResidual: float64Ptr(0.122)},
+		{
+			// The observation is after the predictions, so there's no residual
+			name:      "past range",
+			start:     stringPtr(pastStart),
+			end:       stringPtr(pastEnd),
+			units:     models.UnitsFeet,
+			wantLevel: 5.4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &Service{
+				HttpClient: client.New(client.Options{BaseURL: server.URL, Timeout: 5 * time.Second}),
+				StationFinder: &mockStationFinder2{
+					findStationFn: func(_ context.Context, _ string) (*models.Station, error) {
+						return createTestStation(0), nil
+					},
+				},
+				PredictionCache: predictionCache,
+			}
+
+			response, err := service.GetCurrentTideForStation(context.Background(), "TEST001", tt.start, tt.end, models.DatumMLLW, tt.units)
+			require.NoError(t, err)
+
+			assert.Equal(t, models.ObservationStatusObserved, response.ObservationStatus)
+			require.NotNil(t, response.WaterLevel)
+			assert.InDelta(t, tt.wantLevel, *response.WaterLevel, 1e-9)
+			if tt.wantResidual == nil {
+				assert.Nil(t, response.Residual)
+			} else {
+				require.NotNil(t, response.Residual)
+				assert.InDelta(t, *tt.wantResidual, *response.Residual, 1e-9)
+			}
+			require.NotNil(t, response.PredictedLevel)
+			assert.InDelta(t, models.ConvertHeight(5.0, models.UnitsFeet, tt.units), *response.PredictedLevel, 0.001)
+
+			// NOAA timestamps are to the minute
+			require.NotNil(t, response.ObservationAgeSeconds)
+			assert.GreaterOrEqual(t, *response.ObservationAgeSeconds, int64(5*60))
+			assert.Less(t, *response.ObservationAgeSeconds, int64(7*60))
+		})
+	}
+}
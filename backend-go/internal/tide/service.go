@@ -4,14 +4,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/bbernstein/flowebb/backend-go/internal/astronomy"
 	"github.com/bbernstein/flowebb/backend-go/internal/cache"
+	"github.com/bbernstein/flowebb/backend-go/internal/coalesce"
 	"github.com/bbernstein/flowebb/backend-go/internal/config"
+	"github.com/bbernstein/flowebb/backend-go/internal/harmonics"
+	"github.com/bbernstein/flowebb/backend-go/internal/met"
 	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/internal/timezone"
 	"github.com/bbernstein/flowebb/backend-go/pkg/http/client"
 	"github.com/rs/zerolog/log"
 	"math"
 	"sort"
 	"strconv"
+	"sync"
 	"time"
 )
 
@@ -23,6 +29,33 @@ type Service struct {
 	HttpClient      *client.Client
 	StationFinder   models.StationFinder
 	PredictionCache cache.CacheService
+
+	// Latest water level observations; observations are not cached when nil
+	ObservationCache *cache.ObservationCache
+
+	// Finds current prediction stations; currents are unavailable when nil
+	CurrentStationFinder models.StationFinder
+
+	// Latest met readings; responses have no conditions when nil
+	Conditions ConditionsProvider
+
+	// Harmonic constituents of NOAA reference stations, which are predicted
+	// from them instead of fetched from NOAA. Stations without them, and
+	// every station when nil, are fetched.
+	Harmonics harmonics.ConstituentStore
+
+	// Predictions for stations from sources other than NOAA, by source.
+	// Stations from sources without one use NOAA's API.
+	PredictionFetchers map[models.Source]PredictionFetcher
+
+	// Subordinate station offsets by station ID, fetched on first use
+	offsets sync.Map
+
+	// Counts requests for each station, so the most requested can be warmed
+	RequestCounter cache.RequestCounter
+
+	// Coalesces concurrent fetches of the same predictions
+	predictionFetches coalesce.Group
 }
 
 type DefaultServiceFactory struct{}
@@ -30,7 +63,26 @@ type DefaultServiceFactory struct{}
 func (f *DefaultServiceFactory) NewService(ctx context.Context, httpClient *client.Client, finder models.StationFinder) (*Service, error) {
 	return NewService(ctx, httpClient, finder)
 }
-func NewService(ctx context.Context, httpClient *client.Client, stationFinder models.StationFinder) (*Service, error) {
+
+type ServiceOption func(*Service)
+
+// WithPredictionCache caches predictions in the given cache instead of the
+// layers the cache config enables
+func WithPredictionCache(predictionCache cache.CacheService) ServiceOption {
+	return func(s *Service) {
+		s.PredictionCache = predictionCache
+	}
+}
+
+// WithRequestCounter counts requests for each station with the given counter
+// instead of the one the cache config enables
+func WithRequestCounter(counter cache.RequestCounter) ServiceOption {
+	return func(s *Service) {
+		s.RequestCounter = counter
+	}
+}
+
+func NewService(ctx context.Context, httpClient *client.Client, stationFinder models.StationFinder, opts ...ServiceOption) (*Service, error) {
 	if httpClient == nil {
 		return nil, fmt.Errorf("http client is required")
 	}
@@ -38,19 +90,42 @@ func NewService(ctx context.Context, httpClient *client.Client, stationFinder mo
 		return nil, fmt.Errorf("station finder is required")
 	}
 
-	cacheService, err := cache.NewCacheService(ctx, config.GetCacheConfig())
+	cacheConfig := config.GetCacheConfig()
+	metService, err := met.NewService(httpClient, cache.NewConditionsCache(cacheConfig))
 	if err != nil {
-		return nil, fmt.Errorf("creating cache service: %w", err)
+		return nil, fmt.Errorf("creating met service: %w", err)
+	}
+
+	s := &Service{
+		HttpClient:       httpClient,
+		StationFinder:    stationFinder,
+		ObservationCache: cache.NewObservationCache(cacheConfig),
+		Conditions:       metService,
+		Harmonics:        harmonics.NewEmbeddedStore(),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
 
-	return &Service{
-		HttpClient:      httpClient,
-		StationFinder:   stationFinder,
-		PredictionCache: cacheService,
-	}, nil
+	// Only build the configured cache layers when none was injected, so a
+	// service with its own cache needs no DynamoDB
+	if s.PredictionCache == nil {
+		s.PredictionCache, err = cache.NewCacheService(ctx, cacheConfig)
+		if err != nil {
+			return nil, fmt.Errorf("creating cache service: %w", err)
+		}
+	}
+	if s.RequestCounter == nil {
+		s.RequestCounter, err = cache.NewRequestCounter(ctx, cacheConfig)
+		if err != nil {
+			return nil, fmt.Errorf("creating request counter: %w", err)
+		}
+	}
+
+	return s, nil
 }
 
-func (s *Service) GetCurrentTide(ctx context.Context, lat, lon float64, startTimeStr, endTimeStr *string) (*models.ExtendedTideResponse, error) {
+func (s *Service) GetCurrentTide(ctx context.Context, lat, lon float64, startTimeStr, endTimeStr *string, datum models.Datum, units models.Units) (*models.ExtendedTideResponse, error) {
 	// validate params
 	if lat < -90 || lat > 90 {
 		return nil, fmt.Errorf("invalid latitude: %f", lat)
@@ -58,7 +133,7 @@ func (s *Service) GetCurrentTide(ctx context.Context, lat, lon float64, startTim
 	if lon < -180 || lon > 180 {
 		return nil, fmt.Errorf("invalid longitude: %f", lon)
 	}
-	stations, err := s.StationFinder.FindNearestStations(ctx, lat, lon, 1)
+	stations, err := s.StationFinder.FindNearestStations(ctx, lat, lon, 1, nil)
 	if err != nil {
 		return nil, fmt.Errorf("finding nearest station: %w", err)
 	}
@@ -67,7 +142,7 @@ func (s *Service) GetCurrentTide(ctx context.Context, lat, lon float64, startTim
 		return nil, fmt.Errorf("no stations found near coordinates")
 	}
 
-	response, err := s.GetCurrentTideForStation(ctx, stations[0].ID, startTimeStr, endTimeStr)
+	response, err := s.GetCurrentTideForStation(ctx, stations[0].ID, startTimeStr, endTimeStr, datum, units)
 	if err != nil {
 		return nil, fmt.Errorf("getting current tide: %w", err)
 	}
@@ -79,43 +154,31 @@ func (s *Service) GetCurrentTide(ctx context.Context, lat, lon float64, startTim
 	return response, nil
 }
 
-func (s *Service) GetCurrentTideForStation(ctx context.Context, stationID string, startTimeStr, endTimeStr *string) (*models.ExtendedTideResponse, error) {
+func (s *Service) GetCurrentTideForStation(ctx context.Context, stationID string, startTimeStr, endTimeStr *string, datum models.Datum, units models.Units) (*models.ExtendedTideResponse, error) {
 	log.Debug().Str("station_id", stationID).Msg("Getting current tide for station")
 
+	if err := datum.OrDefault().Validate(); err != nil {
+		return nil, err
+	}
+	units = units.OrDefault()
+	if err := units.Validate(); err != nil {
+		return nil, err
+	}
+
 	localStation, err := s.StationFinder.FindStation(ctx, stationID)
 	if err != nil {
 		return nil, fmt.Errorf("finding localStation: %w", err)
 	}
+	s.recordRequest(localStation.ID)
+	datum = s.stationDatum(localStation, datum)
 
-	// Create timezone location for the localStation
-	location := time.FixedZone("Station", localStation.TimeZoneOffset)
+	// Local times follow the station's zone, including daylight saving time
+	location := timezone.ForStation(localStation)
 	now := time.Now().In(location)
 
-	// Parse start time if provided, otherwise use start of today in localStation's timezone
-	var startTime time.Time
-	if startTimeStr != nil {
-		// Parse local datetime string in localStation's timezone
-		var err error
-		startTime, err = time.ParseInLocation("2006-01-02T15:04:05", *startTimeStr, location)
-		if err != nil {
-			return nil, fmt.Errorf("parsing start time: %w", err)
-		}
-	} else {
-		// Use start of today in localStation's timezone
-		startTime = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
-	}
-
-	// Parse end time if provided, otherwise use next day
-	var endTime time.Time
-	if endTimeStr != nil {
-		var err error
-		endTime, err = time.ParseInLocation("2006-01-02T15:04:05", *endTimeStr, location)
-		if err != nil {
-			return nil, fmt.Errorf("parsing end time: %w", err)
-		}
-	} else {
-		// don't add an extra day here, we add that to the query below
-		endTime = startTime.AddDate(0, 0, 1).Add(-time.Second)
+	startTime, endTime, err := parseTimeRange(startTimeStr, endTimeStr, now, location)
+	if err != nil {
+		return nil, err
 	}
 
 	// Validate date range
@@ -124,45 +187,17 @@ func (s *Service) GetCurrentTideForStation(ctx context.Context, stationID string
 		return nil, NewInvalidRangeError(fmt.Sprintf("date range cannot exceed %d days", daysDataAllowed))
 	}
 
-	// Calculate query range
-	useExtremes := localStation.StationType != nil && *localStation.StationType == "S"
-	queryStart := startTime
-	if useExtremes {
-		// For extremes, go back one day for better interpolation
-		queryStart = startTime.Truncate(24*time.Hour).AddDate(0, 0, -1)
-	}
-
-	// End time should be the start of the day after the last day
-	queryEnd := endTime.Truncate(24*time.Hour).AddDate(0, 0, 1)
-
-	records, err := s.getPredictionsForDateRange(ctx, localStation, queryStart, queryEnd, location)
+	allPredictions, allExtremes, calculationMethod, freshness, err := s.getTideCurve(ctx, localStation, startTime, endTime, location, datum)
 	if err != nil {
-		return nil, fmt.Errorf("getting predictions: %w", err)
+		return nil, err
 	}
 
-	// Combine predictions and extremes from all records
-	var allPredictions []models.TidePrediction
-	var allExtremes []models.TideExtreme
-	for _, record := range records {
-		allPredictions = append(allPredictions, record.Predictions...)
-		allExtremes = append(allExtremes, record.Extremes...)
-	}
-
-	// Sort combined data
-	sort.Slice(allPredictions, func(i, j int) bool {
-		return allPredictions[i].Timestamp < allPredictions[j].Timestamp
-	})
-	sort.Slice(allExtremes, func(i, j int) bool {
-		return allExtremes[i].Timestamp < allExtremes[j].Timestamp
-	})
-
 	// Filter to requested time range
 	startTimestamp := startTime.Unix() * 1000
 	endTimestamp := endTime.Unix() * 1000
 
-	// Calculate current tide level and type
+	// Calculate current tide level
 	var currentLevel *float64
-	var currentType *models.TideType
 
 	// Convert times for filtering while preserving local time meaning
 	nowLocal := now.Unix() * 1000 // milliseconds
@@ -190,11 +225,205 @@ func (s *Service) GetCurrentTideForStation(ctx context.Context, stationID string
 	filteredPredictions := filterTimestamps(allPredictions, startTimestamp, endTimestamp)
 	filteredExtremes := filterExtremes(allExtremes, startTimestamp, endTimestamp)
 
+	observation, observationStatus := s.getLatestObservation(ctx, localStation, datum)
+	// Without predictions around the observation, as for a past range, there
+	// is nothing to compare it with
+	var predictedAtObservation *float64
+	if observation != nil && coversTimestamp(allPredictions, observation.Timestamp) {
+		predicted := interpolatePredictions(allPredictions, observation.Timestamp)
+		predictedAtObservation = &predicted
+	}
+
+	// Heights are fetched and cached in feet, then converted for the response
+	filteredPredictions, filteredExtremes = models.ConvertHeights(filteredPredictions, filteredExtremes, models.UnitsFeet, units)
+	level := models.ConvertHeight(*currentLevel, models.UnitsFeet, units)
+
+	response, err := newTideResponse(localStation, now, level, filteredPredictions, filteredExtremes, calculationMethod, datum, units)
+	if err != nil {
+		return nil, err
+	}
+
+	applyObservation(response, observation, observationStatus, predictedAtObservation, now, units)
+	response.Freshness = &freshness
+
+	// Summaries cover whole days, so they use everything fetched rather than the filtered range
+	summaryPredictions, summaryExtremes := models.ConvertHeights(allPredictions, allExtremes, models.UnitsFeet, units)
+	response.Days = summarizeDays(summaryPredictions, summaryExtremes, startTime, endTime, location)
+
+	if s.Conditions != nil {
+		conditions, err := s.Conditions.GetConditions(ctx, localStation, units)
+		if err != nil {
+			// Conditions are supplementary, so the tide response is still returned
+			log.Warn().Err(err).
+				Str("station_id", localStation.ID).
+				Msg("Error fetching met conditions")
+		}
+		response.Conditions = conditions
+	}
+
+	response.Astronomy = astronomy.Days(localStation.Latitude, localStation.Longitude, startTime, endTime, location)
+
+	return response, nil
+}
+
+// getTideCurve returns the station's predictions and extremes in feet for
+// whole days around the range, and how fresh they are. Predictions are nil
+// for subordinate stations that only have extremes.
+func (s *Service) getTideCurve(ctx context.Context, station *models.Station, startTime, endTime time.Time, location *time.Location, datum models.Datum) ([]models.TidePrediction, []models.TideExtreme, string, models.DataFreshness, error) {
+	// Calculate query range
+	subordinate := station.StationType != nil && *station.StationType == "S"
+	useExtremes := subordinate || s.extremesOnly(station)
+	queryStart := startOfDay(startTime, location)
+	queryEnd := startOfDay(endTime, location)
+	if useExtremes {
+		// For extremes, add a day either side for better interpolation
+		queryStart = queryStart.AddDate(0, 0, -1)
+		queryEnd = queryEnd.AddDate(0, 0, 1)
+	}
+
+	var allPredictions []models.TidePrediction
+	var allExtremes []models.TideExtreme
+	var freshness models.DataFreshness
+	calculationMethod := "NOAA API"
+	if _, ok := s.PredictionFetchers[station.Source]; ok {
+		calculationMethod = fmt.Sprintf("%s API", station.Source)
+	} else if _, _, ok := s.harmonicPredictor(station, datum); ok {
+		calculationMethod = "Harmonic"
+	}
+	if subordinate && datum == models.DatumMLLW {
+		// Prefer a full curve derived from the reference station over interpolating
+		// extremes. NOAA publishes the offsets relative to MLLW only.
+		predictions, extremes, subordinateFreshness, err := s.getSubordinatePredictions(ctx, station, queryStart, queryEnd, location)
+		if err != nil {
+			log.Warn().Err(err).
+				Str("station_id", station.ID).
+				Msg("Could not derive subordinate predictions, interpolating extremes instead")
+		} else {
+			allPredictions, allExtremes, freshness = predictions, extremes, subordinateFreshness
+			calculationMethod = "Subordinate offsets"
+		}
+	}
+
+	if allPredictions == nil {
+		var err error
+		allPredictions, allExtremes, freshness, err = s.getSortedPredictions(ctx, station, queryStart, queryEnd, location, datum)
+		if err != nil {
+			return nil, nil, "", models.DataFreshness{}, fmt.Errorf("getting predictions: %w", err)
+		}
+	}
+
+	return allPredictions, allExtremes, calculationMethod, freshness, nil
+}
+
+// stationDatum returns the requested datum, or when none was requested the
+// one the station's source publishes, which is the DefaultDatum for NOAA
+func (s *Service) stationDatum(station *models.Station, datum models.Datum) models.Datum {
+	if datum != "" {
+		return datum
+	}
+	if fetcher, ok := s.PredictionFetchers[station.Source].(SingleDatumFetcher); ok {
+		return fetcher.Datum()
+	}
+	return models.DefaultDatum
+}
+
+// extremesOnly reports whether the station's source only publishes high and
+// low waters
+func (s *Service) extremesOnly(station *models.Station) bool {
+	fetcher, ok := s.PredictionFetchers[station.Source].(ExtremesOnlyFetcher)
+	return ok && fetcher.ExtremesOnly()
+}
+
+// getSortedPredictions returns the station's predictions and extremes for the
+// date range, combined across the per-day records and sorted by time, and how
+// fresh the records are.
+func (s *Service) getSortedPredictions(ctx context.Context, station *models.Station, startDate, endDate time.Time, location *time.Location, datum models.Datum) ([]models.TidePrediction, []models.TideExtreme, models.DataFreshness, error) {
+	records, err := s.getPredictionsForDateRange(ctx, station, startDate, endDate, location, datum)
+	if err != nil {
+		return nil, nil, models.DataFreshness{}, err
+	}
+
+	// Combine predictions and extremes from all records, in feet
+	var allPredictions []models.TidePrediction
+	var allExtremes []models.TideExtreme
+	for _, record := range records {
+		predictions, extremes := models.ConvertHeights(record.Predictions, record.Extremes, record.Units, models.UnitsFeet)
+		allPredictions = append(allPredictions, predictions...)
+		allExtremes = append(allExtremes, extremes...)
+	}
+
+	// Sort combined data
+	sort.Slice(allPredictions, func(i, j int) bool {
+		return allPredictions[i].Timestamp < allPredictions[j].Timestamp
+	})
+	sort.Slice(allExtremes, func(i, j int) bool {
+		return allExtremes[i].Timestamp < allExtremes[j].Timestamp
+	})
+
+	return allPredictions, allExtremes, recordsFreshness(records, time.Now()), nil
+}
+
+// recordsFreshness describes prediction records as of now: stale if any of
+// them is, and as old as the oldest. Records without an update time are
+// taken to be fresh.
+func recordsFreshness(records []*models.TidePredictionRecord, now time.Time) models.DataFreshness {
+	freshness := models.DataFreshness{Status: models.FreshnessFresh, UpdatedAt: now.UnixMilli()}
+	for _, r := range records {
+		if r.Stale {
+			freshness.Status = models.FreshnessStale
+		}
+		if r.LastUpdated > 0 && r.LastUpdated*1000 < freshness.UpdatedAt {
+			freshness.UpdatedAt = r.LastUpdated * 1000
+		}
+	}
+	freshness.AgeSeconds = (now.UnixMilli() - freshness.UpdatedAt) / 1000
+	return freshness
+}
+
+// parseTimeRange resolves the requested local date-time strings in the station's
+// timezone, defaulting to the whole of the current local day.
+func parseTimeRange(startTimeStr, endTimeStr *string, now time.Time, location *time.Location) (time.Time, time.Time, error) {
+	// Parse start time if provided, otherwise use start of today in localStation's timezone
+	var startTime time.Time
+	if startTimeStr != nil {
+		// Parse local datetime string in localStation's timezone
+		var err error
+		startTime, err = time.ParseInLocation("2006-01-02T15:04:05", *startTimeStr, location)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("parsing start time: %w", err)
+		}
+	} else {
+		// Use start of today in localStation's timezone
+		startTime = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+	}
+
+	// Parse end time if provided, otherwise use next day
+	var endTime time.Time
+	if endTimeStr != nil {
+		var err error
+		endTime, err = time.ParseInLocation("2006-01-02T15:04:05", *endTimeStr, location)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("parsing end time: %w", err)
+		}
+	} else {
+		// don't add an extra day here, we add that to the query below
+		endTime = startTime.AddDate(0, 0, 1).Add(-time.Second)
+	}
+
+	return startTime, endTime, nil
+}
+
+// newTideResponse builds and validates the response for a station from the
+// predictions and extremes already filtered to the requested range.
+func newTideResponse(station *models.Station, now time.Time, currentLevel float64, predictions []models.TidePrediction, extremes []models.TideExtreme, calculationMethod string, datum models.Datum, units models.Units) (*models.ExtendedTideResponse, error) {
+	nowLocal := now.Unix() * 1000 // milliseconds
+
 	// Determine tide type
-	if len(filteredPredictions) >= 2 {
-		idx := findNearestIndex(filteredPredictions, nowLocal)
-		if idx > 0 && idx < len(filteredPredictions) {
-			if *currentLevel > filteredPredictions[idx-1].Height {
+	var currentType *models.TideType
+	if len(predictions) >= 2 {
+		idx := findNearestIndex(predictions, nowLocal)
+		if idx > 0 && idx < len(predictions) {
+			if currentLevel > predictions[idx-1].Height {
 				rising := models.TideTypeRising
 				currentType = &rising
 			} else {
@@ -211,18 +440,21 @@ func (s *Service) GetCurrentTideForStation(ctx context.Context, stationID string
 		ResponseType:          "tide",
 		Timestamp:             nowLocal,
 		LocalTime:             nowStr, // Add local time string
-		WaterLevel:            currentLevel,
-		PredictedLevel:        currentLevel,
-		NearestStation:        localStation.ID,
-		Location:              &localStation.Name,
-		Latitude:              localStation.Latitude,
-		Longitude:             localStation.Longitude,
-		StationDistance:       localStation.Distance,
+		PredictedLevel:        &currentLevel,
+		ObservationStatus:     models.ObservationStatusUnavailable,
+		NearestStation:        station.ID,
+		Location:              &station.Name,
+		Latitude:              station.Latitude,
+		Longitude:             station.Longitude,
+		StationDistance:       station.Distance,
 		TideType:              currentType,
-		CalculationMethod:     "NOAA API",
-		Extremes:              filteredExtremes,
-		Predictions:           filteredPredictions,
-		TimeZoneOffsetSeconds: &localStation.TimeZoneOffset,
+		CalculationMethod:     calculationMethod,
+		Datum:                 datum,
+		Units:                 units,
+		Extremes:              extremes,
+		Predictions:           predictions,
+		TimeZoneOffsetSeconds: &station.TimeZoneOffset,
+		TimeZone:              now.Location().String(),
 	}
 
 	if err := response.Validate(); err != nil {
@@ -232,11 +464,11 @@ func (s *Service) GetCurrentTideForStation(ctx context.Context, stationID string
 	return response, nil
 }
 
-func (s *Service) fetchNoaaPredictions(ctx context.Context, stationID, startDate, endDate string, location *time.Location) ([]models.TidePrediction, error) {
+func (s *Service) fetchNoaaPredictions(ctx context.Context, stationID, startDate, endDate string, location *time.Location, datum models.Datum) ([]models.TidePrediction, error) {
 	resp, err := s.HttpClient.Get(ctx, fmt.Sprintf("/api/prod/datagetter"+
-		"?station=%s&begin_date=%s&end_date=%s&product=predictions&datum=MLLW"+
+		"?station=%s&begin_date=%s&end_date=%s&product=predictions&datum=%s"+
 		"&units=english&time_zone=lst_ldt&format=json&interval=6",
-		stationID, startDate, endDate))
+		stationID, startDate, endDate, datum))
 	if err != nil {
 		return nil, NewNoaaAPIError("error making HTTP request for predictions", err)
 	}
@@ -254,8 +486,9 @@ func (s *Service) fetchNoaaPredictions(ctx context.Context, stationID, startDate
 	}
 
 	predictions := make([]models.TidePrediction, len(noaaResp.Predictions))
+	var previous int64
 	for i, p := range noaaResp.Predictions {
-		timestamp, err := parseNoaaTime(p.Time, location)
+		timestamp, err := parseNoaaTime(p.Time, location, previous)
 		if err != nil {
 			return nil, err
 		}
@@ -270,16 +503,17 @@ func (s *Service) fetchNoaaPredictions(ctx context.Context, stationID, startDate
 			LocalTime: formatLocalTime(timestamp, location),
 			Height:    height,
 		}
+		previous = timestamp
 	}
 
 	return predictions, nil
 }
 
-func (s *Service) fetchNoaaExtremes(ctx context.Context, stationID, startDate, endDate string, location *time.Location) ([]models.TideExtreme, error) {
+func (s *Service) fetchNoaaExtremes(ctx context.Context, stationID, startDate, endDate string, location *time.Location, datum models.Datum) ([]models.TideExtreme, error) {
 	resp, err := s.HttpClient.Get(ctx, fmt.Sprintf("/api/prod/datagetter"+
-		"?station=%s&begin_date=%s&end_date=%s&product=predictions&datum=MLLW"+
+		"?station=%s&begin_date=%s&end_date=%s&product=predictions&datum=%s"+
 		"&units=english&time_zone=lst_ldt&format=json&interval=hilo",
-		stationID, startDate, endDate))
+		stationID, startDate, endDate, datum))
 	if err != nil {
 		return nil, NewNoaaAPIError("error making HTTP request for extremes", err)
 	}
@@ -297,8 +531,9 @@ func (s *Service) fetchNoaaExtremes(ctx context.Context, stationID, startDate, e
 	}
 
 	extremes := make([]models.TideExtreme, len(noaaResp.Predictions))
+	var previous int64
 	for i, p := range noaaResp.Predictions {
-		timestamp, err := parseNoaaTime(p.Time, location)
+		timestamp, err := parseNoaaTime(p.Time, location, previous)
 		if err != nil {
 			return nil, err
 		}
@@ -323,6 +558,7 @@ func (s *Service) fetchNoaaExtremes(ctx context.Context, stationID, startDate, e
 			LocalTime: formatLocalTime(timestamp, location),
 			Height:    height,
 		}
+		previous = timestamp
 	}
 
 	return extremes, nil
@@ -330,6 +566,14 @@ func (s *Service) fetchNoaaExtremes(ctx context.Context, stationID, startDate, e
 
 // Helper functions for interpolation and filtering
 
+// coversTimestamp reports whether the sorted predictions span the timestamp,
+// so interpolating them there doesn't clamp to the first or last
+func coversTimestamp(predictions []models.TidePrediction, timestamp int64) bool {
+	return len(predictions) > 0 &&
+		timestamp >= predictions[0].Timestamp &&
+		timestamp <= predictions[len(predictions)-1].Timestamp
+}
+
 func interpolatePredictions(predictions []models.TidePrediction, timestamp int64) float64 {
 	if len(predictions) == 0 {
 		return 0
@@ -390,7 +634,7 @@ func interpolateExtremes(extremes []models.TideExtreme, timestamp int64) float64
 		h01*e2.Height + h11*m2*float64(e2.Timestamp-e1.Timestamp)
 }
 
-func (s *Service) getPredictionsForDateRange(ctx context.Context, station *models.Station, startDate, endDate time.Time, location *time.Location) ([]*models.TidePredictionRecord, error) {
+func (s *Service) getPredictionsForDateRange(ctx context.Context, station *models.Station, startDate, endDate time.Time, location *time.Location, datum models.Datum) ([]*models.TidePredictionRecord, error) {
 	// Get list of dates in the range
 	var dates []time.Time
 	for d := startDate; !d.After(endDate); d = d.AddDate(0, 0, 1) {
@@ -399,12 +643,12 @@ func (s *Service) getPredictionsForDateRange(ctx context.Context, station *model
 
 	// Try to get all dates from cache first
 	var cachedRecords []*models.TidePredictionRecord
-	var missingDates []time.Time
+	var missingDates, staleDates []time.Time
 
 	log.Debug().Times("dates", dates).Msg("Checking cache for predictions on dates")
 
 	for _, date := range dates {
-		record, err := s.PredictionCache.GetPredictions(ctx, station.ID, date)
+		record, err := s.PredictionCache.GetPredictions(ctx, station.ID, date, datum)
 		if err != nil {
 			log.Error().Err(err).
 				Str("station_id", station.ID).
@@ -414,6 +658,9 @@ func (s *Service) getPredictionsForDateRange(ctx context.Context, station *model
 
 		if record != nil {
 			cachedRecords = append(cachedRecords, record)
+			if record.Stale {
+				staleDates = append(staleDates, date)
+			}
 		} else {
 			missingDates = append(missingDates, date)
 		}
@@ -422,6 +669,11 @@ func (s *Service) getPredictionsForDateRange(ctx context.Context, station *model
 	log.Debug().Times("missing_dates", missingDates).Msg("Missing dates from cache")
 	log.Debug().Int("cached_records", len(cachedRecords)).Msg("Cached records")
 
+	// Stale records are served while they're refreshed
+	if len(staleDates) > 0 {
+		s.refreshPredictions(ctx, station, staleDates, location, datum)
+	}
+
 	// If we have all dates cached, return them
 	if len(missingDates) == 0 {
 		log.Debug().
@@ -432,48 +684,57 @@ func (s *Service) getPredictionsForDateRange(ctx context.Context, station *model
 	}
 
 	// Find the min and max dates that need fetching
-	minDate := missingDates[0]
-	maxDate := missingDates[0]
-	for _, date := range missingDates[1:] {
-		if date.Before(minDate) {
-			minDate = date
-		}
-		if date.After(maxDate) {
-			maxDate = date
-		}
-	}
+	minDate, maxDate := dateSpan(missingDates)
 
-	// Fetch from NOAA API for the full range that includes missing dates
+	// Fetch from the station's source for the full range that includes missing dates
 	log.Debug().
 		Str("station_id", station.ID).
 		Time("min_date", minDate).
 		Time("max_date", maxDate).
 		Int("missing_days", len(missingDates)).
-		Msg("Fetching missing dates from NOAA")
-
-	startStr := minDate.Format("20060102")
-	endStr := maxDate.Format("20060102")
+		Msg("Fetching missing dates from source")
 
-	predictions, err := s.fetchNoaaPredictions(ctx, station.ID, startStr, endStr, location)
+	predictions, extremes, units, err := s.fetchPredictionsOnce(ctx, station, minDate, maxDate, location, datum)
 	if err != nil {
-		// don't return error, we can interpolate from extremes instead
-		log.Warn().Err(err).
-			Str("station-id", station.ID).
-			Msg("Error fetching predictions from NOAA")
-		//return nil, err
+		return nil, err
 	}
 
-	extremes, err := s.fetchNoaaExtremes(ctx, station.ID, startStr, endStr, location)
-	if err != nil {
-		// it's possible there were no extremes for the station
-		log.Warn().Err(err).
-			Str("station-id", station.ID).
-			Msg("Error fetching extremes from NOAA")
-		if len(predictions) == 0 {
-			return nil, err
+	newRecords := s.newPredictionRecords(station, missingDates, predictions, extremes, units, location, datum)
+
+	// Save new records to cache asynchronously
+	inBackground(ctx, func() {
+		s.savePredictionRecords(station, newRecords)
+	})
+
+	// Combine cached and new records
+	allRecords := append(cachedRecords, newRecords...)
+
+	// Sort records by date
+	sort.Slice(allRecords, func(i, j int) bool {
+		return allRecords[i].Date < allRecords[j].Date
+	})
+
+	return allRecords, nil
+}
+
+// dateSpan returns the earliest and latest of the dates
+func dateSpan(dates []time.Time) (time.Time, time.Time) {
+	minDate := dates[0]
+	maxDate := dates[0]
+	for _, date := range dates[1:] {
+		if date.Before(minDate) {
+			minDate = date
+		}
+		if date.After(maxDate) {
+			maxDate = date
 		}
 	}
+	return minDate, maxDate
+}
 
+// newPredictionRecords splits fetched predictions and extremes into a
+// record for each of the dates, by local day
+func (s *Service) newPredictionRecords(station *models.Station, dates []time.Time, predictions []models.TidePrediction, extremes []models.TideExtreme, units models.Units, location *time.Location, datum models.Datum) []*models.TidePredictionRecord {
 	// Group predictions and extremes by day
 	predictionsByDay := make(map[string][]models.TidePrediction)
 	extremesByDay := make(map[string][]models.TideExtreme)
@@ -488,9 +749,17 @@ func (s *Service) getPredictionsForDateRange(ctx context.Context, station *model
 		extremesByDay[day] = append(extremesByDay[day], e)
 	}
 
-	// Create and save cache records for missing dates
+	// Records say whether they hold a full curve (R) or only extremes (S),
+	// which for stations without a type depends on their source
+	stationType := models.StationTypeReference
+	if station.StationType != nil {
+		stationType = *station.StationType
+	} else if s.extremesOnly(station) {
+		stationType = models.StationTypeSubordinate
+	}
+	now := time.Now().Unix()
 	var newRecords []*models.TidePredictionRecord
-	for _, date := range missingDates {
+	for _, date := range dates {
 		dateStr := date.Format("2006-01-02")
 		dayExtremes := extremesByDay[dateStr]
 		if dayExtremes == nil {
@@ -502,37 +771,204 @@ func (s *Service) getPredictionsForDateRange(ctx context.Context, station *model
 		record := &models.TidePredictionRecord{
 			StationID:   station.ID,
 			Date:        dateStr,
-			StationType: *station.StationType,
+			StationType: stationType,
+			Datum:       datum,
+			Units:       units,
 			Predictions: predictionsByDay[dateStr],
 			Extremes:    dayExtremes,
+			LastUpdated: now,
 		}
 		newRecords = append(newRecords, record)
 	}
 
-	// Save new records to cache asynchronously
-	go func(records []*models.TidePredictionRecord) {
-		recordsToSave := make([]models.TidePredictionRecord, len(records))
-		for i, r := range records {
-			recordsToSave[i] = *r
+	return newRecords
+}
+
+// savePredictionRecords saves fetched records to the cache
+func (s *Service) savePredictionRecords(station *models.Station, records []*models.TidePredictionRecord) {
+	// Days the source had nothing for, as beyond the week UKHO publishes,
+	// aren't cached so they're fetched again once published
+	recordsToSave := make([]models.TidePredictionRecord, 0, len(records))
+	for _, r := range records {
+		if len(r.Predictions) == 0 && len(r.Extremes) == 0 && len(r.Currents) == 0 && len(r.CurrentEvents) == 0 {
+			continue
 		}
+		recordsToSave = append(recordsToSave, *r)
+	}
+	if len(recordsToSave) == 0 {
+		return
+	}
 
-		if err := s.PredictionCache.SavePredictionsBatch(context.Background(), recordsToSave); err != nil {
-			log.Error().Err(err).
+	if err := s.PredictionCache.SavePredictionsBatch(context.Background(), recordsToSave); err != nil {
+		log.Error().Err(err).
+			Str("station_id", station.ID).
+			Int("record_count", len(recordsToSave)).
+			Msg("Error saving predictions to cache")
+	}
+}
+
+// refreshPredictions fetches stale cached predictions for the dates again in
+// the background and saves them over the stale records. The stale records
+// are served meanwhile, and after a failure until the cache drops them.
+func (s *Service) refreshPredictions(ctx context.Context, station *models.Station, dates []time.Time, location *time.Location, datum models.Datum) {
+	inBackground(ctx, func() {
+		minDate, maxDate := dateSpan(dates)
+		log.Debug().
+			Str("station_id", station.ID).
+			Time("min_date", minDate).
+			Time("max_date", maxDate).
+			Msg("Refreshing stale predictions")
+
+		predictions, extremes, units, err := s.fetchPredictionsOnce(context.Background(), station, minDate, maxDate, location, datum)
+		if err != nil {
+			log.Warn().Err(err).
 				Str("station_id", station.ID).
-				Int("record_count", len(records)).
-				Msg("Error saving predictions to cache")
+				Msg("Error refreshing stale predictions")
+			return
 		}
-	}(newRecords)
+		s.savePredictionRecords(station, s.newPredictionRecords(station, dates, predictions, extremes, units, location, datum))
+	})
+}
 
-	// Combine cached and new records
-	allRecords := append(cachedRecords, newRecords...)
+// backgroundWorkKey keys the WaitGroup a context's caller waits on for the
+// background work its calls start
+type backgroundWorkKey struct{}
 
-	// Sort records by date
-	sort.Slice(allRecords, func(i, j int) bool {
-		return allRecords[i].Date < allRecords[j].Date
+// withBackgroundWork returns a context whose calls add the background work
+// they start, like saving fetched predictions, to work
+func withBackgroundWork(ctx context.Context, work *sync.WaitGroup) context.Context {
+	return context.WithValue(ctx, backgroundWorkKey{}, work)
+}
+
+// inBackground runs fn in the background, as part of the context's
+// background work if it has any. Only fn's caller's context is tracked, so
+// one caller doesn't wait on another's work.
+func inBackground(ctx context.Context, fn func()) {
+	work, _ := ctx.Value(backgroundWorkKey{}).(*sync.WaitGroup)
+	if work == nil {
+		go fn()
+		return
+	}
+	work.Add(1)
+	go func() {
+		defer work.Done()
+		fn()
+	}()
+}
+
+// recordRequest counts a request for the station in the background. A
+// failure to count it doesn't fail the request.
+func (s *Service) recordRequest(stationID string) {
+	if s.RequestCounter == nil {
+		return
+	}
+	go func() {
+		if err := s.RequestCounter.RecordRequest(context.Background(), stationID); err != nil {
+			log.Warn().Err(err).
+				Str("station_id", stationID).
+				Msg("Error counting station request")
+		}
+	}()
+}
+
+// fetchedPredictions is the result of one fetch, shared by the callers it
+// was coalesced for
+type fetchedPredictions struct {
+	predictions []models.TidePrediction
+	extremes    []models.TideExtreme
+	units       models.Units
+}
+
+// predictionFetchTimeout bounds a shared prediction fetch, which runs apart
+// from the request that started it
+const predictionFetchTimeout = 30 * time.Second
+
+// fetchPredictionsOnce fetches as fetchPredictions does, with concurrent
+// callers for the same station, date range and datum sharing one fetch. The
+// fetch isn't cancelled with the caller that started it.
+func (s *Service) fetchPredictionsOnce(ctx context.Context, station *models.Station, startDate, endDate time.Time, location *time.Location, datum models.Datum) ([]models.TidePrediction, []models.TideExtreme, models.Units, error) {
+	key := fmt.Sprintf("%s|%s|%s|predictions|%s", station.ID,
+		startDate.Format("2006-01-02"), endDate.Format("2006-01-02"), datum.OrDefault())
+	result, err := s.predictionFetches.DoContext(ctx, key, predictionFetchTimeout, func(ctx context.Context) (interface{}, error) {
+		predictions, extremes, units, err := s.fetchPredictions(ctx, station, startDate, endDate, location, datum)
+		return &fetchedPredictions{predictions: predictions, extremes: extremes, units: units}, err
 	})
+	if err != nil {
+		return nil, nil, "", err
+	}
 
-	return allRecords, nil
+	fetched := result.(*fetchedPredictions)
+	return fetched.predictions, fetched.extremes, fetched.units, nil
+}
+
+// GetCoalescingStats returns the number of prediction fetches made, and of
+// callers served by another caller's fetch, along with the counts of the
+// station finders that keep them
+func (s *Service) GetCoalescingStats() map[string]uint64 {
+	fetches := s.predictionFetches.Stats()
+	stats := map[string]uint64{
+		"prediction_fetches":           fetches.Calls,
+		"prediction_fetches_coalesced": fetches.Coalesced,
+	}
+	for _, finder := range []models.StationFinder{s.StationFinder, s.CurrentStationFinder} {
+		if reporter, ok := finder.(coalesce.StatsReporter); ok {
+			for name, count := range reporter.GetCoalescingStats() {
+				stats[name] = count
+			}
+		}
+	}
+	return stats
+}
+
+// fetchPredictions fetches the predictions and extremes for whole days from
+// the station's source, along with the units of their heights
+func (s *Service) fetchPredictions(ctx context.Context, station *models.Station, startDate, endDate time.Time, location *time.Location, datum models.Datum) ([]models.TidePrediction, []models.TideExtreme, models.Units, error) {
+	if fetcher, ok := s.PredictionFetchers[station.Source]; ok {
+		predictions, extremes, err := fetcher.FetchPredictions(ctx, station, startDate, endDate.AddDate(0, 0, 1), location, datum)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		return predictions, extremes, fetcher.Units(), nil
+	}
+
+	// Chart datum is only published by the other sources
+	if datum == models.DatumCD {
+		return nil, nil, "", &UnsupportedDatumError{Source: models.SourceNOAA, Datum: datum}
+	}
+
+	// Stations with harmonic constituents are predicted without asking NOAA
+	if predictions, extremes, units, ok := s.harmonicPredictions(station, startDate, endDate.AddDate(0, 0, 1), location, datum); ok {
+		return predictions, extremes, units, nil
+	}
+
+	startStr := startDate.Format("20060102")
+	endStr := endDate.Format("20060102")
+
+	predictions, err := s.fetchNoaaPredictions(ctx, station.ID, startStr, endStr, location, datum)
+	if err != nil {
+		// don't return error, we can interpolate from extremes instead
+		log.Warn().Err(err).
+			Str("station-id", station.ID).
+			Msg("Error fetching predictions from NOAA")
+	}
+
+	extremes, err := s.fetchNoaaExtremes(ctx, station.ID, startStr, endStr, location, datum)
+	if err != nil {
+		// it's possible there were no extremes for the station
+		log.Warn().Err(err).
+			Str("station-id", station.ID).
+			Msg("Error fetching extremes from NOAA")
+		if len(predictions) == 0 {
+			return nil, nil, "", err
+		}
+	}
+
+	return predictions, extremes, models.UnitsFeet, nil
+}
+
+// roundHeight rounds to the three decimals NOAA publishes
+func roundHeight(h float64) float64 {
+	return math.Round(h*1000) / 1000
 }
 
 func findNearestIndex(predictions []models.TidePrediction, timestamp int64) int {
@@ -567,16 +1003,45 @@ func filterExtremes(extremes []models.TideExtreme, start, end int64) []models.Ti
 	return filtered
 }
 
-func parseNoaaTime(timeStr string, location *time.Location) (int64, error) {
+// parseNoaaTime parses a lst_ldt time from a series in time order. When the
+// clocks go back NOAA lists the repeated hour twice, so a time that doesn't
+// come after the previous one in the series is taken as its second occurrence.
+func parseNoaaTime(timeStr string, location *time.Location, previous int64) (int64, error) {
 	// NOAA time format is "2006-01-02 15:04"
 	// Parse in the station's local timezone
 	t, err := time.ParseInLocation("2006-01-02 15:04", timeStr, location)
 	if err != nil {
 		return 0, fmt.Errorf("parsing time %s: %w", timeStr, err)
 	}
+	if previous != 0 && t.UnixMilli() <= previous {
+		t = laterOccurrence(t)
+	}
 	return t.UnixMilli(), nil
 }
 
+// laterOccurrence returns the second time the wall clock reads the same as t
+// when the clocks go back, or t if it only happens once. Go resolves an
+// ambiguous wall clock time to the first occurrence.
+func laterOccurrence(t time.Time) time.Time {
+	_, offset := t.Zone()
+	_, offsetAfter := t.Add(24 * time.Hour).Zone()
+	if offsetAfter >= offset {
+		return t
+	}
+	later := t.Add(time.Duration(offset-offsetAfter) * time.Second)
+	if later.Format("2006-01-02 15:04") != t.Format("2006-01-02 15:04") {
+		return t
+	}
+	return later
+}
+
+// startOfDay returns local midnight on t's day in location. Days are not
+// always 24 hours long, so this can't be done by truncating t.
+func startOfDay(t time.Time, location *time.Location) time.Time {
+	t = t.In(location)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, location)
+}
+
 func formatLocalTime(timestamp int64, location *time.Location) string {
 	t := time.Unix(timestamp/1000, 0).In(location)
 	return t.Format("2006-01-02T15:04:05")
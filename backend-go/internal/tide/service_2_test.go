@@ -2,7 +2,9 @@ package tide
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/bbernstein/flowebb/backend-go/internal/cache"
 	"github.com/bbernstein/flowebb/backend-go/internal/models"
 	"github.com/bbernstein/flowebb/backend-go/pkg/http/client"
 	"github.com/rs/zerolog/log"
@@ -18,8 +20,10 @@ import (
 
 // Mock StationFinder for testing
 type mockStationFinder2 struct {
-	findStationFn         func(ctx context.Context, stationID string) (*models.Station, error)
-	findNearestStationsFn func(ctx context.Context, lat, lon float64, limit int) ([]models.Station, error)
+	findStationFn          func(ctx context.Context, stationID string) (*models.Station, error)
+	findNearestStationsFn  func(ctx context.Context, lat, lon float64, limit int, filter *models.StationFilter) ([]models.Station, error)
+	findStationsInBoundsFn func(ctx context.Context, south, west, north, east float64, limit int, filter *models.StationFilter) ([]models.Station, error)
+	searchStationsFn       func(ctx context.Context, query string, near *models.Coordinates, limit int, filter *models.StationFilter) ([]models.Station, error)
 }
 
 func (m *mockStationFinder2) FindStation(ctx context.Context, stationID string) (*models.Station, error) {
@@ -29,9 +33,23 @@ func (m *mockStationFinder2) FindStation(ctx context.Context, stationID string)
 	return nil, nil
 }
 
-func (m *mockStationFinder2) FindNearestStations(ctx context.Context, lat, lon float64, limit int) ([]models.Station, error) {
+func (m *mockStationFinder2) FindNearestStations(ctx context.Context, lat, lon float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
 	if m.findNearestStationsFn != nil {
-		return m.findNearestStationsFn(ctx, lat, lon, limit)
+		return m.findNearestStationsFn(ctx, lat, lon, limit, filter)
+	}
+	return nil, nil
+}
+
+func (m *mockStationFinder2) FindStationsInBounds(ctx context.Context, south, west, north, east float64, limit int, filter *models.StationFilter) ([]models.Station, error) {
+	if m.findStationsInBoundsFn != nil {
+		return m.findStationsInBoundsFn(ctx, south, west, north, east, limit, filter)
+	}
+	return nil, nil
+}
+
+func (m *mockStationFinder2) SearchStations(ctx context.Context, query string, near *models.Coordinates, limit int, filter *models.StationFilter) ([]models.Station, error) {
+	if m.searchStationsFn != nil {
+		return m.searchStationsFn(ctx, query, near, limit, filter)
 	}
 	return nil, nil
 }
@@ -42,7 +60,7 @@ type mockStationService2 struct {
 	savePredictionsBatchFn func(ctx context.Context, records []models.TidePredictionRecord) error
 }
 
-func (m *mockStationService2) GetPredictions(ctx context.Context, stationID string, date time.Time) (*models.TidePredictionRecord, error) {
+func (m *mockStationService2) GetPredictions(ctx context.Context, stationID string, date time.Time, _ models.Datum) (*models.TidePredictionRecord, error) {
 	if m.getPredictionsFn != nil {
 		return m.getPredictionsFn(ctx, stationID, date)
 	}
@@ -114,7 +132,7 @@ func TestGetCurrentTide_InvalidParameters(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			response, err := service.GetCurrentTide(context.Background(), tt.lat, tt.lon, nil, nil)
+			response, err := service.GetCurrentTide(context.Background(), tt.lat, tt.lon, nil, nil, models.DatumMLLW, models.UnitsFeet)
 			if tt.wantErr {
 				require.Error(t, err)
 				assert.Contains(t, err.Error(), tt.errMessage)
@@ -178,7 +196,7 @@ func TestGetCurrentTideForStation_DateRangeValidation(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			response, err := service.GetCurrentTideForStation(context.Background(), "TEST001", &tt.startTime, &tt.endTime)
+			response, err := service.GetCurrentTideForStation(context.Background(), "TEST001", &tt.startTime, &tt.endTime, models.DatumMLLW, models.UnitsFeet)
 			if tt.wantErr {
 				require.Error(t, err)
 				assert.Contains(t, err.Error(), tt.errMessage)
@@ -239,7 +257,7 @@ func TestTideTypeDetermination(t *testing.T) {
 		PredictionCache: cache,
 	}
 
-	response, err := service.GetCurrentTideForStation(context.Background(), "TEST001", nil, nil)
+	response, err := service.GetCurrentTideForStation(context.Background(), "TEST001", nil, nil, models.DatumMLLW, models.UnitsFeet)
 	require.NoError(t, err)
 	require.NotNil(t, response)
 
@@ -249,7 +267,7 @@ func TestTideTypeDetermination(t *testing.T) {
 
 	// Test falling tide
 	predictions[1].Height = 0.5 // Make current height lower than previous
-	response, err = service.GetCurrentTideForStation(context.Background(), "TEST001", nil, nil)
+	response, err = service.GetCurrentTideForStation(context.Background(), "TEST001", nil, nil, models.DatumMLLW, models.UnitsFeet)
 	require.NoError(t, err)
 	require.NotNil(t, response)
 	require.NotNil(t, response.TideType)
@@ -387,7 +405,7 @@ func TestTimeZoneHandling(t *testing.T) {
 				PredictionCache: cache,
 			}
 
-			response, err := service.GetCurrentTideForStation(context.Background(), station.ID, nil, nil)
+			response, err := service.GetCurrentTideForStation(context.Background(), station.ID, nil, nil, models.DatumMLLW, models.UnitsFeet)
 			if tt.wantErr {
 				require.Error(t, err)
 				return
@@ -416,6 +434,11 @@ func TestCacheIntegration(t *testing.T) {
 
 	// Mock server for NOAA API that uses the actual requested dates
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("product") == "water_level" {
+			_, _ = fmt.Fprint(w, `{"error":{"message":"No data was found."}}`)
+			return
+		}
+
 		if r.URL.Path == "/api/prod/datagetter" {
 			beginDate := r.URL.Query().Get("begin_date")
 			endDate := r.URL.Query().Get("end_date")
@@ -541,7 +564,7 @@ func TestCacheIntegration(t *testing.T) {
 		context.Background(),
 		"TEST001",
 		stringPtr(nowPacific.Format("2006-01-02T15:04:05")),
-		stringPtr(nowPacific.Add(24*time.Hour).Format("2006-01-02T15:04:05")))
+		stringPtr(nowPacific.Add(24*time.Hour).Format("2006-01-02T15:04:05")), models.DatumMLLW, models.UnitsFeet)
 	require.NoError(t, err)
 	require.NotNil(t, response)
 
@@ -587,6 +610,251 @@ func TestCacheIntegration(t *testing.T) {
 	}
 }
 
+func TestGetPredictionsForDateRange_CoalescesConcurrentMisses(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("product") != "predictions" {
+			_, _ = fmt.Fprint(w, `{"error":{"message":"No data was found."}}`)
+			return
+		}
+
+		mu.Lock()
+		requests[query.Get("interval")]++
+		mu.Unlock()
+		<-release
+
+		if query.Get("interval") == "hilo" {
+			_, _ = fmt.Fprint(w, `{"predictions":[
+				{"t":"2026-07-01 03:00","v":"8.0","type":"H"},
+				{"t":"2026-07-01 09:00","v":"1.0","type":"L"},
+				{"t":"2026-07-01 15:00","v":"7.5","type":"H"},
+				{"t":"2026-07-01 21:00","v":"0.5","type":"L"}
+			]}`)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"predictions":[
+			{"t":"2026-07-01 00:00","v":"5.0"},
+			{"t":"2026-07-01 06:00","v":"4.0"},
+			{"t":"2026-07-01 12:00","v":"4.5"},
+			{"t":"2026-07-01 18:00","v":"3.5"}
+		]}`)
+	}))
+	defer server.Close()
+
+	service := &Service{
+		HttpClient: client.New(client.Options{BaseURL: server.URL, Timeout: 5 * time.Second}),
+		StationFinder: &mockStationFinder2{
+			findStationFn: func(ctx context.Context, stationID string) (*models.Station, error) {
+				return createTestStation(-8 * 3600), nil
+			},
+		},
+		PredictionCache: &mockStationService2{},
+	}
+
+	// The first caller starts the fetch, then gives up on it
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := service.GetCurrentTideForStation(leaderCtx, "TEST001",
+			stringPtr("2026-07-01T00:00:00"), stringPtr("2026-07-01T23:00:00"), models.DatumMLLW, models.UnitsFeet)
+		leaderErr <- err
+	}()
+	require.Eventually(t, func() bool {
+		return service.GetCoalescingStats()["prediction_fetches"] == 1
+	}, 2*time.Second, time.Millisecond)
+
+	const callers = 10
+	var wg sync.WaitGroup
+	for i := 1; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response, err := service.GetCurrentTideForStation(context.Background(), "TEST001",
+				stringPtr("2026-07-01T00:00:00"), stringPtr("2026-07-01T23:00:00"), models.DatumMLLW, models.UnitsFeet)
+			assert.NoError(t, err)
+			if response != nil {
+				assert.Len(t, response.Extremes, 4)
+			}
+		}()
+	}
+
+	// Hold NOAA's responses until every caller has joined the first fetch
+	require.Eventually(t, func() bool {
+		return service.GetCoalescingStats()["prediction_fetches_coalesced"] == callers-1
+	}, 2*time.Second, time.Millisecond)
+	cancelLeader()
+	assert.ErrorIs(t, <-leaderErr, context.Canceled)
+	close(release)
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, map[string]int{"6": 1, "hilo": 1}, requests)
+	assert.Equal(t, map[string]uint64{
+		"prediction_fetches":           1,
+		"prediction_fetches_coalesced": callers - 1,
+	}, service.GetCoalescingStats())
+}
+
+func TestGetCurrentTideForStation_ServesStalePredictionsWhileRefreshing(t *testing.T) {
+	losAngeles, err := time.LoadLocation("America/Los_Angeles")
+	require.NoError(t, err)
+	updated := time.Now().Add(-3 * 24 * time.Hour).Unix()
+	staleRecord := func() *models.TidePredictionRecord {
+		extremes := make([]models.TideExtreme, 4)
+		for i, hour := range []int{2, 8, 14, 20} {
+			timestamp := time.Date(2026, 7, 1, hour, 0, 0, 0, losAngeles).UnixMilli()
+			extremes[i] = models.TideExtreme{Type: models.TideTypeHigh, Timestamp: timestamp, LocalTime: formatLocalTime(timestamp, losAngeles), Height: 6}
+			if i%2 == 1 {
+				extremes[i].Type, extremes[i].Height = models.TideTypeLow, 2
+			}
+		}
+		return &models.TidePredictionRecord{
+			StationID: "TEST001", Date: "2026-07-01", StationType: "R",
+			Extremes: extremes, LastUpdated: updated, Stale: true,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		noaaDown  bool
+		wantSaved bool
+	}{
+		{name: "refreshed in the background", wantSaved: true},
+		{name: "kept when the refresh fails", noaaDown: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				query := r.URL.Query()
+				if query.Get("product") != "predictions" {
+					_, _ = fmt.Fprint(w, `{"error":{"message":"No data was found."}}`)
+					return
+				}
+				mu.Lock()
+				requests++
+				mu.Unlock()
+				if tt.noaaDown {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				if query.Get("interval") == "hilo" {
+					_, _ = fmt.Fprint(w, `{"predictions":[
+						{"t":"2026-07-01 03:00","v":"8.0","type":"H"},
+						{"t":"2026-07-01 09:00","v":"1.0","type":"L"}
+					]}`)
+					return
+				}
+				_, _ = fmt.Fprint(w, `{"predictions":[{"t":"2026-07-01 00:00","v":"5.0"},{"t":"2026-07-01 06:00","v":"4.0"}]}`)
+			}))
+			defer server.Close()
+
+			saved := make(chan []models.TidePredictionRecord, 1)
+			service := &Service{
+				HttpClient: client.New(client.Options{BaseURL: server.URL, Timeout: 5 * time.Second, MaxRetries: 1}),
+				StationFinder: &mockStationFinder2{
+					findStationFn: func(ctx context.Context, stationID string) (*models.Station, error) {
+						return createTestStation(-8 * 3600), nil
+					},
+				},
+				PredictionCache: &mockStationService2{
+					getPredictionsFn: func(ctx context.Context, stationID string, date time.Time) (*models.TidePredictionRecord, error) {
+						return staleRecord(), nil
+					},
+					savePredictionsBatchFn: func(ctx context.Context, records []models.TidePredictionRecord) error {
+						saved <- records
+						return nil
+					},
+				},
+			}
+
+			response, err := service.GetCurrentTideForStation(context.Background(), "TEST001",
+				stringPtr("2026-07-01T00:00:00"), stringPtr("2026-07-01T23:00:00"), models.DatumMLLW, models.UnitsFeet)
+			require.NoError(t, err)
+
+			// The stale record is served straight away
+			require.Len(t, response.Extremes, 4)
+			assert.Equal(t, 6.0, response.Extremes[0].Height)
+			require.NotNil(t, response.Freshness)
+			assert.Equal(t, models.FreshnessStale, response.Freshness.Status)
+			assert.Equal(t, updated*1000, response.Freshness.UpdatedAt)
+			assert.InDelta(t, 3*24*60*60, response.Freshness.AgeSeconds, 5)
+
+			require.Eventually(t, func() bool {
+				mu.Lock()
+				defer mu.Unlock()
+				return requests > 0
+			}, 2*time.Second, 10*time.Millisecond)
+
+			if !tt.wantSaved {
+				require.Eventually(t, func() bool {
+					return service.GetCoalescingStats()["prediction_fetches"] == 1
+				}, 2*time.Second, 10*time.Millisecond)
+				select {
+				case <-saved:
+					t.Fatal("nothing should be saved when the refresh fails")
+				case <-time.After(50 * time.Millisecond):
+				}
+				return
+			}
+
+			select {
+			case records := <-saved:
+				require.Len(t, records, 1)
+				assert.Equal(t, "2026-07-01", records[0].Date)
+				assert.False(t, records[0].Stale)
+				assert.InDelta(t, time.Now().Unix(), records[0].LastUpdated, 5)
+				require.Len(t, records[0].Extremes, 2)
+				assert.Equal(t, 8.0, records[0].Extremes[0].Height)
+			case <-time.After(2 * time.Second):
+				t.Fatal("stale predictions were not refreshed")
+			}
+		})
+	}
+}
+
+func TestRecordsFreshness(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+	tests := []struct {
+		name    string
+		records []*models.TidePredictionRecord
+		want    models.DataFreshness
+	}{
+		{
+			name:    "just fetched",
+			records: []*models.TidePredictionRecord{{LastUpdated: now.Unix()}},
+			want:    models.DataFreshness{Status: models.FreshnessFresh, UpdatedAt: now.UnixMilli()},
+		},
+		{
+			name:    "oldest record",
+			records: []*models.TidePredictionRecord{{LastUpdated: now.Unix() - 60}, {LastUpdated: now.Unix() - 3600}},
+			want:    models.DataFreshness{Status: models.FreshnessFresh, UpdatedAt: now.UnixMilli() - 3600*1000, AgeSeconds: 3600},
+		},
+		{
+			name:    "any stale record",
+			records: []*models.TidePredictionRecord{{LastUpdated: now.Unix()}, {LastUpdated: now.Unix() - 60, Stale: true}},
+			want:    models.DataFreshness{Status: models.FreshnessStale, UpdatedAt: now.UnixMilli() - 60*1000, AgeSeconds: 60},
+		},
+		{
+			name:    "unknown update time",
+			records: []*models.TidePredictionRecord{{}},
+			want:    models.DataFreshness{Status: models.FreshnessFresh, UpdatedAt: now.UnixMilli()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, recordsFreshness(tt.records, now))
+		})
+	}
+}
+
 func TestInterpolateExtremes(t *testing.T) {
 	now := time.Now()
 	tests := []struct {
@@ -712,11 +980,19 @@ func TestNewService(t *testing.T) {
 				require.NotNil(t, service.HttpClient)
 				require.NotNil(t, service.StationFinder)
 				require.NotNil(t, service.PredictionCache)
+				require.NotNil(t, service.RequestCounter)
 			}
 		})
 	}
 }
 
+func TestNewService_WithPredictionCache(t *testing.T) {
+	predictionCache := cache.NoopCacheService{}
+	service, err := NewService(context.Background(), &client.Client{}, &mockStationFinder2{}, WithPredictionCache(predictionCache))
+	require.NoError(t, err)
+	assert.Equal(t, predictionCache, service.PredictionCache)
+}
+
 func TestFetchNoaaExtremes_ErrorResponse(t *testing.T) {
 	// Create service with mock dependencies
 	stationFinder := &mockStationFinder2{
@@ -770,6 +1046,7 @@ func TestFetchNoaaExtremes_ErrorResponse(t *testing.T) {
 		"20240101",
 		"20240102",
 		location,
+		models.DatumMLLW,
 	)
 
 	// Verify that we get an error and no extremes
@@ -784,6 +1061,8 @@ func TestFetchNoaaExtremes_ErrorResponse(t *testing.T) {
 		"TEST001",
 		stringPtr("2024-01-01T00:00:00"),
 		stringPtr("2024-01-02T00:00:00"),
+		models.DatumMLLW,
+		models.UnitsFeet,
 	)
 
 	// Verify that the service continues without extremes
@@ -792,3 +1071,413 @@ func TestFetchNoaaExtremes_ErrorResponse(t *testing.T) {
 	assert.Contains(t, err.Error(), "product may not be offered")
 	assert.Nil(t, response)
 }
+
+// datumCache records the datums requested from and saved to the cache
+type datumCache struct {
+	mu        sync.Mutex
+	requested []models.Datum
+	saved     chan []models.TidePredictionRecord
+}
+
+func (c *datumCache) GetPredictions(_ context.Context, _ string, _ time.Time, datum models.Datum) (*models.TidePredictionRecord, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requested = append(c.requested, datum)
+	return nil, nil
+}
+
+func (c *datumCache) SavePredictionsBatch(_ context.Context, records []models.TidePredictionRecord) error {
+	c.saved <- records
+	return nil
+}
+
+func TestDatumSelection(t *testing.T) {
+	tests := []struct {
+		name      string
+		datum     models.Datum
+		wantDatum models.Datum
+		wantErr   string
+	}{
+		{name: "default datum", datum: "", wantDatum: models.DatumMLLW},
+		{name: "NAVD88", datum: models.DatumNAVD88, wantDatum: models.DatumNAVD88},
+		{name: "MHHW", datum: models.DatumMHHW, wantDatum: models.DatumMHHW},
+		{name: "invalid datum", datum: models.Datum("WGS84"), wantErr: "invalid datum"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var noaaDatums []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				noaaDatums = append(noaaDatums, r.URL.Query().Get("datum"))
+				mu.Unlock()
+				if r.URL.Query().Get("interval") == "hilo" {
+					_, _ = fmt.Fprint(w, `{"predictions":[{"t":"2024-01-01 04:00","v":"3.0","type":"H"}]}`)
+					return
+				}
+				_, _ = fmt.Fprint(w, `{"predictions":[{"t":"2024-01-01 00:00","v":"1.0"},{"t":"2024-01-01 06:00","v":"2.0"}]}`)
+			}))
+			defer server.Close()
+
+			predictionCache := &datumCache{saved: make(chan []models.TidePredictionRecord, 1)}
+			service := &Service{
+				HttpClient: client.New(client.Options{BaseURL: server.URL, Timeout: 5 * time.Second}),
+				StationFinder: &mockStationFinder2{
+					findStationFn: func(ctx context.Context, stationID string) (*models.Station, error) {
+						return createTestStation(0), nil
+					},
+				},
+				PredictionCache: predictionCache,
+			}
+
+			response, err := service.GetCurrentTideForStation(context.Background(), "TEST001",
+				stringPtr("2024-01-01T00:00:00"), stringPtr("2024-01-01T12:00:00"), tt.datum, models.UnitsFeet)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantDatum, response.Datum)
+
+			mu.Lock()
+			require.NotEmpty(t, noaaDatums)
+			for _, d := range noaaDatums {
+				assert.Equal(t, string(tt.wantDatum), d)
+			}
+			mu.Unlock()
+
+			predictionCache.mu.Lock()
+			for _, d := range predictionCache.requested {
+				assert.Equal(t, tt.wantDatum, d)
+			}
+			predictionCache.mu.Unlock()
+
+			select {
+			case records := <-predictionCache.saved:
+				require.NotEmpty(t, records)
+				for _, r := range records {
+					assert.Equal(t, tt.wantDatum, r.Datum)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("predictions were not saved to the cache")
+			}
+		})
+	}
+}
+
+func TestUnitsConversion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "english", r.URL.Query().Get("units"), "NOAA data should always be fetched in feet")
+		if r.URL.Query().Get("interval") == "hilo" {
+			_, _ = fmt.Fprint(w, `{"predictions":[{"t":"2024-01-01 04:00","v":"10.0","type":"H"}]}`)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"predictions":[{"t":"2024-01-01 00:00","v":"1.0"},{"t":"2024-01-01 06:00","v":"2.0"}]}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name            string
+		cached          *models.TidePredictionRecord
+		units           models.Units
+		wantUnits       models.Units
+		wantPrediction  float64
+		wantExtremeHigh float64
+	}{
+		{
+			name:            "default units are feet",
+			units:           "",
+			wantUnits:       models.UnitsFeet,
+			wantPrediction:  1.0,
+			wantExtremeHigh: 10.0,
+		},
+		{
+			name:            "meters converted from NOAA feet",
+			units:           models.UnitsMeters,
+			wantUnits:       models.UnitsMeters,
+			wantPrediction:  0.305,
+			wantExtremeHigh: 3.048,
+		},
+		{
+			name: "record cached in meters is served in feet",
+			cached: &models.TidePredictionRecord{
+				StationID:   "TEST001",
+				Date:        "2024-01-01",
+				StationType: "R",
+				Units:       models.UnitsMeters,
+				Predictions: []models.TidePrediction{
+					{Timestamp: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli(), Height: 0.3048},
+				},
+				Extremes: []models.TideExtreme{
+					{Type: models.TideTypeHigh, Timestamp: time.Date(2024, 1, 1, 4, 0, 0, 0, time.UTC).UnixMilli(), Height: 3.048},
+				},
+			},
+			units:           models.UnitsFeet,
+			wantUnits:       models.UnitsFeet,
+			wantPrediction:  1.0,
+			wantExtremeHigh: 10.0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &Service{
+				HttpClient: client.New(client.Options{BaseURL: server.URL, Timeout: 5 * time.Second}),
+				StationFinder: &mockStationFinder2{
+					findStationFn: func(ctx context.Context, stationID string) (*models.Station, error) {
+						return createTestStation(0), nil
+					},
+				},
+				PredictionCache: &mockStationService2{
+					getPredictionsFn: func(ctx context.Context, stationID string, date time.Time) (*models.TidePredictionRecord, error) {
+						if tt.cached != nil && date.Format("2006-01-02") == tt.cached.Date {
+							return tt.cached, nil
+						}
+						return nil, nil
+					},
+				},
+			}
+
+			response, err := service.GetCurrentTideForStation(context.Background(), "TEST001",
+				stringPtr("2024-01-01T00:00:00"), stringPtr("2024-01-01T12:00:00"), models.DatumMLLW, tt.units)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantUnits, response.Units)
+			require.NotEmpty(t, response.Predictions)
+			assert.InDelta(t, tt.wantPrediction, response.Predictions[0].Height, 1e-9)
+			require.NotEmpty(t, response.Extremes)
+			assert.InDelta(t, tt.wantExtremeHigh, response.Extremes[0].Height, 1e-9)
+		})
+	}
+
+	_, err := (&Service{StationFinder: &mockStationFinder2{}}).GetCurrentTideForStation(context.Background(), "TEST001",
+		nil, nil, models.DatumMLLW, models.Units("fathoms"))
+	assert.ErrorContains(t, err, "invalid units")
+}
+
+type mockConditionsProvider struct {
+	conditions *models.MarineConditions
+	err        error
+}
+
+func (m *mockConditionsProvider) GetConditions(_ context.Context, _ *models.Station, _ models.Units) (*models.MarineConditions, error) {
+	return m.conditions, m.err
+}
+
+func TestGetCurrentTideForStation_Conditions(t *testing.T) {
+	airTemperature := &models.MetReading{Timestamp: time.Now().UnixMilli(), Value: 61.2, Units: "F"}
+	predictions, extremes := referenceTide(time.Now().UTC().Truncate(12*time.Hour), 96)
+	predictionCache := &mockStationService2{
+		getPredictionsFn: func(_ context.Context, stationID string, date time.Time) (*models.TidePredictionRecord, error) {
+			return &models.TidePredictionRecord{
+				StationID:   stationID,
+				Date:        date.Format("2006-01-02"),
+				StationType: "R",
+				Predictions: predictions,
+				Extremes:    extremes,
+			}, nil
+		},
+	}
+
+	tests := []struct {
+		name     string
+		provider ConditionsProvider
+		want     *models.MarineConditions
+	}{
+		{
+			name:     "conditions are attached",
+			provider: &mockConditionsProvider{conditions: &models.MarineConditions{AirTemperature: airTemperature}},
+			want:     &models.MarineConditions{AirTemperature: airTemperature},
+		},
+		{
+			name:     "errors are not fatal",
+			provider: &mockConditionsProvider{err: errors.New("upstream error")},
+		},
+		{
+			name: "no provider",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &Service{
+				HttpClient: &client.Client{},
+				StationFinder: &mockStationFinder2{
+					findStationFn: func(_ context.Context, _ string) (*models.Station, error) {
+						return createTestStation(0), nil
+					},
+				},
+				PredictionCache: predictionCache,
+				Conditions:      tt.provider,
+			}
+
+			response, err := service.GetCurrentTideForStation(context.Background(), "TEST001", nil, nil, models.DatumMLLW, models.UnitsFeet)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, response.Conditions)
+		})
+	}
+}
+
+func TestGetCurrentTideForStation_Astronomy(t *testing.T) {
+	predictionCache := &mockStationService2{
+		getPredictionsFn: func(_ context.Context, stationID string, date time.Time) (*models.TidePredictionRecord, error) {
+			predictions, extremes := referenceTide(date.Add(12*time.Hour), 12)
+			return &models.TidePredictionRecord{
+				StationID:   stationID,
+				Date:        date.Format("2006-01-02"),
+				StationType: "R",
+				Predictions: predictions,
+				Extremes:    extremes,
+			}, nil
+		},
+	}
+
+	service := &Service{
+		HttpClient: &client.Client{},
+		StationFinder: &mockStationFinder2{
+			findStationFn: func(_ context.Context, _ string) (*models.Station, error) {
+				return createTestStation(-25200), nil
+			},
+		},
+		PredictionCache: predictionCache,
+	}
+
+	start, end := "2024-04-07T12:00:00", "2024-04-09T06:00:00"
+	response, err := service.GetCurrentTideForStation(context.Background(), "TEST001", &start, &end, models.DatumMLLW, models.UnitsFeet)
+	require.NoError(t, err)
+
+	require.Len(t, response.Astronomy, 3)
+	assert.Equal(t, "2024-04-07", response.Astronomy[0].Date)
+	assert.Equal(t, "2024-04-09", response.Astronomy[2].Date)
+	assert.Equal(t, models.MoonPhaseNew, response.Astronomy[1].MoonPhase)
+	require.NotNil(t, response.Astronomy[1].Sunrise)
+	assert.Equal(t, "2024-04-08T06", response.Astronomy[1].Sunrise.LocalTime[:13])
+}
+
+// TestGetCurrentTideForStation_DaylightSavingTime checks the days the clocks
+// change. NOAA's lst_ldt times skip the missing hour in spring and list the
+// repeated hour twice in autumn.
+func TestGetCurrentTideForStation_DaylightSavingTime(t *testing.T) {
+	losAngeles, err := time.LoadLocation("America/Los_Angeles")
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("product") == "water_level" {
+			_, _ = fmt.Fprint(w, `{"error":{"message":"No data was found."}}`)
+			return
+		}
+
+		begin, _ := time.ParseInLocation("20060102", query.Get("begin_date"), losAngeles)
+		end, _ := time.ParseInLocation("20060102", query.Get("end_date"), losAngeles)
+		end = end.AddDate(0, 0, 1)
+
+		var entries []string
+		if query.Get("interval") == "hilo" {
+			for day := begin; day.Before(end); day = day.AddDate(0, 0, 1) {
+				date := day.Format("2006-01-02")
+				entries = append(entries,
+					fmt.Sprintf(`{"t":"%s 04:00","v":"9.000","type":"H"}`, date),
+					fmt.Sprintf(`{"t":"%s 10:00","v":"1.000","type":"L"}`, date))
+			}
+		} else {
+			for ts := begin; ts.Before(end); ts = ts.Add(6 * time.Minute) {
+				entries = append(entries, fmt.Sprintf(`{"t":"%s","v":"5.000"}`, ts.Format("2006-01-02 15:04")))
+			}
+		}
+		_, _ = fmt.Fprintf(w, `{"predictions":[%s]}`, strings.Join(entries, ","))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name            string
+		date            string
+		wantPredictions int
+		wantHighUTC     time.Time
+	}{
+		{
+			name:            "spring forward",
+			date:            "2024-03-10",
+			wantPredictions: 23 * 10,
+			wantHighUTC:     time.Date(2024, 3, 10, 11, 0, 0, 0, time.UTC), // 04:00 PDT
+		},
+		{
+			name:            "fall back",
+			date:            "2024-11-03",
+			wantPredictions: 25 * 10,
+			wantHighUTC:     time.Date(2024, 11, 3, 12, 0, 0, 0, time.UTC), // 04:00 PST
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved []models.TidePredictionRecord
+			var wg sync.WaitGroup
+			wg.Add(1)
+
+			service := &Service{
+				HttpClient: client.New(client.Options{BaseURL: server.URL, Timeout: 5 * time.Second}),
+				StationFinder: &mockStationFinder2{
+					findStationFn: func(_ context.Context, _ string) (*models.Station, error) {
+						return createTestStation(-8 * 3600), nil
+					},
+				},
+				PredictionCache: &mockStationService2{
+					savePredictionsBatchFn: func(_ context.Context, records []models.TidePredictionRecord) error {
+						defer wg.Done()
+						saved = records
+						return nil
+					},
+				},
+			}
+
+			start, end := tt.date+"T00:00:00", tt.date+"T23:59:59"
+			response, err := service.GetCurrentTideForStation(context.Background(), "TEST001", &start, &end, models.DatumMLLW, models.UnitsFeet)
+			require.NoError(t, err)
+			wg.Wait()
+
+			assert.Equal(t, "America/Los_Angeles", response.TimeZone)
+
+			// The day is bucketed by local midnights, so it holds every prediction
+			require.Len(t, saved, 1)
+			assert.Equal(t, tt.date, saved[0].Date)
+			assert.Len(t, saved[0].Predictions, tt.wantPredictions)
+
+			require.Len(t, response.Predictions, tt.wantPredictions)
+			for i := 1; i < len(response.Predictions); i++ {
+				require.Equal(t, int64(6*60*1000), response.Predictions[i].Timestamp-response.Predictions[i-1].Timestamp,
+					"predictions should be 6 minutes apart at %s", response.Predictions[i].LocalTime)
+			}
+			assert.Equal(t, tt.date+"T00:00:00", response.Predictions[0].LocalTime)
+			assert.Equal(t, tt.date+"T23:54:00", response.Predictions[len(response.Predictions)-1].LocalTime)
+
+			require.NotEmpty(t, response.Extremes)
+			assert.Equal(t, tt.wantHighUTC.UnixMilli(), response.Extremes[0].Timestamp)
+			assert.Equal(t, tt.date+"T04:00:00", response.Extremes[0].LocalTime)
+
+			require.Len(t, response.Days, 1)
+			assert.Equal(t, tt.date, response.Days[0].Date)
+		})
+	}
+}
+
+func TestParseNoaaTime_RepeatedHour(t *testing.T) {
+	losAngeles, err := time.LoadLocation("America/Los_Angeles")
+	require.NoError(t, err)
+
+	first, err := parseNoaaTime("2024-11-03 01:30", losAngeles, 0)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 11, 3, 8, 30, 0, 0, time.UTC).UnixMilli(), first)
+
+	// The same wall clock time again is the second, standard time, occurrence
+	second, err := parseNoaaTime("2024-11-03 01:30", losAngeles, first)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 11, 3, 9, 30, 0, 0, time.UTC).UnixMilli(), second)
+
+	// Times outside the repeated hour are unaffected by the previous time
+	later, err := parseNoaaTime("2024-11-03 03:00", losAngeles, second)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 11, 3, 11, 0, 0, 0, time.UTC).UnixMilli(), later)
+}
@@ -13,7 +13,7 @@ import (
 
 type mockCacheService struct{}
 
-func (m *mockCacheService) GetPredictions(_ context.Context, stationID string, date time.Time) (*models.TidePredictionRecord, error) {
+func (m *mockCacheService) GetPredictions(_ context.Context, stationID string, date time.Time, _ models.Datum) (*models.TidePredictionRecord, error) {
 	return &models.TidePredictionRecord{
 		StationID:   stationID,
 		Date:        date.Format("2006-01-02"),
@@ -31,7 +31,7 @@ var ErrStationNotFound = errors.New("station not found")
 
 type mockStationFinder struct{}
 
-func (m *mockStationFinder) FindNearestStations(_ context.Context, lat, lon float64, _ int) ([]models.Station, error) {
+func (m *mockStationFinder) FindNearestStations(_ context.Context, lat, lon float64, _ int, _ *models.StationFilter) ([]models.Station, error) {
 	return []models.Station{
 		{
 			ID:             "1234567",
@@ -43,6 +43,14 @@ func (m *mockStationFinder) FindNearestStations(_ context.Context, lat, lon floa
 	}, nil
 }
 
+func (m *mockStationFinder) FindStationsInBounds(_ context.Context, _, _, _, _ float64, _ int, _ *models.StationFilter) ([]models.Station, error) {
+	return nil, nil
+}
+
+func (m *mockStationFinder) SearchStations(_ context.Context, _ string, _ *models.Coordinates, _ int, _ *models.StationFilter) ([]models.Station, error) {
+	return nil, nil
+}
+
 func (m *mockStationFinder) FindStation(_ context.Context, stationID string) (*models.Station, error) {
 	if stationID == "1234567" {
 		return &models.Station{
@@ -88,7 +96,7 @@ func TestGetCurrentTide(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ctx := context.Background()
-			response, err := mockService.GetCurrentTide(ctx, tt.lat, tt.lon, nil, nil)
+			response, err := mockService.GetCurrentTide(ctx, tt.lat, tt.lon, nil, nil, models.DatumMLLW, models.UnitsFeet)
 
 			if tt.wantErr {
 				require.Error(t, err)
@@ -135,7 +143,7 @@ func TestGetCurrentTideForStation(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ctx := context.Background()
-			response, err := mockService.GetCurrentTideForStation(ctx, tt.stationID, nil, nil)
+			response, err := mockService.GetCurrentTideForStation(ctx, tt.stationID, nil, nil, models.DatumMLLW, models.UnitsFeet)
 
 			if tt.wantErr {
 				require.Error(t, err)
@@ -256,7 +264,7 @@ func TestDateRangeValidation(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := mockService.GetCurrentTideForStation(ctx, "1234567", tt.startTime, tt.endTime)
+			_, err := mockService.GetCurrentTideForStation(ctx, "1234567", tt.startTime, tt.endTime, models.DatumMLLW, models.UnitsFeet)
 
 			if tt.wantErr {
 				require.Error(t, err)
@@ -271,6 +279,28 @@ func TestDateRangeValidation(t *testing.T) {
 }
 
 // Helper functions
+func TestStationDatum(t *testing.T) {
+	service := &Service{
+		PredictionFetchers: map[models.Source]PredictionFetcher{
+			models.SourceCHS: NewCHSPredictionFetcher(nil),
+		},
+	}
+	noaa := &models.Station{ID: "9447130", Source: models.SourceNOAA}
+	chs := &models.Station{ID: "CHS-07795", Source: models.SourceCHS}
+
+	assert.Equal(t, models.DatumMLLW, service.stationDatum(noaa, ""))
+	assert.Equal(t, models.DatumCD, service.stationDatum(chs, ""), "the source's only datum")
+	assert.Equal(t, models.DatumMSL, service.stationDatum(noaa, models.DatumMSL))
+	assert.Equal(t, models.DatumMLLW, service.stationDatum(chs, models.DatumMLLW), "left for the fetcher to reject")
+
+	// NOAA doesn't publish other sources' chart datum
+	start := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	_, _, _, err := service.fetchPredictions(context.Background(), noaa, start, start, time.UTC, models.DatumCD)
+	var datumErr *UnsupportedDatumError
+	require.True(t, errors.As(err, &datumErr))
+	assert.EqualError(t, err, "NOAA predictions are not available relative to CD")
+}
+
 func stringPtr(s string) *string {
 	return &s
 }
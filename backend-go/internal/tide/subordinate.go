@@ -0,0 +1,194 @@
+package tide
+
+import (
+	"context"
+	"fmt"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/internal/station"
+	"github.com/bbernstein/flowebb/backend-go/internal/timezone"
+	"github.com/rs/zerolog/log"
+	"math"
+	"sort"
+	"time"
+)
+
+// Spacing of the derived subordinate curve, matching NOAA's 6-minute predictions
+const subordinateInterval = int64(6 * 60 * 1000)
+
+// getTideOffsets returns the station's offsets from its reference station,
+// fetching them from NOAA the first time they are needed.
+func (s *Service) getTideOffsets(ctx context.Context, station *models.Station) (*models.TideOffsets, error) {
+	if station.Offsets != nil {
+		return station.Offsets, nil
+	}
+
+	if cached, ok := s.offsets.Load(station.ID); ok {
+		return cached.(*models.TideOffsets), nil
+	}
+
+	offsets, err := s.fetchNoaaOffsets(ctx, station.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.offsets.Store(station.ID, offsets)
+	return offsets, nil
+}
+
+func (s *Service) fetchNoaaOffsets(ctx context.Context, stationID string) (*models.TideOffsets, error) {
+	resp, err := s.HttpClient.Get(ctx, fmt.Sprintf("/mdapi/prod/webapi/stations/%s/tidepredoffsets.json", stationID))
+	if err != nil {
+		return nil, NewNoaaAPIError("error making HTTP request for offsets", err)
+	}
+
+	offsets, err := station.DecodeTideOffsets(resp.Body)
+	if err != nil {
+		return nil, NewNoaaAPIError("error decoding offsets response", err)
+	}
+
+	if err := offsets.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid offsets for station %s: %w", stationID, err)
+	}
+
+	log.Debug().
+		Str("station_id", stationID).
+		Str("reference_station_id", offsets.ReferenceStationID).
+		Msg("Fetched tide offsets from NOAA")
+
+	return offsets, nil
+}
+
+// getSubordinatePredictions derives a full 6-minute curve and extremes for a
+// subordinate station from its reference station's predictions relative to
+// MLLW, the only datum NOAA publishes offsets for. The derived curve is as
+// fresh as the reference predictions it came from.
+func (s *Service) getSubordinatePredictions(ctx context.Context, station *models.Station, startDate, endDate time.Time, location *time.Location) ([]models.TidePrediction, []models.TideExtreme, models.DataFreshness, error) {
+	offsets, err := s.getTideOffsets(ctx, station)
+	if err != nil {
+		return nil, nil, models.DataFreshness{}, fmt.Errorf("getting tide offsets: %w", err)
+	}
+
+	refStation, err := s.StationFinder.FindStation(ctx, offsets.ReferenceStationID)
+	if err != nil {
+		return nil, nil, models.DataFreshness{}, fmt.Errorf("finding reference station %s: %w", offsets.ReferenceStationID, err)
+	}
+
+	refLocation := timezone.ForStation(refStation)
+	refPredictions, refExtremes, freshness, err := s.getSortedPredictions(ctx, refStation, startDate, endDate, refLocation, models.DatumMLLW)
+	if err != nil {
+		return nil, nil, models.DataFreshness{}, fmt.Errorf("getting reference predictions: %w", err)
+	}
+
+	if len(refPredictions) == 0 || len(refExtremes) == 0 {
+		return nil, nil, models.DataFreshness{}, fmt.Errorf("reference station %s has no predictions for the range", refStation.ID)
+	}
+
+	predictions, extremes := applyTideOffsets(offsets, refPredictions, refExtremes, location)
+	return predictions, extremes, freshness, nil
+}
+
+// applyTideOffsets shifts and scales a reference station's tide to a
+// subordinate station. Extremes take the high or low offsets directly. Between
+// extremes the time offset is blended by progress from one extreme to the next,
+// and the height correction by how close the level is to high water, so the
+// curve meets each corrected extreme. The shifted curve is resampled onto an
+// even 6-minute grid.
+func applyTideOffsets(offsets *models.TideOffsets, refPredictions []models.TidePrediction, refExtremes []models.TideExtreme, location *time.Location) ([]models.TidePrediction, []models.TideExtreme) {
+	extremes := make([]models.TideExtreme, len(refExtremes))
+	for i, e := range refExtremes {
+		high := e.Type == models.TideTypeHigh
+		timestamp := e.Timestamp + int64(offsets.TimeOffset(high))*60*1000
+		extremes[i] = models.TideExtreme{
+			Type:      e.Type,
+			Timestamp: timestamp,
+			LocalTime: formatLocalTime(timestamp, location),
+			Height:    roundHeight(offsets.AdjustHeight(e.Height, extremeWeight(high))),
+		}
+	}
+
+	shifted := make([]models.TidePrediction, len(refPredictions))
+	for i, p := range refPredictions {
+		idx := findNearestExtremeIndex(refExtremes, p.Timestamp)
+
+		var timeOffset float64
+		var weight float64
+		switch {
+		case idx == 0:
+			next := refExtremes[0]
+			timeOffset = float64(offsets.TimeOffset(next.Type == models.TideTypeHigh))
+			weight = extremeWeight(next.Type == models.TideTypeHigh)
+		case idx == len(refExtremes):
+			prev := refExtremes[idx-1]
+			timeOffset = float64(offsets.TimeOffset(prev.Type == models.TideTypeHigh))
+			weight = extremeWeight(prev.Type == models.TideTypeHigh)
+		default:
+			prev, next := refExtremes[idx-1], refExtremes[idx]
+			progress := float64(p.Timestamp-prev.Timestamp) / float64(next.Timestamp-prev.Timestamp)
+			prevOffset := float64(offsets.TimeOffset(prev.Type == models.TideTypeHigh))
+			nextOffset := float64(offsets.TimeOffset(next.Type == models.TideTypeHigh))
+			timeOffset = prevOffset + progress*(nextOffset-prevOffset)
+			weight = weightBetween(p.Height, prev, next)
+		}
+
+		shifted[i] = models.TidePrediction{
+			Timestamp: p.Timestamp + int64(math.Round(timeOffset*60*1000)),
+			Height:    offsets.AdjustHeight(p.Height, weight),
+		}
+	}
+
+	sort.Slice(shifted, func(i, j int) bool {
+		return shifted[i].Timestamp < shifted[j].Timestamp
+	})
+
+	return resamplePredictions(shifted, location), extremes
+}
+
+// extremeWeight is the weight of the high water correction at, or when the
+// level can only be related to, a single extreme.
+func extremeWeight(high bool) float64 {
+	if high {
+		return 1
+	}
+	return 0
+}
+
+// weightBetween returns where height lies between the low and high of two
+// neighbouring extremes: 0 at low water, 1 at high water.
+func weightBetween(height float64, prev, next models.TideExtreme) float64 {
+	if prev.Type == next.Type {
+		return extremeWeight(prev.Type == models.TideTypeHigh)
+	}
+
+	low, high := prev.Height, next.Height
+	if prev.Type == models.TideTypeHigh {
+		low, high = next.Height, prev.Height
+	}
+	if high <= low {
+		return 0.5
+	}
+
+	return math.Max(0, math.Min(1, (height-low)/(high-low)))
+}
+
+// resamplePredictions interpolates time-sorted predictions onto 6-minute
+// boundaries within their span.
+func resamplePredictions(predictions []models.TidePrediction, location *time.Location) []models.TidePrediction {
+	if len(predictions) == 0 {
+		return nil
+	}
+
+	first := predictions[0].Timestamp
+	last := predictions[len(predictions)-1].Timestamp
+	start := (first + subordinateInterval - 1) / subordinateInterval * subordinateInterval
+
+	resampled := make([]models.TidePrediction, 0, (last-start)/subordinateInterval+1)
+	for t := start; t <= last; t += subordinateInterval {
+		resampled = append(resampled, models.TidePrediction{
+			Timestamp: t,
+			LocalTime: formatLocalTime(t, location),
+			Height:    roundHeight(interpolatePredictions(predictions, t)),
+		})
+	}
+
+	return resampled
+}
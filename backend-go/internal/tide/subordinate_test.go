@@ -0,0 +1,363 @@
+package tide
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/pkg/http/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// referenceTide builds a semidiurnal reference curve with a 12 hour period,
+// high water (9 ft) at t0 and low water (1 ft) six hours later.
+func referenceTide(t0 time.Time, hours int) ([]models.TidePrediction, []models.TideExtreme) {
+	var predictions []models.TidePrediction
+	var extremes []models.TideExtreme
+
+	start := t0.Add(-time.Duration(hours) * time.Hour)
+	end := t0.Add(time.Duration(hours) * time.Hour)
+	for t := start; !t.After(end); t = t.Add(6 * time.Minute) {
+		phase := 2 * math.Pi * t.Sub(t0).Hours() / 12
+		predictions = append(predictions, models.TidePrediction{
+			Timestamp: t.UnixMilli(),
+			LocalTime: formatLocalTime(t.UnixMilli(), time.UTC),
+			Height:    roundHeight(5 + 4*math.Cos(phase)),
+		})
+	}
+
+	for t := start; !t.After(end); t = t.Add(6 * time.Hour) {
+		tideType := models.TideTypeHigh
+		height := 9.0
+		if int(math.Round(t.Sub(t0).Hours()/6))%2 != 0 {
+			tideType = models.TideTypeLow
+			height = 1.0
+		}
+		extremes = append(extremes, models.TideExtreme{
+			Type:      tideType,
+			Timestamp: t.UnixMilli(),
+			LocalTime: formatLocalTime(t.UnixMilli(), time.UTC),
+			Height:    height,
+		})
+	}
+
+	return predictions, extremes
+}
+
+func heightAt(predictions []models.TidePrediction, t time.Time) (float64, bool) {
+	for _, p := range predictions {
+		if p.Timestamp == t.UnixMilli() {
+			return p.Height, true
+		}
+	}
+	return 0, false
+}
+
+func TestApplyTideOffsets(t *testing.T) {
+	t0 := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	refPredictions, refExtremes := referenceTide(t0, 24)
+
+	tests := []struct {
+		name     string
+		offsets  *models.TideOffsets
+		wantHigh float64
+		wantLow  float64
+	}{
+		{
+			name: "additive heights",
+			offsets: &models.TideOffsets{
+				ReferenceStationID: "REF001",
+				HighTimeOffset:     30,
+				LowTimeOffset:      60,
+				HighHeightOffset:   0.5,
+				LowHeightOffset:    -0.2,
+				HeightAdjustment:   models.HeightAdjustmentAdditive,
+			},
+			wantHigh: 9.5,
+			wantLow:  0.8,
+		},
+		{
+			name: "ratio heights",
+			offsets: &models.TideOffsets{
+				ReferenceStationID: "REF001",
+				HighTimeOffset:     30,
+				LowTimeOffset:      60,
+				HighHeightOffset:   1.2,
+				LowHeightOffset:    0.8,
+				HeightAdjustment:   models.HeightAdjustmentRatio,
+			},
+			wantHigh: 10.8,
+			wantLow:  0.8,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			predictions, extremes := applyTideOffsets(tt.offsets, refPredictions, refExtremes, time.UTC)
+
+			require.Len(t, extremes, len(refExtremes))
+			for i, e := range extremes {
+				assert.Equal(t, refExtremes[i].Type, e.Type)
+				if e.Type == models.TideTypeHigh {
+					assert.Equal(t, refExtremes[i].Timestamp+30*60*1000, e.Timestamp)
+					assert.InDelta(t, tt.wantHigh, e.Height, 1e-9)
+				} else {
+					assert.Equal(t, refExtremes[i].Timestamp+60*60*1000, e.Timestamp)
+					assert.InDelta(t, tt.wantLow, e.Height, 1e-9)
+				}
+			}
+
+			require.NotEmpty(t, predictions)
+			for i, p := range predictions {
+				assert.Zero(t, p.Timestamp%subordinateInterval, "predictions should fall on 6-minute boundaries")
+				assert.NotEmpty(t, p.LocalTime)
+				if i > 0 {
+					assert.Greater(t, p.Timestamp, predictions[i-1].Timestamp)
+				}
+			}
+
+			// The curve should pass through the corrected extremes
+			high, ok := heightAt(predictions, t0.Add(30*time.Minute))
+			require.True(t, ok)
+			assert.InDelta(t, tt.wantHigh, high, 0.001)
+
+			low, ok := heightAt(predictions, t0.Add(7*time.Hour))
+			require.True(t, ok)
+			assert.InDelta(t, tt.wantLow, low, 0.001)
+
+			// And stay between them
+			for _, p := range predictions {
+				assert.GreaterOrEqual(t, p.Height, tt.wantLow-0.001)
+				assert.LessOrEqual(t, p.Height, tt.wantHigh+0.001)
+			}
+		})
+	}
+}
+
+func TestWeightBetween(t *testing.T) {
+	high := models.TideExtreme{Type: models.TideTypeHigh, Height: 9}
+	low := models.TideExtreme{Type: models.TideTypeLow, Height: 1}
+
+	assert.Equal(t, 1.0, weightBetween(9, high, low))
+	assert.Equal(t, 0.0, weightBetween(1, low, high))
+	assert.Equal(t, 0.5, weightBetween(5, high, low))
+	assert.Equal(t, 1.0, weightBetween(12, low, high), "weight should be clamped")
+	assert.Equal(t, 0.0, weightBetween(-3, low, high), "weight should be clamped")
+	assert.Equal(t, 1.0, weightBetween(5, high, high))
+}
+
+func TestFetchNoaaOffsets(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    *models.TideOffsets
+		wantErr string
+	}{
+		{
+			name: "valid offsets",
+			body: `{"refStationId":"9414290","type":"S","heightOffsetHighTide":1.12,` +
+				`"heightOffsetLowTide":0.97,"timeOffsetHighTide":24,"timeOffsetLowTide":51,` +
+				`"heightAdjustedType":"R"}`,
+			want: &models.TideOffsets{
+				ReferenceStationID: "9414290",
+				HighTimeOffset:     24,
+				LowTimeOffset:      51,
+				HighHeightOffset:   1.12,
+				LowHeightOffset:    0.97,
+				HeightAdjustment:   models.HeightAdjustmentRatio,
+			},
+		},
+		{
+			name:    "missing reference station",
+			body:    `{"heightOffsetHighTide":0.5,"heightOffsetLowTide":0.1,"heightAdjustedType":"A"}`,
+			wantErr: "reference station ID is required",
+		},
+		{
+			name:    "malformed response",
+			body:    `not json`,
+			wantErr: "error decoding offsets response",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/mdapi/prod/webapi/stations/9414305/tidepredoffsets.json", r.URL.Path)
+				_, _ = fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			service := &Service{
+				HttpClient: client.New(client.Options{BaseURL: server.URL, Timeout: 5 * time.Second}),
+			}
+
+			offsets, err := service.fetchNoaaOffsets(context.Background(), "9414305")
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, offsets)
+		})
+	}
+}
+
+func TestGetTideOffsets_CachesFetchedOffsets(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = fmt.Fprint(w, `{"refStationId":"9414290","heightOffsetHighTide":0.3,`+
+			`"heightOffsetLowTide":0.1,"timeOffsetHighTide":10,"timeOffsetLowTide":20,"heightAdjustedType":"A"}`)
+	}))
+	defer server.Close()
+
+	service := &Service{
+		HttpClient: client.New(client.Options{BaseURL: server.URL, Timeout: 5 * time.Second}),
+	}
+	station := &models.Station{ID: "9414305"}
+
+	for i := 0; i < 3; i++ {
+		offsets, err := service.getTideOffsets(context.Background(), station)
+		require.NoError(t, err)
+		assert.Equal(t, "9414290", offsets.ReferenceStationID)
+	}
+	assert.Equal(t, 1, requests)
+}
+
+func TestGetCurrentTideForStation_Subordinate(t *testing.T) {
+	now := time.Now().UTC()
+	t0 := now.Truncate(12 * time.Hour)
+	refPredictions, refExtremes := referenceTide(t0, 96)
+
+	subordinateType := "S"
+	subordinate := &models.Station{
+		ID:             "SUB001",
+		Name:           "Subordinate Station",
+		Latitude:       47.5,
+		Longitude:      -122.4,
+		Source:         models.SourceNOAA,
+		TimeZoneOffset: 0,
+		StationType:    &subordinateType,
+		Offsets: &models.TideOffsets{
+			ReferenceStationID: "REF001",
+			HighTimeOffset:     30,
+			LowTimeOffset:      60,
+			HighHeightOffset:   0.5,
+			LowHeightOffset:    -0.2,
+			HeightAdjustment:   models.HeightAdjustmentAdditive,
+		},
+	}
+
+	// Records per day: the reference station has a full curve, the
+	// subordinate station only has extremes
+	predictionCache := &mockStationService2{
+		getPredictionsFn: func(_ context.Context, stationID string, date time.Time) (*models.TidePredictionRecord, error) {
+			dayStart, dayEnd := date.UnixMilli(), date.AddDate(0, 0, 1).UnixMilli()
+			record := &models.TidePredictionRecord{
+				StationID: stationID,
+				Date:      date.Format("2006-01-02"),
+			}
+			for _, e := range refExtremes {
+				if e.Timestamp >= dayStart && e.Timestamp < dayEnd {
+					record.Extremes = append(record.Extremes, e)
+				}
+			}
+			if stationID == "REF001" {
+				record.StationType = "R"
+				for _, p := range refPredictions {
+					if p.Timestamp >= dayStart && p.Timestamp < dayEnd {
+						record.Predictions = append(record.Predictions, p)
+					}
+				}
+			} else {
+				record.StationType = "S"
+			}
+			return record, nil
+		},
+	}
+
+	tests := []struct {
+		name       string
+		datum      models.Datum
+		findRef    func() (*models.Station, error)
+		wantMethod string
+	}{
+		{
+			name:       "derived from reference station",
+			datum:      models.DatumMLLW,
+			findRef:    func() (*models.Station, error) { return createTestStation(0), nil },
+			wantMethod: "Subordinate offsets",
+		},
+		{
+			name:       "falls back to extremes when reference is unavailable",
+			datum:      models.DatumMLLW,
+			findRef:    func() (*models.Station, error) { return nil, errors.New("station not found") },
+			wantMethod: "NOAA API",
+		},
+		{
+			// NOAA only publishes subordinate offsets relative to MLLW
+			name:       "other datums interpolate the station's own extremes",
+			datum:      models.DatumMSL,
+			wantMethod: "NOAA API",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			finder := &mockStationFinder2{
+				findStationFn: func(_ context.Context, stationID string) (*models.Station, error) {
+					if stationID == "SUB001" {
+						return subordinate, nil
+					}
+					if tt.findRef == nil {
+						t.Errorf("reference station %s looked up", stationID)
+						return nil, errors.New("station not found")
+					}
+					ref, err := tt.findRef()
+					if ref != nil {
+						ref.ID = stationID
+					}
+					return ref, err
+				},
+			}
+
+			service := &Service{
+				HttpClient:      &client.Client{},
+				StationFinder:   finder,
+				PredictionCache: predictionCache,
+			}
+
+			response, err := service.GetCurrentTideForStation(context.Background(), "SUB001", nil, nil, tt.datum, models.UnitsFeet)
+			require.NoError(t, err)
+			require.NotNil(t, response)
+
+			assert.Equal(t, tt.wantMethod, response.CalculationMethod)
+			assert.Equal(t, tt.datum, response.Datum)
+			assert.Equal(t, "SUB001", response.NearestStation)
+			assert.NotEmpty(t, response.Predictions)
+			assert.NotEmpty(t, response.Extremes)
+
+			// Derived extremes take the offsets; interpolated ones are the
+			// station's own
+			wantHigh, wantLow := 9.0, 1.0
+			if tt.wantMethod == "Subordinate offsets" {
+				wantHigh, wantLow = 9.5, 0.8
+			}
+			for _, e := range response.Extremes {
+				if e.Type == models.TideTypeHigh {
+					assert.InDelta(t, wantHigh, e.Height, 1e-9)
+				} else {
+					assert.InDelta(t, wantLow, e.Height, 1e-9)
+				}
+			}
+		})
+	}
+}
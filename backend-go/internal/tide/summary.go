@@ -0,0 +1,152 @@
+package tide
+
+import (
+	"github.com/bbernstein/flowebb/backend-go/internal/astronomy"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// Spring and neap tides lag the phases of the moon by about a day
+	springNeapLag = 24 * time.Hour
+	// How close, in days, the lagged phase must be to new or full moon for a
+	// spring tide, or to a quarter moon for a neap tide
+	springNeapWindowDays = 2.0
+	// The moon gains this many degrees on the sun per day
+	moonPhaseDegreesPerDay = 360 / 29.530589
+	// Below this many days with a range, percentiles say little about the
+	// fortnightly cycle and days are classified by the moon alone
+	minDaysForRangePercentile = 7
+)
+
+// summarizeDays builds a summary for every local calendar day from start
+// through end. Heights are taken as they are, so they must all be in the
+// same units and datum.
+func summarizeDays(predictions []models.TidePrediction, extremes []models.TideExtreme, start, end time.Time, location *time.Location) []models.TideDaySummary {
+	start, end = start.In(location), end.In(location)
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, location)
+
+	var days []models.TideDaySummary
+	var middays []time.Time
+	for !day.After(end) {
+		next := day.AddDate(0, 0, 1)
+		days = append(days, summarizeDay(predictions, extremes, day, next))
+		middays = append(middays, day.Add(next.Sub(day)/2))
+		day = next
+	}
+
+	rangedDays := applyRangePercentiles(days)
+
+	for i := range days {
+		var rangePercentile *float64
+		if rangedDays >= minDaysForRangePercentile {
+			rangePercentile = days[i].RangePercentile
+		}
+		days[i].SpringNeap = classifySpringNeap(middays[i], rangePercentile)
+	}
+
+	return days
+}
+
+func summarizeDay(predictions []models.TidePrediction, extremes []models.TideExtreme, dayStart, dayEnd time.Time) models.TideDaySummary {
+	startMillis, endMillis := dayStart.UnixMilli(), dayEnd.UnixMilli()
+	summary := models.TideDaySummary{Date: dayStart.Format("2006-01-02")}
+
+	var extremeSum float64
+	var extremeCount int
+	for _, e := range extremes {
+		if e.Timestamp < startMillis || e.Timestamp >= endMillis {
+			continue
+		}
+		extremeSum += e.Height
+		extremeCount++
+
+		e := e
+		switch e.Type {
+		case models.TideTypeHigh:
+			if summary.HigherHigh == nil || e.Height > summary.HigherHigh.Height {
+				summary.HigherHigh = &e
+			}
+		case models.TideTypeLow:
+			if summary.LowerLow == nil || e.Height < summary.LowerLow.Height {
+				summary.LowerLow = &e
+			}
+		}
+	}
+
+	if summary.HigherHigh != nil && summary.LowerLow != nil {
+		tideRange := roundHeight(summary.HigherHigh.Height - summary.LowerLow.Height)
+		summary.Range = &tideRange
+	}
+
+	// Average the curve when there is one, otherwise the extremes
+	var predictionSum float64
+	var predictionCount int
+	for _, p := range predictions {
+		if p.Timestamp >= startMillis && p.Timestamp < endMillis {
+			predictionSum += p.Height
+			predictionCount++
+		}
+	}
+	switch {
+	case predictionCount > 0:
+		meanLevel := roundHeight(predictionSum / float64(predictionCount))
+		summary.MeanLevel = &meanLevel
+	case extremeCount > 0:
+		meanLevel := roundHeight(extremeSum / float64(extremeCount))
+		summary.MeanLevel = &meanLevel
+	}
+
+	return summary
+}
+
+// applyRangePercentiles ranks each day's range against the other days and
+// returns how many days had one. Days without a range are left out.
+func applyRangePercentiles(days []models.TideDaySummary) int {
+	var ranges []float64
+	for _, d := range days {
+		if d.Range != nil {
+			ranges = append(ranges, *d.Range)
+		}
+	}
+	if len(ranges) < 2 {
+		return len(ranges)
+	}
+	sort.Float64s(ranges)
+
+	for i := range days {
+		if days[i].Range == nil {
+			continue
+		}
+		// Midpoint rank, so ties share a percentile
+		below := sort.SearchFloat64s(ranges, *days[i].Range)
+		equal := sort.SearchFloat64s(ranges, math.Nextafter(*days[i].Range, math.Inf(1))) - below
+		percentile := math.Round(100*(float64(below)+float64(equal-1)/2)/float64(len(ranges)-1)*10) / 10
+		days[i].RangePercentile = &percentile
+	}
+
+	return len(ranges)
+}
+
+// classifySpringNeap places a day in the fortnightly cycle from the phase of
+// the moon, lagged by the usual age of the tide. When there are enough days to
+// rank the ranges, the day's range must also agree: above the median for a
+// spring tide and below it for a neap tide.
+func classifySpringNeap(midday time.Time, rangePercentile *float64) models.SpringNeap {
+	phase := astronomy.MoonPhaseAngle(midday.Add(-springNeapLag))
+
+	// Days from the nearest new or full moon, and from the nearest quarter
+	syzygyDays := math.Abs(math.Remainder(phase, 180)) / moonPhaseDegreesPerDay
+	quadratureDays := math.Abs(math.Remainder(phase-90, 180)) / moonPhaseDegreesPerDay
+
+	switch {
+	case syzygyDays <= springNeapWindowDays && (rangePercentile == nil || *rangePercentile >= 50):
+		return models.SpringNeapSpring
+	case quadratureDays <= springNeapWindowDays && (rangePercentile == nil || *rangePercentile <= 50):
+		return models.SpringNeapNeap
+	default:
+		return models.SpringNeapIntermediate
+	}
+}
@@ -0,0 +1,187 @@
+package tide
+
+import (
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+	"time"
+)
+
+// fortnightlyTide builds semidiurnal extremes whose range peaks at springPeak
+// and shrinks to a minimum a quarter of a lunar month later.
+func fortnightlyTide(springPeak, start, end time.Time) []models.TideExtreme {
+	const halfCycle = 12*time.Hour + 25*time.Minute
+	fortnight := 29.530589 / 2 * 24 * float64(time.Hour)
+
+	var extremes []models.TideExtreme
+	high := true
+	for t := start; t.Before(end); t = t.Add(halfCycle / 2) {
+		amplitude := 3 + 2*math.Cos(2*math.Pi*float64(t.Sub(springPeak))/fortnight)
+		extreme := models.TideExtreme{
+			Type:      models.TideTypeHigh,
+			Timestamp: t.UnixMilli(),
+			LocalTime: formatLocalTime(t.UnixMilli(), time.UTC),
+			Height:    roundHeight(4 + amplitude),
+		}
+		if !high {
+			extreme.Type = models.TideTypeLow
+			extreme.Height = roundHeight(4 - amplitude)
+		}
+		extremes = append(extremes, extreme)
+		high = !high
+	}
+	return extremes
+}
+
+func TestSummarizeDays_SpringNeap(t *testing.T) {
+	// New moon 2024-04-08 18:21 UTC and first quarter 2024-04-15 19:13 UTC,
+	// with the spring tide a day after the new moon
+	start := time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 4, 29, 23, 59, 59, 0, time.UTC)
+	extremes := fortnightlyTide(time.Date(2024, 4, 9, 18, 0, 0, 0, time.UTC), start, end)
+
+	days := summarizeDays(nil, extremes, start, end, time.UTC)
+	require.Len(t, days, 28)
+
+	byDate := make(map[string]models.TideDaySummary)
+	for _, d := range days {
+		byDate[d.Date] = d
+	}
+
+	tests := []struct {
+		date string
+		want models.SpringNeap
+	}{
+		{date: "2024-04-09", want: models.SpringNeapSpring},
+		{date: "2024-04-10", want: models.SpringNeapSpring},
+		{date: "2024-04-12", want: models.SpringNeapIntermediate},
+		{date: "2024-04-16", want: models.SpringNeapNeap},
+		{date: "2024-04-17", want: models.SpringNeapNeap},
+		{date: "2024-04-20", want: models.SpringNeapIntermediate},
+		{date: "2024-04-24", want: models.SpringNeapSpring},
+	}
+	for _, tt := range tests {
+		t.Run(tt.date, func(t *testing.T) {
+			day := byDate[tt.date]
+			assert.Equal(t, tt.want, day.SpringNeap)
+			require.NotNil(t, day.RangePercentile)
+			if tt.want == models.SpringNeapSpring {
+				assert.Greater(t, *day.RangePercentile, 75.0)
+			}
+			if tt.want == models.SpringNeapNeap {
+				assert.Less(t, *day.RangePercentile, 25.0)
+			}
+		})
+	}
+}
+
+func TestSummarizeDays_ShortRangeUsesMoonPhase(t *testing.T) {
+	// A single day has no percentile to go on
+	start := time.Date(2024, 4, 9, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24*time.Hour - time.Second)
+	extremes := fortnightlyTide(start, start, end)
+
+	days := summarizeDays(nil, extremes, start, end, time.UTC)
+	require.Len(t, days, 1)
+	assert.Nil(t, days[0].RangePercentile)
+	assert.Equal(t, models.SpringNeapSpring, days[0].SpringNeap)
+}
+
+func TestSummarizeDay(t *testing.T) {
+	dayStart := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	dayEnd := dayStart.AddDate(0, 0, 1)
+	at := func(hour int) int64 { return dayStart.Add(time.Duration(hour) * time.Hour).UnixMilli() }
+
+	extremes := []models.TideExtreme{
+		{Type: models.TideTypeLow, Timestamp: at(-2), Height: -3.0}, // Previous day
+		{Type: models.TideTypeHigh, Timestamp: at(4), Height: 8.2},
+		{Type: models.TideTypeLow, Timestamp: at(10), Height: 1.4},
+		{Type: models.TideTypeHigh, Timestamp: at(16), Height: 9.6},
+		{Type: models.TideTypeLow, Timestamp: at(22), Height: -0.8},
+		{Type: models.TideTypeHigh, Timestamp: at(24), Height: 11.0}, // Next day
+	}
+
+	tests := []struct {
+		name        string
+		predictions []models.TidePrediction
+		extremes    []models.TideExtreme
+		wantHigh    *float64
+		wantLow     *float64
+		wantRange   *float64
+		wantMean    *float64
+	}{
+		{
+			name: "mean of the predictions",
+			predictions: []models.TidePrediction{
+				{Timestamp: at(0), Height: 4},
+				{Timestamp: at(12), Height: 5},
+				{Timestamp: at(24), Height: 100}, // Next day
+			},
+			extremes:  extremes,
+			wantHigh:  float64Ptr(9.6),
+			wantLow:   float64Ptr(-0.8),
+			wantRange: float64Ptr(10.4),
+			wantMean:  float64Ptr(4.5),
+		},
+		{
+			name:      "mean of the extremes without predictions",
+			extremes:  extremes,
+			wantHigh:  float64Ptr(9.6),
+			wantLow:   float64Ptr(-0.8),
+			wantRange: float64Ptr(10.4),
+			wantMean:  float64Ptr(4.6),
+		},
+		{
+			name:     "no range without a low",
+			extremes: extremes[1:2],
+			wantHigh: float64Ptr(8.2),
+			wantMean: float64Ptr(8.2),
+		},
+		{
+			name: "empty day",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := summarizeDay(tt.predictions, tt.extremes, dayStart, dayEnd)
+			assert.Equal(t, "2024-06-01", summary.Date)
+
+			if tt.wantHigh == nil {
+				assert.Nil(t, summary.HigherHigh)
+			} else {
+				require.NotNil(t, summary.HigherHigh)
+				assert.Equal(t, *tt.wantHigh, summary.HigherHigh.Height)
+			}
+			if tt.wantLow == nil {
+				assert.Nil(t, summary.LowerLow)
+			} else {
+				require.NotNil(t, summary.LowerLow)
+				assert.Equal(t, *tt.wantLow, summary.LowerLow.Height)
+			}
+			assert.Equal(t, tt.wantRange, summary.Range)
+			assert.Equal(t, tt.wantMean, summary.MeanLevel)
+		})
+	}
+}
+
+func TestApplyRangePercentiles(t *testing.T) {
+	days := []models.TideDaySummary{
+		{Range: float64Ptr(4)},
+		{Range: float64Ptr(2)},
+		{},
+		{Range: float64Ptr(6)},
+		{Range: float64Ptr(4)},
+		{Range: float64Ptr(8)},
+	}
+
+	assert.Equal(t, 5, applyRangePercentiles(days))
+	assert.Equal(t, float64Ptr(37.5), days[0].RangePercentile)
+	assert.Equal(t, float64Ptr(0), days[1].RangePercentile)
+	assert.Nil(t, days[2].RangePercentile)
+	assert.Equal(t, float64Ptr(75), days[3].RangePercentile)
+	assert.Equal(t, float64Ptr(37.5), days[4].RangePercentile)
+	assert.Equal(t, float64Ptr(100), days[5].RangePercentile)
+}
@@ -0,0 +1,137 @@
+package tide
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/internal/station"
+	"github.com/bbernstein/flowebb/backend-go/pkg/http/client"
+	"github.com/rs/zerolog/log"
+	"math"
+	"net/http"
+	"time"
+)
+
+// Days of tidal events the Admiralty API publishes, starting today
+const ukhoMaxDurationDays = 7
+
+// UKHOPredictionFetcher fetches high and low waters for UK Hydrographic
+// Office stations from the Admiralty Tidal API. Heights are in metres above
+// chart datum, approximately lowest astronomical tide, served as
+// models.DatumCD; no other datum, MLLW included, is available. The API only
+// publishes a week of events from today, so curves are interpolated from the
+// extremes, and earlier dates can't be fetched.
+type UKHOPredictionFetcher struct {
+	HttpClient *client.Client
+
+	now func() time.Time
+}
+
+var _ PredictionFetcher = (*UKHOPredictionFetcher)(nil)
+var _ ExtremesOnlyFetcher = (*UKHOPredictionFetcher)(nil)
+var _ SingleDatumFetcher = (*UKHOPredictionFetcher)(nil)
+
+// NewUKHOPredictionFetcher creates a fetcher whose client's base URL is the
+// Tidal API's, e.g. https://admiraltyapi.azure-api.net/uktidalapi, and which
+// sends the subscription key in the station.UKHOAPIKeyHeader header
+func NewUKHOPredictionFetcher(httpClient *client.Client) *UKHOPredictionFetcher {
+	return &UKHOPredictionFetcher{HttpClient: httpClient, now: time.Now}
+}
+
+func (f *UKHOPredictionFetcher) Units() models.Units {
+	return models.UnitsMeters
+}
+
+func (f *UKHOPredictionFetcher) ExtremesOnly() bool {
+	return true
+}
+
+func (f *UKHOPredictionFetcher) Datum() models.Datum {
+	return models.DatumCD
+}
+
+func (f *UKHOPredictionFetcher) FetchPredictions(ctx context.Context, localStation *models.Station, start, end time.Time, location *time.Location, datum models.Datum) ([]models.TidePrediction, []models.TideExtreme, error) {
+	if datum != models.DatumCD {
+		return nil, nil, &UnsupportedDatumError{Source: models.SourceUKHO, Datum: datum}
+	}
+
+	id, ok := station.UKHOStationCode(localStation.ID)
+	if !ok {
+		return nil, nil, fmt.Errorf("not a UKHO station: %s", localStation.ID)
+	}
+
+	// Events are published from the start of today, UTC
+	now := f.now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	lastDay := today.AddDate(0, 0, ukhoMaxDurationDays)
+	if !end.After(today) || !start.Before(lastDay) {
+		return nil, nil, NewInvalidRangeError(fmt.Sprintf("UKHO tidal events are only published for the next %d days", ukhoMaxDurationDays))
+	}
+
+	duration := int(math.Ceil(end.Sub(today).Hours() / 24))
+	if duration > ukhoMaxDurationDays {
+		duration = ukhoMaxDurationDays
+	}
+
+	resp, err := f.HttpClient.Get(ctx, fmt.Sprintf("/api/V1/Stations/%s/TidalEvents?duration=%d", id, duration))
+	if err != nil {
+		return nil, nil, NewSourceAPIError(models.SourceUKHO, "error making HTTP request for tidal events", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil, fmt.Errorf("UKHO station not found: %s", id)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, nil, NewSourceAPIError(models.SourceUKHO, fmt.Sprintf("tidal events request returned status %d", resp.StatusCode), nil)
+	}
+
+	var events []struct {
+		EventType string   `json:"EventType"`
+		DateTime  string   `json:"DateTime"` // UTC, without a zone designator
+		Height    *float64 `json:"Height"`
+	}
+	if err := json.Unmarshal(resp.Body, &events); err != nil {
+		return nil, nil, NewSourceAPIError(models.SourceUKHO, "error decoding tidal events response", err)
+	}
+
+	extremes := make([]models.TideExtreme, 0, len(events))
+	for _, e := range events {
+		var tideType models.TideType
+		switch e.EventType {
+		case "HighWater":
+			tideType = models.TideTypeHigh
+		case "LowWater":
+			tideType = models.TideTypeLow
+		default:
+			continue
+		}
+		if e.Height == nil {
+			continue
+		}
+
+		t, err := time.ParseInLocation("2006-01-02T15:04:05", e.DateTime, time.UTC)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parsing time %s: %w", e.DateTime, err)
+		}
+		if t.Before(start) || !t.Before(end) {
+			continue
+		}
+
+		timestamp := t.UnixMilli()
+		extremes = append(extremes, models.TideExtreme{
+			Type:      tideType,
+			Timestamp: timestamp,
+			LocalTime: formatLocalTime(timestamp, location),
+			Height:    *e.Height,
+		})
+	}
+
+	log.Debug().
+		Str("station_id", localStation.ID).
+		Time("start", start).
+		Time("end", end).
+		Int("extremes", len(extremes)).
+		Msg("Fetched tidal events from UKHO")
+
+	return nil, extremes, nil
+}
@@ -0,0 +1,247 @@
+package tide
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/internal/station"
+	"github.com/bbernstein/flowebb/backend-go/pkg/http/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The first day of the tidal events in testdata, which the stub treats as today
+var ukhoTestToday = time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+
+// ukhoTestServer serves Admiralty tidal events for Plymouth from testdata,
+// which cover a week from 2026-07-01, trimmed to each request's duration the
+// way the API does. Requests without the subscription key are refused.
+type ukhoTestServer struct {
+	*httptest.Server
+	mu        sync.Mutex
+	durations []string
+}
+
+func newUKHOTestServer(t *testing.T) *ukhoTestServer {
+	body, err := os.ReadFile("testdata/ukho_tidal_events.json")
+	require.NoError(t, err)
+	var events []map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &events))
+
+	srv := &ukhoTestServer{}
+	srv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(station.UKHOAPIKeyHeader) != "test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/api/V1/Stations/0014/TidalEvents" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		duration := r.URL.Query().Get("duration")
+		srv.mu.Lock()
+		srv.durations = append(srv.durations, duration)
+		srv.mu.Unlock()
+
+		days, err := strconv.Atoi(duration)
+		if err != nil || days < 1 || days > 7 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		until := ukhoTestToday.AddDate(0, 0, days)
+		result := make([]map[string]interface{}, 0)
+		for _, e := range events {
+			t, _ := time.Parse("2006-01-02T15:04:05", e["DateTime"].(string))
+			if t.Before(until) {
+				result = append(result, e)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(result)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func (s *ukhoTestServer) requestedDurations() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.durations...)
+}
+
+func (s *ukhoTestServer) fetcher(apiKey string) *UKHOPredictionFetcher {
+	fetcher := NewUKHOPredictionFetcher(client.New(client.Options{
+		BaseURL:    s.URL,
+		Timeout:    5 * time.Second,
+		MaxRetries: 1,
+		Headers:    map[string]string{station.UKHOAPIKeyHeader: apiKey},
+	}))
+	fetcher.now = func() time.Time { return ukhoTestToday.Add(8 * time.Hour) }
+	return fetcher
+}
+
+func ukhoTestStation() *models.Station {
+	return &models.Station{
+		ID:        "UKHO-0014",
+		Name:      "Plymouth (Devonport)",
+		Latitude:  50.3683,
+		Longitude: -4.185,
+		Source:    models.SourceUKHO,
+		TimeZone:  "Europe/London",
+	}
+}
+
+func TestUKHOPredictionFetcher(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	require.NoError(t, err)
+	start := time.Date(2026, 7, 1, 0, 0, 0, 0, london)
+	end := start.AddDate(0, 0, 2)
+
+	t.Run("high and low waters", func(t *testing.T) {
+		srv := newUKHOTestServer(t)
+		fetcher := srv.fetcher("test-key")
+		assert.Equal(t, models.UnitsMeters, fetcher.Units())
+		assert.True(t, fetcher.ExtremesOnly())
+
+		predictions, extremes, err := fetcher.FetchPredictions(context.Background(), ukhoTestStation(), start, end, london, models.DatumCD)
+		require.NoError(t, err)
+
+		assert.Nil(t, predictions, "the API only publishes extremes")
+		require.Len(t, extremes, 7)
+		assert.Equal(t, models.TideExtreme{
+			Type:      models.TideTypeHigh,
+			Timestamp: time.Date(2026, 7, 1, 4, 12, 0, 0, time.UTC).UnixMilli(),
+			LocalTime: "2026-07-01T05:12:00",
+			Height:    5.21,
+		}, extremes[0])
+		assert.Equal(t, models.TideTypeLow, extremes[1].Type)
+		assert.Equal(t, "2026-07-02T18:30:00", extremes[6].LocalTime)
+
+		// Events start today, so two days cover the range
+		assert.Equal(t, []string{"2"}, srv.requestedDurations())
+	})
+
+	t.Run("at most a week is requested", func(t *testing.T) {
+		srv := newUKHOTestServer(t)
+		_, extremes, err := srv.fetcher("test-key").FetchPredictions(context.Background(), ukhoTestStation(), start, start.AddDate(0, 0, 10), london, models.DatumCD)
+		require.NoError(t, err)
+		assert.Len(t, extremes, 27)
+		assert.Equal(t, []string{"7"}, srv.requestedDurations())
+	})
+
+	t.Run("outside the published week", func(t *testing.T) {
+		srv := newUKHOTestServer(t)
+		fetcher := srv.fetcher("test-key")
+
+		for _, from := range []time.Time{start.AddDate(0, 0, -3), start.AddDate(0, 0, 8)} {
+			_, _, err := fetcher.FetchPredictions(context.Background(), ukhoTestStation(), from, from.AddDate(0, 0, 1), london, models.DatumCD)
+			var rangeErr *InvalidRangeError
+			require.True(t, errors.As(err, &rangeErr), from)
+			assert.EqualError(t, err, "UKHO tidal events are only published for the next 7 days")
+		}
+		assert.Empty(t, srv.requestedDurations())
+	})
+
+	t.Run("missing subscription key", func(t *testing.T) {
+		_, _, err := newUKHOTestServer(t).fetcher("").FetchPredictions(context.Background(), ukhoTestStation(), start, end, london, models.DatumCD)
+		var sourceErr *SourceAPIError
+		require.True(t, errors.As(err, &sourceErr))
+		assert.Equal(t, models.SourceUKHO, sourceErr.Source)
+		assert.EqualError(t, err, "UKHO API error: tidal events request returned status 401")
+	})
+
+	t.Run("only chart datum", func(t *testing.T) {
+		fetcher := newUKHOTestServer(t).fetcher("test-key")
+		assert.Equal(t, models.DatumCD, fetcher.Datum())
+
+		// Chart datum isn't MLLW, so it isn't served as MLLW either
+		for _, datum := range []models.Datum{models.DatumMLLW, models.DatumMHHW} {
+			_, _, err := fetcher.FetchPredictions(context.Background(), ukhoTestStation(), start, end, london, datum)
+			var datumErr *UnsupportedDatumError
+			require.True(t, errors.As(err, &datumErr))
+			assert.EqualError(t, err, fmt.Sprintf("UKHO predictions are not available relative to %s", datum))
+		}
+	})
+
+	t.Run("unknown station", func(t *testing.T) {
+		fetcher := newUKHOTestServer(t).fetcher("test-key")
+
+		s := ukhoTestStation()
+		s.ID = "UKHO-9999"
+		_, _, err := fetcher.FetchPredictions(context.Background(), s, start, end, london, models.DatumCD)
+		assert.EqualError(t, err, "UKHO station not found: 9999")
+
+		s.ID = "9447130"
+		_, _, err = fetcher.FetchPredictions(context.Background(), s, start, end, london, models.DatumCD)
+		assert.EqualError(t, err, "not a UKHO station: 9447130")
+	})
+}
+
+func TestGetCurrentTideForStation_UKHO(t *testing.T) {
+	srv := newUKHOTestServer(t)
+	saved := make(chan []models.TidePredictionRecord, 1)
+	service := &Service{
+		// Nothing should be asked of NOAA
+		HttpClient: client.New(client.Options{BaseURL: "http://127.0.0.1:0", Timeout: time.Second, MaxRetries: 1}),
+		StationFinder: &mockStationFinder2{
+			findStationFn: func(ctx context.Context, stationID string) (*models.Station, error) {
+				return ukhoTestStation(), nil
+			},
+		},
+		PredictionCache: &mockStationService2{
+			savePredictionsBatchFn: func(ctx context.Context, records []models.TidePredictionRecord) error {
+				saved <- records
+				return nil
+			},
+		},
+		PredictionFetchers: map[models.Source]PredictionFetcher{
+			models.SourceUKHO: srv.fetcher("test-key"),
+		},
+	}
+
+	// Requests that don't name a datum get UKHO's chart datum
+	response, err := service.GetCurrentTideForStation(context.Background(), "UKHO-0014",
+		stringPtr("2026-07-01T00:00:00"), stringPtr("2026-07-01T12:00:00"), "", models.UnitsMeters)
+	require.NoError(t, err)
+
+	assert.Equal(t, models.DatumCD, response.Datum)
+	assert.Equal(t, "UKHO API", response.CalculationMethod)
+	assert.Equal(t, "Europe/London", response.TimeZone)
+	assert.Equal(t, models.ObservationStatusUnavailable, response.ObservationStatus)
+	require.Len(t, response.Extremes, 2)
+	assert.Equal(t, "2026-07-01T05:12:00", response.Extremes[0].LocalTime)
+	assert.InDelta(t, 5.21, response.Extremes[0].Height, 0.001)
+
+	// The curve is interpolated from the extremes, passing through them
+	require.Len(t, response.Predictions, 121, "every 6 minutes over 12 hours")
+	for _, p := range response.Predictions {
+		if p.LocalTime == "2026-07-01T05:12:00" {
+			assert.InDelta(t, 5.21, p.Height, 0.001)
+		}
+	}
+
+	select {
+	case records := <-saved:
+		dates := make([]string, len(records))
+		for i, r := range records {
+			dates[i] = r.Date
+			assert.Equal(t, models.StationTypeSubordinate, r.StationType, "records hold extremes only")
+			assert.NoError(t, r.Validate())
+		}
+		// The day before today has no events and isn't cached
+		assert.ElementsMatch(t, []string{"2026-07-01", "2026-07-02"}, dates)
+	case <-time.After(2 * time.Second):
+		t.Fatal("predictions were not saved to the cache")
+	}
+}
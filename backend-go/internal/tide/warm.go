@@ -0,0 +1,45 @@
+package tide
+
+import (
+	"context"
+	"fmt"
+	"github.com/bbernstein/flowebb/backend-go/internal/timezone"
+	"sync"
+	"time"
+)
+
+// MaxWarmDays is the most days of predictions a station can be warmed for,
+// as for a request's range
+const MaxWarmDays = 30
+
+// WarmPredictions fills the prediction cache with the station's predictions
+// and extremes for the given number of days from today in the station's
+// zone, relative to the datum requests that don't name one get. Days the
+// cache is missing are fetched from the station's source and stale days are
+// refreshed, as a request for them would; fresh days are left alone. It
+// returns once the records it fetched are saved, so the cache is filled when
+// a run that ends with it, like a scheduled Lambda, stops. Save failures are
+// logged, as for requests.
+//
+// Warming a station isn't counted as a request for it.
+func (s *Service) WarmPredictions(ctx context.Context, stationID string, days int) error {
+	if days < 1 || days > MaxWarmDays {
+		return NewInvalidRangeError(fmt.Sprintf("days must be between 1 and %d", MaxWarmDays))
+	}
+
+	station, err := s.StationFinder.FindStation(ctx, stationID)
+	if err != nil {
+		return fmt.Errorf("finding station: %w", err)
+	}
+
+	location := timezone.ForStation(station)
+	start := startOfDay(time.Now().In(location), location)
+	end := start.AddDate(0, 0, days-1)
+
+	// Waits only for this station's saves, which other warm-ups may be
+	// starting alongside
+	var saves sync.WaitGroup
+	_, _, _, _, err = s.getTideCurve(withBackgroundWork(ctx, &saves), station, start, end, location, s.stationDatum(station, ""))
+	saves.Wait()
+	return err
+}
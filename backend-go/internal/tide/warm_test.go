@@ -0,0 +1,226 @@
+package tide
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/internal/timezone"
+	"github.com/bbernstein/flowebb/backend-go/pkg/http/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockRequestCounter records the stations requests are counted for
+type mockRequestCounter struct {
+	mu       sync.Mutex
+	stations []string
+}
+
+func (m *mockRequestCounter) RecordRequest(ctx context.Context, stationID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stations = append(m.stations, stationID)
+	return nil
+}
+
+func (m *mockRequestCounter) counted() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.stations...)
+}
+
+// newDailyNOAAServer serves a high, a low and two predictions for each day
+// of a request's range, and records the ranges asked for
+func newDailyNOAAServer(t *testing.T) (*httptest.Server, func() []string) {
+	var mu sync.Mutex
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("product") != "predictions" {
+			_, _ = fmt.Fprint(w, `{"error":{"message":"No data was found."}}`)
+			return
+		}
+		begin, _ := time.Parse("20060102", query.Get("begin_date"))
+		end, _ := time.Parse("20060102", query.Get("end_date"))
+
+		var values []string
+		for d := begin; !d.After(end); d = d.AddDate(0, 0, 1) {
+			day := d.Format("2006-01-02")
+			if query.Get("interval") == "hilo" {
+				values = append(values,
+					fmt.Sprintf(`{"t":"%s 03:00","v":"8.0","type":"H"}`, day),
+					fmt.Sprintf(`{"t":"%s 09:00","v":"1.0","type":"L"}`, day))
+				continue
+			}
+			mu.Lock()
+			ranges = append(ranges, day)
+			mu.Unlock()
+			values = append(values,
+				fmt.Sprintf(`{"t":"%s 00:00","v":"5.0"}`, day),
+				fmt.Sprintf(`{"t":"%s 06:00","v":"4.0"}`, day))
+		}
+		_, _ = fmt.Fprintf(w, `{"predictions":[%s]}`, strings.Join(values, ","))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), ranges...)
+	}
+}
+
+func TestWarmPredictions(t *testing.T) {
+	station := createTestStation(-8 * 3600)
+	location := timezone.ForStation(station)
+	today := startOfDay(time.Now().In(location), location)
+	todayStr := today.Format("2006-01-02")
+
+	server, fetchedDays := newDailyNOAAServer(t)
+
+	var mu sync.Mutex
+	var saved []models.TidePredictionRecord
+	counter := &mockRequestCounter{}
+	service := &Service{
+		HttpClient: client.New(client.Options{BaseURL: server.URL, Timeout: 5 * time.Second, MaxRetries: 1}),
+		StationFinder: &mockStationFinder2{
+			findStationFn: func(ctx context.Context, stationID string) (*models.Station, error) {
+				return station, nil
+			},
+		},
+		PredictionCache: &mockStationService2{
+			getPredictionsFn: func(ctx context.Context, stationID string, date time.Time) (*models.TidePredictionRecord, error) {
+				// Today is already cached and fresh
+				if date.Format("2006-01-02") != todayStr {
+					return nil, nil
+				}
+				return &models.TidePredictionRecord{
+					StationID: stationID, Date: todayStr, StationType: "R",
+					Extremes: []models.TideExtreme{}, LastUpdated: time.Now().Unix(),
+				}, nil
+			},
+			savePredictionsBatchFn: func(ctx context.Context, records []models.TidePredictionRecord) error {
+				// Saved slowly, to show the warm-up waits for it
+				time.Sleep(50 * time.Millisecond)
+				mu.Lock()
+				defer mu.Unlock()
+				saved = append(saved, records...)
+				return nil
+			},
+		},
+		RequestCounter: counter,
+	}
+
+	require.NoError(t, service.WarmPredictions(context.Background(), "TEST001", 3))
+
+	mu.Lock()
+	savedDates := make([]string, len(saved))
+	for i, r := range saved {
+		savedDates[i] = r.Date
+		assert.Len(t, r.Predictions, 2, r.Date)
+		assert.Len(t, r.Extremes, 2, r.Date)
+	}
+	mu.Unlock()
+
+	wantDates := []string{today.AddDate(0, 0, 1).Format("2006-01-02"), today.AddDate(0, 0, 2).Format("2006-01-02")}
+	assert.Equal(t, wantDates, savedDates, "only the missing days are fetched, and saved before returning")
+	assert.Equal(t, wantDates, fetchedDays())
+	assert.Empty(t, counter.counted(), "warming isn't a request")
+}
+
+func TestWarmPredictions_ConcurrentStations(t *testing.T) {
+	server, _ := newDailyNOAAServer(t)
+
+	var mu sync.Mutex
+	savedDays := map[string]int{}
+	service := &Service{
+		HttpClient: client.New(client.Options{BaseURL: server.URL, Timeout: 5 * time.Second, MaxRetries: 1}),
+		StationFinder: &mockStationFinder2{
+			findStationFn: func(ctx context.Context, stationID string) (*models.Station, error) {
+				station := createTestStation(-8 * 3600)
+				station.ID = stationID
+				return station, nil
+			},
+		},
+		PredictionCache: &mockStationService2{
+			savePredictionsBatchFn: func(ctx context.Context, records []models.TidePredictionRecord) error {
+				// Stations' saves take different times, so warm-ups overlap
+				// with others' saves starting and finishing
+				var n int
+				_, _ = fmt.Sscanf(records[0].StationID, "STATION%d", &n)
+				time.Sleep(time.Duration(n) * 10 * time.Millisecond)
+				mu.Lock()
+				defer mu.Unlock()
+				savedDays[records[0].StationID] += len(records)
+				return nil
+			},
+		},
+	}
+
+	const stations = 8
+	var wg sync.WaitGroup
+	for i := 0; i < stations; i++ {
+		wg.Add(1)
+		go func(stationID string) {
+			defer wg.Done()
+			assert.NoError(t, service.WarmPredictions(context.Background(), stationID, 3))
+
+			mu.Lock()
+			defer mu.Unlock()
+			assert.Equal(t, 3, savedDays[stationID], "%s is saved before its warm-up returns", stationID)
+		}(fmt.Sprintf("STATION%d", i))
+	}
+	wg.Wait()
+}
+
+func TestWarmPredictions_Errors(t *testing.T) {
+	service := &Service{
+		StationFinder: &mockStationFinder2{
+			findStationFn: func(ctx context.Context, stationID string) (*models.Station, error) {
+				return nil, fmt.Errorf("station not found: %s", stationID)
+			},
+		},
+		PredictionCache: &mockStationService2{},
+	}
+
+	for _, days := range []int{0, MaxWarmDays + 1} {
+		var rangeErr *InvalidRangeError
+		err := service.WarmPredictions(context.Background(), "TEST001", days)
+		assert.True(t, errors.As(err, &rangeErr), "%d days", days)
+	}
+
+	err := service.WarmPredictions(context.Background(), "NOPE", 1)
+	assert.EqualError(t, err, "finding station: station not found: NOPE")
+}
+
+func TestGetCurrentTideForStation_CountsRequests(t *testing.T) {
+	server, _ := newDailyNOAAServer(t)
+	counter := &mockRequestCounter{}
+	service := &Service{
+		HttpClient: client.New(client.Options{BaseURL: server.URL, Timeout: 5 * time.Second, MaxRetries: 1}),
+		StationFinder: &mockStationFinder2{
+			findStationFn: func(ctx context.Context, stationID string) (*models.Station, error) {
+				// Namespaced IDs are counted under the station's own
+				return createTestStation(-8 * 3600), nil
+			},
+		},
+		PredictionCache: &mockStationService2{},
+		RequestCounter:  counter,
+	}
+
+	_, err := service.GetCurrentTideForStation(context.Background(), "noaa:TEST001", nil, nil, models.DatumMLLW, models.UnitsFeet)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return len(counter.counted()) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"TEST001"}, counter.counted())
+}
@@ -0,0 +1,197 @@
+package tide
+
+import (
+	"context"
+	"fmt"
+	"github.com/bbernstein/flowebb/backend-go/internal/astronomy"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/internal/timezone"
+	"github.com/rs/zerolog/log"
+	"math"
+	"time"
+)
+
+var _ WindowService = (*Service)(nil)
+
+// windowStep is the spacing of the predictions the windows are searched on
+const windowStep = int64(6 * 60 * 1000)
+
+// timeSpan is a span of time in Unix milliseconds
+type timeSpan struct {
+	start, end int64
+}
+
+// GetTideWindows returns each contiguous span of the range that the water is
+// above or below the query's threshold, with the crossing times interpolated
+// from the predictions, or from the extremes for stations that only have those.
+func (s *Service) GetTideWindows(ctx context.Context, stationID string, startTimeStr, endTimeStr *string, query models.TideWindowQuery) ([]models.TideWindow, error) {
+	log.Debug().Str("station_id", stationID).Msg("Getting tide windows for station")
+
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	units := query.Units.OrDefault()
+
+	station, err := s.StationFinder.FindStation(ctx, stationID)
+	if err != nil {
+		return nil, fmt.Errorf("finding station: %w", err)
+	}
+	datum := s.stationDatum(station, query.Datum)
+
+	location := timezone.ForStation(station)
+	now := time.Now().In(location)
+
+	startTime, endTime, err := parseTimeRange(startTimeStr, endTimeStr, now, location)
+	if err != nil {
+		return nil, err
+	}
+
+	daysDataAllowed := time.Duration(30)
+	if endTime.Sub(startTime) > daysDataAllowed*24*time.Hour {
+		return nil, NewInvalidRangeError(fmt.Sprintf("date range cannot exceed %d days", daysDataAllowed))
+	}
+
+	predictions, extremes, _, _, err := s.getTideCurve(ctx, station, startTime, endTime, location, datum)
+	if err != nil {
+		return nil, err
+	}
+
+	level := func(timestamp int64) float64 { return interpolatePredictions(predictions, timestamp) }
+	if predictions == nil {
+		level = func(timestamp int64) float64 { return interpolateExtremes(extremes, timestamp) }
+	}
+
+	// Levels are in feet, so compare against the threshold in feet too
+	threshold := models.ConvertHeight(query.Threshold, units, models.UnitsFeet)
+	meets := func(height float64) bool { return height < threshold }
+	if query.Condition == models.WindowAbove {
+		meets = func(height float64) bool { return height > threshold }
+	}
+
+	spans := findSpans(level, meets, startTime.UnixMilli(), endTime.UnixMilli())
+
+	if query.DaylightOnly {
+		var daylight []timeSpan
+		for _, p := range astronomy.DaylightPeriods(station.Latitude, station.Longitude, startTime, endTime) {
+			daylight = append(daylight, timeSpan{start: p.Start.UnixMilli(), end: p.End.UnixMilli()})
+		}
+		spans = intersectSpans(spans, daylight)
+	}
+
+	windows := make([]models.TideWindow, 0, len(spans))
+	for _, span := range spans {
+		if time.Duration(span.end-span.start)*time.Millisecond < query.MinDuration {
+			continue
+		}
+
+		peak := peakLevel(level, extremes, span, query.Condition)
+		windows = append(windows, models.TideWindow{
+			StartTimestamp:  span.start,
+			StartLocalTime:  formatLocalTime(span.start, location),
+			EndTimestamp:    span.end,
+			EndLocalTime:    formatLocalTime(span.end, location),
+			DurationMinutes: int((span.end - span.start) / 60000),
+			PeakHeight:      roundHeight(models.ConvertHeight(peak, models.UnitsFeet, units)),
+		})
+	}
+
+	return windows, nil
+}
+
+// findSpans returns the spans between start and end where the level meets the
+// condition. Spans already underway at start or still open at end are cut
+// off there.
+func findSpans(level func(int64) float64, meets func(float64) bool, start, end int64) []timeSpan {
+	var spans []timeSpan
+
+	inside := meets(level(start))
+	spanStart := start
+	for t := start; t < end; {
+		next := t + windowStep
+		if next > end {
+			next = end
+		}
+
+		if nextInside := meets(level(next)); nextInside != inside {
+			crossing := findCrossing(level, meets, t, next)
+			if nextInside {
+				spanStart = crossing
+			} else {
+				spans = append(spans, timeSpan{start: spanStart, end: crossing})
+			}
+			inside = nextInside
+		}
+		t = next
+	}
+
+	if inside {
+		spans = append(spans, timeSpan{start: spanStart, end: end})
+	}
+
+	return spans
+}
+
+// findCrossing narrows down where the condition changes between low and high
+// to the nearest second
+func findCrossing(level func(int64) float64, meets func(float64) bool, low, high int64) int64 {
+	lowMeets := meets(level(low))
+	for high-low > 1000 {
+		mid := (low + high) / 2
+		if meets(level(mid)) == lowMeets {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return int64(math.Round(float64(low+high)/2000)) * 1000
+}
+
+// intersectSpans returns the parts of spans that fall within allowed. Both
+// must be sorted and non-overlapping.
+func intersectSpans(spans, allowed []timeSpan) []timeSpan {
+	var result []timeSpan
+	i, j := 0, 0
+	for i < len(spans) && j < len(allowed) {
+		start := max(spans[i].start, allowed[j].start)
+		end := min(spans[i].end, allowed[j].end)
+		if start < end {
+			result = append(result, timeSpan{start: start, end: end})
+		}
+
+		if spans[i].end < allowed[j].end {
+			i++
+		} else {
+			j++
+		}
+	}
+	return result
+}
+
+// peakLevel returns the lowest level in the span for BELOW windows and the
+// highest for ABOVE windows
+func peakLevel(level func(int64) float64, extremes []models.TideExtreme, span timeSpan, condition models.WindowCondition) float64 {
+	peak := level(span.start)
+	better := func(h float64) bool { return h < peak }
+	if condition == models.WindowAbove {
+		better = func(h float64) bool { return h > peak }
+	}
+
+	// Sample on the prediction grid, then the end of the span
+	for t := span.start - span.start%windowStep + windowStep; t < span.end; t += windowStep {
+		if h := level(t); better(h) {
+			peak = h
+		}
+	}
+	if h := level(span.end); better(h) {
+		peak = h
+	}
+
+	// The turning points fall between samples
+	for _, e := range extremes {
+		if e.Timestamp > span.start && e.Timestamp < span.end && better(e.Height) {
+			peak = e.Height
+		}
+	}
+
+	return peak
+}
@@ -0,0 +1,249 @@
+package tide
+
+import (
+	"context"
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/bbernstein/flowebb/backend-go/pkg/http/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFindSpans(t *testing.T) {
+	t0 := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	// High water at t0, low water six hours later
+	level := func(timestamp int64) float64 {
+		hours := float64(timestamp-t0.UnixMilli()) / float64(time.Hour.Milliseconds())
+		return 5 + 4*math.Cos(2*math.Pi*hours/12)
+	}
+	at := func(hours float64) int64 {
+		return t0.Add(time.Duration(hours * float64(time.Hour))).UnixMilli()
+	}
+
+	tests := []struct {
+		name  string
+		meets func(float64) bool
+		start int64
+		end   int64
+		want  []timeSpan
+	}{
+		{
+			name:  "below",
+			meets: func(h float64) bool { return h < 3 },
+			start: at(0),
+			end:   at(24),
+			want:  []timeSpan{{start: at(4), end: at(8)}, {start: at(16), end: at(20)}},
+		},
+		{
+			name:  "above, cut off at both ends",
+			meets: func(h float64) bool { return h > 7 },
+			start: at(0),
+			end:   at(14),
+			want:  []timeSpan{{start: at(0), end: at(2)}, {start: at(10), end: at(14)}},
+		},
+		{
+			name:  "never met",
+			meets: func(h float64) bool { return h > 10 },
+			start: at(0),
+			end:   at(24),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spans := findSpans(level, tt.meets, tt.start, tt.end)
+			require.Len(t, spans, len(tt.want))
+			for i, span := range spans {
+				assert.InDelta(t, tt.want[i].start, span.start, 1000, "start of span %d", i)
+				assert.InDelta(t, tt.want[i].end, span.end, 1000, "end of span %d", i)
+			}
+		})
+	}
+}
+
+func TestIntersectSpans(t *testing.T) {
+	tests := []struct {
+		name    string
+		spans   []timeSpan
+		allowed []timeSpan
+		want    []timeSpan
+	}{
+		{
+			name:    "clipped at both ends",
+			spans:   []timeSpan{{start: 0, end: 10}},
+			allowed: []timeSpan{{start: 2, end: 8}},
+			want:    []timeSpan{{start: 2, end: 8}},
+		},
+		{
+			name:    "split by a gap",
+			spans:   []timeSpan{{start: 0, end: 10}},
+			allowed: []timeSpan{{start: 1, end: 3}, {start: 6, end: 12}},
+			want:    []timeSpan{{start: 1, end: 3}, {start: 6, end: 10}},
+		},
+		{
+			name:    "several spans in one allowed period",
+			spans:   []timeSpan{{start: 0, end: 2}, {start: 4, end: 6}, {start: 8, end: 12}},
+			allowed: []timeSpan{{start: 1, end: 9}},
+			want:    []timeSpan{{start: 1, end: 2}, {start: 4, end: 6}, {start: 8, end: 9}},
+		},
+		{
+			name:    "no overlap",
+			spans:   []timeSpan{{start: 0, end: 2}},
+			allowed: []timeSpan{{start: 2, end: 4}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, intersectSpans(tt.spans, tt.allowed))
+		})
+	}
+}
+
+func TestGetTideWindows(t *testing.T) {
+	t0 := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	refPredictions, refExtremes := referenceTide(t0, 48)
+	at := func(hours float64) int64 {
+		return t0.Add(time.Duration(hours * float64(time.Hour))).UnixMilli()
+	}
+
+	predictionCache := &mockStationService2{
+		getPredictionsFn: func(_ context.Context, stationID string, date time.Time) (*models.TidePredictionRecord, error) {
+			dayStart, dayEnd := date.UnixMilli(), date.AddDate(0, 0, 1).UnixMilli()
+			record := &models.TidePredictionRecord{
+				StationID:   stationID,
+				Date:        date.Format("2006-01-02"),
+				StationType: "R",
+			}
+			for _, p := range refPredictions {
+				if p.Timestamp >= dayStart && p.Timestamp < dayEnd {
+					record.Predictions = append(record.Predictions, p)
+				}
+			}
+			for _, e := range refExtremes {
+				if e.Timestamp >= dayStart && e.Timestamp < dayEnd {
+					record.Extremes = append(record.Extremes, e)
+				}
+			}
+			return record, nil
+		},
+	}
+
+	type window struct {
+		start, end float64 // Hours after t0
+		peak       float64
+	}
+
+	tests := []struct {
+		name    string
+		query   models.TideWindowQuery
+		want    []window
+		wantErr string
+	}{
+		{
+			name:  "below in feet",
+			query: models.TideWindowQuery{Condition: models.WindowBelow, Threshold: 3},
+			want:  []window{{start: 4, end: 8, peak: 1}, {start: 16, end: 20, peak: 1}},
+		},
+		{
+			name:  "below in meters",
+			query: models.TideWindowQuery{Condition: models.WindowBelow, Threshold: 0.9144, Units: models.UnitsMeters},
+			want:  []window{{start: 4, end: 8, peak: 0.305}, {start: 16, end: 20, peak: 0.305}},
+		},
+		{
+			name:  "above with a minimum duration",
+			query: models.TideWindowQuery{Condition: models.WindowAbove, Threshold: 7, MinDuration: 3 * time.Hour},
+			want:  []window{{start: 10, end: 14, peak: 9}},
+		},
+		{
+			// At 110W the sun sets around 03:00 UTC and rises around 11:30 UTC
+			name:  "daylight only",
+			query: models.TideWindowQuery{Condition: models.WindowBelow, Threshold: 3, DaylightOnly: true},
+			want:  []window{{start: 16, end: 20, peak: 1}},
+		},
+		{
+			name:    "invalid condition",
+			query:   models.TideWindowQuery{Condition: "BESIDE", Threshold: 3},
+			wantErr: "invalid window condition",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &Service{
+				HttpClient: &client.Client{},
+				StationFinder: &mockStationFinder2{
+					findStationFn: func(_ context.Context, _ string) (*models.Station, error) {
+						station := createTestStation(0)
+						station.Longitude = -110
+						return station, nil
+					},
+				},
+				PredictionCache: predictionCache,
+			}
+
+			start, end := "2024-06-01T00:00:00", "2024-06-01T23:59:59"
+			windows, err := service.GetTideWindows(context.Background(), "TEST001", &start, &end, tt.query)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			require.Len(t, windows, len(tt.want))
+			for i, w := range windows {
+				assert.InDelta(t, at(tt.want[i].start), w.StartTimestamp, float64(time.Minute.Milliseconds()))
+				assert.InDelta(t, at(tt.want[i].end), w.EndTimestamp, float64(time.Minute.Milliseconds()))
+				assert.Equal(t, formatLocalTime(w.StartTimestamp, time.UTC), w.StartLocalTime)
+				assert.InDelta(t, (tt.want[i].end-tt.want[i].start)*60, w.DurationMinutes, 2)
+				assert.Equal(t, tt.want[i].peak, w.PeakHeight)
+			}
+		})
+	}
+}
+
+func TestGetTideWindows_ExtremesOnly(t *testing.T) {
+	t0 := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	_, refExtremes := referenceTide(t0, 48)
+
+	subordinateType := "S"
+	service := &Service{
+		HttpClient: &client.Client{},
+		StationFinder: &mockStationFinder2{
+			findStationFn: func(_ context.Context, _ string) (*models.Station, error) {
+				station := createTestStation(0)
+				station.StationType = &subordinateType
+				return station, nil
+			},
+		},
+		PredictionCache: &mockStationService2{
+			getPredictionsFn: func(_ context.Context, stationID string, date time.Time) (*models.TidePredictionRecord, error) {
+				record := &models.TidePredictionRecord{StationID: stationID, Date: date.Format("2006-01-02"), StationType: "S"}
+				for _, e := range refExtremes {
+					if e.Timestamp >= date.UnixMilli() && e.Timestamp < date.AddDate(0, 0, 1).UnixMilli() {
+						record.Extremes = append(record.Extremes, e)
+					}
+				}
+				return record, nil
+			},
+		},
+	}
+
+	// MHHW has no subordinate offsets, so the curve comes from the extremes
+	start, end := "2024-06-01T00:00:00", "2024-06-01T23:59:59"
+	windows, err := service.GetTideWindows(context.Background(), "TEST001", &start, &end,
+		models.TideWindowQuery{Condition: models.WindowBelow, Threshold: 3, Datum: models.DatumMHHW})
+	require.NoError(t, err)
+
+	// The curve between extremes is symmetric, so the window is centered on low water
+	require.Len(t, windows, 2)
+	for i, w := range windows {
+		low := t0.Add(time.Duration(6+12*i) * time.Hour).UnixMilli()
+		assert.InDelta(t, low-w.StartTimestamp, w.EndTimestamp-low, 2000)
+		assert.Less(t, w.StartTimestamp, low)
+		assert.Equal(t, 1.0, w.PeakHeight)
+	}
+}
@@ -0,0 +1,176 @@
+// Package timezone resolves the IANA time zone of a station from its
+// position, so local times follow daylight saving time the way NOAA's
+// lst_ldt data does. The region boundaries and the zone database are both
+// embedded in the binary, so no external service or system zoneinfo is needed.
+//
+// The regions in data/zones.json are boxes drawn around the coasts with
+// stations, not real zone boundaries, down to the zones that split a state
+// such as southeast Alaska's. A station outside every box, or in a box whose
+// zone disagrees with its offset, gets a fixed Etc/GMT zone without daylight
+// saving time as a last resort, with a warning logged;
+// TestZoneName_NOAAStations lists the NOAA stations that do.
+package timezone
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/rs/zerolog/log"
+)
+
+//go:embed data/zones.json
+var zonesJSON []byte
+
+// region is one box of a zone's area
+type region struct {
+	zone                     string
+	south, west, north, east float64
+}
+
+var (
+	regions   = mustLoadRegions(zonesJSON)
+	locations sync.Map // zone name -> *time.Location
+)
+
+func mustLoadRegions(data []byte) []region {
+	var zones []struct {
+		Zone  string       `json:"zone"`
+		Boxes [][4]float64 `json:"boxes"`
+	}
+	if err := json.Unmarshal(data, &zones); err != nil {
+		panic(fmt.Sprintf("decoding time zone regions: %v", err))
+	}
+
+	var result []region
+	for _, z := range zones {
+		for _, b := range z.Boxes {
+			result = append(result, region{zone: z.Zone, south: b[0], west: b[1], north: b[2], east: b[3]})
+		}
+	}
+	return result
+}
+
+// Lookup returns the IANA zone covering the position, if any
+func Lookup(lat, lon float64) (string, bool) {
+	var best *region
+	var bestArea float64
+	for i := range regions {
+		r := &regions[i]
+		if lat < r.south || lat > r.north || lon < r.west || lon > r.east {
+			continue
+		}
+		// The smallest box is the most specific
+		area := (r.north - r.south) * (r.east - r.west)
+		if best == nil || area < bestArea {
+			best, bestArea = r, area
+		}
+	}
+	if best == nil {
+		return "", false
+	}
+	return best.zone, true
+}
+
+// ZoneName returns the IANA zone for a station at the position whose
+// standard (non-DST) offset from UTC is standardOffset seconds. When the
+// position isn't covered, or the zone found disagrees with the offset, it
+// falls back to the Etc/GMT zone for whole-hour offsets and to "" otherwise.
+func ZoneName(lat, lon float64, standardOffset int) string {
+	if name, ok := Lookup(lat, lon); ok {
+		if location := loadLocation(name); location != nil && StandardOffset(location) == standardOffset {
+			return name
+		}
+		log.Debug().
+			Str("zone", name).
+			Int("standard_offset", standardOffset).
+			Float64("lat", lat).
+			Float64("lon", lon).
+			Msg("Time zone region disagrees with station offset")
+	}
+
+	// A fixed offset has no daylight saving time, so local times are an
+	// hour off for half the year wherever the real zone observes it
+	fixed := fixedZoneName(standardOffset)
+	log.Warn().
+		Str("zone", fixed).
+		Int("standard_offset", standardOffset).
+		Float64("lat", lat).
+		Float64("lon", lon).
+		Msg("No time zone region for station, using a fixed offset")
+	return fixed
+}
+
+// Location loads the named zone, falling back to a fixed standardOffset when
+// the name is empty or unknown
+func Location(name string, standardOffset int) *time.Location {
+	if name != "" {
+		if location := loadLocation(name); location != nil {
+			return location
+		}
+	}
+	return time.FixedZone(formatOffset(standardOffset), standardOffset)
+}
+
+// ForStation returns the station's time zone. Stations cached before zone
+// names were recorded are resolved from their position.
+func ForStation(station *models.Station) *time.Location {
+	name := station.TimeZone
+	if name == "" {
+		name = ZoneName(station.Latitude, station.Longitude, station.TimeZoneOffset)
+	}
+	return Location(name, station.TimeZoneOffset)
+}
+
+// StandardOffset returns the zone's offset from UTC in seconds outside of
+// daylight saving time this year
+func StandardOffset(location *time.Location) int {
+	year := time.Now().Year()
+	_, january := time.Date(year, time.January, 1, 0, 0, 0, 0, location).Zone()
+	_, july := time.Date(year, time.July, 1, 0, 0, 0, 0, location).Zone()
+	if july < january {
+		return july
+	}
+	return january
+}
+
+func loadLocation(name string) *time.Location {
+	if location, ok := locations.Load(name); ok {
+		return location.(*time.Location)
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		log.Warn().Err(err).Str("zone", name).Msg("Unknown time zone")
+		return nil
+	}
+	locations.Store(name, location)
+	return location
+}
+
+// fixedZoneName names a whole-hour offset in the Etc area, whose signs are
+// inverted: Etc/GMT+8 is eight hours behind UTC
+func fixedZoneName(offset int) string {
+	switch {
+	case offset == 0:
+		return "Etc/GMT"
+	case offset%3600 != 0:
+		return ""
+	case offset < 0:
+		return fmt.Sprintf("Etc/GMT+%d", -offset/3600)
+	default:
+		return fmt.Sprintf("Etc/GMT-%d", offset/3600)
+	}
+}
+
+// formatOffset names a fixed zone like UTC+05:30
+func formatOffset(offset int) string {
+	sign := '+'
+	if offset < 0 {
+		sign, offset = '-', -offset
+	}
+	return fmt.Sprintf("UTC%c%02d:%02d", sign, offset/3600, offset%3600/60)
+}
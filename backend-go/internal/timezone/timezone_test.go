@@ -0,0 +1,214 @@
+package timezone
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bbernstein/flowebb/backend-go/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		name     string
+		lat, lon float64
+		want     string
+	}{
+		{"Seattle", 47.6026, -122.3393, "America/Los_Angeles"},
+		{"San Diego", 32.7142, -117.1736, "America/Los_Angeles"},
+		{"Friday Harbor", 48.5453, -123.0125, "America/Los_Angeles"},
+		{"Victoria", 48.4243, -123.3707, "America/Vancouver"},
+		{"Prince Rupert", 54.3170, -130.3240, "America/Vancouver"},
+		{"Ketchikan", 55.3317, -131.6261, "America/Sitka"},
+		{"Sitka", 57.0517, -135.3417, "America/Sitka"},
+		{"Juneau", 58.2988, -134.4117, "America/Juneau"},
+		{"Skagway", 59.4500, -135.3267, "America/Juneau"},
+		{"Metlakatla", 55.1283, -131.5783, "America/Metlakatla"},
+		{"Yakutat", 59.5483, -139.7333, "America/Yakutat"},
+		{"Nome", 64.4946, -165.4396, "America/Nome"},
+		{"Anchorage", 61.2381, -149.8900, "America/Anchorage"},
+		{"Adak Island", 51.8633, -176.6320, "America/Adak"},
+		{"Honolulu", 21.3067, -157.8670, "Pacific/Honolulu"},
+		{"Boston", 42.3539, -71.0503, "America/New_York"},
+		{"Key West", 24.5511, -81.8081, "America/New_York"},
+		{"Eastport", 44.9046, -66.9829, "America/New_York"},
+		{"Campobello Island", 44.8800, -66.9300, "America/Halifax"},
+		{"Apalachicola", 29.7244, -84.9806, "America/New_York"},
+		{"Pensacola", 30.4044, -87.2112, "America/Chicago"},
+		{"Galveston", 29.3100, -94.7933, "America/Chicago"},
+		{"San Juan", 18.4592, -66.1164, "America/Puerto_Rico"},
+		{"Bermuda", 32.3733, -64.7033, "Atlantic/Bermuda"},
+		{"Apra Harbor", 13.4389, 144.6533, "Pacific/Guam"},
+		{"Pago Pago", -14.2767, -170.6900, "Pacific/Pago_Pago"},
+		{"Halifax", 44.6667, -63.5833, "America/Halifax"},
+		{"Quebec City", 46.8167, -71.2000, "America/Toronto"},
+		{"St. John's", 47.5667, -52.7167, "America/St_Johns"},
+		{"Blanc-Sablon", 51.4300, -57.1300, "America/Blanc-Sablon"},
+		{"Moosonee", 51.2700, -80.6500, "America/Toronto"},
+		{"Kuujjuaq", 58.1000, -68.4000, "America/Toronto"},
+		{"Churchill", 58.7700, -94.1800, "America/Winnipeg"},
+		{"Iqaluit", 63.7500, -68.5200, "America/Iqaluit"},
+		{"Resolute", 74.6900, -94.8300, "America/Resolute"},
+		{"Cambridge Bay", 69.1100, -105.0600, "America/Cambridge_Bay"},
+		{"Tuktoyaktuk", 69.4400, -133.0300, "America/Inuvik"},
+		{"Liverpool", 53.4497, -3.0181, "Europe/London"},
+		{"Belfast", 54.6000, -5.9167, "Europe/London"},
+		{"Dublin", 53.3456, -6.2217, "Europe/Dublin"},
+		{"St Helier", 49.1833, -2.1167, "Europe/Jersey"},
+		{"Boulogne", 50.7270, 1.5770, "Europe/Paris"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Lookup(tt.lat, tt.lon)
+			require.True(t, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("open ocean", func(t *testing.T) {
+		_, ok := Lookup(0, -140)
+		assert.False(t, ok)
+	})
+}
+
+func TestRegionsLoad(t *testing.T) {
+	var zones []struct {
+		Zone  string       `json:"zone"`
+		Boxes [][4]float64 `json:"boxes"`
+	}
+	require.NoError(t, json.Unmarshal(zonesJSON, &zones))
+
+	for _, z := range zones {
+		t.Run(z.Zone, func(t *testing.T) {
+			_, err := time.LoadLocation(z.Zone)
+			require.NoError(t, err)
+
+			for _, b := range z.Boxes {
+				assert.Less(t, b[0], b[2], "south must be below north")
+				assert.Less(t, b[1], b[3], "west must be west of east")
+			}
+		})
+	}
+}
+
+func TestZoneName(t *testing.T) {
+	tests := []struct {
+		name           string
+		lat, lon       float64
+		standardOffset int
+		want           string
+	}{
+		{"matching offset", 47.6026, -122.3393, -8 * 3600, "America/Los_Angeles"},
+		{"offset disagrees with region", 47.6026, -122.3393, -5 * 3600, "Etc/GMT+5"},
+		{"uncovered, west of UTC", 0, -140, -9 * 3600, "Etc/GMT+9"},
+		{"uncovered, east of UTC", 0, 73, 5 * 3600, "Etc/GMT-5"},
+		{"uncovered, UTC", 0, 0, 0, "Etc/GMT"},
+		{"uncovered, half hour", 0, 80, 5*3600 + 1800, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ZoneName(tt.lat, tt.lon, tt.standardOffset))
+		})
+	}
+}
+
+// The regions are hand-drawn boxes around the coasts NOAA, CHS and UKHO
+// stations are on, not real zone boundaries. These NOAA stations fall outside
+// them and get a fixed Etc/GMT zone for their offset, which is only wrong
+// where the real zone has daylight saving time.
+var noaaFallbackStations = map[string]string{
+	"1619000": "Etc/GMT+10", // Johnston Atoll: no DST, so the same as Pacific/Honolulu
+	"9710441": "Etc/GMT+5",  // Guantanamo Bay: misses Cuba's DST, as America/Havana isn't drawn
+}
+
+// TestZoneName_NOAAStations resolves a sample of NOAA tide stations from the
+// positions and offsets in NOAA's station list, so a box that stops covering
+// one, or a new fallback, shows up here
+func TestZoneName_NOAAStations(t *testing.T) {
+	data, err := os.ReadFile("testdata/noaa_stations.json")
+	require.NoError(t, err)
+	var stations []struct {
+		ID           string  `json:"id"`
+		Name         string  `json:"name"`
+		Lat          float64 `json:"lat"`
+		Lng          float64 `json:"lng"`
+		TimezoneCorr int     `json:"timezonecorr"`
+	}
+	require.NoError(t, json.Unmarshal(data, &stations))
+
+	fellBack := make(map[string]string)
+	for _, s := range stations {
+		zone := ZoneName(s.Lat, s.Lng, s.TimezoneCorr*3600)
+		if _, ok := Lookup(s.Lat, s.Lng); !ok {
+			fellBack[s.ID] = zone
+			continue
+		}
+		assert.NotEqual(t, fixedZoneName(s.TimezoneCorr*3600), zone,
+			"%s %s is covered by a region that disagrees with its offset", s.ID, s.Name)
+	}
+	assert.Equal(t, noaaFallbackStations, fellBack)
+}
+
+func TestLocation(t *testing.T) {
+	t.Run("named zone", func(t *testing.T) {
+		location := Location("America/New_York", -5*3600)
+		assert.Equal(t, "America/New_York", location.String())
+	})
+
+	t.Run("unknown zone falls back to the offset", func(t *testing.T) {
+		location := Location("Not/AZone", 5*3600+1800)
+		assert.Equal(t, "UTC+05:30", location.String())
+		_, offset := time.Date(2024, 7, 1, 0, 0, 0, 0, location).Zone()
+		assert.Equal(t, 5*3600+1800, offset)
+	})
+
+	t.Run("no zone", func(t *testing.T) {
+		assert.Equal(t, "UTC-03:30", Location("", -3*3600-1800).String())
+	})
+}
+
+func TestForStation(t *testing.T) {
+	t.Run("recorded zone", func(t *testing.T) {
+		station := &models.Station{Latitude: 47.6026, Longitude: -122.3393, TimeZoneOffset: -8 * 3600, TimeZone: "America/Vancouver"}
+		assert.Equal(t, "America/Vancouver", ForStation(station).String())
+	})
+
+	t.Run("resolved from position", func(t *testing.T) {
+		station := &models.Station{Latitude: 47.6026, Longitude: -122.3393, TimeZoneOffset: -8 * 3600}
+		location := ForStation(station)
+		assert.Equal(t, "America/Los_Angeles", location.String())
+
+		// Daylight saving time applies in summer
+		_, winter := time.Date(2024, 1, 15, 12, 0, 0, 0, location).Zone()
+		_, summer := time.Date(2024, 7, 15, 12, 0, 0, 0, location).Zone()
+		assert.Equal(t, -8*3600, winter)
+		assert.Equal(t, -7*3600, summer)
+	})
+}
+
+func TestStandardOffset(t *testing.T) {
+	tests := []struct {
+		zone string
+		want int
+	}{
+		{"America/Los_Angeles", -8 * 3600},
+		{"America/St_Johns", -3*3600 - 1800},
+		{"Pacific/Honolulu", -10 * 3600},
+		{"Europe/London", 0},
+		{"Europe/Dublin", 0},
+		{"Pacific/Guam", 10 * 3600},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.zone, func(t *testing.T) {
+			location, err := time.LoadLocation(tt.zone)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, StandardOffset(location))
+		})
+	}
+}
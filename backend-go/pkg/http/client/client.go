@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"net/http"
+	"sync"
 	"time"
 )
 
@@ -20,6 +21,8 @@ type Client struct {
 	baseURL    string
 	httpClient *http.Client
 	maxRetries int
+	headers    map[string]string
+	limiter    *rateLimiter
 	GetFunc    func(ctx context.Context, path string) (*Response, error)
 }
 
@@ -27,6 +30,11 @@ type Options struct {
 	BaseURL    string
 	Timeout    time.Duration
 	MaxRetries int
+	// Headers are sent with every request, e.g. an API subscription key
+	Headers map[string]string
+	// RequestsPerSecond spaces requests out so no more than this many are
+	// made each second; requests are unlimited when zero
+	RequestsPerSecond float64
 }
 
 func New(opts Options) *Client {
@@ -38,13 +46,18 @@ func New(opts Options) *Client {
 		opts.MaxRetries = 3
 	}
 
-	return &Client{
+	c := &Client{
 		baseURL: opts.BaseURL,
 		httpClient: &http.Client{
 			Timeout: opts.Timeout,
 		},
 		maxRetries: opts.MaxRetries,
+		headers:    opts.Headers,
 	}
+	if opts.RequestsPerSecond > 0 {
+		c.limiter = &rateLimiter{interval: time.Duration(float64(time.Second) / opts.RequestsPerSecond)}
+	}
+	return c
 }
 
 func (c *Client) Get(ctx context.Context, path string) (*Response, error) {
@@ -59,12 +72,26 @@ func (c *Client) Get(ctx context.Context, path string) (*Response, error) {
 		fullURL = c.baseURL + path // Otherwise combine them
 	}
 
+	if c.limiter != nil {
+		if err := c.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
 	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
 	if err != nil {
 		return nil, err
 	}
+	for name, value := range c.headers {
+		req.Header.Set(name, value)
+	}
+
+	httpClient := c.httpClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -85,3 +112,35 @@ func (c *Client) Get(ctx context.Context, path string) (*Response, error) {
 		Body:       body,
 	}, nil
 }
+
+// rateLimiter spaces requests at least interval apart
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time // Earliest time the next request can be made
+}
+
+// wait blocks until a request can be made, or the context is done
+func (l *rateLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := at.Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -142,6 +142,64 @@ func TestTimeout(t *testing.T) {
 	assert.Contains(t, err.Error(), "context deadline exceeded")
 }
 
+func TestHeaders(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+
+	resp, err := New(Options{BaseURL: server.URL}).Get(ctx, "/test")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp, err = New(Options{
+		BaseURL: server.URL,
+		Headers: map[string]string{"X-Api-Key": "secret"},
+	}).Get(ctx, "/test")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestRateLimit(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := New(Options{
+		BaseURL:           server.URL,
+		RequestsPerSecond: 20,
+	})
+
+	// The first request goes straight away, the rest 50ms apart
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		_, err := client.Get(context.Background(), "/test")
+		require.NoError(t, err)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+
+	// Waiting for a turn gives up with the context
+	slow := New(Options{BaseURL: server.URL, RequestsPerSecond: 0.1})
+	_, err := slow.Get(context.Background(), "/test")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = slow.Get(ctx, "/test")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
 func BenchmarkHTTPClient(b *testing.B) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -15,7 +15,7 @@ type Station = {
     latitude: number;
     longitude: number;
     source: "NOAA" | "UKHO" | "CHS";
-    capabilities: Array<"WATER_LEVEL" | "TIDAL_CURRENTS" | "WATER_TEMPERATURE" | "AIR_TEMPERATURE" | "WIND">;
+    capabilities: Array<"WATER_LEVEL" | "TIDAL_CURRENTS" | "AIR_PRESSURE" | "WATER_TEMPERATURE" | "AIR_TEMPERATURE" | "WIND">;
 };
 
 type MapProps = {
@@ -73,7 +73,7 @@ export function TideInfo({ stationId, timeZoneOffsetSeconds }: TideInfoProps) {
                 <Box display="flex" justifyContent="space-between" alignItems="flex-start" mb={ 2 }>
                     <Typography variant="body2" color="text.secondary">Current</Typography>
                     <Typography variant="subtitle1">
-                        { tideData.waterLevel.toFixed(2) } ft
+                        { (tideData.waterLevel ?? tideData.predictedLevel).toFixed(2) } ft
                     </Typography>
                     <Chip
                         label={ tideData.tideType }
@@ -19,7 +19,7 @@ export interface TidePrediction {
 export interface TideData {
     timestamp: number;
     localTime: string;
-    waterLevel: number;
+    waterLevel: number | null;
     predictedLevel: number;
     nearestStation: string;
     location: string | null;
@@ -36,6 +36,7 @@ const TIDE_QUERY = gql`
         tides(stationId: $stationId, startDateTime: $startDateTime, endDateTime: $endDateTime) {
             localTime
             waterLevel
+            predictedLevel
             nearestStation
             tideType
             timeZoneOffsetSeconds
@@ -3,6 +3,7 @@
 export type StationType =
     | "WATER_LEVEL"
     | "TIDAL_CURRENTS"
+    | "AIR_PRESSURE"
     | "WATER_TEMPERATURE"
     | "AIR_TEMPERATURE"
     | "WIND";
@@ -20,6 +21,7 @@ export interface Station {
     source: StationSource;
     capabilities: StationType[];
     timeZoneOffset: number;
+    timeZone?: string;
     level?: string;
     stationType?: string;
 }
@@ -116,6 +116,28 @@ resource "aws_dynamodb_table" "tide_predictions_cache" {
   }
 }
 
+# Requests per station, for warming the prediction cache for popular stations
+resource "aws_dynamodb_table" "station_request_counts" {
+  name         = "station-request-counts"
+  billing_mode = "PAY_PER_REQUEST"
+  hash_key     = "stationId"
+
+  attribute {
+    name = "stationId"
+    type = "S"
+  }
+
+  ttl {
+    attribute_name = "ttl"
+    enabled        = true
+  }
+
+  tags = {
+    Name        = "station-request-counts"
+    Environment = var.environment
+  }
+}
+
 # Add S3 bucket for CloudFront logs
 resource "aws_s3_bucket" "cloudfront_logs" {
   bucket = "${var.project_name}-cloudfront-logs-${var.environment}"
@@ -27,6 +27,7 @@ output "dynamodb_table_arns" {
   description = "ARNs of all DynamoDB tables"
   value = [
     aws_dynamodb_table.stations_cache.arn,
-    aws_dynamodb_table.tide_predictions_cache.arn
+    aws_dynamodb_table.tide_predictions_cache.arn,
+    aws_dynamodb_table.station_request_counts.arn
   ]
 }
@@ -8,6 +8,7 @@ aws dynamodb list-tables --endpoint-url http://localhost:8000
 # Delete each table
 aws dynamodb delete-table --table-name stations-cache --endpoint-url http://localhost:8000
 aws dynamodb delete-table --table-name tide-predictions-cache --endpoint-url http://localhost:8000
+aws dynamodb delete-table --table-name station-request-counts --endpoint-url http://localhost:8000
 
 # Recreate the tables
 ./scripts/init-local-dynamo.sh
@@ -0,0 +1,54 @@
+#!/bin/bash
+#
+# Downloads a NOAA station's harmonic constituents for the embedded harmonic
+# predictor, along with a month of NOAA's published highs and lows to check
+# its predictions against.
+#
+# Usage: ./scripts/fetch-harmonics.sh <stationId> [yyyymm]
+#
+# Writes backend-go/internal/harmonics/data/<stationId>.json and
+# backend-go/internal/harmonics/testdata/noaa/<stationId>_hilo.json.
+# The month defaults to January of the current year.
+
+set -euo pipefail
+
+STATION_ID=${1:?Usage: $0 <stationId> [yyyymm]}
+MONTH=${2:-$(date -u +%Y)01}
+
+MDAPI="https://api.tidesandcurrents.noaa.gov/mdapi/prod/webapi/stations/${STATION_ID}"
+DATAGETTER="https://api.tidesandcurrents.noaa.gov/api/prod/datagetter"
+HARMONICS_DIR="backend-go/internal/harmonics"
+
+mkdir -p "${HARMONICS_DIR}/data" "${HARMONICS_DIR}/testdata/noaa"
+
+TMP=$(mktemp -d)
+trap 'rm -rf "${TMP}"' EXIT
+
+curl -sSf "${MDAPI}.json" -o "${TMP}/station.json"
+curl -sSf "${MDAPI}/harcon.json?units=english" -o "${TMP}/harcon.json"
+curl -sSf "${MDAPI}/datums.json?units=english" -o "${TMP}/datums.json"
+
+# MSL above MLLW is the predictor's mean level, so its heights are referenced
+# to MLLW like NOAA's
+jq -n \
+  --arg id "${STATION_ID}" \
+  --slurpfile station "${TMP}/station.json" \
+  --slurpfile harcon "${TMP}/harcon.json" \
+  --slurpfile datums "${TMP}/datums.json" \
+  '($datums[0].datums | map({(.name): .value}) | add) as $d
+   | {
+       stationId: $id,
+       name: $station[0].stations[0].name,
+       units: "feet",
+       datum: "MLLW",
+       msl: (($d.MSL - $d.MLLW) * 1000 | round / 1000),
+       HarmonicConstituents: [$harcon[0].HarmonicConstituents[]
+         | select(.amplitude > 0)
+         | {name, amplitude, phase_GMT, speed}]
+     }' > "${HARMONICS_DIR}/data/${STATION_ID}.json"
+
+LAST_DAY=$(date -u -d "${MONTH}01 +1 month -1 day" +%Y%m%d)
+curl -sSf "${DATAGETTER}?product=predictions&interval=hilo&datum=MLLW&units=english&time_zone=gmt&format=json&application=flowebb&station=${STATION_ID}&begin_date=${MONTH}01&end_date=${LAST_DAY}" |
+  jq '{predictions}' > "${HARMONICS_DIR}/testdata/noaa/${STATION_ID}_hilo.json"
+
+echo "Wrote ${HARMONICS_DIR}/data/${STATION_ID}.json and testdata/noaa/${STATION_ID}_hilo.json"
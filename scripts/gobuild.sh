@@ -30,6 +30,8 @@ rm -rf .aws-sam/build
 mkdir -p ../.aws-sam/build/GraphQLFunction/
 mkdir -p ../.aws-sam/build/StationsFunction/
 mkdir -p ../.aws-sam/build/TidesFunction/
+mkdir -p ../.aws-sam/build/CurrentsFunction/
+mkdir -p ../.aws-sam/build/WarmFunction/
 
 # Build the Lambda functions
 echo "Building graphql function..."
@@ -43,6 +45,14 @@ GOOS=linux GOARCH=arm64 CGO_ENABLED=0 go build -o ../.aws-sam/build/StationsFunc
 echo "Building tides function..."
 GOOS=linux GOARCH=arm64 CGO_ENABLED=0 go build -o ../.aws-sam/build/TidesFunction/bootstrap ./cmd/tides
 
+# Build the currents Lambda
+echo "Building currents function..."
+GOOS=linux GOARCH=arm64 CGO_ENABLED=0 go build -o ../.aws-sam/build/CurrentsFunction/bootstrap ./cmd/currents
+
+# Build the cache warm-up Lambda
+echo "Building warm function..."
+GOOS=linux GOARCH=arm64 CGO_ENABLED=0 go build -o ../.aws-sam/build/WarmFunction/bootstrap ./cmd/warm
+
 cd "$ROOT_DIR"
 
 # Verify builds
@@ -57,8 +67,20 @@ if [ ! -x .aws-sam/build/TidesFunction/bootstrap ]; then
     exit 1
 fi
 
+if [ ! -x .aws-sam/build/CurrentsFunction/bootstrap ]; then
+    echo "Error: CurrentsFunction bootstrap not found or not executable"
+    exit 1
+fi
+
+if [ ! -x .aws-sam/build/WarmFunction/bootstrap ]; then
+    echo "Error: WarmFunction bootstrap not found or not executable"
+    exit 1
+fi
+
 # Make sure binaries are executable
 chmod +x .aws-sam/build/StationsFunction/bootstrap
 chmod +x .aws-sam/build/TidesFunction/bootstrap
+chmod +x .aws-sam/build/CurrentsFunction/bootstrap
+chmod +x .aws-sam/build/WarmFunction/bootstrap
 
 echo "Build complete!"
@@ -5,26 +5,46 @@ export PAGER=""
 # check if dynamodb table tide-prediction-cache exists
 if aws dynamodb describe-table --table-name tide-predictions-cache --endpoint-url http://localhost:8000 > /dev/null 2>&1; then
     echo "Table tide-predictions-cache already exists. Skipping table creation."
-    exit 0
+else
+    # Create tide predictions cache table with composite key
+    aws dynamodb create-table \
+        --table-name tide-predictions-cache \
+        --attribute-definitions \
+            AttributeName=stationId,AttributeType=S \
+            AttributeName=date,AttributeType=S \
+        --key-schema \
+            AttributeName=stationId,KeyType=HASH \
+            AttributeName=date,KeyType=RANGE \
+        --provisioned-throughput \
+            ReadCapacityUnits=5,WriteCapacityUnits=5 \
+        --endpoint-url http://localhost:8000
+
+    aws dynamodb update-time-to-live \
+        --table-name tide-predictions-cache \
+        --time-to-live-specification "Enabled=true, AttributeName=ttl" \
+        --endpoint-url http://localhost:8000
 fi
 
-# Create tide predictions cache table with composite key
-aws dynamodb create-table \
-    --table-name tide-predictions-cache \
-    --attribute-definitions \
-        AttributeName=stationId,AttributeType=S \
-        AttributeName=date,AttributeType=S \
-    --key-schema \
-        AttributeName=stationId,KeyType=HASH \
-        AttributeName=date,KeyType=RANGE \
-    --provisioned-throughput \
-        ReadCapacityUnits=5,WriteCapacityUnits=5 \
-    --endpoint-url http://localhost:8000
+# check if dynamodb table station-request-counts exists
+if aws dynamodb describe-table --table-name station-request-counts --endpoint-url http://localhost:8000 > /dev/null 2>&1; then
+    echo "Table station-request-counts already exists. Skipping table creation."
+else
+    # Create request counts table, one item per station
+    aws dynamodb create-table \
+        --table-name station-request-counts \
+        --attribute-definitions \
+            AttributeName=stationId,AttributeType=S \
+        --key-schema \
+            AttributeName=stationId,KeyType=HASH \
+        --provisioned-throughput \
+            ReadCapacityUnits=5,WriteCapacityUnits=5 \
+        --endpoint-url http://localhost:8000
 
-aws dynamodb update-time-to-live \
-    --table-name tide-predictions-cache \
-    --time-to-live-specification "Enabled=true, AttributeName=ttl" \
-    --endpoint-url http://localhost:8000
+    aws dynamodb update-time-to-live \
+        --table-name station-request-counts \
+        --time-to-live-specification "Enabled=true, AttributeName=ttl" \
+        --endpoint-url http://localhost:8000
+fi
 
 echo "Tables created successfully!"
 